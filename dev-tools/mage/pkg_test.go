@@ -5,6 +5,8 @@
 package mage
 
 import (
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -107,3 +109,23 @@ func TestLoadSpecs(t *testing.T) {
 		}
 	}
 }
+
+func TestChecksumsOf(t *testing.T) {
+	dir := t.TempDir()
+	downloads := filepath.Join(dir, "downloads")
+	assert.NoError(t, os.MkdirAll(downloads, 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "brewbeat"), []byte("binary"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(downloads, "module.tar.gz"), []byte("module"), 0644))
+
+	content, err := checksumsOf(map[string]PackageFile{
+		"data/brewbeat-abcdef/brewbeat":  {Source: filepath.Join(dir, "brewbeat")},
+		"data/brewbeat-abcdef/downloads": {Source: downloads},
+		"data/brewbeat-abcdef/missing":   {Source: filepath.Join(dir, "missing"), SkipOnMissing: true},
+		"README.txt":                     {Source: filepath.Join(dir, "brewbeat")},
+	}, "data/brewbeat-abcdef")
+	assert.NoError(t, err)
+	assert.Equal(t,
+		"9a3a45d01531a20e89ac6ae10b0b0beb0492acd7216a368aa062d1a5fecaf9cd  brewbeat\n"+
+			"120970d812836f19888625587a4606a5ad23cef31c8684e601771552548fc6b9  downloads/module.tar.gz\n",
+		string(content))
+}
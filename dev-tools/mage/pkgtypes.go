@@ -9,6 +9,8 @@ import (
 	"archive/zip"
 	"bytes"
 	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -17,6 +19,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -96,6 +99,7 @@ type PackageFile struct {
 	Owner         string                  `yaml:"owner,omitempty"`           // File Owner, for user and group name (rpm only).
 	SkipOnMissing bool                    `yaml:"skip_on_missing,omitempty"` // Prevents build failure if the file is missing.
 	Symlink       bool                    `yaml:"symlink"`                   // Symlink marks file as a symlink pointing from target to source.
+	Checksums     bool                    `yaml:"checksums,omitempty"`       // Checksums generates the file as the SHA256 manifest of the files in its directory.
 }
 
 // OSArchNames defines the names of architectures for use in packages.
@@ -389,6 +393,7 @@ func (s PackageSpec) Evaluate(args ...map[string]interface{}) PackageSpec {
 	s.evalContext["PackageDir"] = s.packageDir
 
 	evaluatedFiles := make(map[string]PackageFile, len(s.Files))
+	var checksums []PackageFile
 	for target, f := range s.Files {
 		// Execute the dependency if it exists.
 		if f.Dep != nil {
@@ -404,6 +409,10 @@ func (s PackageSpec) Evaluate(args ...map[string]interface{}) PackageSpec {
 
 		// Expand templates.
 		switch {
+		case f.Checksums:
+			// generated once every other file is evaluated
+			checksums = append(checksums, f)
+			continue
 		case f.Source != "":
 		case f.Content != "":
 			content, err := s.Expand(f.Content)
@@ -427,6 +436,19 @@ func (s PackageSpec) Evaluate(args ...map[string]interface{}) PackageSpec {
 
 		evaluatedFiles[f.Target] = f
 	}
+	for _, f := range checksums {
+		content, err := checksumsOf(evaluatedFiles, filepath.Dir(f.Target))
+		if err != nil {
+			panic(errors.Wrapf(err, "failed to generate checksums for target=%v", f.Target))
+		}
+		f.Source = filepath.Join(s.packageDir, filepath.Base(filepath.Dir(f.Target)), filepath.Base(f.Target))
+		//nolint:gosec,G306 // 0644 is fine.
+		if err = ioutil.WriteFile(CreateDir(f.Source), content, 0644); err != nil {
+			panic(errors.Wrapf(err, "failed to write checksums for target=%v", f.Target))
+		}
+		evaluatedFiles[f.Target] = f
+	}
+
 	// Replace the map instead of modifying the source.
 	s.Files = evaluatedFiles
 
@@ -994,3 +1016,68 @@ func PackageDocker(spec PackageSpec) error {
 	}
 	return b.Build()
 }
+
+// checksumsOf returns the SHA256 manifest of the evaluated files placed in the directory dir of the
+// package, in the format of sha256sum with the paths relative to dir.
+func checksumsOf(files map[string]PackageFile, dir string) ([]byte, error) {
+	sums := map[string]string{}
+	for target, f := range files {
+		rel, err := filepath.Rel(dir, target)
+		if err != nil || f.Symlink || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		err = filepath.Walk(f.Source, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+			name := rel
+			if path != f.Source {
+				sub, err := filepath.Rel(f.Source, path)
+				if err != nil {
+					return err
+				}
+				name = filepath.Join(rel, sub)
+			}
+			sum, err := sha256File(path)
+			if err != nil {
+				return err
+			}
+			sums[filepath.ToSlash(name)] = sum
+			return nil
+		})
+		if f.SkipOnMissing && os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	names := make([]string, 0, len(sums))
+	for name := range sums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var buf bytes.Buffer
+	for _, name := range names {
+		fmt.Fprintf(&buf, "%s  %s\n", sums[name], name)
+	}
+	return buf.Bytes(), nil
+}
+
+func sha256File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
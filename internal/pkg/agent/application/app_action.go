@@ -0,0 +1,49 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/pipeline/actions/handlers"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/configuration"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/storage/store"
+	"github.com/elastic/elastic-agent/internal/pkg/fleetapi"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+	"github.com/elastic/elastic-agent/pkg/core/server"
+)
+
+// newAuditLog creates the action audit log, nil when it is disabled.
+func newAuditLog(log *logger.Logger, cfg *configuration.SettingsConfig) (*store.AuditLog, error) {
+	if cfg.ActionAudit == nil || !cfg.ActionAudit.Enabled {
+		return nil, nil
+	}
+	return store.NewAuditLog(log, paths.AgentActionAuditFile(), cfg.ActionAudit)
+}
+
+// performAppAction performs an application action requested through the control socket
+// and records its outcome in the audit log when there is one.
+func performAppAction(log *logger.Logger, srv *server.Server, auditLog *store.AuditLog, action *fleetapi.ActionApp) {
+	if err := handlers.PerformAppAction(log, srv, action); err != nil {
+		// nothing is acked to Fleet for a local action, the error is recorded in the action
+		action.StartedAt = time.Now().UTC().Format(time.RFC3339Nano)
+		action.CompletedAt = action.StartedAt
+		action.Error = err.Error()
+	}
+	if auditLog == nil {
+		return
+	}
+
+	result := store.AuditResultSuccess
+	if action.Error != "" {
+		result = store.AuditResultFailure
+	}
+	entry := store.NewAuditEntry(store.WithAuditSource(context.Background(), store.AuditSourceLocal), action, result)
+	entry.InputType = action.InputType
+	entry.Error = action.Error
+	auditLog.Audit(entry)
+}
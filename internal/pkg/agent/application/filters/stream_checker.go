@@ -5,6 +5,8 @@
 package filters
 
 import (
+	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
@@ -13,16 +15,268 @@ import (
 )
 
 // ErrInvalidNamespace is error returned when namespace value provided is invalid.
-var ErrInvalidNamespace = errors.New("provided namespace is invalid", errors.TypeConfig)
+var ErrInvalidNamespace = newStreamError("provided namespace is invalid")
 
 // ErrInvalidDataset is error returned when datastream name value provided is invalid.
-var ErrInvalidDataset = errors.New("provided datastream dataset is invalid", errors.TypeConfig)
+var ErrInvalidDataset = newStreamError("provided datastream dataset is invalid")
 
 // ErrInvalidIndex occurs when concatenation of {data_stream.type}-{data_stream.dataset}-{data_stream.namespace} does not meet index criteria.
-var ErrInvalidIndex = errors.New("provided combination of type, datastream dataset and namespace is invalid", errors.TypeConfig)
+var ErrInvalidIndex = newStreamError("provided combination of type, datastream dataset and namespace is invalid")
+
+// streamError is the kind of a violation, agent errors are not comparable so the kinds are
+// pointers to them.
+type streamError struct {
+	err errors.Error
+}
+
+func newStreamError(msg string) error {
+	return &streamError{err: errors.New(msg, errors.TypeConfig).(errors.Error)}
+}
+
+func (e *streamError) Error() string                { return e.err.Error() }
+func (e *streamError) Type() errors.ErrorType       { return e.err.Type() }
+func (e *streamError) ReadableType() string         { return e.err.ReadableType() }
+func (e *streamError) Meta() map[string]interface{} { return e.err.Meta() }
+
+const (
+	namespaceField = "namespace"
+	datasetField   = "dataset"
+	typeField      = "type"
+)
+
+// FieldRules are the rules a data stream field must follow on top of the Elasticsearch index
+// naming constraints.
+type FieldRules struct {
+	// Allow is a list of regular expressions, when set the value must match one of them.
+	Allow []string `config:"allow" yaml:"allow,omitempty" json:"allow,omitempty"`
+	// Deny is a list of regular expressions the value must not match.
+	Deny []string `config:"deny" yaml:"deny,omitempty" json:"deny,omitempty"`
+	// Reserved is a list of values that cannot be used.
+	Reserved []string `config:"reserved" yaml:"reserved,omitempty" json:"reserved,omitempty"`
+}
+
+// StreamOverride replaces the rules of the inputs matching its input ID and input type, an
+// empty ID or type matches every input.
+type StreamOverride struct {
+	InputID   string      `config:"input_id" yaml:"input_id,omitempty" json:"input_id,omitempty"`
+	InputType string      `config:"input_type" yaml:"input_type,omitempty" json:"input_type,omitempty"`
+	Namespace *FieldRules `config:"namespace" yaml:"namespace,omitempty" json:"namespace,omitempty"`
+	Dataset   *FieldRules `config:"dataset" yaml:"dataset,omitempty" json:"dataset,omitempty"`
+	Type      *FieldRules `config:"type" yaml:"type,omitempty" json:"type,omitempty"`
+}
+
+// StreamCheckerConfig is the naming policy of the data streams of the inputs.
+type StreamCheckerConfig struct {
+	Namespace FieldRules       `config:"namespace" yaml:"namespace" json:"namespace"`
+	Dataset   FieldRules       `config:"dataset" yaml:"dataset" json:"dataset"`
+	Type      FieldRules       `config:"type" yaml:"type" json:"type"`
+	Overrides []StreamOverride `config:"overrides" yaml:"overrides,omitempty" json:"overrides,omitempty"`
+}
+
+// DefaultStreamCheckerConfig creates a config only enforcing the Elasticsearch index naming constraints.
+func DefaultStreamCheckerConfig() *StreamCheckerConfig {
+	return &StreamCheckerConfig{}
+}
+
+// Violation is a data stream field of an input breaking the naming policy.
+type Violation struct {
+	// Index is the position of the input in the inputs list.
+	Index     int
+	InputID   string
+	InputType string
+	Field     string
+	Value     string
+	Reason    string
+
+	err error
+}
+
+func (v Violation) String() string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "input %d", v.Index)
+	switch {
+	case v.InputID != "" && v.InputType != "":
+		fmt.Fprintf(&s, " (id: %s, type: %s)", v.InputID, v.InputType)
+	case v.InputID != "":
+		fmt.Fprintf(&s, " (id: %s)", v.InputID)
+	case v.InputType != "":
+		fmt.Fprintf(&s, " (type: %s)", v.InputType)
+	}
+	fmt.Fprintf(&s, ": %s %q %s", v.Field, v.Value, v.Reason)
+	return s.String()
+}
+
+// StreamCheckError is returned when inputs break the naming policy, it lists every violation.
+type StreamCheckError struct {
+	Violations []Violation
+}
+
+func (e *StreamCheckError) Error() string {
+	msgs := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		msgs = append(msgs, v.String())
+	}
+	return fmt.Sprintf("%d data stream violation(s): %s", len(e.Violations), strings.Join(msgs, "; "))
+}
+
+// Is returns true when target is the error of one of the violations, e.g. ErrInvalidNamespace.
+func (e *StreamCheckError) Is(target error) bool {
+	for _, v := range e.Violations {
+		if v.err == target {
+			return true
+		}
+	}
+	return false
+}
+
+type fieldMatcher struct {
+	allow    []*regexp.Regexp
+	deny     []*regexp.Regexp
+	reserved map[string]struct{}
+}
+
+func newFieldMatcher(field string, rules FieldRules) (*fieldMatcher, error) {
+	m := &fieldMatcher{reserved: make(map[string]struct{}, len(rules.Reserved))}
+	for _, p := range rules.Allow {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, errors.New(err, fmt.Sprintf("invalid allow pattern %q for data stream %s", p, field), errors.TypeConfig)
+		}
+		m.allow = append(m.allow, re)
+	}
+	for _, p := range rules.Deny {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, errors.New(err, fmt.Sprintf("invalid deny pattern %q for data stream %s", p, field), errors.TypeConfig)
+		}
+		m.deny = append(m.deny, re)
+	}
+	for _, r := range rules.Reserved {
+		m.reserved[r] = struct{}{}
+	}
+	return m, nil
+}
+
+// check returns the reason the value breaks the rules, empty when it follows them.
+func (m *fieldMatcher) check(value string) string {
+	if _, ok := m.reserved[value]; ok {
+		return "is reserved"
+	}
+	for _, re := range m.deny {
+		if re.MatchString(value) {
+			return fmt.Sprintf("matches denied pattern %q", re.String())
+		}
+	}
+	if len(m.allow) == 0 {
+		return ""
+	}
+	for _, re := range m.allow {
+		if re.MatchString(value) {
+			return ""
+		}
+	}
+	patterns := make([]string, 0, len(m.allow))
+	for _, re := range m.allow {
+		patterns = append(patterns, fmt.Sprintf("%q", re.String()))
+	}
+	return fmt.Sprintf("does not match any allowed pattern [%s]", strings.Join(patterns, ", "))
+}
+
+type fieldMatchers struct {
+	namespace *fieldMatcher
+	dataset   *fieldMatcher
+	dsType    *fieldMatcher
+}
+
+type streamOverride struct {
+	inputID   string
+	inputType string
+	matchers  fieldMatchers
+}
+
+type streamChecker struct {
+	matchers  fieldMatchers
+	overrides []streamOverride
+}
+
+var defaultStreamChecker = &streamChecker{
+	matchers: fieldMatchers{
+		namespace: &fieldMatcher{},
+		dataset:   &fieldMatcher{},
+		dsType:    &fieldMatcher{},
+	},
+}
+
+// NewStreamChecker creates a filter checking the data streams of the inputs against the
+// Elasticsearch index naming constraints and the rules of the config.
+func NewStreamChecker(cfg *StreamCheckerConfig) (func(*logger.Logger, *transpiler.AST) error, error) {
+	if cfg == nil {
+		return StreamChecker, nil
+	}
+
+	c := &streamChecker{}
+	var err error
+	if c.matchers, err = newFieldMatchers(cfg.Namespace, cfg.Dataset, cfg.Type, fieldMatchers{}); err != nil {
+		return nil, err
+	}
+
+	for _, o := range cfg.Overrides {
+		if o.InputID == "" && o.InputType == "" {
+			return nil, errors.New("data stream override requires an input_id or an input_type", errors.TypeConfig)
+		}
+		var namespace, dataset, dsType FieldRules
+		fallback := c.matchers
+		if o.Namespace != nil {
+			namespace, fallback.namespace = *o.Namespace, nil
+		}
+		if o.Dataset != nil {
+			dataset, fallback.dataset = *o.Dataset, nil
+		}
+		if o.Type != nil {
+			dsType, fallback.dsType = *o.Type, nil
+		}
+		matchers, err := newFieldMatchers(namespace, dataset, dsType, fallback)
+		if err != nil {
+			return nil, err
+		}
+		c.overrides = append(c.overrides, streamOverride{
+			inputID:   o.InputID,
+			inputType: o.InputType,
+			matchers:  matchers,
+		})
+	}
+
+	return c.check, nil
+}
+
+// newFieldMatchers compiles the rules of the fields not already set in base.
+func newFieldMatchers(namespace, dataset, dsType FieldRules, base fieldMatchers) (fieldMatchers, error) {
+	var err error
+	if base.namespace == nil {
+		if base.namespace, err = newFieldMatcher(namespaceField, namespace); err != nil {
+			return base, err
+		}
+	}
+	if base.dataset == nil {
+		if base.dataset, err = newFieldMatcher(datasetField, dataset); err != nil {
+			return base, err
+		}
+	}
+	if base.dsType == nil {
+		if base.dsType, err = newFieldMatcher(typeField, dsType); err != nil {
+			return base, err
+		}
+	}
+	return base, nil
+}
 
 // StreamChecker checks for invalid values in stream namespace and dataset.
 func StreamChecker(log *logger.Logger, ast *transpiler.AST) error {
+	return defaultStreamChecker.check(log, ast)
+}
+
+// check checks every input and returns a StreamCheckError listing all the violations.
+func (c *streamChecker) check(_ *logger.Logger, ast *transpiler.AST) error {
 	inputsNode, found := transpiler.Lookup(ast, "inputs")
 	if !found {
 		return nil
@@ -38,116 +292,149 @@ func StreamChecker(log *logger.Logger, ast *transpiler.AST) error {
 		return errors.New("inputs is not a list", errors.TypeConfig)
 	}
 
-	for _, inputNode := range inputsNodeListCollection {
-		namespace := "default"
-		datasetName := "generic"
+	var violations []Violation
+	for idx, inputNode := range inputsNodeListCollection {
+		inputID, _ := stringValue(inputNode, "id")
+		inputType, _ := stringValue(inputNode, "type")
+		matchers := c.matchersFor(inputID, inputType)
+
+		violation := func(field, value, reason string, err error) {
+			violations = append(violations, Violation{
+				Index:     idx,
+				InputID:   inputID,
+				InputType: inputType,
+				Field:     field,
+				Value:     value,
+				Reason:    reason,
+				err:       err,
+			})
+		}
+
 		// fail only if data_stream.namespace or data_stream[namespace] is found and invalid
 		// not provided values are ok and will be fixed by rules
-		if nsNode, found := inputNode.Find("data_stream.namespace"); found {
-			nsKey, ok := nsNode.(*transpiler.Key)
-			if ok {
-				namespace = nsKey.Value().(transpiler.Node).String()
-			}
-		} else {
-			dsNode, found := inputNode.Find("data_stream")
-			if found {
-				// got a datastream
-				datasetMap, ok := dsNode.Value().(*transpiler.Dict)
-				if ok {
-					nsNode, found := datasetMap.Find("namespace")
-					if found {
-						nsKey, ok := nsNode.(*transpiler.Key)
-						if ok {
-							namespace = nsKey.Value().(transpiler.Node).String()
-						}
-					}
-				}
-			}
+		namespace, ok := dataStreamValue(inputNode, namespaceField)
+		if !ok {
+			namespace = "default"
 		}
-
 		if !matchesNamespaceContraints(namespace) {
-			return ErrInvalidNamespace
+			violation(namespaceField, namespace, "does not meet the Elasticsearch index naming constraints", ErrInvalidNamespace)
+		} else if reason := matchers.namespace.check(namespace); reason != "" {
+			violation(namespaceField, namespace, reason, ErrInvalidNamespace)
 		}
 
 		// get the type, longest type for now is metrics
-		datasetType := "metrics"
-		if nsNode, found := inputNode.Find("data_stream.type"); found {
-			nsKey, ok := nsNode.(*transpiler.Key)
-			if ok {
-				newDataset := nsKey.Value().(transpiler.Node).String()
-				datasetType = newDataset
-			}
-		} else {
-			dsNode, found := inputNode.Find("data_stream")
-			if found {
-				// got a dataset
-				datasetMap, ok := dsNode.Value().(*transpiler.Dict)
-				if ok {
-					nsNode, found := datasetMap.Find("type")
-					if found {
-						nsKey, ok := nsNode.(*transpiler.Key)
-						if ok {
-							newDataset := nsKey.Value().(transpiler.Node).String()
-							datasetType = newDataset
-						}
-					}
-				}
-			}
+		datasetType, ok := dataStreamValue(inputNode, typeField)
+		if !ok {
+			datasetType = "metrics"
 		}
-
 		if !matchesTypeConstraints(datasetType) {
-			return ErrInvalidIndex
-		}
-
-		streamsNode, ok := inputNode.Find("streams")
-		if ok {
-			streamsList, ok := streamsNode.Value().(*transpiler.List)
-			if ok {
-				streamNodes, ok := streamsList.Value().([]transpiler.Node)
-				if !ok {
-					return errors.New("streams is not a list", errors.TypeConfig)
-				}
-
-				for _, streamNode := range streamNodes {
-					streamMap, ok := streamNode.(*transpiler.Dict)
-					if !ok {
-						continue
-					}
-
-					// fix this only if in compact form
-					if dsNameNode, found := streamMap.Find("data_stream.dataset"); found {
-						dsKey, ok := dsNameNode.(*transpiler.Key)
-						if ok {
-							datasetName = dsKey.Value().(transpiler.Node).String()
-							break
-						}
-					} else {
-						datasetNode, found := streamMap.Find("data_stream")
-						if found {
-							datasetMap, ok := datasetNode.Value().(*transpiler.Dict)
-							if !ok {
-								continue
-							}
-
-							dsNameNode, found := datasetMap.Find("dataset")
-							if found {
-								dsKey, ok := dsNameNode.(*transpiler.Key)
-								if ok {
-									datasetName = dsKey.Value().(transpiler.Node).String()
-									break
-								}
-							}
-						}
-					}
-				}
+			violation(typeField, datasetType, "does not meet the Elasticsearch index naming constraints", ErrInvalidIndex)
+		} else if reason := matchers.dsType.check(datasetType); reason != "" {
+			violation(typeField, datasetType, reason, ErrInvalidIndex)
+		}
+
+		datasets, err := streamDatasets(inputNode)
+		if err != nil {
+			return err
+		}
+		if len(datasets) == 0 {
+			datasets = []string{"generic"}
+		}
+		for _, datasetName := range datasets {
+			if !matchesDatasetConstraints(datasetName) {
+				violation(datasetField, datasetName, "does not meet the Elasticsearch index naming constraints", ErrInvalidDataset)
+			} else if reason := matchers.dataset.check(datasetName); reason != "" {
+				violation(datasetField, datasetName, reason, ErrInvalidDataset)
 			}
 		}
-		if !matchesDatasetConstraints(datasetName) {
-			return ErrInvalidDataset
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return &StreamCheckError{Violations: violations}
+}
+
+// matchersFor returns the rules of an input, later overrides win over earlier ones.
+func (c *streamChecker) matchersFor(inputID, inputType string) fieldMatchers {
+	matchers := c.matchers
+	for _, o := range c.overrides {
+		if o.inputID != "" && o.inputID != inputID {
+			continue
+		}
+		if o.inputType != "" && o.inputType != inputType {
+			continue
+		}
+		matchers = o.matchers
+	}
+	return matchers
+}
+
+// dataStreamValue returns the value of a data stream field, in compact (data_stream.namespace)
+// or long form.
+func dataStreamValue(node transpiler.Node, field string) (string, bool) {
+	if n, found := node.Find("data_stream." + field); found {
+		key, ok := n.(*transpiler.Key)
+		if !ok {
+			return "", false
 		}
+		return key.Value().(transpiler.Node).String(), true
+	}
+
+	dsNode, found := node.Find("data_stream")
+	if !found {
+		return "", false
+	}
+	dsMap, ok := dsNode.Value().(*transpiler.Dict)
+	if !ok {
+		return "", false
+	}
+	return stringValue(dsMap, field)
+}
+
+func stringValue(node transpiler.Node, name string) (string, bool) {
+	n, found := node.Find(name)
+	if !found {
+		return "", false
+	}
+	key, ok := n.(*transpiler.Key)
+	if !ok {
+		return "", false
+	}
+	value, ok := key.Value().(transpiler.Node)
+	if !ok {
+		return "", false
+	}
+	return value.String(), true
+}
+
+// streamDatasets returns the datasets of the streams of an input.
+func streamDatasets(inputNode transpiler.Node) ([]string, error) {
+	streamsNode, ok := inputNode.Find("streams")
+	if !ok {
+		return nil, nil
+	}
+	streamsList, ok := streamsNode.Value().(*transpiler.List)
+	if !ok {
+		return nil, nil
+	}
+	streamNodes, ok := streamsList.Value().([]transpiler.Node)
+	if !ok {
+		return nil, errors.New("streams is not a list", errors.TypeConfig)
 	}
 
-	return nil
+	var datasets []string
+	for _, streamNode := range streamNodes {
+		streamMap, ok := streamNode.(*transpiler.Dict)
+		if !ok {
+			continue
+		}
+		if dataset, ok := dataStreamValue(streamMap, datasetField); ok {
+			datasets = append(datasets, dataset)
+		}
+	}
+	return datasets, nil
 }
 
 // The only two requirement are that it has only characters allowed in an Elasticsearch index name
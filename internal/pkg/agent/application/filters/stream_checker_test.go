@@ -7,11 +7,14 @@ package filters
 import (
 	"crypto/sha512"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	agenterrors "github.com/elastic/elastic-agent/internal/pkg/agent/errors"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/transpiler"
 	"github.com/elastic/elastic-agent/pkg/core/logger"
 )
@@ -258,7 +261,97 @@ func TestStreamCheck(t *testing.T) {
 			assert.NoError(t, err)
 
 			result := StreamChecker(log, ast)
-			assert.Equal(t, tc.result, result)
+			assert.ErrorIs(t, result, tc.result)
 		})
 	}
 }
+
+func TestStreamCheckerRules(t *testing.T) {
+	cfg := &StreamCheckerConfig{
+		Namespace: FieldRules{
+			Allow: []string{`^(prod|stage|dev)_[a-z]+$`},
+		},
+		Dataset: FieldRules{
+			Deny:     []string{`^internal\.`},
+			Reserved: []string{"generic"},
+		},
+		Overrides: []StreamOverride{
+			{
+				InputType: "logfile",
+				Namespace: &FieldRules{Allow: []string{`^sandbox$`}},
+			},
+		},
+	}
+
+	checker, err := NewStreamChecker(cfg)
+	require.NoError(t, err)
+
+	ast, err := transpiler.NewAST(map[string]interface{}{
+		"inputs": []map[string]interface{}{
+			{
+				"id":                    "ok",
+				"type":                  "system/metrics",
+				"data_stream.namespace": "prod_web",
+				"streams":               []map[string]interface{}{{"data_stream.dataset": "system.cpu"}},
+			},
+			{
+				"id":                    "bad-namespace",
+				"type":                  "system/metrics",
+				"data_stream.namespace": "qa_web",
+				"streams":               []map[string]interface{}{{"data_stream.dataset": "system.cpu"}},
+			},
+			{
+				"id":                    "overridden",
+				"type":                  "logfile",
+				"data_stream.namespace": "sandbox",
+				"streams": []map[string]interface{}{
+					{"data_stream.dataset": "app.log"},
+					{"data_stream.dataset": "internal.audit"},
+				},
+			},
+			{
+				"type":                  "logfile",
+				"data_stream.namespace": "prod_web",
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	err = checker(nil, ast)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrInvalidNamespace)
+	assert.ErrorIs(t, err, ErrInvalidDataset)
+	assert.False(t, errors.Is(err, ErrInvalidIndex))
+	// agent errors are not comparable, matching them must not panic
+	assert.False(t, errors.Is(err, agenterrors.New("provided namespace is invalid", agenterrors.TypeConfig)))
+	assert.Equal(t, agenterrors.TypeConfig, ErrInvalidNamespace.(agenterrors.Error).Type())
+
+	var checkErr *StreamCheckError
+	require.True(t, errors.As(err, &checkErr))
+	require.Len(t, checkErr.Violations, 4)
+
+	assert.Equal(t, 1, checkErr.Violations[0].Index)
+	assert.Equal(t, "bad-namespace", checkErr.Violations[0].InputID)
+	assert.Equal(t, "namespace", checkErr.Violations[0].Field)
+	assert.Contains(t, checkErr.Violations[0].Reason, "does not match any allowed pattern")
+
+	assert.Equal(t, 2, checkErr.Violations[1].Index)
+	assert.Equal(t, "internal.audit", checkErr.Violations[1].Value)
+	assert.Contains(t, checkErr.Violations[1].Reason, "matches denied pattern")
+
+	assert.Equal(t, 3, checkErr.Violations[2].Index)
+	assert.Equal(t, "prod_web", checkErr.Violations[2].Value)
+	assert.Equal(t, 3, checkErr.Violations[3].Index)
+	assert.Equal(t, "generic", checkErr.Violations[3].Value)
+	assert.Equal(t, "is reserved", checkErr.Violations[3].Reason)
+
+	assert.Contains(t, err.Error(), `input 1 (id: bad-namespace, type: system/metrics): namespace "qa_web"`)
+}
+
+func TestNewStreamCheckerInvalidConfig(t *testing.T) {
+	_, err := NewStreamChecker(&StreamCheckerConfig{Namespace: FieldRules{Allow: []string{"("}}})
+	assert.Error(t, err)
+
+	_, err = NewStreamChecker(&StreamCheckerConfig{Overrides: []StreamOverride{{Namespace: &FieldRules{}}}})
+	assert.Error(t, err)
+}
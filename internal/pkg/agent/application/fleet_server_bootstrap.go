@@ -44,6 +44,7 @@ type FleetServerBootstrap struct {
 	router      pipeline.Router
 	source      source
 	srv         *server.Server
+	reporter    *reporting.Reporter
 }
 
 func newFleetServerBootstrap(
@@ -87,7 +88,12 @@ func newFleetServerBootstrap(
 		return nil, errors.New(err, "initialize GRPC listener")
 	}
 
-	reporter := reporting.NewReporter(bootstrapApp.bgContext, log, bootstrapApp.agentInfo, logR)
+	backends, err := NewReportingBackends(log, cfg.Settings)
+	if err != nil {
+		return nil, err
+	}
+	reporter := reporting.NewReporter(bootstrapApp.bgContext, log, bootstrapApp.agentInfo, append([]reporting.Backend{logR}, backends...)...)
+	bootstrapApp.reporter = reporter
 
 	if cfg.Settings.MonitoringConfig != nil {
 		cfg.Settings.MonitoringConfig.Enabled = false
@@ -105,13 +111,18 @@ func newFleetServerBootstrap(
 	}
 	bootstrapApp.router = router
 
+	streamChecker, err := filters.NewStreamChecker(cfg.Settings.DataStreamPolicy)
+	if err != nil {
+		return nil, errors.New(err, "invalid data stream policy")
+	}
+
 	emit, err := bootstrapEmitter(
 		bootstrapApp.bgContext,
 		log,
 		agentInfo,
 		router,
 		&pipeline.ConfigModifiers{
-			Filters: []pipeline.FilterFunc{filters.StreamChecker, modifiers.InjectFleet(rawConfig, sysInfo.Info(), agentInfo)},
+			Filters: []pipeline.FilterFunc{streamChecker, modifiers.InjectFleet(rawConfig, sysInfo.Info(), agentInfo)},
 		},
 	)
 	if err != nil {
@@ -119,7 +130,9 @@ func newFleetServerBootstrap(
 	}
 
 	loader := config.NewLoader(log, "")
-	discover := discoverer(pathConfigFile, cfg.Settings.Path)
+	loader.SetLayers(cfg.Settings.Layers.Patterns(), cfg.Settings.Layers.Strategies())
+	patterns := append([]string{pathConfigFile}, cfg.Settings.Layers.Patterns()...)
+	discover := discoverer(append(patterns, cfg.Settings.Path)...)
 	bootstrapApp.source = newOnce(log, discover, loader, emit)
 	return bootstrapApp, nil
 }
@@ -150,6 +163,7 @@ func (b *FleetServerBootstrap) Stop() error {
 	b.cancelCtxFn()
 	b.router.Shutdown()
 	b.srv.Stop()
+	b.reporter.Close()
 	return err
 }
 
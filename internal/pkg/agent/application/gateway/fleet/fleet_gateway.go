@@ -10,6 +10,7 @@ import (
 	"context"
 	stderr "errors"
 	"fmt"
+	"math/rand"
 	"sync"
 	"time"
 
@@ -65,6 +66,29 @@ type stateStore interface {
 	Actions() []fleetapi.Action
 }
 
+// Reenroller enrolls the agent again with Fleet when its API key is rejected.
+type Reenroller interface {
+	Reenroll(ctx context.Context) error
+}
+
+// Option configures optional behaviour of the gateway.
+type Option func(*fleetGateway)
+
+// WithReenroller makes the gateway re-enroll the agent instead of unenrolling
+// it when the API key is rejected too many times.
+func WithReenroller(r Reenroller) Option {
+	return func(f *fleetGateway) {
+		f.reenroller = r
+	}
+}
+
+// WithAuditor records the scheduled actions expiring in the queue with the auditor.
+func WithAuditor(auditor store.Auditor) Option {
+	return func(f *fleetGateway) {
+		f.auditor = auditor
+	}
+}
+
 type actionQueue interface {
 	Add(fleetapi.Action, int64)
 	DequeueActions() []fleetapi.Action
@@ -91,6 +115,11 @@ type fleetGateway struct {
 	localReporter      status.Reporter
 	stateStore         stateStore
 	queue              actionQueue
+	queueMx            sync.Mutex // serializes the queue updates with their persistence
+	reenroller         Reenroller
+	auditor            store.Auditor
+	stats              checkinStats
+	pollTimeout        time.Duration
 }
 
 // New creates a new fleet gateway
@@ -104,6 +133,7 @@ func New(
 	statusController status.Controller,
 	stateStore stateStore,
 	queue actionQueue,
+	opts ...Option,
 ) (gateway.FleetGateway, error) {
 
 	scheduler := scheduler.NewPeriodicJitter(defaultGatewaySettings.Duration, defaultGatewaySettings.Jitter)
@@ -119,6 +149,7 @@ func New(
 		statusController,
 		stateStore,
 		queue,
+		opts...,
 	)
 }
 
@@ -134,6 +165,7 @@ func newFleetGatewayWithScheduler(
 	statusController status.Controller,
 	stateStore stateStore,
 	queue actionQueue,
+	opts ...Option,
 ) (gateway.FleetGateway, error) {
 
 	// Backoff implementation doesn't support the use of a context [cancellation]
@@ -141,7 +173,7 @@ func newFleetGatewayWithScheduler(
 	// So we keep a done channel that will be closed when the current context is shutdown.
 	done := make(chan struct{})
 
-	return &fleetGateway{
+	f := &fleetGateway{
 		bgContext:  ctx,
 		log:        log,
 		dispatcher: d,
@@ -161,7 +193,13 @@ func newFleetGatewayWithScheduler(
 		statusController: statusController,
 		stateStore:       stateStore,
 		queue:            queue,
-	}, nil
+	}
+
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	return f, nil
 }
 
 func (f *fleetGateway) worker() {
@@ -178,6 +216,7 @@ func (f *fleetGateway) worker() {
 				continue
 			}
 
+			f.queueMx.Lock()
 			actions := f.queueScheduledActions(resp.Actions)
 			actions, err = f.dispatchCancelActions(actions)
 			if err != nil {
@@ -187,6 +226,7 @@ func (f *fleetGateway) worker() {
 			queued, expired := f.gatherQueuedActions(ts.UTC())
 			f.log.Debugf("Gathered %d actions from queue, %d actions expired", len(queued), len(expired))
 			f.log.Debugf("Expired actions: %v", expired)
+			f.auditExpired(expired)
 
 			actions = append(actions, queued...)
 
@@ -198,6 +238,7 @@ func (f *fleetGateway) worker() {
 				f.log.Error(errMsg)
 				f.statusReporter.Update(state.Failed, errMsg, nil)
 			}
+			f.queueMx.Unlock()
 
 			if err := f.dispatcher.Dispatch(context.Background(), f.acker, actions...); err != nil {
 				errMsg = fmt.Sprintf("failed to dispatch actions, error: %s", err)
@@ -205,12 +246,12 @@ func (f *fleetGateway) worker() {
 				f.statusReporter.Update(state.Failed, errMsg, nil)
 			}
 
-			f.log.Debugf("FleetGateway is sleeping, next update in %s", f.settings.Duration)
+			f.log.Debugf("FleetGateway is sleeping, next update in %s", f.nextCheckin(resp))
 			if errMsg != "" {
 				f.statusReporter.Update(state.Failed, errMsg, nil)
 			} else {
 				f.statusReporter.Update(state.Healthy, "", nil)
-				f.localReporter.Update(state.Healthy, "", nil) // we don't need to specifically set the local reporter to failed above, but it needs to be reset to healthy if a checkin succeeds
+				f.localReporter.Update(state.Healthy, "", f.stats.payload()) // we don't need to specifically set the local reporter to failed above, but it needs to be reset to healthy if a checkin succeeds
 			}
 
 		case <-f.bgContext.Done():
@@ -274,6 +315,17 @@ func (f *fleetGateway) gatherQueuedActions(ts time.Time) (queued, expired []flee
 	return queued, expired
 }
 
+// auditExpired records the actions which expired before they could be dispatched.
+func (f *fleetGateway) auditExpired(expired []fleetapi.Action) {
+	if f.auditor == nil {
+		return
+	}
+	ctx := store.WithAuditSource(context.Background(), store.AuditSourceQueue)
+	for _, action := range expired {
+		f.auditor.Audit(store.NewAuditEntry(ctx, action, store.AuditResultExpired))
+	}
+}
+
 func (f *fleetGateway) executeCheckinWithRetries() (*fleetapi.CheckinResponse, error) {
 	f.backoff.Reset()
 
@@ -284,21 +336,36 @@ func (f *fleetGateway) executeCheckinWithRetries() (*fleetapi.CheckinResponse, e
 		resp, took, err := f.executeCheckin(f.bgContext)
 		if err != nil {
 			f.checkinFailCounter++
+			f.stats.record(took, f.checkinFailCounter)
+
+			// Fleet-server is overloaded, honour the delay it requested instead of the backoff.
+			retryAfter := retryAfterDelay(err, f.settings.Backoff.Max)
+			nextWait := f.backoff.NextWait()
+			if retryAfter > 0 {
+				nextWait = retryAfter
+			}
 
 			// Report the first two failures at warn level as they may be recoverable with retries.
 			if f.checkinFailCounter <= 2 {
 				f.log.Warnw("Possible transient error during checkin with fleet-server, retrying",
 					"error.message", err, "request_duration_ns", took, "failed_checkins", f.checkinFailCounter,
-					"retry_after_ns", f.backoff.NextWait())
+					"retry_after_ns", nextWait)
 			} else {
 				// Only update the local status after repeated failures: https://github.com/elastic/elastic-agent/issues/1148
-				f.localReporter.Update(state.Degraded, fmt.Sprintf("checkin failed: %v", err), nil)
+				f.localReporter.Update(state.Degraded, fmt.Sprintf("checkin failed: %v", err), f.stats.payload())
 				f.log.Errorw("Cannot checkin in with fleet-server, retrying",
 					"error.message", err, "request_duration_ns", took, "failed_checkins", f.checkinFailCounter,
-					"retry_after_ns", f.backoff.NextWait())
+					"retry_after_ns", nextWait)
 			}
 
-			if !f.backoff.Wait() {
+			var waited bool
+			if retryAfter > 0 {
+				waited = f.waitRetryAfter(retryAfter)
+			} else {
+				waited = f.backoff.Wait()
+			}
+
+			if !waited {
 				// Something bad has happened and we log it and we should update our current state.
 				err := errors.New(
 					"checkin retry loop was stopped",
@@ -319,6 +386,8 @@ func (f *fleetGateway) executeCheckinWithRetries() (*fleetapi.CheckinResponse, e
 		}
 
 		f.checkinFailCounter = 0
+		f.stats.record(took, 0)
+		f.applyCheckinHints(resp)
 		// Request was successful, return the collected actions.
 		return resp, nil
 	}
@@ -348,11 +417,26 @@ func (f *fleetGateway) executeCheckin(ctx context.Context) (*fleetapi.CheckinRes
 		Status:   f.statusController.StatusString(),
 		Message:  f.statusController.Status().Message,
 	}
+	if f.pollTimeout > 0 {
+		req.PollTimeout = f.pollTimeout.String()
+	}
 
 	resp, took, err := cmd.Execute(ctx, req)
 	if isUnauth(err) {
 		f.unauthCounter++
 
+		if f.shouldUnenroll() && f.reenroller != nil {
+			f.log.Warnf("received an invalid api key error '%d' times. Starting to re-enroll the elastic agent.", f.unauthCounter)
+			if rerr := f.reenroller.Reenroll(ctx); rerr != nil {
+				f.log.Errorw("Failed to re-enroll the elastic agent", "error.message", rerr)
+				return nil, took, err
+			}
+
+			// checkin again right away with the new API key
+			f.unauthCounter = 0
+			return f.executeCheckin(ctx)
+		}
+
 		if f.shouldUnenroll() {
 			f.log.Warnf("received an invalid api key error '%d' times. Starting to unenroll the elastic agent.", f.unauthCounter)
 			return &fleetapi.CheckinResponse{
@@ -380,6 +464,69 @@ func (f *fleetGateway) executeCheckin(ctx context.Context) (*fleetapi.CheckinRes
 	return resp, took, nil
 }
 
+// applyCheckinHints applies the poll timeout and the next checkin delay sent by fleet-server.
+func (f *fleetGateway) applyCheckinHints(resp *fleetapi.CheckinResponse) {
+	pollTimeout, err := resp.PollTimeoutDuration()
+	if err != nil {
+		f.log.Warnw("Ignoring poll timeout sent by fleet-server", "error.message", err)
+	} else {
+		f.pollTimeout = pollTimeout
+	}
+
+	next, err := resp.NextCheckinDuration()
+	if err != nil {
+		f.log.Warnw("Ignoring next checkin delay sent by fleet-server", "error.message", err)
+		next = 0
+	}
+
+	if setter, ok := f.scheduler.(scheduler.NextSetter); ok {
+		setter.SetNext(next)
+	}
+	checkinNextDelay.Set(f.nextCheckin(resp).Milliseconds())
+}
+
+// nextCheckin returns the delay before the next checkin, the one requested by
+// fleet-server when valid or the configured checkin frequency.
+func (f *fleetGateway) nextCheckin(resp *fleetapi.CheckinResponse) time.Duration {
+	if next, err := resp.NextCheckinDuration(); err == nil && next > 0 {
+		return next
+	}
+	return f.settings.Duration
+}
+
+// waitRetryAfter waits for the delay requested by fleet-server plus up to 10% of
+// jitter, so agents rejected at the same time don't checkin at the same time.
+// It returns false when the gateway is stopped.
+func (f *fleetGateway) waitRetryAfter(d time.Duration) bool {
+	d += time.Duration(rand.Int63n(int64(d/10) + 1))
+	if max := f.settings.Backoff.Max; max > 0 && d > max {
+		d = max
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-f.done:
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// retryAfterDelay returns the delay requested by fleet-server when it rejected
+// the checkin with a 429 status code, capped at max so a bogus value cannot
+// stop the checkins.
+func retryAfterDelay(err error, max time.Duration) time.Duration {
+	var tooManyRequests *client.TooManyRequestsError
+	if !stderr.As(err, &tooManyRequests) {
+		return 0
+	}
+	if max > 0 && tooManyRequests.RetryAfter > max {
+		return max
+	}
+	return tooManyRequests.RetryAfter
+}
+
 // shouldUnenroll checks if the max number of trying an invalid key is reached
 func (f *fleetGateway) shouldUnenroll() bool {
 	return f.unauthCounter > maxUnauthCounter
@@ -412,3 +559,21 @@ func (f *fleetGateway) stop() {
 func (f *fleetGateway) SetClient(c client.Sender) {
 	f.client = c
 }
+
+// CancelAction removes the actions with the given ID from the action queue and persists the
+// updated queue, it is serialized with the checkin loop updating the same queue.
+func (f *fleetGateway) CancelAction(id string) (int, error) {
+	f.queueMx.Lock()
+	defer f.queueMx.Unlock()
+
+	n := f.queue.Cancel(id)
+	if n == 0 {
+		return 0, nil
+	}
+
+	f.stateStore.SetQueue(f.queue.Actions())
+	if err := f.stateStore.Save(); err != nil {
+		return n, errors.New(err, "failed to persist action queue", errors.TypeFilesystem)
+	}
+	return n, nil
+}
@@ -14,6 +14,8 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -22,14 +24,18 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/monitoring"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/gateway"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/storage"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/storage/store"
 	"github.com/elastic/elastic-agent/internal/pkg/core/state"
 	"github.com/elastic/elastic-agent/internal/pkg/core/status"
 	"github.com/elastic/elastic-agent/internal/pkg/fleetapi"
 	noopacker "github.com/elastic/elastic-agent/internal/pkg/fleetapi/acker/noop"
+	"github.com/elastic/elastic-agent/internal/pkg/fleetapi/client"
+	"github.com/elastic/elastic-agent/internal/pkg/remote"
 	"github.com/elastic/elastic-agent/internal/pkg/scheduler"
 	"github.com/elastic/elastic-agent/internal/pkg/testutils"
 	"github.com/elastic/elastic-agent/pkg/core/logger"
@@ -828,3 +834,266 @@ func newInfoLogger(t *testing.T, name string) *logger.Logger {
 	require.NoError(t, err)
 	return log
 }
+
+type testReenroller struct {
+	called  int
+	gateway gateway.FleetGateway
+	client  *testingClient
+}
+
+func (r *testReenroller) Reenroll(_ context.Context) error {
+	r.called++
+	r.gateway.SetClient(r.client)
+	return nil
+}
+
+func TestReenrollOnInvalidAPIKey(t *testing.T) {
+	log, _ := logger.New("fleet_gateway", false)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	diskStore := storage.NewDiskStore(paths.AgentStateStoreFile())
+	stateStore, err := store.NewStateStore(log, diskStore)
+	require.NoError(t, err)
+
+	rejectingClient := newTestingClient()
+	rejectingClient.Answer(func(_ http.Header, _ io.Reader) (*http.Response, error) {
+		return nil, client.ErrInvalidAPIKey
+	})
+
+	reenrolledClient := newTestingClient()
+	reenrolledClient.Answer(func(_ http.Header, _ io.Reader) (*http.Response, error) {
+		return wrapStrToResp(http.StatusOK, `{ "actions": [] }`), nil
+	})
+
+	reenroller := &testReenroller{client: reenrolledClient}
+	gw, err := newFleetGatewayWithScheduler(
+		ctx,
+		log,
+		defaultGatewaySettings,
+		&testAgentInfo{},
+		rejectingClient,
+		newTestingDispatcher(),
+		scheduler.NewStepper(),
+		noopacker.NewAcker(),
+		&noopController{},
+		stateStore,
+		&mockQueue{},
+		WithReenroller(reenroller),
+	)
+	require.NoError(t, err)
+	reenroller.gateway = gw
+
+	f, ok := gw.(*fleetGateway)
+	require.True(t, ok)
+
+	for i := 0; i < maxUnauthCounter; i++ {
+		_, _, err := f.executeCheckin(ctx)
+		<-rejectingClient.received
+		require.ErrorIs(t, err, client.ErrInvalidAPIKey)
+	}
+	require.Equal(t, 0, reenroller.called)
+
+	resp, _, err := f.executeCheckin(ctx)
+	<-rejectingClient.received
+	<-reenrolledClient.received
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	require.Equal(t, 1, reenroller.called)
+	require.Equal(t, 0, f.unauthCounter)
+}
+
+func TestCheckinHints(t *testing.T) {
+	agentInfo := &testAgentInfo{}
+	settings := &fleetGatewaySettings{
+		Duration: 5 * time.Second,
+		Backoff:  backoffSettings{Init: 10 * time.Minute, Max: 20 * time.Minute},
+	}
+
+	t.Run("Next checkin and poll timeout sent by fleet-server are honoured", withGateway(agentInfo, settings, func(
+		t *testing.T,
+		gateway gateway.FleetGateway,
+		client *testingClient,
+		dispatcher *testingDispatcher,
+		scheduler *scheduler.Stepper,
+	) {
+		waitFn := ackSeq(
+			client.Answer(func(headers http.Header, body io.Reader) (*http.Response, error) {
+				resp := wrapStrToResp(http.StatusOK, `{ "actions": [], "next_checkin": "42s", "poll_timeout": "5m" }`)
+				return resp, nil
+			}),
+			dispatcher.Answer(func(actions ...fleetapi.Action) error { return nil }),
+		)
+		require.NoError(t, gateway.Start())
+
+		scheduler.Next()
+		waitFn()
+		require.Equal(t, 42*time.Second, scheduler.NextDelay)
+
+		waitFn = ackSeq(
+			client.Answer(func(headers http.Header, body io.Reader) (*http.Response, error) {
+				var req fleetapi.CheckinRequest
+				require.NoError(t, json.NewDecoder(body).Decode(&req))
+				require.Equal(t, "5m0s", req.PollTimeout)
+				return wrapStrToResp(http.StatusOK, `{ "actions": [] }`), nil
+			}),
+			dispatcher.Answer(func(actions ...fleetapi.Action) error { return nil }),
+		)
+
+		scheduler.Next()
+		waitFn()
+		require.Zero(t, scheduler.NextDelay)
+	}))
+
+	t.Run("Retry-After is used instead of the backoff on 429", withGateway(agentInfo, settings, func(
+		t *testing.T,
+		gateway gateway.FleetGateway,
+		c *testingClient,
+		dispatcher *testingDispatcher,
+		scheduler *scheduler.Stepper,
+	) {
+		var calls int
+		clientCh := c.Answer(func(headers http.Header, body io.Reader) (*http.Response, error) {
+			calls++
+			if calls == 1 {
+				return nil, &client.TooManyRequestsError{RetryAfter: 100 * time.Millisecond}
+			}
+			return wrapStrToResp(http.StatusOK, `{ "actions": [] }`), nil
+		})
+		waitFn := ackSeq(
+			clientCh,
+			clientCh,
+			dispatcher.Answer(func(actions ...fleetapi.Action) error { return nil }),
+		)
+		require.NoError(t, gateway.Start())
+
+		started := time.Now()
+		scheduler.Next()
+		waitFn()
+
+		// the configured backoff waits at least 5 minutes
+		require.Less(t, time.Since(started), time.Minute)
+		require.Equal(t, 2, calls)
+	}))
+}
+
+func TestRetryAfterDelay(t *testing.T) {
+	require.Equal(t, 3*time.Second, retryAfterDelay(errors.New(&client.TooManyRequestsError{RetryAfter: 3 * time.Second}, "checkin failed"), 10*time.Minute))
+	require.Equal(t, 10*time.Minute, retryAfterDelay(errors.New(&client.TooManyRequestsError{RetryAfter: 240 * time.Hour}, "checkin failed"), 10*time.Minute))
+	require.Zero(t, retryAfterDelay(fmt.Errorf("other error"), 10*time.Minute))
+}
+
+func TestReplayedSession(t *testing.T) {
+	settings := &fleetGatewaySettings{
+		Duration: 5 * time.Second,
+		Backoff:  backoffSettings{Init: 1 * time.Second, Max: 5 * time.Second},
+	}
+	checkin := func(body string) remote.Entry {
+		return remote.Entry{
+			Request:  remote.EntryRequest{Method: http.MethodPost, URL: "https://fleet:8220/api/fleet/agents/agent-secret/checkin?"},
+			Response: remote.EntryResponse{Status: http.StatusOK, Content: remote.EntryContent{Text: body}},
+		}
+	}
+	replay, err := client.NewReplay([]remote.Entry{
+		checkin(`{"actions":[{"type":"POLICY_CHANGE","id":"id1","data":{"policy":{"id":"policy-id","outputs":{"default":{"api_key":"[REDACTED]"}}}}}]}`),
+		checkin(`{"actions":[]}`),
+	})
+	require.NoError(t, err)
+
+	scheduler := scheduler.NewStepper()
+	dispatcher := newTestingDispatcher()
+	log, _ := logger.New("fleet_gateway", false)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stateStore, err := store.NewStateStore(log, storage.NewDiskStore(paths.AgentStateStoreFile()))
+	require.NoError(t, err)
+	queue := &mockQueue{}
+	queue.On("DequeueActions").Return([]fleetapi.Action{})
+	queue.On("Actions").Return([]fleetapi.Action{})
+
+	gateway, err := newFleetGatewayWithScheduler(
+		ctx,
+		log,
+		settings,
+		&testAgentInfo{},
+		replay,
+		dispatcher,
+		scheduler,
+		noopacker.NewAcker(),
+		&noopController{},
+		stateStore,
+		queue,
+	)
+	require.NoError(t, err)
+
+	waitFn := ackSeq(dispatcher.Answer(func(actions ...fleetapi.Action) error {
+		require.Len(t, actions, 1)
+		require.Equal(t, "id1", actions[0].ID())
+		require.Equal(t, "POLICY_CHANGE", actions[0].Type())
+		return nil
+	}))
+	require.NoError(t, gateway.Start())
+	scheduler.Next()
+	waitFn()
+
+	waitFn = ackSeq(dispatcher.Answer(func(actions ...fleetapi.Action) error {
+		require.Empty(t, actions)
+		return nil
+	}))
+	scheduler.Next()
+	waitFn()
+	require.Equal(t, 0, replay.Remaining())
+}
+
+func TestCancelAction(t *testing.T) {
+	log, _ := logger.New("fleet_gateway", false)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	file := filepath.Join(t.TempDir(), "state.enc")
+	stateStore, err := store.NewStateStore(log, storage.NewDiskStore(file))
+	require.NoError(t, err)
+	queue := &mockQueue{}
+	queue.On("Cancel", "unknown").Return(0)
+	queue.On("Cancel", "id1").Return(1)
+	queue.On("Actions").Return([]fleetapi.Action{})
+
+	gateway, err := newFleetGatewayWithScheduler(
+		ctx,
+		log,
+		defaultGatewaySettings,
+		&testAgentInfo{},
+		newTestingClient(),
+		newTestingDispatcher(),
+		scheduler.NewStepper(),
+		noopacker.NewAcker(),
+		&noopController{},
+		stateStore,
+		queue,
+	)
+	require.NoError(t, err)
+	require.NoError(t, os.RemoveAll(file))
+
+	n, err := gateway.CancelAction("unknown")
+	require.NoError(t, err)
+	require.Equal(t, 0, n)
+	_, err = os.Stat(file)
+	require.True(t, os.IsNotExist(err), "queue persisted while nothing was cancelled")
+
+	n, err = gateway.CancelAction("id1")
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	_, err = os.Stat(file)
+	require.NoError(t, err, "queue not persisted after the cancellation")
+	queue.AssertExpectations(t)
+}
+
+func TestCheckinMetricsServedInStats(t *testing.T) {
+	stats := &checkinStats{}
+	stats.record(42*time.Millisecond, 3)
+
+	snapshot := monitoring.CollectFlatSnapshot(monitoring.GetNamespace("stats").GetRegistry(), monitoring.Full, false)
+	require.Equal(t, int64(42), snapshot.Ints["fleet.checkin.latency_ms"])
+	require.Equal(t, int64(3), snapshot.Ints["fleet.checkin.consecutive_failures"])
+}
@@ -0,0 +1,55 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package fleet
+
+import (
+	"time"
+
+	"github.com/elastic/elastic-agent-libs/monitoring"
+)
+
+// Checkin metrics exposed under fleet.checkin by the /stats monitoring endpoint, the fleet registry
+// is shared with the ack spool.
+var (
+	checkinRegistry = monitoring.GetNamespace("stats").GetRegistry().NewRegistry("fleet.checkin")
+
+	checkinLatency             = monitoring.NewInt(checkinRegistry, "latency_ms")
+	checkinLastSuccess         = monitoring.NewString(checkinRegistry, "last_success")
+	checkinConsecutiveFailures = monitoring.NewInt(checkinRegistry, "consecutive_failures")
+	checkinNextDelay           = monitoring.NewInt(checkinRegistry, "next_checkin_ms")
+)
+
+// checkinStats keeps track of the result of the last checkins.
+type checkinStats struct {
+	latency             time.Duration
+	lastSuccess         time.Time
+	consecutiveFailures int
+}
+
+// record updates the stats and the monitoring metrics with the result of a checkin,
+// failures is the number of consecutive failed checkins, zero for a successful checkin.
+func (s *checkinStats) record(latency time.Duration, failures int) {
+	s.latency = latency
+	s.consecutiveFailures = failures
+	if failures == 0 {
+		s.lastSuccess = time.Now().UTC()
+		checkinLastSuccess.Set(s.lastSuccess.Format(time.RFC3339Nano))
+	}
+
+	checkinLatency.Set(latency.Milliseconds())
+	checkinConsecutiveFailures.Set(int64(s.consecutiveFailures))
+}
+
+// payload returns the stats as a status payload.
+func (s *checkinStats) payload() map[string]interface{} {
+	payload := map[string]interface{}{
+		"checkin_latency_ms":           s.latency.Milliseconds(),
+		"consecutive_checkin_failures": s.consecutiveFailures,
+	}
+	if !s.lastSuccess.IsZero() {
+		payload["last_checkin_success"] = s.lastSuccess.Format(time.RFC3339Nano)
+	}
+	return payload
+}
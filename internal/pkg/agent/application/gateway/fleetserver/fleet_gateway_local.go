@@ -89,6 +89,11 @@ func (w *fleetServerWrapper) SetClient(c client.Sender) {
 	w.wrapped.SetClient(c)
 }
 
+// CancelAction cancels a queued action in the wrapped gateway.
+func (w *fleetServerWrapper) CancelAction(id string) (int, error) {
+	return w.wrapped.CancelAction(id)
+}
+
 func injectFleetServer(rawConfig *config.Config) (*config.Config, error) {
 	cfg := map[string]interface{}{}
 	err := rawConfig.Unpack(cfg)
@@ -16,4 +16,8 @@ type FleetGateway interface {
 
 	// Set the client for the gateway.
 	SetClient(client.Sender)
+
+	// CancelAction removes the actions with the given ID from the action queue and persists the
+	// updated queue, it returns the number of removed actions.
+	CancelAction(id string) (int, error)
 }
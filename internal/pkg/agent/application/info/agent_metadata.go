@@ -13,6 +13,7 @@ import (
 	"github.com/elastic/go-sysinfo"
 	"github.com/elastic/go-sysinfo/types"
 
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/override"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
 	"github.com/elastic/elastic-agent/internal/pkg/release"
 )
@@ -44,6 +45,8 @@ type AgentECSMeta struct {
 	// LogLevel describes currently set log level.
 	// Possible values: "debug"|"info"|"warning"|"error"
 	LogLevel string `json:"log_level"`
+	// Overrides are the paths of the policy changed by the local override file.
+	Overrides []string `json:"overrides,omitempty"`
 }
 
 // SystemECSMeta is a collection of operating system metadata in ECS compliant object form.
@@ -160,6 +163,7 @@ func (i *AgentInfo) ECSMetadata() (*ECSMeta, error) {
 				// control of the system supervisor (or built specifically with upgrading enabled)
 				Upgradeable: release.Upgradeable() || (RunningInstalled() && RunningUnderSupervisor()),
 				LogLevel:    i.LogLevel(),
+				Overrides:   override.Applied(),
 			},
 		},
 		Host: &HostECSMeta{
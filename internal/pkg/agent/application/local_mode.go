@@ -22,6 +22,7 @@ import (
 	"github.com/elastic/elastic-agent/internal/pkg/agent/configuration"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/operation"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/storage/store"
 	"github.com/elastic/elastic-agent/internal/pkg/artifact"
 	"github.com/elastic/elastic-agent/internal/pkg/capabilities"
 	"github.com/elastic/elastic-agent/internal/pkg/composable"
@@ -29,6 +30,7 @@ import (
 	"github.com/elastic/elastic-agent/internal/pkg/core/monitoring"
 	"github.com/elastic/elastic-agent/internal/pkg/core/status"
 	"github.com/elastic/elastic-agent/internal/pkg/dir"
+	"github.com/elastic/elastic-agent/internal/pkg/fleetapi"
 	acker "github.com/elastic/elastic-agent/internal/pkg/fleetapi/acker/noop"
 	reporting "github.com/elastic/elastic-agent/internal/pkg/reporter"
 	logreporter "github.com/elastic/elastic-agent/internal/pkg/reporter/log"
@@ -52,6 +54,8 @@ type Local struct {
 	source      source
 	agentInfo   *info.AgentInfo
 	srv         *server.Server
+	auditLog    *store.AuditLog
+	reporter    *reporting.Reporter
 }
 
 type source interface {
@@ -101,7 +105,17 @@ func newLocal(
 		return nil, errors.New(err, "initialize GRPC listener")
 	}
 
-	reporter := reporting.NewReporter(localApplication.bgContext, log, localApplication.agentInfo, logR)
+	localApplication.auditLog, err = newAuditLog(log, cfg.Settings)
+	if err != nil {
+		return nil, err
+	}
+
+	backends, err := NewReportingBackends(log, cfg.Settings)
+	if err != nil {
+		return nil, err
+	}
+	reporter := reporting.NewReporter(localApplication.bgContext, log, localApplication.agentInfo, append([]reporting.Backend{logR}, backends...)...)
+	localApplication.reporter = reporter
 
 	monitor, err := monitoring.NewMonitor(cfg.Settings)
 	if err != nil {
@@ -124,7 +138,13 @@ func newLocal(
 		return nil, errors.New("router not capable of artifact reload") // Needed for client reloading
 	}
 
-	discover := discoverer(pathConfigFile, cfg.Settings.Path, externalConfigsGlob())
+	patterns := append([]string{pathConfigFile}, cfg.Settings.Layers.Patterns()...)
+	discover := discoverer(append(patterns, cfg.Settings.Path, externalConfigsGlob())...)
+	streamChecker, err := filters.NewStreamChecker(cfg.Settings.DataStreamPolicy)
+	if err != nil {
+		return nil, errors.New(err, "invalid data stream policy")
+	}
+
 	emit, err := emitter.New(
 		localApplication.bgContext,
 		log,
@@ -132,8 +152,8 @@ func newLocal(
 		composableCtrl,
 		router,
 		&pipeline.ConfigModifiers{
-			Decorators: []pipeline.DecoratorFunc{modifiers.InjectMonitoring},
-			Filters:    []pipeline.FilterFunc{filters.StreamChecker},
+			Decorators: []pipeline.DecoratorFunc{modifiers.InjectLogLevels, modifiers.InjectMonitoring},
+			Filters:    []pipeline.FilterFunc{streamChecker},
 		},
 		caps,
 		monitor,
@@ -145,6 +165,7 @@ func newLocal(
 	}
 
 	loader := config.NewLoader(log, externalConfigsGlob())
+	loader.SetLayers(cfg.Settings.Layers.Patterns(), cfg.Settings.Layers.Strategies())
 
 	var cfgSource source
 	if !cfg.Settings.Reload.Enabled {
@@ -202,9 +223,20 @@ func (l *Local) Stop() error {
 	l.cancelCtxFn()
 	l.router.Shutdown()
 	l.srv.Stop()
+	if l.auditLog != nil {
+		if err := l.auditLog.Close(); err != nil {
+			l.log.Warnf("failed to close action audit log: %v", err)
+		}
+	}
+	l.reporter.Close()
 	return err
 }
 
+// PerformAppAction performs an application action requested through the control socket.
+func (l *Local) PerformAppAction(action *fleetapi.ActionApp) {
+	performAppAction(l.log, l.srv, l.auditLog, action)
+}
+
 // AgentInfo retrieves agent information.
 func (l *Local) AgentInfo() *info.AgentInfo {
 	return l.agentInfo
@@ -17,6 +17,7 @@ import (
 	fleetgateway "github.com/elastic/elastic-agent/internal/pkg/agent/application/gateway/fleet"
 	localgateway "github.com/elastic/elastic-agent/internal/pkg/agent/application/gateway/fleetserver"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/info"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/override"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/pipeline"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/pipeline/actions/handlers"
@@ -25,6 +26,7 @@ import (
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/pipeline/emitter/modifiers"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/pipeline/router"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/pipeline/stream"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/reenroll"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/configuration"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
@@ -41,6 +43,7 @@ import (
 	"github.com/elastic/elastic-agent/internal/pkg/fleetapi/acker/fleet"
 	"github.com/elastic/elastic-agent/internal/pkg/fleetapi/acker/lazy"
 	"github.com/elastic/elastic-agent/internal/pkg/fleetapi/acker/retrier"
+	"github.com/elastic/elastic-agent/internal/pkg/fleetapi/acker/spool"
 	"github.com/elastic/elastic-agent/internal/pkg/fleetapi/client"
 	"github.com/elastic/elastic-agent/internal/pkg/queue"
 	reporting "github.com/elastic/elastic-agent/internal/pkg/reporter"
@@ -57,6 +60,7 @@ type stateStore interface {
 	Save() error
 	Actions() []fleetapi.Action
 	Queue() []fleetapi.Action
+	SetQueue([]fleetapi.Action)
 }
 
 // Managed application, when the application is run in managed mode, most of the configuration are
@@ -71,6 +75,9 @@ type Managed struct {
 	router      pipeline.Router
 	srv         *server.Server
 	stateStore  stateStore
+	actionQueue *queue.SyncActionQueue
+	auditLog    *store.AuditLog
+	reporter    *reporting.Reporter
 	upgrader    *upgrade.Upgrader
 }
 
@@ -123,7 +130,12 @@ func newManaged(
 	}
 
 	logR := logreporter.NewReporter(log)
-	combinedReporter := reporting.NewReporter(managedApplication.bgContext, log, agentInfo, logR)
+	backends, err := NewReportingBackends(log, cfg.Settings)
+	if err != nil {
+		return nil, err
+	}
+	combinedReporter := reporting.NewReporter(managedApplication.bgContext, log, agentInfo, append([]reporting.Backend{logR}, backends...)...)
+	managedApplication.reporter = combinedReporter
 	monitor, err := monitoring.NewMonitor(cfg.Settings)
 	if err != nil {
 		return nil, errors.New(err, "failed to initialize monitoring")
@@ -145,6 +157,11 @@ func newManaged(
 		return nil, errors.New("router not capable of artifact reload") // Needed for client reloading
 	}
 
+	streamChecker, err := filters.NewStreamChecker(cfg.Settings.DataStreamPolicy)
+	if err != nil {
+		return nil, errors.New(err, "invalid data stream policy")
+	}
+
 	emit, err := emitter.New(
 		managedApplication.bgContext,
 		log,
@@ -152,8 +169,8 @@ func newManaged(
 		composableCtrl,
 		router,
 		&pipeline.ConfigModifiers{
-			Decorators: []pipeline.DecoratorFunc{modifiers.InjectMonitoring},
-			Filters:    []pipeline.FilterFunc{filters.StreamChecker, modifiers.InjectFleet(rawConfig, sysInfo.Info(), agentInfo)},
+			Decorators: []pipeline.DecoratorFunc{modifiers.InjectLogLevels, modifiers.InjectMonitoring},
+			Filters:    append(overrideFilters(paths.AgentOverrideFile(), cfg.Settings.Override.Allow, streamChecker), modifiers.InjectFleet(rawConfig, sysInfo.Info(), agentInfo), modifiers.InjectProxyRules(rawConfig)),
 		},
 		caps,
 		monitor,
@@ -168,12 +185,24 @@ func newManaged(
 		return nil, err
 	}
 
-	// Create ack retrier that is used by lazyAcker to enqueue/retry failed acks
-	retrier := retrier.New(acker, log)
+	// Create ack retrier that is used by lazyAcker to enqueue/retry failed acks, the ack spool
+	// keeps the failed acks on disk so they survive restarts and long Fleet outages.
+	var ackRetrier interface {
+		lazy.Retrier
+		Run(context.Context)
+	}
+	if cfg.Settings.AckSpool.Enabled {
+		ackRetrier, err = spool.New(acker, storage.NewEncryptedDiskStore(paths.AgentAckSpoolFile()), log, cfg.Settings.AckSpool, spool.WithAgentID(agentInfo.AgentID()))
+		if err != nil {
+			return nil, errors.New(err, "fail to initialize ack spool", errors.M(errors.MetaKeyPath, paths.AgentAckSpoolFile()))
+		}
+	} else {
+		ackRetrier = retrier.New(acker, log)
+	}
 	// Run acking retrier. The lazy acker sends failed actions acks to retrier.
-	go retrier.Run(ctx)
+	go ackRetrier.Run(ctx)
 
-	batchedAcker := lazy.NewAcker(acker, log, lazy.WithRetrier(retrier))
+	batchedAcker := lazy.NewAcker(acker, log, lazy.WithRetrier(ackRetrier))
 
 	// Create the state store that will persist the last good policy change on disk.
 	stateStore, err := store.NewStateStoreWithMigration(log, paths.AgentActionStoreFile(), paths.AgentStateStoreFile())
@@ -183,12 +212,27 @@ func newManaged(
 	managedApplication.stateStore = stateStore
 	actionAcker := store.NewStateStoreActionAcker(batchedAcker, stateStore)
 
-	actionQueue, err := queue.NewActionQueue(stateStore.Queue())
+	q, err := queue.NewActionQueue(stateStore.Queue())
 	if err != nil {
 		return nil, fmt.Errorf("unable to initialize action queue: %w", err)
 	}
+	// the queue is shared between the gateway and the control server.
+	actionQueue := queue.NewSyncActionQueue(q)
+	managedApplication.actionQueue = actionQueue
+
+	var dispatcherOpts []dispatcher.Option
+	var gatewayOpts []fleetgateway.Option
+	auditLog, err := newAuditLog(log, cfg.Settings)
+	if err != nil {
+		return nil, err
+	}
+	if auditLog != nil {
+		managedApplication.auditLog = auditLog
+		dispatcherOpts = append(dispatcherOpts, dispatcher.WithAuditor(auditLog))
+		gatewayOpts = append(gatewayOpts, fleetgateway.WithAuditor(auditLog))
+	}
 
-	actionDispatcher, err := dispatcher.New(managedApplication.bgContext, log, handlers.NewDefault(log))
+	actionDispatcher, err := dispatcher.New(managedApplication.bgContext, log, handlers.NewDefault(log), dispatcherOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -276,6 +320,13 @@ func newManaged(
 		stateRestored = true
 	}
 
+	var reenroller *reenroll.Reenroller
+	if cfg.Fleet.Server == nil && cfg.Fleet.Enrollment.ReenrollEnabled() {
+		reenroller = reenroll.New(log, agentInfo, cfg, storeSaver, policyChanger.ConfigLock())
+		gatewayOpts = append(gatewayOpts, fleetgateway.WithReenroller(reenroller))
+		go reenroller.Watch(managedApplication.bgContext, 0)
+	}
+
 	gateway, err := fleetgateway.New(
 		managedApplication.bgContext,
 		log,
@@ -286,6 +337,7 @@ func newManaged(
 		statusCtrl,
 		stateStore,
 		actionQueue,
+		gatewayOpts...,
 	)
 	if err != nil {
 		return nil, err
@@ -301,6 +353,10 @@ func newManaged(
 		policyChanger.AddSetter(gateway)
 		policyChanger.AddSetter(acker)
 	}
+	if reenroller != nil {
+		reenroller.AddSetter(gateway)
+		reenroller.AddSetter(acker)
+	}
 
 	managedApplication.gateway = gateway
 	return managedApplication, nil
@@ -342,14 +398,42 @@ func (m *Managed) Stop() error {
 	m.cancelCtxFn()
 	m.router.Shutdown()
 	m.srv.Stop()
+	if m.auditLog != nil {
+		if err := m.auditLog.Close(); err != nil {
+			m.log.Warnf("failed to close action audit log: %v", err)
+		}
+	}
+	m.reporter.Close()
 	return nil
 }
 
+// PerformAppAction performs an application action requested through the control socket.
+func (m *Managed) PerformAppAction(action *fleetapi.ActionApp) {
+	performAppAction(m.log, m.srv, m.auditLog, action)
+}
+
+// QueuedActions returns the scheduled actions waiting in the action queue.
+func (m *Managed) QueuedActions() []fleetapi.Action {
+	return m.actionQueue.Actions()
+}
+
+// CancelAction removes the actions with the given ID from the action queue and
+// persists the updated queue. The gateway serializes it with its checkin loop.
+func (m *Managed) CancelAction(id string) (int, error) {
+	return m.gateway.CancelAction(id)
+}
+
 // AgentInfo retrieves elastic-agent information.
 func (m *Managed) AgentInfo() *info.AgentInfo {
 	return m.agentInfo
 }
 
+// overrideFilters applies the local override before the stream checker, the streams set by the
+// override are checked like the ones of the policy.
+func overrideFilters(file string, allow []override.Rule, streamChecker pipeline.FilterFunc) []pipeline.FilterFunc {
+	return []pipeline.FilterFunc{modifiers.InjectOverride(file, allow), streamChecker}
+}
+
 func (m *Managed) wasUnenrolled() bool {
 	actions := m.stateStore.Actions()
 	for _, a := range actions {
@@ -7,6 +7,8 @@ package application
 import (
 	"context"
 	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
 	"testing"
 
 	"github.com/elastic/elastic-agent/internal/pkg/agent/configuration"
@@ -15,7 +17,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/filters"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/info"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/override"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/pipeline"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/pipeline/actions/handlers"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/pipeline/dispatcher"
@@ -24,6 +28,7 @@ import (
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/pipeline/router"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/configrequest"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/storage"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/transpiler"
 	"github.com/elastic/elastic-agent/internal/pkg/composable"
 	"github.com/elastic/elastic-agent/internal/pkg/fleetapi"
 	"github.com/elastic/elastic-agent/pkg/core/logger"
@@ -300,3 +305,38 @@ const fleetResponse = `
 	}]
 }
 	`
+
+func TestOverrideFiltersCheckOverriddenStreams(t *testing.T) {
+	defer override.SetApplied(nil)
+
+	log, err := logger.New("", false)
+	require.NoError(t, err)
+
+	file := filepath.Join(t.TempDir(), "elastic-agent.override.yml")
+	require.NoError(t, ioutil.WriteFile(file, []byte(`
+inputs:
+  - id: logs
+    data_stream:
+      namespace: Not A Namespace
+`), 0600))
+
+	ast, err := transpiler.NewAST(map[string]interface{}{
+		"inputs": []interface{}{
+			map[string]interface{}{
+				"id":          "logs",
+				"type":        "logfile",
+				"data_stream": map[string]interface{}{"namespace": "default"},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	var filterErr error
+	for _, filter := range overrideFilters(file, []override.Rule{{Path: "inputs.*.data_stream"}}, filters.StreamChecker) {
+		if filterErr = filter(log, ast); filterErr != nil {
+			break
+		}
+	}
+	require.Error(t, filterErr, "the stream set by the override must be checked")
+	assert.ErrorIs(t, filterErr, filters.ErrInvalidNamespace)
+}
@@ -0,0 +1,284 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+// Package override merges the local override file, elastic-agent.override.yml, over the policies
+// received from Fleet. Only the paths of the allowlist, agent.override.allow, can be overridden.
+package override
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/internal/pkg/config"
+)
+
+// Config is the allowlist of the paths of the policy the override file can change.
+type Config struct {
+	Allow []Rule `config:"allow" yaml:"allow,omitempty"`
+}
+
+// DefaultConfig creates a config without allowed paths, the override file is ignored.
+func DefaultConfig() *Config {
+	return &Config{}
+}
+
+// Rule allows to override the paths matching Path, the value of the override file is merged with
+// the one of the policy with Strategy.
+//
+// The segments of Path are separated by dots, a * matches any segment. The inputs are matched by
+// their id, e.g. inputs.*.processors matches the processors of every input.
+type Rule struct {
+	Path     string               `config:"path" yaml:"path" validate:"required"`
+	Strategy config.MergeStrategy `config:"strategy" yaml:"strategy,omitempty"`
+}
+
+// Matches returns true when the rule matches the path.
+func (r Rule) Matches(path []string) bool {
+	pattern := strings.Split(r.Path, ".")
+	if len(pattern) != len(path) {
+		return false
+	}
+	for i, p := range pattern {
+		if p != "*" && p != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Override is the content of an override file.
+type Override struct {
+	file    string
+	content map[string]interface{}
+}
+
+// Load reads the override file, it returns nil when the file does not exist.
+func Load(file string) (*Override, error) {
+	content, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.New(err,
+			fmt.Sprintf("could not read override file %s", file),
+			errors.TypeFilesystem,
+			errors.M(errors.MetaKeyPath, file))
+	}
+
+	var data map[string]interface{}
+	if err := yaml.Unmarshal(content, &data); err != nil {
+		return nil, errors.New(err,
+			fmt.Sprintf("could not parse override file %s", file),
+			errors.TypeConfig,
+			errors.M(errors.MetaKeyPath, file))
+	}
+
+	// normalize the nested objects and expand the dotted keys like the configuration files
+	c, err := config.NewConfigFrom(data)
+	if err != nil {
+		return nil, errors.New(err,
+			fmt.Sprintf("could not parse override file %s", file),
+			errors.TypeConfig,
+			errors.M(errors.MetaKeyPath, file))
+	}
+	m, err := c.ToMapStr()
+	if err != nil {
+		return nil, err
+	}
+	return &Override{file: file, content: m}, nil
+}
+
+// File returns the path of the override file.
+func (o *Override) File() string {
+	return o.file
+}
+
+// Result describes the paths of an override applied to a policy.
+type Result struct {
+	// Applied are the paths of the policy changed by the override.
+	Applied []string
+	// Rejected are the paths of the override which are not allowed, or which refer to an input
+	// missing from the policy.
+	Rejected []string
+}
+
+// Apply merges the override over the policy, only the paths allowed by the rules are merged.
+func (o *Override) Apply(policy map[string]interface{}, rules []Rule) Result {
+	var res Result
+	if o == nil {
+		return res
+	}
+	// the content is copied, the policy keeps references to the merged values
+	content, _ := deepCopy(o.content).(map[string]interface{})
+	applyMap(policy, content, nil, rules, &res)
+	sort.Strings(res.Applied)
+	sort.Strings(res.Rejected)
+	return res
+}
+
+func applyMap(policy, override map[string]interface{}, path []string, rules []Rule, res *Result) {
+	for k, v := range override {
+		p := append(append([]string{}, path...), k)
+		if rule, ok := match(rules, p); ok {
+			policy[k] = merge(policy[k], v, rule.Strategy)
+			res.Applied = append(res.Applied, strings.Join(p, "."))
+			continue
+		}
+
+		if !allowsUnder(rules, p) {
+			res.Rejected = append(res.Rejected, strings.Join(p, "."))
+			continue
+		}
+		switch ov := v.(type) {
+		case map[string]interface{}:
+			pv, ok := policy[k].(map[string]interface{})
+			if !ok {
+				pv = make(map[string]interface{})
+				policy[k] = pv
+			}
+			applyMap(pv, ov, p, rules, res)
+		case []interface{}:
+			pv, ok := policy[k].([]interface{})
+			if !ok {
+				res.Rejected = append(res.Rejected, strings.Join(p, "."))
+				continue
+			}
+			applyList(pv, ov, p, rules, res)
+		default:
+			res.Rejected = append(res.Rejected, strings.Join(p, "."))
+		}
+	}
+}
+
+// applyList merges the items of the override into the items of the policy with the same id.
+func applyList(policy, override []interface{}, path []string, rules []Rule, res *Result) {
+	for i, v := range override {
+		item, _ := v.(map[string]interface{})
+		id, _ := item["id"].(string)
+		if id == "" {
+			res.Rejected = append(res.Rejected, fmt.Sprintf("%s.%d", strings.Join(path, "."), i))
+			continue
+		}
+
+		p := append(append([]string{}, path...), id)
+		target, ok := findByID(policy, id)
+		if !ok {
+			res.Rejected = append(res.Rejected, strings.Join(p, "."))
+			continue
+		}
+		delete(item, "id")
+		applyMap(target, item, p, rules, res)
+	}
+}
+
+func findByID(items []interface{}, id string) (map[string]interface{}, bool) {
+	for _, v := range items {
+		item, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if itemID, _ := item["id"].(string); itemID == id {
+			return item, true
+		}
+	}
+	return nil, false
+}
+
+func match(rules []Rule, path []string) (Rule, bool) {
+	for _, r := range rules {
+		if r.Matches(path) {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
+
+// allowsUnder returns true when a rule matches a path under the prefix.
+func allowsUnder(rules []Rule, prefix []string) bool {
+	for _, r := range rules {
+		pattern := strings.Split(r.Path, ".")
+		if len(pattern) <= len(prefix) {
+			continue
+		}
+		if (Rule{Path: strings.Join(pattern[:len(prefix)], ".")}).Matches(prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func merge(policy, override interface{}, strategy config.MergeStrategy) interface{} {
+	switch strategy {
+	case config.MergeReplace:
+		return override
+	case config.MergeAppend, config.MergePrepend:
+		pl, pok := policy.([]interface{})
+		ol, ook := override.([]interface{})
+		if !pok || !ook {
+			return override
+		}
+		if strategy == config.MergePrepend {
+			return append(append([]interface{}{}, ol...), pl...)
+		}
+		return append(append([]interface{}{}, pl...), ol...)
+	}
+
+	// deep merge the objects, the other values are replaced
+	pm, pok := policy.(map[string]interface{})
+	om, ook := override.(map[string]interface{})
+	if !pok || !ook {
+		return override
+	}
+	for k, v := range om {
+		pm[k] = merge(pm[k], v, config.MergeDeep)
+	}
+	return pm
+}
+
+func deepCopy(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, child := range t {
+			m[k] = deepCopy(child)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, 0, len(t))
+		for _, child := range t {
+			l = append(l, deepCopy(child))
+		}
+		return l
+	}
+	return v
+}
+
+var (
+	appliedMx sync.RWMutex
+	applied   []string
+)
+
+// SetApplied records the paths of the policy changed by the override, they are reported to Fleet.
+func SetApplied(paths []string) {
+	appliedMx.Lock()
+	defer appliedMx.Unlock()
+	applied = append([]string{}, paths...)
+}
+
+// Applied returns the paths of the running policy changed by the override.
+func Applied() []string {
+	appliedMx.RLock()
+	defer appliedMx.RUnlock()
+	if len(applied) == 0 {
+		return nil
+	}
+	return append([]string{}, applied...)
+}
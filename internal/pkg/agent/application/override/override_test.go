@@ -0,0 +1,179 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package override
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent/internal/pkg/config"
+)
+
+const overrideContent = `
+outputs:
+  default:
+    queue.mem.events: 8192
+    hosts: ["http://other:9200"]
+inputs:
+  - id: logs
+    processors:
+      - add_tags:
+          tags: [host-specific]
+  - id: unknown
+    processors: []
+agent.monitoring.enabled: false
+`
+
+func testPolicy() map[string]interface{} {
+	return map[string]interface{}{
+		"outputs": map[string]interface{}{
+			"default": map[string]interface{}{
+				"type":  "elasticsearch",
+				"hosts": []interface{}{"http://localhost:9200"},
+			},
+		},
+		"inputs": []interface{}{
+			map[string]interface{}{
+				"id":   "logs",
+				"type": "logfile",
+				"processors": []interface{}{
+					map[string]interface{}{"add_fields": map[string]interface{}{"target": "policy"}},
+				},
+			},
+			map[string]interface{}{"id": "metrics", "type": "system/metrics"},
+		},
+		"agent": map[string]interface{}{
+			"monitoring": map[string]interface{}{"enabled": true},
+		},
+	}
+}
+
+func writeOverride(t *testing.T, content string) string {
+	file := filepath.Join(t.TempDir(), "elastic-agent.override.yml")
+	require.NoError(t, ioutil.WriteFile(file, []byte(content), 0600))
+	return file
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	o, err := Load(filepath.Join(t.TempDir(), "elastic-agent.override.yml"))
+	require.NoError(t, err)
+	assert.Nil(t, o)
+
+	// a missing override changes nothing
+	policy := testPolicy()
+	res := o.Apply(policy, []Rule{{Path: "outputs"}})
+	assert.Empty(t, res.Applied)
+	assert.Equal(t, testPolicy(), policy)
+}
+
+func TestLoadInvalidFile(t *testing.T) {
+	_, err := Load(writeOverride(t, "outputs: [\n"))
+	require.Error(t, err)
+}
+
+func TestApply(t *testing.T) {
+	o, err := Load(writeOverride(t, overrideContent))
+	require.NoError(t, err)
+	require.NotNil(t, o)
+
+	rules := []Rule{
+		{Path: "outputs.*.queue"},
+		{Path: "inputs.*.processors", Strategy: config.MergeAppend},
+	}
+	policy := testPolicy()
+	res := o.Apply(policy, rules)
+
+	assert.Equal(t, []string{"inputs.logs.processors", "outputs.default.queue"}, res.Applied)
+	assert.Equal(t, []string{"agent", "inputs.unknown", "outputs.default.hosts"}, res.Rejected)
+
+	output := policy["outputs"].(map[string]interface{})["default"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"http://localhost:9200"}, output["hosts"])
+	assert.Equal(t, map[string]interface{}{
+		"mem": map[string]interface{}{"events": uint64(8192)},
+	}, output["queue"])
+
+	logs := policy["inputs"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "logs", logs["id"])
+	assert.Len(t, logs["processors"], 2)
+	assert.Contains(t, logs["processors"].([]interface{})[1], "add_tags")
+
+	assert.Equal(t, true, policy["agent"].(map[string]interface{})["monitoring"].(map[string]interface{})["enabled"])
+
+	// the override is applied the same way to every policy
+	policy = testPolicy()
+	assert.Equal(t, res, o.Apply(policy, rules))
+	assert.Len(t, policy["inputs"].([]interface{})[0].(map[string]interface{})["processors"], 2)
+}
+
+func TestApplyStrategies(t *testing.T) {
+	o, err := Load(writeOverride(t, overrideContent))
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		strategy config.MergeStrategy
+		first    string
+		count    int
+	}{
+		"replace": {strategy: config.MergeReplace, first: "add_tags", count: 1},
+		"append":  {strategy: config.MergeAppend, first: "add_fields", count: 2},
+		"prepend": {strategy: config.MergePrepend, first: "add_tags", count: 2},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			policy := testPolicy()
+			o.Apply(policy, []Rule{{Path: "inputs.logs.processors", Strategy: tc.strategy}})
+
+			processors := policy["inputs"].([]interface{})[0].(map[string]interface{})["processors"].([]interface{})
+			require.Len(t, processors, tc.count)
+			assert.Contains(t, processors[0], tc.first)
+		})
+	}
+}
+
+func TestApplyNothingAllowed(t *testing.T) {
+	o, err := Load(writeOverride(t, overrideContent))
+	require.NoError(t, err)
+
+	policy := testPolicy()
+	res := o.Apply(policy, DefaultConfig().Allow)
+	assert.Empty(t, res.Applied)
+	assert.Equal(t, []string{"agent", "inputs", "outputs"}, res.Rejected)
+	assert.Equal(t, testPolicy(), policy)
+}
+
+func TestRuleUnpack(t *testing.T) {
+	c, err := config.NewConfigFrom(`
+allow:
+  - path: outputs.*.queue
+  - path: inputs.*.processors
+    strategy: insert_after
+`)
+	require.NoError(t, err)
+
+	cfg := DefaultConfig()
+	require.NoError(t, c.Unpack(cfg))
+	assert.Equal(t, []Rule{
+		{Path: "outputs.*.queue"},
+		{Path: "inputs.*.processors", Strategy: config.MergeAppend},
+	}, cfg.Allow)
+
+	c, err = config.NewConfigFrom(`allow: [{strategy: replace}]`)
+	require.NoError(t, err)
+	require.Error(t, c.Unpack(DefaultConfig()))
+}
+
+func TestApplied(t *testing.T) {
+	defer SetApplied(nil)
+
+	assert.Nil(t, Applied())
+	SetApplied([]string{"outputs.default.queue"})
+	assert.Equal(t, []string{"outputs.default.queue"}, Applied())
+	SetApplied(nil)
+	assert.Nil(t, Applied())
+}
@@ -148,6 +148,11 @@ func VersionedHome(base string) string {
 	return filepath.Join(base, "data", fmt.Sprintf("elastic-agent-%s", release.ShortCommit()))
 }
 
+// Run returns the directory holding the runtime files of the agent and of the applications it runs.
+func Run() string {
+	return filepath.Join(Home(), "run")
+}
+
 // Downloads returns the downloads directory for Agent
 func Downloads() string {
 	if downloadsPath == "" {
@@ -7,6 +7,7 @@ package paths
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/filelock"
 )
@@ -32,9 +33,24 @@ const defaultAgentStateStoreYmlFile = "state.yml"
 // defaultAgentStateStoreFile is the file that will contain the action that can be replayed after restart encrypted.
 const defaultAgentStateStoreFile = "state.enc"
 
+// defaultAgentAckSpoolFile is the file that contains the acks not sent to Fleet yet encrypted.
+const defaultAgentAckSpoolFile = "ack_spool.enc"
+
+// defaultAgentActionAuditFile is the name of the audit log of the actions handled by the agent.
+const defaultAgentActionAuditFile = "elastic-agent-actions"
+
+// defaultAgentEventsFile is the name of the files the events are reported to.
+const defaultAgentEventsFile = "elastic-agent-events"
+
 // defaultInputDPath return the location of the inputs.d.
 const defaultInputsDPath = "inputs.d"
 
+// defaultConfDPath is the location of the conf.d, the overlays of the configuration file.
+const defaultConfDPath = "conf.d"
+
+// defaultAgentOverrideFile is the name of the local override of the policies received from Fleet.
+const defaultAgentOverrideFile = "elastic-agent.override.yml"
+
 // AgentConfigYmlFile is a name of file used to store agent information
 func AgentConfigYmlFile() string {
 	return filepath.Join(Config(), defaultAgentFleetYmlFile)
@@ -86,7 +102,45 @@ func AgentStateStoreFile() string {
 	return filepath.Join(Home(), defaultAgentStateStoreFile)
 }
 
+// AgentAckSpoolFile is the file that contains the acks not sent to Fleet yet, they are sent after restart.
+func AgentAckSpoolFile() string {
+	return filepath.Join(Home(), defaultAgentAckSpoolFile)
+}
+
+// AgentEncryptedFiles are the files encrypted with the agent secret.
+func AgentEncryptedFiles() []string {
+	return []string{AgentConfigFile(), AgentStateStoreFile(), AgentAckSpoolFile()}
+}
+
+// AgentActionAuditFile is the base name of the audit log files of the actions handled by the agent.
+func AgentActionAuditFile() string {
+	return filepath.Join(Logs(), "actions", defaultAgentActionAuditFile)
+}
+
+// AgentEventsFile is the base name of the files the events are reported to by the file reporter.
+func AgentEventsFile() string {
+	return filepath.Join(Logs(), "events", defaultAgentEventsFile)
+}
+
 // AgentInputsDPath is directory that contains the fragment of inputs yaml for K8s deployment.
 func AgentInputsDPath() string {
 	return filepath.Join(Config(), defaultInputsDPath)
 }
+
+// AgentConfDPath is the directory containing the overlays merged over the configuration file.
+func AgentConfDPath() string {
+	return filepath.Join(Config(), defaultConfDPath)
+}
+
+// AgentOverrideFile is the local override file merged over the policies received from Fleet.
+func AgentOverrideFile() string {
+	return filepath.Join(Config(), defaultAgentOverrideFile)
+}
+
+// AgentEnvConfigFile is the overlay of the configuration file specific to the environment, e.g.
+// elastic-agent.production.yml next to elastic-agent.yml.
+func AgentEnvConfigFile(env string) string {
+	cfgFile := ConfigFile()
+	ext := filepath.Ext(cfgFile)
+	return fmt.Sprintf("%s.%s%s", strings.TrimSuffix(cfgFile, ext), env, ext)
+}
@@ -17,6 +17,10 @@ const (
 	// SocketPath is the socket path used when installed.
 	SocketPath = "unix:///run/elastic-agent.sock"
 
+	// ServiceUserSocketPath is the socket path used when installed with a service user, it is
+	// in the runtime directory systemd creates for the service.
+	ServiceUserSocketPath = "unix:///run/elastic-agent/elastic-agent.sock"
+
 	// ServiceName is the service name when installed.
 	ServiceName = "elastic-agent"
 
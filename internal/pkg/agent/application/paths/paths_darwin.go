@@ -17,6 +17,10 @@ const (
 	// SocketPath is the socket path used when installed.
 	SocketPath = "unix:///var/run/elastic-agent.sock"
 
+	// ServiceUserSocketPath is the socket path used when installed with a service user, the
+	// service always runs as root on macOS.
+	ServiceUserSocketPath = SocketPath
+
 	// ServiceName is the service name when installed.
 	ServiceName = "co.elastic.elastic-agent"
 
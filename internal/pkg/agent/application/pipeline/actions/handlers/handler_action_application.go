@@ -45,13 +45,23 @@ func (h *AppAction) Handle(ctx context.Context, a fleetapi.Action, acker store.F
 		return fmt.Errorf("invalid type, expected ActionApp and received %T", a)
 	}
 
-	appState, ok := h.srv.FindByInputType(action.InputType)
+	if err := PerformAppAction(h.log, h.srv, action); err != nil {
+		return err
+	}
+	return acker.Ack(ctx, action)
+}
+
+// PerformAppAction performs the action on the running application handling its input
+// type and records the outcome in the action, as it is reported back to Fleet. An error
+// is returned when the action parameters cannot be encoded, the action is not performed.
+func PerformAppAction(log *logger.Logger, srv *server.Server, action *fleetapi.ActionApp) error {
+	appState, ok := srv.FindByInputType(action.InputType)
 	if !ok {
 		// If the matching action is not found ack the action with the error for action result document
 		action.StartedAt = time.Now().UTC().Format(time.RFC3339Nano)
 		action.CompletedAt = action.StartedAt
 		action.Error = fmt.Sprintf("matching app is not found for action input: %s", action.InputType)
-		return acker.Ack(ctx, action)
+		return nil
 	}
 
 	params, err := action.MarshalMap()
@@ -64,21 +74,21 @@ func (h *AppAction) Handle(ctx context.Context, a fleetapi.Action, acker store.F
 	if action.Timeout > 0 {
 		timeout = time.Duration(action.Timeout) * time.Second
 		if timeout > maxActionTimeout {
-			h.log.Debugf("handlerAppAction: action '%v' timeout exceeds maximum allowed %v", action.InputType, maxActionTimeout)
+			log.Debugf("handlerAppAction: action '%v' timeout exceeds maximum allowed %v", action.InputType, maxActionTimeout)
 			err = errActionTimeoutInvalid
 		}
 	}
 
 	var res map[string]interface{}
 	if err == nil {
-		h.log.Debugf("handlerAppAction: action '%v' started with timeout: %v", action.InputType, timeout)
+		log.Debugf("handlerAppAction: action '%v' started with timeout: %v", action.InputType, timeout)
 		res, err = appState.PerformAction(action.InputType, params, timeout)
 	}
 	end := time.Now().UTC()
 
 	startFormatted := start.Format(time.RFC3339Nano)
 	endFormatted := end.Format(time.RFC3339Nano)
-	h.log.Debugf("handlerAppAction: action '%v' finished, startFormatted: %v, endFormatted: %v, err: %v", action.InputType, startFormatted, endFormatted, err)
+	log.Debugf("handlerAppAction: action '%v' finished, startFormatted: %v, endFormatted: %v, err: %v", action.InputType, startFormatted, endFormatted, err)
 	if err != nil {
 		action.StartedAt = startFormatted
 		action.CompletedAt = endFormatted
@@ -89,8 +99,7 @@ func (h *AppAction) Handle(ctx context.Context, a fleetapi.Action, acker store.F
 		action.Error = readMapString(res, "error", "")
 		appendActionResponse(action, action.InputType, res)
 	}
-
-	return acker.Ack(ctx, action)
+	return nil
 }
 
 var (
@@ -12,6 +12,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"sort"
+	"sync"
 	"time"
 
 	"gopkg.in/yaml.v2"
@@ -23,6 +24,7 @@ import (
 	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/storage"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/storage/store"
+	"github.com/elastic/elastic-agent/internal/pkg/artifact"
 	"github.com/elastic/elastic-agent/internal/pkg/config"
 	"github.com/elastic/elastic-agent/internal/pkg/fleetapi"
 	"github.com/elastic/elastic-agent/internal/pkg/fleetapi/client"
@@ -42,6 +44,9 @@ type PolicyChange struct {
 	config    *configuration.Configuration
 	store     storage.Store
 	setters   []actions.ClientSetter
+
+	// configMx guards the fleet configuration shared with the re-enrollment
+	configMx sync.Mutex
 }
 
 // NewPolicyChange creates a new PolicyChange handler.
@@ -72,6 +77,12 @@ func (h *PolicyChange) AddSetter(cs actions.ClientSetter) {
 	h.setters = append(h.setters, cs)
 }
 
+// ConfigLock returns the lock held while the shared fleet configuration is
+// updated and persisted.
+func (h *PolicyChange) ConfigLock() sync.Locker {
+	return &h.configMx
+}
+
 // Handle handles policy change action.
 func (h *PolicyChange) Handle(ctx context.Context, a fleetapi.Action, acker store.FleetAcker) error {
 	h.log.Debugf("handlerPolicyChange: action '%+v' received", a)
@@ -115,6 +126,9 @@ func (h *PolicyChange) handleFleetServerHosts(ctx context.Context, c *config.Con
 	if err != nil {
 		return errors.New(err, "could not parse the configuration from the policy", errors.TypeConfig)
 	}
+
+	h.configMx.Lock()
+	defer h.configMx.Unlock()
 	if clientEqual(h.config.Fleet.Client, cfg.Fleet.Client) {
 		// already the same hosts
 		return nil
@@ -158,7 +172,7 @@ func (h *PolicyChange) handleFleetServerHosts(ctx context.Context, c *config.Con
 	_, _ = io.Copy(ioutil.Discard, resp.Body)
 	resp.Body.Close()
 
-	reader, err := fleetToReader(h.agentInfo, h.config)
+	reader, err := FleetToReader(h.agentInfo.AgentID(), h.agentInfo.Headers(), h.config)
 	if err != nil {
 		return errors.New(
 			err, "fail to persist new Fleet Server API client hosts",
@@ -199,15 +213,25 @@ func clientEqual(k1 remote.Config, k2 remote.Config) bool {
 	return true
 }
 
-func fleetToReader(agentInfo *info.AgentInfo, cfg *configuration.Configuration) (io.Reader, error) {
+// FleetToReader returns the content of the fleet configuration persisted in fleet.enc, the agent
+// headers and the staging download source set at enrollment are kept.
+func FleetToReader(agentID string, headers map[string]string, cfg *configuration.Configuration) (io.Reader, error) {
+	agentConfig := map[string]interface{}{
+		"id":               agentID,
+		"logging.level":    cfg.Settings.LoggingConfig.Level,
+		"monitoring.http":  cfg.Settings.MonitoringConfig.HTTP,
+		"monitoring.pprof": cfg.Settings.MonitoringConfig.Pprof,
+	}
+	if len(headers) > 0 {
+		agentConfig["headers"] = headers
+	}
+	if cfg.Settings.DownloadConfig != nil && cfg.Settings.DownloadConfig.SourceURI != artifact.DefaultSourceURI {
+		agentConfig["download.sourceURI"] = cfg.Settings.DownloadConfig.SourceURI
+	}
+
 	configToStore := map[string]interface{}{
 		"fleet": cfg.Fleet,
-		"agent": map[string]interface{}{
-			"id":               agentInfo.AgentID(),
-			"logging.level":    cfg.Settings.LoggingConfig.Level,
-			"monitoring.http":  cfg.Settings.MonitoringConfig.HTTP,
-			"monitoring.pprof": cfg.Settings.MonitoringConfig.Pprof,
-		},
+		"agent": agentConfig,
 	}
 
 	data, err := yaml.Marshal(configToStore)
@@ -6,6 +6,10 @@ package handlers
 
 import (
 	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"sync"
 	"testing"
 
@@ -16,10 +20,13 @@ import (
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
 
 	"github.com/elastic/elastic-agent/internal/pkg/config"
 	"github.com/elastic/elastic-agent/internal/pkg/fleetapi"
 	noopacker "github.com/elastic/elastic-agent/internal/pkg/fleetapi/acker/noop"
+	"github.com/elastic/elastic-agent/internal/pkg/fleetapi/client"
+	"github.com/elastic/elastic-agent/internal/pkg/testutils"
 	"github.com/elastic/elastic-agent/pkg/core/logger"
 )
 
@@ -154,6 +161,101 @@ func TestPolicyAcked(t *testing.T) {
 	})
 }
 
+func TestPolicyChangePersistsFleetConfig(t *testing.T) {
+	testutils.InitStorage(t)
+
+	log, _ := logger.New("", false)
+	agentInfo, err := info.NewAgentInfo(true)
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	var saved []byte
+	store := storage.NewHandlerStore(func(in io.Reader) error {
+		b, err := ioutil.ReadAll(in)
+		saved = b
+		return err
+	})
+
+	cfg := configuration.DefaultConfiguration()
+	cfg.Fleet.Enabled = true
+	cfg.Fleet.AccessAPIKey = "api-key"
+	cfg.Settings.DownloadConfig.SourceURI = "https://staging.elastic.co/downloads/"
+	setter := &testSetter{}
+	handler := NewPolicyChange(log, (&mockEmitter{}).Emitter, agentInfo, cfg, store, setter)
+
+	action := &fleetapi.ActionPolicyChange{
+		ActionID:   "TestPolicyChangePersistsFleetConfig",
+		ActionType: "POLICY_CHANGE",
+		Policy: map[string]interface{}{
+			"fleet": map[string]interface{}{"hosts": []interface{}{srv.URL}},
+		},
+	}
+	require.NoError(t, handler.Handle(context.Background(), action, noopacker.NewAcker()))
+	require.NotNil(t, setter.client)
+
+	stored := struct {
+		Fleet struct {
+			AccessAPIKey string   `yaml:"access_api_key"`
+			Hosts        []string `yaml:"hosts"`
+		} `yaml:"fleet"`
+		Agent map[string]interface{} `yaml:"agent"`
+	}{}
+	require.NoError(t, yaml.Unmarshal(saved, &stored))
+	assert.Equal(t, "api-key", stored.Fleet.AccessAPIKey)
+	assert.Equal(t, []string{srv.URL}, stored.Fleet.Hosts)
+	assert.Equal(t, agentInfo.AgentID(), stored.Agent["id"])
+	assert.Equal(t, "https://staging.elastic.co/downloads/", stored.Agent["download.sourceURI"])
+}
+
+func TestFleetToReader(t *testing.T) {
+	testCases := map[string]struct {
+		headers           map[string]string
+		sourceURI         string
+		expectedHeaders   interface{}
+		expectedSourceURI interface{}
+	}{
+		"defaults are not persisted": {
+			sourceURI: "https://artifacts.elastic.co/downloads/",
+		},
+		"headers and source URI set at enrollment": {
+			headers:           map[string]string{"X-Custom": "value"},
+			sourceURI:         "https://staging.elastic.co/downloads/",
+			expectedHeaders:   map[interface{}]interface{}{"X-Custom": "value"},
+			expectedSourceURI: "https://staging.elastic.co/downloads/",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			cfg := configuration.DefaultConfiguration()
+			cfg.Settings.DownloadConfig.SourceURI = tc.sourceURI
+
+			reader, err := FleetToReader("agent-id", tc.headers, cfg)
+			require.NoError(t, err)
+			b, err := ioutil.ReadAll(reader)
+			require.NoError(t, err)
+
+			stored := struct {
+				Agent map[string]interface{} `yaml:"agent"`
+			}{}
+			require.NoError(t, yaml.Unmarshal(b, &stored))
+			assert.Equal(t, "agent-id", stored.Agent["id"])
+			assert.Equal(t, tc.expectedHeaders, stored.Agent["headers"])
+			assert.Equal(t, tc.expectedSourceURI, stored.Agent["download.sourceURI"])
+		})
+	}
+}
+
+type testSetter struct {
+	client client.Sender
+}
+
+func (s *testSetter) SetClient(c client.Sender) { s.client = c }
+
 type testAcker struct {
 	acked     []string
 	ackedLock sync.Mutex
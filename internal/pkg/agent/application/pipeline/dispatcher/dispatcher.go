@@ -27,10 +27,21 @@ type ActionDispatcher struct {
 	log      *logger.Logger
 	handlers actionHandlers
 	def      actions.Handler
+	auditor  store.Auditor
+}
+
+// Option configures optional behaviour of the dispatcher.
+type Option func(*ActionDispatcher)
+
+// WithAuditor records every dispatched action, its handler and ack result with the auditor.
+func WithAuditor(auditor store.Auditor) Option {
+	return func(ad *ActionDispatcher) {
+		ad.auditor = auditor
+	}
 }
 
 // New creates a new action dispatcher.
-func New(ctx context.Context, log *logger.Logger, def actions.Handler) (*ActionDispatcher, error) {
+func New(ctx context.Context, log *logger.Logger, def actions.Handler, opts ...Option) (*ActionDispatcher, error) {
 	var err error
 	if log == nil {
 		log, err = logger.New("action_dispatcher", false)
@@ -43,12 +54,16 @@ func New(ctx context.Context, log *logger.Logger, def actions.Handler) (*ActionD
 		return nil, errors.New("missing default handler")
 	}
 
-	return &ActionDispatcher{
+	ad := &ActionDispatcher{
 		ctx:      ctx,
 		log:      log,
 		handlers: make(actionHandlers),
 		def:      def,
-	}, nil
+	}
+	for _, opt := range opts {
+		opt(ad)
+	}
+	return ad, nil
 }
 
 // Register registers a new handler for action.
@@ -79,6 +94,7 @@ func (ad *ActionDispatcher) key(a fleetapi.Action) string {
 // ctx is used here ONLY to carry the span, for cancelation use the cancel
 // function of the ActionDispatcher.ctx.
 func (ad *ActionDispatcher) Dispatch(ctx context.Context, acker store.FleetAcker, actions ...fleetapi.Action) (err error) {
+	source := store.AuditSource(ctx)
 	span, ctx := apm.StartSpan(ctx, "dispatch", "app.internal")
 	defer func() {
 		apm.CaptureError(ctx, err).Send()
@@ -90,6 +106,7 @@ func (ad *ActionDispatcher) Dispatch(ctx context.Context, acker store.FleetAcker
 	ctx, cancel := context.WithCancel(ad.ctx)
 	defer cancel()
 	ctx = apm.ContextWithSpan(ctx, span)
+	ctx = store.WithAuditSource(ctx, source)
 
 	if len(actions) == 0 {
 		ad.log.Debug("No action to dispatch")
@@ -107,7 +124,7 @@ func (ad *ActionDispatcher) Dispatch(ctx context.Context, acker store.FleetAcker
 			return err
 		}
 
-		if err := ad.dispatchAction(action, acker); err != nil {
+		if err := ad.dispatchAction(ctx, action, acker); err != nil {
 			ad.log.Debugf("Failed to dispatch action '%+v', error: %+v", action, err)
 			return err
 		}
@@ -117,13 +134,46 @@ func (ad *ActionDispatcher) Dispatch(ctx context.Context, acker store.FleetAcker
 	return acker.Commit(ctx)
 }
 
-func (ad *ActionDispatcher) dispatchAction(a fleetapi.Action, acker store.FleetAcker) error {
+func (ad *ActionDispatcher) dispatchAction(ctx context.Context, a fleetapi.Action, acker store.FleetAcker) error {
 	handler, found := ad.handlers[(ad.key(a))]
 	if !found {
-		return ad.def.Handle(ad.ctx, a, acker)
+		handler = ad.def
+	}
+
+	if ad.auditor == nil {
+		return handler.Handle(ad.ctx, a, acker)
 	}
 
-	return handler.Handle(ad.ctx, a, acker)
+	auditAcker := &auditAcker{FleetAcker: acker}
+	err := handler.Handle(ad.ctx, a, auditAcker)
+
+	entry := store.NewAuditEntry(ctx, a, store.AuditResultSuccess)
+	entry.Handler = reflect.TypeOf(handler).String()
+	entry.Acked = auditAcker.acked
+	if auditAcker.err != nil {
+		entry.AckError = auditAcker.err.Error()
+	}
+	if err != nil {
+		entry.Result = store.AuditResultFailure
+		entry.Error = err.Error()
+	}
+	ad.auditor.Audit(entry)
+
+	return err
+}
+
+// auditAcker keeps track of the ack result of an action for the audit log.
+type auditAcker struct {
+	store.FleetAcker
+	acked bool
+	err   error
+}
+
+func (a *auditAcker) Ack(ctx context.Context, action fleetapi.Action) error {
+	err := a.FleetAcker.Ack(ctx, action)
+	a.acked = err == nil
+	a.err = err
+	return err
 }
 
 func detectTypes(actions []fleetapi.Action) []string {
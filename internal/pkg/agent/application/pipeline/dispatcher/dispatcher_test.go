@@ -6,6 +6,7 @@ package dispatcher
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -160,4 +161,53 @@ func TestActionDispatcher(t *testing.T) {
 		err = d.Register(&mockAction{}, success2)
 		require.Error(t, err)
 	})
+
+	t.Run("Dispatched actions are audited", func(t *testing.T) {
+		auditor := &recordingAuditor{}
+		def := &mockHandler{}
+		d, err := New(context.Background(), nil, def, WithAuditor(auditor))
+		require.NoError(t, err)
+
+		success := &mockHandler{}
+		success.On("Handle", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			acker, _ := args.Get(2).(store.FleetAcker)
+			action, _ := args.Get(1).(fleetapi.Action)
+			require.NoError(t, acker.Ack(context.Background(), action))
+		}).Return(nil).Once()
+		failure := &mockHandler{}
+		failure.On("Handle", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("handler failed")).Once()
+		require.NoError(t, d.Register(&mockAction{}, success))
+		require.NoError(t, d.Register(&mockOtherAction{}, failure))
+
+		action1 := &mockAction{}
+		action1.On("ID").Return("id1")
+		action1.On("Type").Return("TYPE1")
+		action2 := &mockOtherAction{}
+		action2.On("ID").Return("id2")
+		action2.On("Type").Return("TYPE2")
+
+		ctx := store.WithAuditSource(context.Background(), store.AuditSourceReplay)
+		err = d.Dispatch(ctx, ack, action1, action2)
+		require.Error(t, err)
+
+		require.Len(t, auditor.entries, 2)
+		require.Equal(t, "id1", auditor.entries[0].ActionID)
+		require.Equal(t, store.AuditResultSuccess, auditor.entries[0].Result)
+		require.Equal(t, store.AuditSourceReplay, auditor.entries[0].Source)
+		require.Equal(t, "*dispatcher.mockHandler", auditor.entries[0].Handler)
+		require.True(t, auditor.entries[0].Acked)
+
+		require.Equal(t, "id2", auditor.entries[1].ActionID)
+		require.Equal(t, store.AuditResultFailure, auditor.entries[1].Result)
+		require.Equal(t, "handler failed", auditor.entries[1].Error)
+		require.False(t, auditor.entries[1].Acked)
+	})
+}
+
+type recordingAuditor struct {
+	entries []store.AuditEntry
+}
+
+func (r *recordingAuditor) Audit(e store.AuditEntry) {
+	r.entries = append(r.entries, e)
 }
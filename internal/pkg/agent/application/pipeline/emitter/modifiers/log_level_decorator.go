@@ -0,0 +1,44 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package modifiers
+
+import (
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/info"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/program"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/transpiler"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+const (
+	agentLoggingKey      = "agent.logging"
+	fleetAgentKey        = "fleet.agent"
+	fleetAgentLoggingKey = "fleet.agent.logging"
+)
+
+// InjectLogLevels sets the level override of a program into its configuration, as agent.logging.level
+// and fleet.agent.logging.level when the configuration holds fleet information.
+func InjectLogLevels(_ *info.AgentInfo, _ string, _ *transpiler.AST, programsToRun []program.Program) ([]program.Program, error) {
+	for _, p := range programsToRun {
+		level, ok := logger.ProgramLevel(p.Spec.Name)
+		if !ok {
+			continue
+		}
+
+		if err := transpiler.Insert(p.Config, levelKey(level.String()), agentLoggingKey); err != nil {
+			return nil, errors.New(err, "inserting log level of %s failed", p.Spec.Name)
+		}
+		if _, ok := transpiler.Lookup(p.Config, fleetAgentKey); ok {
+			if err := transpiler.Insert(p.Config, levelKey(level.String()), fleetAgentLoggingKey); err != nil {
+				return nil, errors.New(err, "inserting log level of %s failed", p.Spec.Name)
+			}
+		}
+	}
+	return programsToRun, nil
+}
+
+func levelKey(level string) *transpiler.Key {
+	return transpiler.NewKey("level", transpiler.NewStrVal(level))
+}
@@ -0,0 +1,54 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package modifiers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/program"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/transpiler"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+func TestInjectLogLevels(t *testing.T) {
+	defer logger.ApplyLevelsConfig(nil)
+	logger.SetProgramLevel("filebeat", logp.DebugLevel)
+
+	newProgram := func(name string, cfg map[string]interface{}) program.Program {
+		ast, err := transpiler.NewAST(cfg)
+		require.NoError(t, err)
+		return program.Program{Spec: program.Spec{Name: name}, Config: ast}
+	}
+	programs := []program.Program{
+		newProgram("Filebeat", map[string]interface{}{
+			"agent": map[string]interface{}{"logging": map[string]interface{}{"level": "info"}},
+			"fleet": map[string]interface{}{"agent": map[string]interface{}{"id": "agent-id"}},
+		}),
+		newProgram("Metricbeat", map[string]interface{}{
+			"agent": map[string]interface{}{"logging": map[string]interface{}{"level": "info"}},
+		}),
+	}
+
+	programs, err := InjectLogLevels(nil, "default", nil, programs)
+	require.NoError(t, err)
+
+	level, ok := transpiler.LookupString(programs[0].Config, "agent.logging.level")
+	require.True(t, ok)
+	assert.Equal(t, "debug", level)
+	level, ok = transpiler.LookupString(programs[0].Config, "fleet.agent.logging.level")
+	require.True(t, ok)
+	assert.Equal(t, "debug", level)
+	id, ok := transpiler.LookupString(programs[0].Config, "fleet.agent.id")
+	require.True(t, ok)
+	assert.Equal(t, "agent-id", id)
+
+	level, ok = transpiler.LookupString(programs[1].Config, "agent.logging.level")
+	require.True(t, ok)
+	assert.Equal(t, "info", level)
+}
@@ -0,0 +1,83 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package modifiers
+
+import (
+	"strings"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/override"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/transpiler"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+// InjectOverride merges the local override file over the policy, only the paths allowed by the
+// rules, `agent.override.allow`, are merged. The file is read for every policy so a change of the
+// file is applied with the next policy.
+func InjectOverride(file string, rules []override.Rule) func(*logger.Logger, *transpiler.AST) error {
+	return func(log *logger.Logger, rootAst *transpiler.AST) error {
+		o, err := override.Load(file)
+		if err != nil {
+			return err
+		}
+		if o == nil || len(rules) == 0 {
+			// no override file or nothing allowed; skip
+			override.SetApplied(nil)
+			return nil
+		}
+
+		policy, err := rootAst.Map()
+		if err != nil {
+			return err
+		}
+		res := o.Apply(policy, rules)
+		for _, p := range res.Rejected {
+			log.Warnf("override of %s from %s is not allowed by agent.override.allow, ignoring it", p, file)
+		}
+		if len(res.Applied) > 0 {
+			ast, err := transpiler.NewAST(policy)
+			if err != nil {
+				return errors.New(err, "applying override failed")
+			}
+			for _, k := range touchedKeys(res.Applied) {
+				node, ok := transpiler.Lookup(ast, k)
+				if !ok {
+					continue
+				}
+				value, ok := node.Value().(transpiler.Node)
+				if !ok {
+					continue
+				}
+				switch value.(type) {
+				case *transpiler.Dict, *transpiler.List:
+				default:
+					// the top level values of a policy are objects or lists
+					log.Warnf("override of %s from %s is not an object or a list, ignoring it", k, file)
+					continue
+				}
+				if err := transpiler.Insert(rootAst, value, k); err != nil {
+					return errors.New(err, "applying override failed")
+				}
+			}
+			log.Infof("override from %s applied to %v", file, res.Applied)
+		}
+		override.SetApplied(res.Applied)
+		return nil
+	}
+}
+
+// touchedKeys returns the top level keys of the paths.
+func touchedKeys(paths []string) []string {
+	seen := make(map[string]bool)
+	keys := make([]string, 0, len(paths))
+	for _, p := range paths {
+		k := strings.SplitN(p, ".", 2)[0]
+		if !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,81 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package modifiers
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/override"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/transpiler"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+func TestInjectOverride(t *testing.T) {
+	defer override.SetApplied(nil)
+
+	log, err := logger.New("", false)
+	require.NoError(t, err)
+
+	file := filepath.Join(t.TempDir(), "elastic-agent.override.yml")
+	require.NoError(t, ioutil.WriteFile(file, []byte(`
+outputs.default.queue.mem.events: 8192
+inputs:
+  - id: logs
+    tags: [host-specific]
+`), 0600))
+
+	newPolicy := func() *transpiler.AST {
+		ast, err := transpiler.NewAST(map[string]interface{}{
+			"outputs": map[string]interface{}{
+				"default": map[string]interface{}{"type": "elasticsearch"},
+			},
+			"inputs": []interface{}{
+				map[string]interface{}{"id": "logs", "type": "logfile"},
+			},
+		})
+		require.NoError(t, err)
+		return ast
+	}
+
+	t.Run("nothing allowed", func(t *testing.T) {
+		ast := newPolicy()
+		require.NoError(t, InjectOverride(file, nil)(log, ast))
+
+		_, ok := transpiler.Lookup(ast, "outputs.default.queue")
+		assert.False(t, ok)
+		assert.Nil(t, override.Applied())
+	})
+
+	t.Run("allowed paths", func(t *testing.T) {
+		ast := newPolicy()
+		rules := []override.Rule{{Path: "outputs.*.queue"}, {Path: "inputs.*.tags"}}
+		require.NoError(t, InjectOverride(file, rules)(log, ast))
+
+		events, ok := transpiler.Lookup(ast, "outputs.default.queue.mem.events")
+		require.True(t, ok)
+		assert.Equal(t, "8192", events.Value().(transpiler.Node).String())
+		outputType, ok := transpiler.LookupString(ast, "outputs.default.type")
+		require.True(t, ok)
+		assert.Equal(t, "elasticsearch", outputType)
+
+		tags, ok := transpiler.Lookup(ast, "inputs.0.tags")
+		require.True(t, ok)
+		assert.Equal(t, "[host-specific]", tags.Value().(transpiler.Node).String())
+		assert.Equal(t, []string{"inputs.logs.tags", "outputs.default.queue"}, override.Applied())
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		override.SetApplied([]string{"outputs.default.queue"})
+		ast := newPolicy()
+		missing := filepath.Join(t.TempDir(), "elastic-agent.override.yml")
+		require.NoError(t, InjectOverride(missing, []override.Rule{{Path: "outputs"}})(log, ast))
+		assert.Nil(t, override.Applied())
+	})
+}
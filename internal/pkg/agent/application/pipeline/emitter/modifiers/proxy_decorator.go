@@ -0,0 +1,38 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package modifiers
+
+import (
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/transpiler"
+	"github.com/elastic/elastic-agent/internal/pkg/config"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+// InjectProxyRules injects the proxy rules of the local configuration, `agent.proxy`, into the
+// policy. The rules are local to the host, they replace the ones of the policy so the outputs are
+// routed like the connections of the agent.
+func InjectProxyRules(cfg *config.Config) func(*logger.Logger, *transpiler.AST) error {
+	return func(_ *logger.Logger, rootAst *transpiler.AST) error {
+		config, err := cfg.ToMapStr()
+		if err != nil {
+			return err
+		}
+		ast, err := transpiler.NewAST(config)
+		if err != nil {
+			return err
+		}
+		proxy, ok := transpiler.Lookup(ast, "agent.proxy")
+		if !ok {
+			// no proxy rules from configuration; skip
+			return nil
+		}
+
+		if err := transpiler.Insert(rootAst, proxy, "agent"); err != nil {
+			return errors.New(err, "inserting proxy rules failed")
+		}
+		return nil
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+// Package reenroll enrolls a managed agent again with Fleet using the
+// enrollment token read from a file, it is used when the API key of the agent
+// is rejected by Fleet, e.g. after the enrollment token was rotated.
+package reenroll
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/info"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/pipeline/actions"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/pipeline/actions/handlers"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/configuration"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/storage"
+	"github.com/elastic/elastic-agent/internal/pkg/filewatcher"
+	"github.com/elastic/elastic-agent/internal/pkg/fleetapi"
+	"github.com/elastic/elastic-agent/internal/pkg/fleetapi/client"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+const defaultWatchPeriod = 30 * time.Second
+
+type agentInfo interface {
+	AgentID() string
+	Headers() map[string]string
+	ReloadID() error
+}
+
+// enrollFunc sends the enrollment request to Fleet.
+type enrollFunc func(ctx context.Context, cfg *configuration.FleetAgentConfig, req *fleetapi.EnrollRequest) (*fleetapi.EnrollResponse, error)
+
+// Reenroller enrolls the agent again with the token from file, persists the
+// new API key and updates the registered clients.
+type Reenroller struct {
+	log       *logger.Logger
+	agentInfo agentInfo
+	config    *configuration.Configuration
+	store     storage.Store
+	enroll    enrollFunc
+	// configMx guards the fleet configuration shared with the policy change handler
+	configMx sync.Locker
+
+	mx      sync.Mutex
+	setters []actions.ClientSetter
+	// pending is true when the last re-enrollment failed, it is retried when the token file changes
+	pending bool
+}
+
+// New creates a new Reenroller, configMx is the lock held by the other writers of the
+// fleet configuration.
+func New(log *logger.Logger, agentInfo *info.AgentInfo, cfg *configuration.Configuration, store storage.Store, configMx sync.Locker) *Reenroller {
+	return &Reenroller{
+		log:       log,
+		agentInfo: agentInfo,
+		config:    cfg,
+		store:     store,
+		configMx:  configMx,
+		enroll: func(ctx context.Context, cfg *configuration.FleetAgentConfig, req *fleetapi.EnrollRequest) (*fleetapi.EnrollResponse, error) {
+			c, err := client.NewWithConfig(log, cfg.Client)
+			if err != nil {
+				return nil, err
+			}
+			return fleetapi.NewEnrollCmd(c).Execute(ctx, req)
+		},
+	}
+}
+
+// AddSetter adds a setter into a collection of client setters updated after
+// a successful re-enrollment.
+func (r *Reenroller) AddSetter(cs actions.ClientSetter) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	r.setters = append(r.setters, cs)
+}
+
+// Reenroll enrolls the agent again. The current agent ID is sent along the
+// request so Fleet Server can preserve it, when Fleet assigns a new ID the
+// new one is persisted and reloaded.
+func (r *Reenroller) Reenroll(ctx context.Context) (err error) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	defer func() {
+		r.pending = err != nil
+	}()
+
+	r.configMx.Lock()
+	defer r.configMx.Unlock()
+
+	fleetCfg := r.config.Fleet
+	if !fleetCfg.Enrollment.ReenrollEnabled() {
+		return errors.New("re-enrollment is not configured", errors.TypeConfig)
+	}
+
+	token, err := ReadTokenFile(fleetCfg.Enrollment.TokenFile)
+	if err != nil {
+		return err
+	}
+
+	metadata, err := info.Metadata()
+	if err != nil {
+		return errors.New(err, "acquiring metadata failed")
+	}
+
+	prevID := r.agentInfo.AgentID()
+	resp, err := r.enroll(ctx, fleetCfg, &fleetapi.EnrollRequest{
+		EnrollAPIKey: token,
+		Type:         fleetapi.PermanentEnroll,
+		ID:           prevID,
+		Metadata: fleetapi.Metadata{
+			Local:        metadata,
+			UserProvided: make(map[string]interface{}),
+		},
+	})
+	if err != nil {
+		return errors.New(err, "fail to re-enroll with fleet-server", errors.TypeNetwork)
+	}
+
+	newID := resp.Item.ID
+	if newID == "" {
+		newID = prevID
+	}
+
+	// the new key is persisted from a copy, the shared configuration is only updated on success
+	cfgCopy := *r.config
+	fleetCopy := *fleetCfg
+	fleetCopy.AccessAPIKey = resp.Item.AccessAPIKey
+	cfgCopy.Fleet = &fleetCopy
+	if err := r.persist(newID, &cfgCopy); err != nil {
+		return err
+	}
+
+	if newID != prevID {
+		r.log.Warnw("Fleet assigned a new agent ID during re-enrollment", "agent.id", newID, "previous.agent.id", prevID)
+		if err := r.agentInfo.ReloadID(); err != nil {
+			return errors.New(err, "failed to reload agent ID after re-enrollment")
+		}
+	}
+
+	c, err := client.NewAuthWithConfig(r.log, fleetCopy.AccessAPIKey, fleetCopy.Client)
+	if err != nil {
+		return errors.New(err, "fail to create API client after re-enrollment", errors.TypeNetwork)
+	}
+	// the policy change handler persists the fleet configuration from the shared configuration,
+	// it must hold the new key, configMx is held so the handler does not observe a partial update
+	fleetCfg.AccessAPIKey = fleetCopy.AccessAPIKey
+
+	for _, setter := range r.setters {
+		setter.SetClient(c)
+	}
+
+	r.log.Infow("Elastic Agent re-enrolled with Fleet", "agent.id", newID)
+	return nil
+}
+
+func (r *Reenroller) persist(agentID string, cfg *configuration.Configuration) error {
+	reader, err := handlers.FleetToReader(agentID, r.agentInfo.Headers(), cfg)
+	if err != nil {
+		return errors.New(err, "fail to persist re-enrolled configuration", errors.TypeUnexpected)
+	}
+
+	if err := r.store.Save(reader); err != nil {
+		return errors.New(err, "fail to persist re-enrolled configuration", errors.TypeFilesystem)
+	}
+	return nil
+}
+
+// Watch watches the enrollment token file until the context is done, a re-enrollment which failed
+// is retried as soon as the token file changes, e.g. when the rotated token is written.
+func (r *Reenroller) Watch(ctx context.Context, period time.Duration) {
+	if period <= 0 {
+		period = defaultWatchPeriod
+	}
+	tokenFile := r.config.Fleet.Enrollment.TokenFile
+
+	w, err := filewatcher.New(r.log, filewatcher.DefaultComparer)
+	if err != nil {
+		r.log.Errorf("could not watch enrollment token file %s: %v", tokenFile, err)
+		return
+	}
+	w.Watch(tokenFile)
+	// record the current state of the file
+	if _, err := w.Update(); err != nil {
+		r.log.Debugf("could not read enrollment token file %s: %v", tokenFile, err)
+	}
+
+	t := time.NewTicker(period)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+
+		s, err := w.Update()
+		if err != nil {
+			r.log.Debugf("could not read enrollment token file %s: %v", tokenFile, err)
+			w.Invalidate()
+			continue
+		}
+		if len(s.Updated) == 0 {
+			continue
+		}
+
+		r.log.Infof("enrollment token file %s changed", tokenFile)
+		if !r.isPending() {
+			continue
+		}
+		if err := r.Reenroll(ctx); err != nil {
+			r.log.Errorw("Failed to re-enroll the elastic agent with the new enrollment token", "error.message", err)
+		}
+	}
+}
+
+func (r *Reenroller) isPending() bool {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	return r.pending
+}
+
+// ReadTokenFile reads the enrollment token from file, surrounding whitespace
+// is ignored.
+func ReadTokenFile(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", errors.New(err, "reading enrollment token file", errors.TypeFilesystem, errors.M(errors.MetaKeyPath, path))
+	}
+
+	token := strings.TrimSpace(string(b))
+	if token == "" {
+		return "", errors.New(fmt.Sprintf("enrollment token file %s is empty", path), errors.TypeConfig, errors.M(errors.MetaKeyPath, path))
+	}
+	return token, nil
+}
@@ -0,0 +1,248 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package reenroll
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/configuration"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/storage"
+	"github.com/elastic/elastic-agent/internal/pkg/fleetapi"
+	"github.com/elastic/elastic-agent/internal/pkg/fleetapi/client"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+type testAgentInfo struct {
+	id       string
+	reloaded int
+}
+
+func (a *testAgentInfo) AgentID() string { return a.id }
+
+func (a *testAgentInfo) Headers() map[string]string {
+	return map[string]string{"X-Custom": "value"}
+}
+
+func (a *testAgentInfo) ReloadID() error {
+	a.reloaded++
+	return nil
+}
+
+type testSetter struct {
+	client client.Sender
+}
+
+func (s *testSetter) SetClient(c client.Sender) { s.client = c }
+
+func TestReenroll(t *testing.T) {
+	testCases := map[string]struct {
+		responseID     string
+		expectedID     string
+		expectedReload int
+	}{
+		"same agent ID":  {responseID: "agent-id", expectedID: "agent-id"},
+		"empty agent ID": {responseID: "", expectedID: "agent-id"},
+		"new agent ID":   {responseID: "new-agent-id", expectedID: "new-agent-id", expectedReload: 1},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			tokenFile := filepath.Join(t.TempDir(), "token")
+			require.NoError(t, os.WriteFile(tokenFile, []byte("  enroll-token\n"), 0600))
+
+			r, agentInfo, saved := newTestReenroller(t, tokenFile)
+			r.enroll = func(_ context.Context, _ *configuration.FleetAgentConfig, req *fleetapi.EnrollRequest) (*fleetapi.EnrollResponse, error) {
+				assert.Equal(t, "enroll-token", req.EnrollAPIKey)
+				assert.Equal(t, "agent-id", req.ID)
+				return &fleetapi.EnrollResponse{
+					Item: fleetapi.EnrollItemResponse{ID: tc.responseID, AccessAPIKey: "new-api-key"},
+				}, nil
+			}
+
+			setter := &testSetter{}
+			r.AddSetter(setter)
+
+			require.NoError(t, r.Reenroll(context.Background()))
+			assert.Equal(t, "new-api-key", r.config.Fleet.AccessAPIKey)
+			assert.Equal(t, tc.expectedReload, agentInfo.reloaded)
+			assert.NotNil(t, setter.client)
+
+			stored := struct {
+				Fleet struct {
+					AccessAPIKey string `yaml:"access_api_key"`
+				} `yaml:"fleet"`
+				Agent map[string]interface{} `yaml:"agent"`
+			}{}
+			require.NoError(t, yaml.Unmarshal(*saved, &stored))
+			assert.Equal(t, "new-api-key", stored.Fleet.AccessAPIKey)
+			assert.Equal(t, tc.expectedID, stored.Agent["id"])
+			assert.Equal(t, map[interface{}]interface{}{"X-Custom": "value"}, stored.Agent["headers"])
+			assert.Equal(t, "https://staging.elastic.co/downloads/", stored.Agent["download.sourceURI"])
+		})
+	}
+}
+
+func TestReenrollFailures(t *testing.T) {
+	t.Run("missing token file", func(t *testing.T) {
+		r, _, _ := newTestReenroller(t, filepath.Join(t.TempDir(), "missing"))
+		r.enroll = func(context.Context, *configuration.FleetAgentConfig, *fleetapi.EnrollRequest) (*fleetapi.EnrollResponse, error) {
+			t.Fatal("enroll must not be called without a token")
+			return nil, nil
+		}
+		require.Error(t, r.Reenroll(context.Background()))
+	})
+
+	t.Run("enroll rejected", func(t *testing.T) {
+		tokenFile := filepath.Join(t.TempDir(), "token")
+		require.NoError(t, os.WriteFile(tokenFile, []byte("enroll-token"), 0600))
+
+		r, _, saved := newTestReenroller(t, tokenFile)
+		r.enroll = func(context.Context, *configuration.FleetAgentConfig, *fleetapi.EnrollRequest) (*fleetapi.EnrollResponse, error) {
+			return nil, errors.New("invalid enrollment token")
+		}
+		setter := &testSetter{}
+		r.AddSetter(setter)
+
+		require.Error(t, r.Reenroll(context.Background()))
+		assert.Equal(t, "api-key", r.config.Fleet.AccessAPIKey)
+		assert.Nil(t, *saved)
+		assert.Nil(t, setter.client)
+	})
+
+	t.Run("persist failure keeps the previous key", func(t *testing.T) {
+		tokenFile := filepath.Join(t.TempDir(), "token")
+		require.NoError(t, os.WriteFile(tokenFile, []byte("enroll-token"), 0600))
+
+		r, _, _ := newTestReenroller(t, tokenFile)
+		r.store = storage.NewHandlerStore(func(io.Reader) error { return errors.New("disk full") })
+		r.enroll = func(context.Context, *configuration.FleetAgentConfig, *fleetapi.EnrollRequest) (*fleetapi.EnrollResponse, error) {
+			return &fleetapi.EnrollResponse{Item: fleetapi.EnrollItemResponse{AccessAPIKey: "new-api-key"}}, nil
+		}
+
+		require.Error(t, r.Reenroll(context.Background()))
+		assert.Equal(t, "api-key", r.config.Fleet.AccessAPIKey)
+	})
+}
+
+func TestReenrollHoldsConfigLock(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("enroll-token"), 0600))
+
+	r, _, _ := newTestReenroller(t, tokenFile)
+	r.enroll = func(context.Context, *configuration.FleetAgentConfig, *fleetapi.EnrollRequest) (*fleetapi.EnrollResponse, error) {
+		return &fleetapi.EnrollResponse{Item: fleetapi.EnrollItemResponse{AccessAPIKey: "new-api-key"}}, nil
+	}
+
+	// a policy change is updating the fleet configuration
+	r.configMx.Lock()
+	done := make(chan error)
+	go func() {
+		done <- r.Reenroll(context.Background())
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("re-enrollment must wait for the configuration lock")
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.Equal(t, "api-key", r.config.Fleet.AccessAPIKey)
+
+	r.configMx.Unlock()
+	require.NoError(t, <-done)
+	assert.Equal(t, "new-api-key", r.config.Fleet.AccessAPIKey)
+}
+
+func TestWatchRetriesOnTokenChange(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("old-token"), 0600))
+
+	r, _, _ := newTestReenroller(t, tokenFile)
+	saved := make(chan struct{}, 1)
+	r.store = storage.NewHandlerStore(func(io.Reader) error {
+		saved <- struct{}{}
+		return nil
+	})
+	r.enroll = func(_ context.Context, _ *configuration.FleetAgentConfig, req *fleetapi.EnrollRequest) (*fleetapi.EnrollResponse, error) {
+		if req.EnrollAPIKey != "new-token" {
+			return nil, errors.New("invalid enrollment token")
+		}
+		return &fleetapi.EnrollResponse{Item: fleetapi.EnrollItemResponse{AccessAPIKey: "new-api-key"}}, nil
+	}
+
+	// the token was rotated in Fleet but not on disk yet
+	require.Error(t, r.Reenroll(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Watch(ctx, 10*time.Millisecond)
+
+	// make sure the modification time changes
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, os.WriteFile(tokenFile, []byte("new-token"), 0600))
+
+	select {
+	case <-saved:
+	case <-time.After(5 * time.Second):
+		t.Fatal("re-enrollment not retried after the token file changed")
+	}
+}
+
+func TestReadTokenFile(t *testing.T) {
+	dir := t.TempDir()
+
+	tokenFile := filepath.Join(dir, "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("\ttoken\r\n"), 0600))
+	token, err := ReadTokenFile(tokenFile)
+	require.NoError(t, err)
+	assert.Equal(t, "token", token)
+
+	emptyFile := filepath.Join(dir, "empty")
+	require.NoError(t, os.WriteFile(emptyFile, []byte(" \n"), 0600))
+	_, err = ReadTokenFile(emptyFile)
+	require.Error(t, err)
+
+	_, err = ReadTokenFile(filepath.Join(dir, "missing"))
+	require.Error(t, err)
+}
+
+func newTestReenroller(t *testing.T, tokenFile string) (*Reenroller, *testAgentInfo, *[]byte) {
+	t.Helper()
+	log, err := logger.New("", false)
+	require.NoError(t, err)
+
+	cfg := configuration.DefaultConfiguration()
+	cfg.Fleet.Enabled = true
+	cfg.Fleet.AccessAPIKey = "api-key"
+	cfg.Fleet.Enrollment = &configuration.EnrollmentConfig{TokenFile: tokenFile, Reenroll: true}
+	cfg.Settings.DownloadConfig.SourceURI = "https://staging.elastic.co/downloads/"
+
+	var saved []byte
+	store := storage.NewHandlerStore(func(in io.Reader) error {
+		b, err := ioutil.ReadAll(in)
+		saved = b
+		return err
+	})
+
+	agentInfo := &testAgentInfo{id: "agent-id"}
+	return &Reenroller{
+		log:       log,
+		agentInfo: agentInfo,
+		config:    cfg,
+		store:     store,
+		configMx:  &sync.Mutex{},
+	}, agentInfo, &saved
+}
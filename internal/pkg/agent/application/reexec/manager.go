@@ -25,11 +25,12 @@ type ExecManager interface {
 }
 
 type manager struct {
-	logger   *logger.Logger
-	exec     string
-	trigger  chan bool
-	shutdown chan bool
-	complete chan bool
+	logger           *logger.Logger
+	exec             string
+	restartByService func() bool
+	trigger          chan bool
+	shutdown         chan bool
+	complete         chan bool
 }
 
 // ShutdownCallbackFn is called once everything is shutdown and allows cleanup during reexec process.
@@ -37,12 +38,20 @@ type ShutdownCallbackFn func() error
 
 // NewManager returns the reexec manager.
 func NewManager(log *logger.Logger, exec string) ExecManager {
+	return NewServiceManager(log, exec, nil)
+}
+
+// NewServiceManager returns the reexec manager of a service. When restartByService returns true the
+// process exits instead of re-executing itself and the service manager restarts it, e.g. when the
+// service manager completes the upgrade before it starts the agent.
+func NewServiceManager(log *logger.Logger, exec string, restartByService func() bool) ExecManager {
 	return &manager{
-		logger:   log,
-		exec:     exec,
-		trigger:  make(chan bool),
-		shutdown: make(chan bool),
-		complete: make(chan bool),
+		logger:           log,
+		exec:             exec,
+		restartByService: restartByService,
+		trigger:          make(chan bool),
+		shutdown:         make(chan bool),
+		complete:         make(chan bool),
 	}
 }
 
@@ -58,7 +67,10 @@ func (m *manager) ReExec(shutdownCallback ShutdownCallbackFn, argOverrides ...st
 			}
 		}
 
-		if err := reexec(m.logger, m.exec, argOverrides...); err != nil {
+		if m.restartByService != nil && m.restartByService() {
+			m.logger.Info("Exiting to be restarted by the service manager")
+			_ = m.logger.Sync()
+		} else if err := reexec(m.logger, m.exec, argOverrides...); err != nil {
 			// panic; because there is no going back, everything is shutdown
 			panic(err)
 		}
@@ -0,0 +1,48 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package application
+
+import (
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/configuration"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	reporting "github.com/elastic/elastic-agent/internal/pkg/reporter"
+	filereporter "github.com/elastic/elastic-agent/internal/pkg/reporter/file"
+	webhookreporter "github.com/elastic/elastic-agent/internal/pkg/reporter/webhook"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+// NewReportingBackends creates the reporter backends enabled under agent.reporting.
+func NewReportingBackends(log *logger.Logger, cfg *configuration.SettingsConfig) ([]reporting.Backend, error) {
+	if cfg.Reporting == nil {
+		return nil, nil
+	}
+
+	var backends []reporting.Backend
+	if fileCfg := cfg.Reporting.File; fileCfg != nil && fileCfg.Enabled {
+		c := *fileCfg
+		if c.Path == "" {
+			c.Path = paths.AgentEventsFile()
+		}
+		r, err := filereporter.NewReporter(&c)
+		if err != nil {
+			return nil, errors.New(err, "failed to initialize file reporter")
+		}
+		backends = append(backends, r)
+	}
+
+	if webhookCfg := cfg.Reporting.Webhook; webhookCfg != nil && webhookCfg.Enabled {
+		r, err := webhookreporter.NewReporter(log, webhookCfg)
+		if err != nil {
+			for _, b := range backends {
+				_ = b.Close()
+			}
+			return nil, errors.New(err, "failed to initialize webhook reporter")
+		}
+		backends = append(backends, r)
+	}
+
+	return backends, nil
+}
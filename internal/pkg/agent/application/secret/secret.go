@@ -135,6 +135,88 @@ func set(v *vault.Vault, key string, secret Secret) error {
 	return v.Set(key, b)
 }
 
+// RotateAgentSecret replaces the seed of the vault and the agent secret. replace is called with the
+// previous and the new agent secret to re-encrypt what the previous secret encrypted, the files it
+// writes through the rotation replace their target only when the rotation is committed.
+func RotateAgentSecret(replace func(r *vault.Rotation, prev, next Secret) error, opts ...OptionFunc) error {
+	options := applyOptions(opts...)
+	v, err := vault.New(options.vaultPath)
+	if err != nil {
+		return fmt.Errorf("could not open vault: %w", err)
+	}
+	defer v.Close()
+
+	mxCreate.Lock()
+	defer mxCreate.Unlock()
+
+	b, err := v.Get(agentSecretKey)
+	if err != nil {
+		return fmt.Errorf("could not read agent secret: %w", err)
+	}
+	var prev Secret
+	if err := json.Unmarshal(b, &prev); err != nil {
+		return fmt.Errorf("could not unmarshal agent secret: %w", err)
+	}
+
+	k, err := vault.NewKey(vault.AES256)
+	if err != nil {
+		return err
+	}
+	next := Secret{
+		Value:     k,
+		CreatedOn: time.Now().UTC(),
+	}
+	b, err = json.Marshal(next)
+	if err != nil {
+		return fmt.Errorf("could not marshal secret: %w", err)
+	}
+
+	r, err := v.BeginRotation(agentSecretKey)
+	if err != nil {
+		return err
+	}
+	if err := r.Set(agentSecretKey, b); err != nil {
+		_ = r.Abort()
+		return err
+	}
+	if err := replace(r, prev, next); err != nil {
+		_ = r.Abort()
+		return err
+	}
+	return r.Commit()
+}
+
+// MigrateVaultSeed moves the seed of the vault to the backend, the secrets are left untouched.
+// Nothing is written once the seed is held by the backend.
+func MigrateVaultSeed(backend vault.SeedBackend, opts ...OptionFunc) error {
+	if backend == "" {
+		backend = vault.SeedBackendFile
+	}
+
+	options := applyOptions(opts...)
+	if current, err := vaultSeedBackend(options.vaultPath); err == nil && current == backend {
+		return nil
+	}
+
+	v, err := vault.New(options.vaultPath)
+	if err != nil {
+		return fmt.Errorf("could not open vault: %w", err)
+	}
+	defer v.Close()
+
+	return v.MigrateSeed(backend)
+}
+
+// vaultSeedBackend returns where the seed of the vault is held, the vault is opened readonly.
+func vaultSeedBackend(vaultPath string) (vault.SeedBackend, error) {
+	v, err := vault.New(vaultPath, vault.WithReadonly(true))
+	if err != nil {
+		return "", err
+	}
+	defer v.Close()
+	return v.SeedBackend(), nil
+}
+
 // Remove removes the secret key from the vault
 func Remove(key string, opts ...OptionFunc) error {
 	options := applyOptions(opts...)
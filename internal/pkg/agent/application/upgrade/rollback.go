@@ -30,8 +30,9 @@ const (
 	restartBackoffMax  = 90 * time.Second
 )
 
-// Rollback rollbacks to previous version which was functioning before upgrade.
-func Rollback(ctx context.Context, log *logger.Logger, prevHash string, currentHash string) error {
+// Rollback rollbacks to previous version which was functioning before upgrade, onRollback is called
+// once the previous version is linked, before the agent is restarted.
+func Rollback(ctx context.Context, log *logger.Logger, prevHash string, currentHash string, onRollback func()) error {
 	// change symlink
 	if err := ChangeSymlink(ctx, log, prevHash); err != nil {
 		return err
@@ -42,6 +43,20 @@ func Rollback(ctx context.Context, log *logger.Logger, prevHash string, currentH
 		return err
 	}
 
+	if onRollback != nil {
+		onRollback()
+	}
+
+	if runningAsServiceUser() {
+		// the service manager stops the watcher with the agent, the marker is removed before the
+		// restart and the relink removes the other versions
+		if err := CleanMarker(log); err != nil {
+			return err
+		}
+		log.Info("Restarting the agent after rollback")
+		return restartAgent(ctx)
+	}
+
 	// Restart
 	log.Info("Restarting the agent after rollback")
 	if err := restartAgent(ctx); err != nil {
@@ -64,22 +79,33 @@ func Cleanup(log *logger.Logger, currentHash string, removeMarker bool) error {
 		}
 	}
 
-	// remove data/elastic-agent-{hash}
+	if runningAsServiceUser() {
+		// the versions and the symlinks are owned by root, they are removed by the relink when the
+		// service manager starts the agent again
+		return nil
+	}
+
+	// remove symlink to avoid upgrade failures, ignore error
+	prevSymlink := prevSymlinkPath()
+	log.Debugw("Removing previous symlink path", "file.path", prevSymlinkPath())
+	_ = os.Remove(prevSymlink)
+
+	return removeOtherVersions(log, currentHash)
+}
+
+// removeOtherVersions removes data/elastic-agent-{hash} of the versions other than currentHash.
+func removeOtherVersions(log *logger.Logger, currentHash string) error {
 	dataDir, err := os.Open(paths.Data())
 	if err != nil {
 		return err
 	}
+	defer dataDir.Close()
 
 	subdirs, err := dataDir.Readdirnames(0)
 	if err != nil {
 		return err
 	}
 
-	// remove symlink to avoid upgrade failures, ignore error
-	prevSymlink := prevSymlinkPath()
-	log.Debugw("Removing previous symlink path", "file.path", prevSymlinkPath())
-	_ = os.Remove(prevSymlink)
-
 	dirPrefix := fmt.Sprintf("%s-", agentName)
 	currentDir := fmt.Sprintf("%s-%s", agentName, currentHash)
 	for _, dir := range subdirs {
@@ -0,0 +1,82 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package upgrade
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/internal/pkg/artifact/bundle"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+const fileURIPrefix = "file://"
+
+// extractBundle extracts the offline bundle referenced by sourceURI, if any,
+// into a temporary directory and returns the directory and the source URI
+// pointing to it, so the bundle is consumed as a regular drop path.
+// When sourceURI does not reference a bundle it is returned unchanged.
+func extractBundle(log *logger.Logger, version, sourceURI string) (*bundle.Manifest, string, string, error) {
+	if !strings.HasPrefix(sourceURI, fileURIPrefix) {
+		return nil, "", sourceURI, nil
+	}
+
+	bundlePath := strings.TrimPrefix(sourceURI, fileURIPrefix)
+	if !bundle.IsBundle(bundlePath) {
+		return nil, "", sourceURI, nil
+	}
+
+	dir, err := os.MkdirTemp(paths.Data(), "bundle-")
+	if err != nil {
+		return nil, "", "", errors.New(err, "creating bundle directory", errors.TypeFilesystem)
+	}
+
+	log.Infow("Extracting upgrade bundle", "file.path", bundlePath, "directory", dir)
+	manifest, err := bundle.Extract(bundlePath, dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, "", "", errors.New(err, "extracting upgrade bundle")
+	}
+
+	if manifest.Version != version {
+		os.RemoveAll(dir)
+		return nil, "", "", errors.New(fmt.Sprintf("upgrade bundle contains version %q, expected %q", manifest.Version, version), errors.TypeConfig)
+	}
+
+	return manifest, dir, fileURIPrefix + dir, nil
+}
+
+// copyBundleArtifacts copies program artifacts of the bundle into the
+// downloads directory of the new version so they are available without
+// network access once the new version starts.
+func copyBundleArtifacts(log *logger.Logger, manifest *bundle.Manifest, dir, newHash string) error {
+	if manifest == nil || len(manifest.Artifacts) == 0 {
+		return nil
+	}
+
+	newDownloads := filepath.Join(paths.Data(), fmt.Sprintf("%s-%s", agentName, newHash), "downloads")
+	if err := os.MkdirAll(newDownloads, 0750); err != nil {
+		return errors.New(err, "creating downloads directory", errors.TypeFilesystem, errors.M(errors.MetaKeyPath, newDownloads))
+	}
+
+	for _, a := range manifest.Artifacts {
+		for _, name := range a.Files() {
+			log.Debugw("Copying bundled artifact", "file.path", name, "to", newDownloads)
+			b, err := os.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				return errors.New(err, "reading bundled artifact", errors.TypeFilesystem, errors.M(errors.MetaKeyPath, name))
+			}
+			if err := os.WriteFile(filepath.Join(newDownloads, name), b, 0640); err != nil {
+				return errors.New(err, "writing bundled artifact", errors.TypeFilesystem, errors.M(errors.MetaKeyPath, name))
+			}
+		}
+	}
+
+	return nil
+}
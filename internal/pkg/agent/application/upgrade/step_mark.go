@@ -126,7 +126,16 @@ func (u *Upgrader) markUpgrade(_ context.Context, log *logger.Logger, hash strin
 }
 
 // UpdateActiveCommit updates active.commit file to point to active version.
+//
+// The file of an agent running as a service user is owned by root, it is updated with the relink.
 func UpdateActiveCommit(log *logger.Logger, hash string) error {
+	if runningAsServiceUser() {
+		return nil
+	}
+	return writeActiveCommit(log, hash)
+}
+
+func writeActiveCommit(log *logger.Logger, hash string) error {
 	activeCommitPath := filepath.Join(paths.Top(), agentCommitFile)
 	log.Infow("Updating active commit", "file.path", activeCommitPath, "hash", hash)
 	if err := ioutil.WriteFile(activeCommitPath, []byte(hash), 0600); err != nil {
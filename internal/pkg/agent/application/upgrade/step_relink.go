@@ -6,24 +6,84 @@ package upgrade
 
 import (
 	"context"
+	"encoding/hex"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 
 	"github.com/elastic/elastic-agent-libs/file"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/install"
+	"github.com/elastic/elastic-agent/internal/pkg/release"
 	"github.com/elastic/elastic-agent/pkg/core/logger"
 )
 
 const (
 	windows = "windows"
 	exe     = ".exe"
+
+	// relinkFilename is the file of the data directory requesting the relink to a version.
+	relinkFilename = ".relink-request"
 )
 
 // ChangeSymlink updates symlink paths to match current version.
+//
+// The symlink of an agent running as a service user is owned by root, the relink is requested and
+// performed by the service manager before it starts the agent again, see ApplyRelink.
 func ChangeSymlink(ctx context.Context, log *logger.Logger, targetHash string) error {
+	if runningAsServiceUser() {
+		return requestRelink(log, targetHash)
+	}
+	return changeSymlink(ctx, log, targetHash)
+}
+
+// RelinkRequested returns true when a relink is left to the service manager, the agent must exit
+// to be restarted by it instead of re-executing itself.
+func RelinkRequested() bool {
+	_, err := os.Stat(relinkFilePath())
+	return err == nil
+}
+
+// ApplyRelink performs the relink requested by an agent running as a service user. The service
+// manager runs it as root before it starts the agent.
+//
+// The version linked is owned by root before the agent runs it. Once the upgrade is over, when no
+// upgrade marker is left, the other versions are removed as the agent cannot remove them.
+func ApplyRelink(ctx context.Context, log *logger.Logger) error {
+	currentHash := release.ShortCommit()
+	targetHash, err := loadRelinkRequest()
+	if err != nil {
+		return err
+	}
+	if targetHash != "" {
+		if err := changeSymlink(ctx, log, targetHash); err != nil {
+			return err
+		}
+		if err := writeActiveCommit(log, targetHash); err != nil {
+			return err
+		}
+		if err := os.Remove(relinkFilePath()); err != nil && !os.IsNotExist(err) {
+			return errors.New(err, errors.TypeFilesystem, "failed to remove the relink request", errors.M(errors.MetaKeyPath, relinkFilePath()))
+		}
+		currentHash = targetHash
+	}
+
+	marker, err := LoadMarker()
+	if err != nil {
+		return err
+	}
+	if marker != nil {
+		// the previous version is kept until the upgrade watcher is done
+		return nil
+	}
+	return removeOtherVersions(log, currentHash)
+}
+
+func changeSymlink(_ context.Context, log *logger.Logger, targetHash string) error {
 	// create symlink to elastic-agent-{hash}
 	hashedDir := fmt.Sprintf("%s-%s", agentName, targetHash)
 
@@ -50,10 +110,62 @@ func ChangeSymlink(ctx context.Context, log *logger.Logger, targetHash string) e
 		return errors.New(err, errors.TypeFilesystem, "failed to update agent symlink")
 	}
 
+	// relinked as root, e.g. by the service manager, the new version unpacked by the service user
+	// is owned by root before it is linked
+	if err := install.FixPermissionsOf(filepath.Join(paths.Top(), "data", hashedDir), prevNewPath); err != nil {
+		return errors.New(err, errors.TypeFilesystem, "failed to fix the permissions of the new version")
+	}
+
 	// safely rotate
 	return file.SafeFileRotate(symlinkPath, prevNewPath)
 }
 
+// requestRelink records the version the service manager links, a relink to the running version
+// cancels the request.
+func requestRelink(log *logger.Logger, targetHash string) error {
+	requestPath := relinkFilePath()
+	if strings.HasPrefix(release.Commit(), targetHash) {
+		log.Infow("Cancelling relink request", "file.path", requestPath, "hash", targetHash)
+		if err := os.Remove(requestPath); err != nil && !os.IsNotExist(err) {
+			return errors.New(err, errors.TypeFilesystem, "failed to remove the relink request", errors.M(errors.MetaKeyPath, requestPath))
+		}
+		return nil
+	}
+
+	log.Infow("Requesting relink from the service manager", "file.path", requestPath, "hash", targetHash)
+	if err := ioutil.WriteFile(requestPath, []byte(targetHash), 0600); err != nil {
+		return errors.New(err, errors.TypeFilesystem, "failed to request the relink", errors.M(errors.MetaKeyPath, requestPath))
+	}
+	return nil
+}
+
+// loadRelinkRequest returns the hash of the version to link, empty when no relink is requested.
+func loadRelinkRequest() (string, error) {
+	requestPath := relinkFilePath()
+	b, err := ioutil.ReadFile(requestPath)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.New(err, errors.TypeFilesystem, "failed to read the relink request", errors.M(errors.MetaKeyPath, requestPath))
+	}
+
+	// written by the service user, only a hash naming a version of the data directory is linked
+	hash := strings.TrimSpace(string(b))
+	if _, err := hex.DecodeString(hash); err != nil || len(hash) < hashLen {
+		return "", errors.New(fmt.Sprintf("invalid hash %q in the relink request", hash), errors.TypeConfig, errors.M(errors.MetaKeyPath, requestPath))
+	}
+	binaryPath := paths.BinaryPath(filepath.Join(paths.Data(), fmt.Sprintf("%s-%s", agentName, hash)), agentName)
+	if _, err := os.Stat(binaryPath); err != nil {
+		return "", errors.New(err, "the version of the relink request is missing", errors.TypeFilesystem, errors.M(errors.MetaKeyPath, binaryPath))
+	}
+	return hash, nil
+}
+
+func relinkFilePath() string {
+	return filepath.Join(paths.Data(), relinkFilename)
+}
+
 func prevSymlinkPath() string {
 	agentPrevName := agentName + ".prev"
 
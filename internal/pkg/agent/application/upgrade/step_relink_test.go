@@ -0,0 +1,93 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+//go:build !windows
+// +build !windows
+
+package upgrade
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
+	"github.com/elastic/elastic-agent/internal/pkg/release"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+func TestApplyRelink(t *testing.T) {
+	const newHash = "abc123"
+	log, _ := logger.New("test", false)
+
+	setup := func(t *testing.T) (string, string) {
+		top := t.TempDir()
+		prevTop := paths.Top()
+		paths.SetTop(top)
+		t.Cleanup(func() { paths.SetTop(prevTop) })
+
+		for _, hash := range []string{release.ShortCommit(), newHash} {
+			home := filepath.Join(paths.Data(), fmt.Sprintf("%s-%s", agentName, hash))
+			require.NoError(t, os.MkdirAll(home, 0750))
+			require.NoError(t, os.WriteFile(paths.BinaryPath(home, agentName), []byte(hash), 0750))
+		}
+		prevBinary := paths.BinaryPath(filepath.Join(paths.Data(), fmt.Sprintf("%s-%s", agentName, release.ShortCommit())), agentName)
+		newBinary := paths.BinaryPath(filepath.Join(paths.Data(), fmt.Sprintf("%s-%s", agentName, newHash)), agentName)
+		require.NoError(t, os.Symlink(prevBinary, filepath.Join(top, agentName)))
+		return prevBinary, newBinary
+	}
+
+	t.Run("links the requested version", func(t *testing.T) {
+		_, newBinary := setup(t)
+		require.NoError(t, os.WriteFile(markerFilePath(), []byte{}, 0600))
+
+		require.NoError(t, requestRelink(log, newHash))
+		require.True(t, RelinkRequested())
+		require.NoError(t, ApplyRelink(context.Background(), log))
+
+		target, err := os.Readlink(filepath.Join(paths.Top(), agentName))
+		require.NoError(t, err)
+		assert.Equal(t, newBinary, target)
+		assert.False(t, RelinkRequested())
+		commit, err := os.ReadFile(filepath.Join(paths.Top(), agentCommitFile))
+		require.NoError(t, err)
+		assert.Equal(t, newHash, string(commit))
+		// the previous version is kept while the upgrade is watched
+		assert.DirExists(t, filepath.Join(paths.Data(), fmt.Sprintf("%s-%s", agentName, release.ShortCommit())))
+	})
+
+	t.Run("removes the other versions once the upgrade is over", func(t *testing.T) {
+		_, newBinary := setup(t)
+
+		require.NoError(t, requestRelink(log, newHash))
+		require.NoError(t, ApplyRelink(context.Background(), log))
+
+		assert.FileExists(t, newBinary)
+		assert.NoDirExists(t, filepath.Join(paths.Data(), fmt.Sprintf("%s-%s", agentName, release.ShortCommit())))
+	})
+
+	t.Run("relink to the running version cancels the request", func(t *testing.T) {
+		setup(t)
+
+		require.NoError(t, requestRelink(log, newHash))
+		require.NoError(t, requestRelink(log, release.ShortCommit()))
+		assert.False(t, RelinkRequested())
+	})
+
+	t.Run("rejects a request outside of the data directory", func(t *testing.T) {
+		prevBinary, _ := setup(t)
+		require.NoError(t, os.WriteFile(relinkFilePath(), []byte("../../tmp"), 0600))
+
+		require.Error(t, ApplyRelink(context.Background(), log))
+
+		target, err := os.Readlink(filepath.Join(paths.Top(), agentName))
+		require.NoError(t, err)
+		assert.Equal(t, prevBinary, target)
+	})
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/reexec"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/install"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/program"
 	"github.com/elastic/elastic-agent/internal/pkg/artifact"
 	"github.com/elastic/elastic-agent/internal/pkg/capabilities"
@@ -84,6 +85,13 @@ func IsUpgradeable() bool {
 	return release.Upgradeable() || (info.RunningInstalled() && info.RunningUnderSupervisor())
 }
 
+// runningAsServiceUser returns true when the service runs as a service user, it cannot replace the
+// symlink and the binaries owned by root and leaves the relink to the service manager.
+func runningAsServiceUser() bool {
+	serviceUser, err := install.LoadServiceUser()
+	return err == nil && !serviceUser.IsRoot()
+}
+
 // NewUpgrader creates an upgrader which is capable of performing upgrade operation
 func NewUpgrader(agentInfo *info.AgentInfo, settings *artifact.Config, log *logger.Logger, closers []context.CancelFunc, reexec reexecManager, a acker, r stateReporter, caps capabilities.Capability) *Upgrader {
 	return &Upgrader{
@@ -139,7 +147,14 @@ func (u *Upgrader) Upgrade(ctx context.Context, a Action, reexecNow bool) (_ ree
 
 	u.reportUpdating(a.Version())
 
-	sourceURI := u.sourceURI(a.SourceURI())
+	manifest, bundleDir, sourceURI, err := extractBundle(u.log, a.Version(), u.sourceURI(a.SourceURI()))
+	if err != nil {
+		return nil, err
+	}
+	if bundleDir != "" {
+		defer os.RemoveAll(bundleDir)
+	}
+
 	archivePath, err := u.downloadArtifact(ctx, a.Version(), sourceURI)
 	if err != nil {
 		// Run the same pre-upgrade cleanup task to get rid of any newly downloaded files
@@ -173,6 +188,10 @@ func (u *Upgrader) Upgrade(ctx context.Context, a Action, reexecNow bool) (_ ree
 		return nil, errors.New(err, "failed to copy action store")
 	}
 
+	if err := copyBundleArtifacts(u.log, manifest, bundleDir, newHash); err != nil {
+		return nil, errors.New(err, "failed to copy bundled artifacts")
+	}
+
 	if err := ChangeSymlink(ctx, u.log, newHash); err != nil {
 		u.log.Errorw("Rolling back: changing symlink failed", "error.message", err)
 		rollbackInstall(ctx, u.log, newHash)
@@ -301,8 +320,8 @@ func rollbackInstall(ctx context.Context, log *logger.Logger, hash string) {
 }
 
 func copyActionStore(log *logger.Logger, newHash string) error {
-	// copies legacy action_store.yml, state.yml and state.enc encrypted file if exists
-	storePaths := []string{paths.AgentActionStoreFile(), paths.AgentStateStoreYmlFile(), paths.AgentStateStoreFile()}
+	// copies legacy action_store.yml, state.yml, state.enc and ack_spool.enc encrypted files if exists
+	storePaths := []string{paths.AgentActionStoreFile(), paths.AgentStateStoreYmlFile(), paths.AgentStateStoreFile(), paths.AgentAckSpoolFile()}
 	newHome := filepath.Join(filepath.Dir(paths.Home()), fmt.Sprintf("%s-%s", agentName, newHash))
 	log.Debugw("Copying action store", "new_home_path", newHome)
 
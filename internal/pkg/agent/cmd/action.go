@@ -0,0 +1,122 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/control/client"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/internal/pkg/cli"
+)
+
+const (
+	// defaultAppActionTimeout matches the timeout the agent uses when none is requested.
+	defaultAppActionTimeout = time.Minute
+	maxAppActionTimeout     = time.Hour
+)
+
+var actionOutputs = map[string]outputter{
+	"json": jsonOutput,
+	"yaml": yamlOutput,
+}
+
+func newActionCommandWithArgs(_ []string, streams *cli.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "action",
+		Short: "Perform application actions on the running Elastic Agent daemon",
+		Long:  "Perform actions on the applications run by the Elastic Agent daemon, as the INPUT_ACTION actions sent by Fleet do.",
+	}
+
+	cmd.AddCommand(newActionRunCommand(streams))
+
+	return cmd
+}
+
+func newActionRunCommand(streams *cli.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Run an action on the application handling an input type",
+		Long: `Run an action on the application handling the input type and print its response.
+
+The parameters file contains the JSON object sent as the data of the action, e.g. the query of an osquery action.`,
+		Example: "elastic-agent action run --input-type osquery --params query.json --timeout 5m",
+		Args:    cobra.ExactArgs(0),
+		Run: func(c *cobra.Command, args []string) {
+			if err := actionRunCmd(streams, c); err != nil {
+				fmt.Fprintf(streams.Err, "Error: %v\n%s\n", err, troubleshootMessage())
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().String("input-type", "", "Input type of the application performing the action")
+	cmd.Flags().String("params", "", "Path to a JSON file with the parameters of the action")
+	cmd.Flags().Duration("timeout", 0, "Timeout of the action, up to 1h (default: 1m)")
+	cmd.Flags().String("output", "json", "Output the action response in either json or yaml (default: json)")
+	_ = cmd.MarkFlagRequired("input-type")
+
+	return cmd
+}
+
+func actionRunCmd(streams *cli.IOStreams, cmd *cobra.Command) error {
+	inputType, _ := cmd.Flags().GetString("input-type")
+	paramsPath, _ := cmd.Flags().GetString("params")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	output, _ := cmd.Flags().GetString("output")
+
+	outputFunc, ok := actionOutputs[output]
+	if !ok {
+		return fmt.Errorf("unsupported output: %s", output)
+	}
+	if timeout < 0 || timeout > maxAppActionTimeout {
+		return fmt.Errorf("timeout must be between 0 and %s", maxAppActionTimeout)
+	}
+
+	params := []byte("{}")
+	if paramsPath != "" {
+		var err error
+		params, err = ioutil.ReadFile(paramsPath)
+		if err != nil {
+			return errors.New(err, "failed to read action parameters", errors.TypeFilesystem, errors.M(errors.MetaKeyPath, paramsPath))
+		}
+		var obj map[string]interface{}
+		if err := json.Unmarshal(params, &obj); err != nil {
+			return errors.New(err, "action parameters must be a JSON object", errors.TypeConfig, errors.M(errors.MetaKeyPath, paramsPath))
+		}
+	}
+
+	// give the daemon time to report the action result after the action times out
+	wait := timeout
+	if wait == 0 {
+		wait = defaultAppActionTimeout
+	}
+	wait += daemonTimeout
+
+	var result *client.AppActionResult
+	err := withDaemon(wait, func(ctx context.Context, c client.Client) error {
+		var err error
+		result, err = c.AppAction(ctx, inputType, params, timeout)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := outputFunc(streams.Out, result); err != nil {
+		return err
+	}
+	if result.Error != "" {
+		return fmt.Errorf("action %s failed: %s", result.ID, result.Error)
+	}
+	return nil
+}
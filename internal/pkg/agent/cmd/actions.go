@@ -0,0 +1,179 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/control"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/control/client"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/internal/pkg/cli"
+)
+
+var actionsOutputs = map[string]outputter{
+	"human": humanActionsOutput,
+	"json":  jsonOutput,
+	"yaml":  yamlOutput,
+}
+
+// queuedAction is the representation of a queued action in the command output.
+type queuedAction struct {
+	ID         string `json:"id" yaml:"id"`
+	Type       string `json:"type" yaml:"type"`
+	StartTime  string `json:"start_time,omitempty" yaml:"start_time,omitempty"`
+	Expiration string `json:"expiration,omitempty" yaml:"expiration,omitempty"`
+}
+
+func newActionsCommandWithArgs(_ []string, streams *cli.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "actions",
+		Short: "Manage the actions queued by the running Elastic Agent daemon",
+		Long:  "Manage the scheduled actions received from Fleet and waiting in the action queue of the running Elastic Agent daemon.",
+	}
+
+	cmd.AddCommand(newActionsListCommand(streams))
+	cmd.AddCommand(newActionsCancelCommand(streams))
+
+	return cmd
+}
+
+func newActionsListCommand(streams *cli.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the queued actions",
+		Long:  "List the queued actions with their start and expiration time.",
+		Args:  cobra.ExactArgs(0),
+		Run: func(c *cobra.Command, args []string) {
+			if err := actionsListCmd(streams, c); err != nil {
+				fmt.Fprintf(streams.Err, "Error: %v\n%s\n", err, troubleshootMessage())
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().String("output", "human", "Output the queued actions in either human, json, or yaml (default: human)")
+
+	return cmd
+}
+
+func newActionsCancelCommand(streams *cli.IOStreams) *cobra.Command {
+	return &cobra.Command{
+		Use:   "cancel <id>",
+		Short: "Cancel a queued action",
+		Long:  "Remove the action with the given ID from the action queue, the action is not executed.",
+		Args:  cobra.ExactArgs(1),
+		Run: func(c *cobra.Command, args []string) {
+			if err := actionsCancelCmd(streams, args[0]); err != nil {
+				fmt.Fprintf(streams.Err, "Error: %v\n%s\n", err, troubleshootMessage())
+				os.Exit(1)
+			}
+		},
+	}
+}
+
+func actionsListCmd(streams *cli.IOStreams, cmd *cobra.Command) error {
+	output, _ := cmd.Flags().GetString("output")
+	outputFunc, ok := actionsOutputs[output]
+	if !ok {
+		return fmt.Errorf("unsupported output: %s", output)
+	}
+
+	var actions []client.QueuedAction
+	err := withDaemon(daemonTimeout, func(ctx context.Context, c client.Client) error {
+		var err error
+		actions, err = c.Actions(ctx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	out := make([]queuedAction, 0, len(actions))
+	for _, a := range actions {
+		out = append(out, queuedAction{
+			ID:         a.ID,
+			Type:       a.Type,
+			StartTime:  formatActionTime(a.StartTime),
+			Expiration: formatActionTime(a.Expiration),
+		})
+	}
+	return outputFunc(streams.Out, out)
+}
+
+func actionsCancelCmd(streams *cli.IOStreams, id string) error {
+	var cancelled int
+	err := withDaemon(daemonTimeout, func(ctx context.Context, c client.Client) error {
+		var err error
+		cancelled, err = c.CancelAction(ctx, id)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(streams.Out, "Cancelled %d queued action(s) with id %s\n", cancelled, id)
+	return nil
+}
+
+// withDaemon connects to the running daemon and calls fn with a context limited to timeout.
+func withDaemon(timeout time.Duration, fn func(context.Context, client.Client) error) error {
+	ctx := handleSignal(context.Background())
+	innerCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	c := client.New()
+	if err := c.Connect(innerCtx); err != nil {
+		return errors.New(err, "Failed communicating to running daemon", errors.TypeNetwork, errors.M("socket", control.Address()))
+	}
+	defer c.Disconnect()
+
+	err := fn(innerCtx, c)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s waiting for Elastic Agent daemon", timeout)
+	} else if err != nil {
+		return fmt.Errorf("failed to communicate with Elastic Agent daemon: %w", err)
+	}
+	return nil
+}
+
+func formatActionTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func humanActionsOutput(w io.Writer, obj interface{}) error {
+	actions, ok := obj.([]queuedAction)
+	if !ok {
+		return fmt.Errorf("unable to cast %T as []queuedAction", obj)
+	}
+	if len(actions) == 0 {
+		fmt.Fprint(w, "No queued actions\n")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 4, 1, 2, ' ', 0)
+	fmt.Fprint(tw, "ID\tTYPE\tSTART\tEXPIRATION\n")
+	for _, a := range actions {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", a.ID, a.Type, orNone(a.StartTime), orNone(a.Expiration))
+	}
+	return tw.Flush()
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,462 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/info"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/install"
+	"github.com/elastic/elastic-agent/internal/pkg/cli"
+	"github.com/elastic/elastic-agent/internal/pkg/config"
+)
+
+// appliedFile records the last answer file applied, it is compared to the next one to only perform
+// the steps needed.
+const appliedFile = "applied.yml"
+
+// answerFile describes the installation and the enrollment of Elastic Agent, the apply command
+// brings the host to it.
+type answerFile struct {
+	Install     answerInstall      `config:"install" yaml:"install"`
+	Enroll      *answerEnroll      `config:"enroll" yaml:"enroll,omitempty"`
+	FleetServer *answerFleetServer `config:"fleet_server" yaml:"fleet_server,omitempty"`
+}
+
+type answerInstall struct {
+	// Path is the installation path, it can only be the path of the platform.
+	Path         string   `config:"path" yaml:"path,omitempty"`
+	User         string   `config:"user" yaml:"user,omitempty"`
+	Group        string   `config:"group" yaml:"group,omitempty"`
+	Capabilities []string `config:"capabilities" yaml:"capabilities,omitempty"`
+}
+
+type answerEnroll struct {
+	URL                    string      `config:"url" yaml:"url,omitempty"`
+	EnrollmentTokenFile    string      `config:"enrollment_token_file" yaml:"enrollment_token_file,omitempty"`
+	Tags                   []string    `config:"tags" yaml:"tags,omitempty"`
+	CertificateAuthorities []string    `config:"certificate_authorities" yaml:"certificate_authorities,omitempty"`
+	CASHA256               []string    `config:"ca_sha256" yaml:"ca_sha256,omitempty"`
+	Insecure               bool        `config:"insecure" yaml:"insecure,omitempty"`
+	Proxy                  answerProxy `config:"proxy" yaml:"proxy,omitempty"`
+}
+
+type answerProxy struct {
+	URL      string            `config:"url" yaml:"url,omitempty"`
+	Disabled bool              `config:"disabled" yaml:"disabled,omitempty"`
+	Headers  map[string]string `config:"headers" yaml:"headers,omitempty"`
+}
+
+type answerFleetServer struct {
+	ES                     string `config:"es" yaml:"es,omitempty"`
+	ESCA                   string `config:"es_ca" yaml:"es_ca,omitempty"`
+	ESCATrustedFingerprint string `config:"es_ca_trusted_fingerprint" yaml:"es_ca_trusted_fingerprint,omitempty"`
+	ESInsecure             bool   `config:"es_insecure" yaml:"es_insecure,omitempty"`
+	ServiceTokenFile       string `config:"service_token_file" yaml:"service_token_file,omitempty"`
+	PolicyID               string `config:"policy_id" yaml:"policy_id,omitempty"`
+	Host                   string `config:"host" yaml:"host,omitempty"`
+	Port                   uint16 `config:"port" yaml:"port,omitempty"`
+	Cert                   string `config:"cert" yaml:"cert,omitempty"`
+	CertKey                string `config:"cert_key" yaml:"cert_key,omitempty"`
+	InsecureHTTP           bool   `config:"insecure_http" yaml:"insecure_http,omitempty"`
+	// Headers are the headers of the requests to Elasticsearch.
+	Headers map[string]string `config:"headers" yaml:"headers,omitempty"`
+}
+
+// Validate validates the answer file.
+func (a *answerFile) Validate() error {
+	if a.Install.Path != "" && !paths.ArePathsEqual(filepath.Clean(a.Install.Path), paths.InstallPath) {
+		return fmt.Errorf("install.path %s is not supported, Elastic Agent is installed at %s", a.Install.Path, paths.InstallPath)
+	}
+	if a.Install.User == "" && a.Install.Group != "" {
+		return fmt.Errorf("install.group requires install.user")
+	}
+
+	var files []string
+	if a.Enroll != nil {
+		if a.Enroll.URL == "" && a.FleetServer == nil {
+			return fmt.Errorf("enroll.url is required")
+		}
+		if a.Enroll.EnrollmentTokenFile == "" && a.FleetServer == nil {
+			return fmt.Errorf("enroll.enrollment_token_file is required")
+		}
+		files = append(files, a.Enroll.EnrollmentTokenFile)
+		files = append(files, a.Enroll.CertificateAuthorities...)
+	}
+	if a.FleetServer != nil {
+		if a.FleetServer.ES == "" {
+			return fmt.Errorf("fleet_server.es is required")
+		}
+		if a.FleetServer.ServiceTokenFile == "" {
+			return fmt.Errorf("fleet_server.service_token_file is required")
+		}
+		files = append(files, a.FleetServer.ServiceTokenFile, a.FleetServer.ESCA, a.FleetServer.Cert, a.FleetServer.CertKey)
+	}
+	for _, f := range files {
+		if f != "" && !filepath.IsAbs(f) {
+			return fmt.Errorf("%s must be provided as an absolute path", f)
+		}
+	}
+	return nil
+}
+
+func (a *answerFile) serviceUser() install.ServiceUser {
+	u := install.ServiceUser{
+		Name:         a.Install.User,
+		Group:        a.Install.Group,
+		Capabilities: a.Install.Capabilities,
+	}
+	if u.Name != "" && u.Group == "" {
+		u.Group = u.Name
+	}
+	if u.Name != "" && u.Capabilities == nil {
+		u.Capabilities = install.DefaultCapabilities
+	}
+	return u
+}
+
+// enrolls returns true when the answer file enrolls Elastic Agent.
+func (a *answerFile) enrolls() bool {
+	return a.Enroll != nil || a.FleetServer != nil
+}
+
+// enrollArgs returns the arguments of the enroll command enrolling as described by the answer file.
+func (a *answerFile) enrollArgs() []string {
+	var args []string
+	add := func(flag, value string) {
+		if value != "" {
+			args = append(args, "--"+flag, value)
+		}
+	}
+	addBool := func(flag string, value bool) {
+		if value {
+			args = append(args, "--"+flag)
+		}
+	}
+	addMap := func(flag string, m map[string]string) {
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			args = append(args, "--"+flag, k+"="+m[k])
+		}
+	}
+
+	if e := a.Enroll; e != nil {
+		add("url", e.URL)
+		add("enrollment-token-file", e.EnrollmentTokenFile)
+		add("certificate-authorities", strings.Join(e.CertificateAuthorities, ","))
+		add("ca-sha256", strings.Join(e.CASHA256, ","))
+		addBool("insecure", e.Insecure)
+		add("proxy-url", e.Proxy.URL)
+		addBool("proxy-disabled", e.Proxy.Disabled)
+		addMap("proxy-header", e.Proxy.Headers)
+		for _, tag := range e.Tags {
+			add("tag", tag)
+		}
+	}
+	if fs := a.FleetServer; fs != nil {
+		add("fleet-server-es", fs.ES)
+		add("fleet-server-es-ca", fs.ESCA)
+		add("fleet-server-es-ca-trusted-fingerprint", fs.ESCATrustedFingerprint)
+		addBool("fleet-server-es-insecure", fs.ESInsecure)
+		add("fleet-server-service-token-path", fs.ServiceTokenFile)
+		add("fleet-server-policy", fs.PolicyID)
+		add("fleet-server-host", fs.Host)
+		if fs.Port > 0 {
+			add("fleet-server-port", strconv.Itoa(int(fs.Port)))
+		}
+		add("fleet-server-cert", fs.Cert)
+		add("fleet-server-cert-key", fs.CertKey)
+		addBool("fleet-server-insecure-http", fs.InsecureHTTP)
+		addMap("header", fs.Headers)
+	}
+	return args
+}
+
+// applyState is the state of the host the answer file is applied to.
+type applyState struct {
+	status      install.StatusType
+	reason      string
+	serviceUser install.ServiceUser
+	// applied is the last answer file applied, nil when none was.
+	applied *answerFile
+}
+
+// applyStep is a step of the plan bringing the host to the answer file.
+type applyStep struct {
+	name   string
+	change bool
+	reason string
+}
+
+const (
+	stepInstall = "install"
+	stepEnroll  = "enroll"
+)
+
+// planApply returns the steps bringing the host from its state to the answer file, the steps without
+// change are kept to be reported.
+func planApply(desired *answerFile, state applyState) ([]applyStep, error) {
+	var plan []applyStep
+
+	serviceUser := desired.serviceUser()
+	reinstall := false
+	switch {
+	case state.status == install.PackageInstall:
+		if !sameServiceUser(serviceUser, state.serviceUser) {
+			return nil, fmt.Errorf("install.user cannot be changed when installed as a system package")
+		}
+		plan = append(plan, applyStep{name: stepInstall, reason: "installed as a system package"})
+	case state.status == install.NotInstalled:
+		reinstall = true
+		plan = append(plan, applyStep{name: stepInstall, change: true, reason: "not installed"})
+	case state.status == install.Broken:
+		reinstall = true
+		plan = append(plan, applyStep{name: stepInstall, change: true, reason: "installation is broken: " + state.reason})
+	case !sameServiceUser(serviceUser, state.serviceUser):
+		reinstall = true
+		plan = append(plan, applyStep{name: stepInstall, change: true, reason: fmt.Sprintf("service user changes from %s to %s", describeServiceUser(state.serviceUser), describeServiceUser(serviceUser))})
+	default:
+		plan = append(plan, applyStep{name: stepInstall, reason: "installed as " + describeServiceUser(serviceUser)})
+	}
+
+	switch {
+	case !desired.enrolls():
+		plan = append(plan, applyStep{name: stepEnroll, reason: "not enrolled by the answer file"})
+	case reinstall:
+		plan = append(plan, applyStep{name: stepEnroll, change: true, reason: "installation replaced"})
+	case state.applied == nil || !state.applied.enrolls():
+		plan = append(plan, applyStep{name: stepEnroll, change: true, reason: "not enrolled by a previous apply"})
+	case !reflect.DeepEqual(normalizeEnroll(desired.Enroll), normalizeEnroll(state.applied.Enroll)):
+		plan = append(plan, applyStep{name: stepEnroll, change: true, reason: "enrollment changed"})
+	case !reflect.DeepEqual(desired.FleetServer, state.applied.FleetServer):
+		plan = append(plan, applyStep{name: stepEnroll, change: true, reason: "fleet server changed"})
+	default:
+		plan = append(plan, applyStep{name: stepEnroll, reason: "enrolled"})
+	}
+	return plan, nil
+}
+
+func sameServiceUser(a, b install.ServiceUser) bool {
+	return a.Name == b.Name && a.Group == b.Group && reflect.DeepEqual(a.Capabilities, b.Capabilities)
+}
+
+func describeServiceUser(u install.ServiceUser) string {
+	if u.IsRoot() {
+		return "root"
+	}
+	return u.Name + ":" + u.Group
+}
+
+// normalizeEnroll ignores the order of the tags.
+func normalizeEnroll(e *answerEnroll) *answerEnroll {
+	if e == nil {
+		return nil
+	}
+	n := *e
+	n.Tags = append([]string(nil), e.Tags...)
+	sort.Strings(n.Tags)
+	return &n
+}
+
+func newApplyCommandWithArgs(_ []string, streams *cli.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Install and enroll Elastic Agent as described by an answer file",
+		Long: `Install and enroll Elastic Agent as described by an answer file, the installation path, the service
+user, the enrollment into Fleet and the bootstrap of a Fleet Server. The answer file is compared to the
+current installation and to the last answer file applied, only the steps needed are performed so the
+command can be run again on a configured host.
+
+With --plan the steps are printed without performing them.`,
+		Args: cobra.ExactArgs(0),
+		Run: func(c *cobra.Command, _ []string) {
+			if err := applyCmd(streams, c); err != nil {
+				fmt.Fprintf(streams.Err, "Error: %v\n%s\n", err, troubleshootMessage())
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().StringP("file", "f", "", "Path to the answer file")
+	cmd.Flags().Bool("plan", false, "Print the steps without performing them")
+	_ = cmd.MarkFlagRequired("file")
+
+	return cmd
+}
+
+func applyCmd(streams *cli.IOStreams, cmd *cobra.Command) error {
+	file, _ := cmd.Flags().GetString("file")
+	desired, err := loadAnswerFile(file)
+	if err != nil {
+		return err
+	}
+
+	isAdmin, err := install.HasRoot()
+	if err != nil {
+		return fmt.Errorf("unable to perform apply command while checking for administrator rights, %w", err)
+	}
+	if !isAdmin {
+		return fmt.Errorf("unable to perform apply command, not executed with %s permissions", install.PermissionUser)
+	}
+
+	state, err := currentApplyState()
+	if err != nil {
+		return err
+	}
+	plan, err := planApply(desired, state)
+	if err != nil {
+		return err
+	}
+
+	onlyPlan, _ := cmd.Flags().GetBool("plan")
+	changes := 0
+	for _, step := range plan {
+		if step.change {
+			changes++
+			fmt.Fprintf(streams.Out, "~ %s: %s\n", step.name, step.reason)
+		} else {
+			fmt.Fprintf(streams.Out, "  %s: %s\n", step.name, step.reason)
+		}
+	}
+	if onlyPlan {
+		fmt.Fprintf(streams.Out, "%d steps to perform\n", changes)
+		return nil
+	}
+	if changes == 0 {
+		fmt.Fprintln(streams.Out, "Elastic Agent is up to date")
+		return nil
+	}
+
+	for _, step := range plan {
+		if !step.change {
+			continue
+		}
+		if err := performApplyStep(streams, step, desired, state); err != nil {
+			return err
+		}
+	}
+
+	if err := saveAppliedFile(appliedDir(state.status), desired); err != nil {
+		return err
+	}
+	fmt.Fprintln(streams.Out, "Elastic Agent is up to date")
+	return nil
+}
+
+func performApplyStep(streams *cli.IOStreams, step applyStep, desired *answerFile, state applyState) error {
+	switch step.name {
+	case stepInstall:
+		if info.RunningInstalled() {
+			return fmt.Errorf("cannot install from the installed Elastic Agent, apply the answer file with the binary of an extracted package")
+		}
+		fmt.Fprintf(streams.Out, "Installing Elastic Agent at %s\n", paths.InstallPath)
+		if err := install.Install(paths.ConfigFile(), desired.serviceUser()); err != nil {
+			return err
+		}
+		return install.StartService()
+	case stepEnroll:
+		args := desired.enrollArgs()
+		fmt.Fprintln(streams.Out, "Enrolling Elastic Agent")
+		// the service is started before enrolling, as with install, so the daemon is reloaded
+		if !install.ServiceRunning() {
+			if err := install.StartService(); err != nil {
+				return err
+			}
+		}
+		enrollArgs := []string{"enroll", "--force"}
+		if state.status != install.PackageInstall {
+			enrollArgs = append(enrollArgs, "--from-install")
+		}
+		enrollCmd := exec.Command(install.ExecutablePath(), append(enrollArgs, args...)...) //nolint:gosec // it's not tainted
+		enrollCmd.Stdout = streams.Out
+		enrollCmd.Stderr = streams.Err
+		if err := enrollCmd.Run(); err != nil {
+			return fmt.Errorf("enroll command failed: %w", err)
+		}
+		return nil
+	}
+	return nil
+}
+
+func loadAnswerFile(path string) (*answerFile, error) {
+	rawConfig, err := config.LoadFile(path)
+	if err != nil {
+		return nil, errors.New(err,
+			fmt.Sprintf("could not read the answer file %s", path),
+			errors.TypeFilesystem,
+			errors.M(errors.MetaKeyPath, path))
+	}
+	a := &answerFile{}
+	if err := rawConfig.Unpack(a); err != nil {
+		return nil, errors.New(err,
+			fmt.Sprintf("invalid answer file %s", path),
+			errors.TypeConfig,
+			errors.M(errors.MetaKeyPath, path))
+	}
+	return a, nil
+}
+
+func currentApplyState() (applyState, error) {
+	state := applyState{}
+	state.status, state.reason = install.Status()
+	if state.status == install.NotInstalled {
+		return state, nil
+	}
+	var err error
+	state.serviceUser, err = install.LoadServiceUser()
+	if err != nil {
+		return state, err
+	}
+
+	p := filepath.Join(appliedDir(state.status), appliedFile)
+	if _, err := os.Stat(p); err == nil {
+		state.applied, err = loadAnswerFile(p)
+		if err != nil {
+			return state, err
+		}
+	}
+	return state, nil
+}
+
+// appliedDir returns the directory of the record of the last answer file applied, removed along with
+// the installation.
+func appliedDir(status install.StatusType) string {
+	if status == install.PackageInstall {
+		return paths.Config()
+	}
+	return paths.InstallPath
+}
+
+func saveAppliedFile(dir string, a *answerFile) error {
+	b, err := yaml.Marshal(a)
+	if err != nil {
+		return err
+	}
+	p := filepath.Join(dir, appliedFile)
+	if err := ioutil.WriteFile(p, b, 0600); err != nil {
+		return errors.New(err,
+			fmt.Sprintf("could not write %s", p),
+			errors.TypeFilesystem,
+			errors.M(errors.MetaKeyPath, p))
+	}
+	return nil
+}
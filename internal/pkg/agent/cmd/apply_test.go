@@ -0,0 +1,139 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/install"
+)
+
+func writeAnswerFile(t *testing.T, content string) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), "agent-install.yml")
+	require.NoError(t, os.WriteFile(p, []byte(content), 0600))
+	return p
+}
+
+func TestLoadAnswerFile(t *testing.T) {
+	a, err := loadAnswerFile(writeAnswerFile(t, `
+install:
+  user: elastic-agent
+enroll:
+  url: https://fleet.example.com:8220
+  enrollment_token_file: /etc/elastic-agent/token
+  tags: [linux, production]
+  proxy:
+    url: http://proxy.example.com:3128
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "elastic-agent", a.serviceUser().Group)
+	assert.Equal(t, install.DefaultCapabilities, a.serviceUser().Capabilities)
+
+	assert.Equal(t, []string{
+		"--url", "https://fleet.example.com:8220",
+		"--enrollment-token-file", "/etc/elastic-agent/token",
+		"--proxy-url", "http://proxy.example.com:3128",
+		"--tag", "linux",
+		"--tag", "production",
+	}, a.enrollArgs())
+
+	a, err = loadAnswerFile(writeAnswerFile(t, `
+fleet_server:
+  es: https://es.example.com:9200
+  service_token_file: /etc/elastic-agent/service-token
+  policy_id: fleet-server-policy
+`))
+	require.NoError(t, err)
+	// the service token is read by enroll, it does not show in the arguments of the process
+	assert.Equal(t, []string{
+		"--fleet-server-es", "https://es.example.com:9200",
+		"--fleet-server-service-token-path", "/etc/elastic-agent/service-token",
+		"--fleet-server-policy", "fleet-server-policy",
+	}, a.enrollArgs())
+
+	for name, content := range map[string]string{
+		"no token file":      "enroll:\n  url: https://fleet.example.com:8220\n",
+		"relative token":     "enroll:\n  url: https://fleet.example.com:8220\n  enrollment_token_file: token\n",
+		"other install path": "install:\n  path: /somewhere/else\n",
+		"group without user": "install:\n  group: elastic-agent\n",
+		"fleet server no es": "fleet_server:\n  service_token_file: /etc/elastic-agent/service-token\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := loadAnswerFile(writeAnswerFile(t, content))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestPlanApply(t *testing.T) {
+	desired := &answerFile{
+		Enroll: &answerEnroll{URL: "https://fleet.example.com:8220", EnrollmentTokenFile: "/token", Tags: []string{"b", "a"}},
+	}
+	applied := &answerFile{
+		Enroll: &answerEnroll{URL: "https://fleet.example.com:8220", EnrollmentTokenFile: "/token", Tags: []string{"a", "b"}},
+	}
+
+	changes := func(plan []applyStep) []string {
+		var names []string
+		for _, step := range plan {
+			if step.change {
+				names = append(names, step.name)
+			}
+		}
+		return names
+	}
+
+	testcases := map[string]struct {
+		state    applyState
+		expected []string
+	}{
+		"not installed": {
+			state:    applyState{status: install.NotInstalled},
+			expected: []string{stepInstall, stepEnroll},
+		},
+		"up to date": {
+			state:    applyState{status: install.Installed, applied: applied},
+			expected: nil,
+		},
+		"never applied": {
+			state:    applyState{status: install.Installed},
+			expected: []string{stepEnroll},
+		},
+		"service user changed": {
+			state:    applyState{status: install.Installed, applied: applied, serviceUser: install.ServiceUser{Name: "agent", Group: "agent"}},
+			expected: []string{stepInstall, stepEnroll},
+		},
+		"package": {
+			state:    applyState{status: install.PackageInstall, applied: applied},
+			expected: nil,
+		},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			plan, err := planApply(desired, tc.state)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, changes(plan))
+		})
+	}
+
+	t.Run("enrollment changed", func(t *testing.T) {
+		changed := &answerFile{Enroll: &answerEnroll{URL: "https://other.example.com:8220", EnrollmentTokenFile: "/token"}}
+		plan, err := planApply(changed, applyState{status: install.Installed, applied: applied})
+		require.NoError(t, err)
+		assert.Equal(t, []string{stepEnroll}, changes(plan))
+	})
+
+	t.Run("package with service user", func(t *testing.T) {
+		withUser := &answerFile{Install: answerInstall{User: "agent"}}
+		_, err := planApply(withUser, applyState{status: install.PackageInstall})
+		assert.Error(t, err)
+	})
+}
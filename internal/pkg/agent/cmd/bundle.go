@@ -0,0 +1,98 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/program"
+	"github.com/elastic/elastic-agent/internal/pkg/artifact"
+	"github.com/elastic/elastic-agent/internal/pkg/artifact/bundle"
+	"github.com/elastic/elastic-agent/internal/pkg/cli"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+func newBundleCommandWithArgs(args []string, streams *cli.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Manage offline upgrade bundles",
+		Long:  "Manage offline upgrade bundles containing signed Elastic Agent and program packages for air-gapped upgrades.",
+	}
+
+	cmd.AddCommand(newBundleCreateCommandWithArgs(args, streams))
+
+	return cmd
+}
+
+func newBundleCreateCommandWithArgs(_ []string, streams *cli.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create <version>",
+		Short: "Create an offline upgrade bundle for the specified version",
+		Long: `Downloads and verifies the Elastic Agent package of the specified version, its checksum and signature,
+and optionally program packages, and writes them into a single bundle file. The bundle can be
+used on an air-gapped host with "elastic-agent upgrade --bundle <file>".`,
+		Args: cobra.ExactArgs(1),
+		Run: func(c *cobra.Command, args []string) {
+			if err := bundleCreateCmd(streams, c, args); err != nil {
+				fmt.Fprintf(streams.Err, "Error: %v\n%s\n", err, troubleshootMessage())
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().StringP("output", "o", "", "Path of the created bundle (default: elastic-agent-<version>-<os>-<arch>.bundle.tar)")
+	cmd.Flags().StringP("source-uri", "s", "", "Source URI to download the packages from")
+	cmd.Flags().StringSliceP("program", "p", nil, "Additional programs to include in the bundle (e.g. filebeat,metricbeat)")
+	cmd.Flags().String("os", "", "Operating system of the target host (default: current operating system)")
+	cmd.Flags().String("arch", "", "Architecture of the target host [32, 64, arm64] (default: current architecture)")
+
+	return cmd
+}
+
+func bundleCreateCmd(streams *cli.IOStreams, cmd *cobra.Command, args []string) error {
+	version := args[0]
+	output, _ := cmd.Flags().GetString("output")
+	sourceURI, _ := cmd.Flags().GetString("source-uri")
+	programNames, _ := cmd.Flags().GetStringSlice("program")
+
+	cfg := artifact.DefaultConfig()
+	cfg.OperatingSystem, _ = cmd.Flags().GetString("os")
+	cfg.Architecture, _ = cmd.Flags().GetString("arch")
+	if sourceURI != "" {
+		cfg.SourceURI = sourceURI
+	}
+
+	programs := make([]program.Spec, 0, len(programNames))
+	for _, name := range programNames {
+		spec, ok := program.SupportedMap[strings.ToLower(name)]
+		if !ok {
+			return fmt.Errorf("unknown program %q", name)
+		}
+		programs = append(programs, spec)
+	}
+
+	if output == "" {
+		output = fmt.Sprintf("elastic-agent-%s-%s-%s.bundle.tar", version, cfg.OS(), cfg.Arch())
+	}
+
+	log, err := logger.NewWithLogpLevel("", logp.InfoLevel, false)
+	if err != nil {
+		return err
+	}
+
+	if err := bundle.Create(context.Background(), log, cfg, version, programs, output); err != nil {
+		return errors.New(err, "failed to create bundle")
+	}
+
+	fmt.Fprintf(streams.Out, "Bundle for version %s written to %s\n", version, output)
+	return nil
+}
@@ -61,12 +61,22 @@ func NewCommandWithArgs(args []string, streams *cli.IOStreams) *cobra.Command {
 	cmd.AddCommand(newInstallCommandWithArgs(args, streams))
 	cmd.AddCommand(newUninstallCommandWithArgs(args, streams))
 	cmd.AddCommand(newUpgradeCommandWithArgs(args, streams))
+	cmd.AddCommand(newBundleCommandWithArgs(args, streams))
 	cmd.AddCommand(newEnrollCommandWithArgs(args, streams))
 	cmd.AddCommand(newInspectCommandWithArgs(args, streams))
 	cmd.AddCommand(newWatchCommandWithArgs(args, streams))
 	cmd.AddCommand(newContainerCommand(args, streams))
 	cmd.AddCommand(newStatusCommand(args, streams))
 	cmd.AddCommand(newDiagnosticsCommand(args, streams))
+	cmd.AddCommand(newActionsCommandWithArgs(args, streams))
+	cmd.AddCommand(newActionCommandWithArgs(args, streams))
+	cmd.AddCommand(newLogLevelCommandWithArgs(args, streams))
+	cmd.AddCommand(newVaultCommandWithArgs(args, streams))
+	cmd.AddCommand(newStateCommandWithArgs(args, streams))
+	cmd.AddCommand(newVerifyCommandWithArgs(args, streams))
+	cmd.AddCommand(newApplyCommandWithArgs(args, streams))
+	cmd.AddCommand(newConfigCommandWithArgs(args, streams))
+	cmd.AddCommand(newRelinkCommandWithArgs(args, streams))
 
 	// windows special hidden sub-command (only added on Windows)
 	reexec := newReExecWindowsCommand(args, streams)
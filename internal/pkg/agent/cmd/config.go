@@ -0,0 +1,137 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/configuration"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/internal/pkg/cli"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+func newConfigCommandWithArgs(_ []string, streams *cli.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Describe and check the Elastic Agent configuration",
+		Long:  "Describe the settings of elastic-agent.yml and check the configuration file for unknown settings.",
+	}
+
+	cmd.AddCommand(newConfigSchemaCommand(streams))
+	cmd.AddCommand(newConfigCheckCommand(streams))
+
+	return cmd
+}
+
+func newConfigSchemaCommand(streams *cli.IOStreams) *cobra.Command {
+	return &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON schema of the Elastic Agent configuration",
+		Long: `Print the JSON schema of the settings of elastic-agent.yml, the fleet.* and agent.* sections.
+The schema can be used by editors to complete and validate the configuration file.`,
+		Args: cobra.ExactArgs(0),
+		Run: func(c *cobra.Command, args []string) {
+			if err := configSchemaCmd(streams); err != nil {
+				fmt.Fprintf(streams.Err, "Error: %v\n%s\n", err, troubleshootMessage())
+				os.Exit(1)
+			}
+		},
+	}
+}
+
+func configSchemaCmd(streams *cli.IOStreams) error {
+	enc := json.NewEncoder(streams.Out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(configuration.Schema())
+}
+
+func newConfigCheckCommand(streams *cli.IOStreams) *cobra.Command {
+	return &cobra.Command{
+		Use:   "check",
+		Short: "Check elastic-agent.yml for unknown settings",
+		Long:  "Report the unknown or misspelled keys under agent.* in elastic-agent.yml and its overlays, the command fails when there is one.",
+		Args:  cobra.ExactArgs(0),
+		Run: func(c *cobra.Command, args []string) {
+			if err := configCheckCmd(streams); err != nil {
+				fmt.Fprintf(streams.Err, "Error: %v\n%s\n", err, troubleshootMessage())
+				os.Exit(1)
+			}
+		},
+	}
+}
+
+func configCheckCmd(streams *cli.IOStreams) error {
+	unknown, err := unknownSettings()
+	if err != nil {
+		return err
+	}
+	for _, k := range unknown {
+		fmt.Fprintln(streams.Out, k)
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("%d unknown settings in the configuration", len(unknown))
+	}
+	fmt.Fprintln(streams.Out, "No unknown settings in the configuration")
+	return nil
+}
+
+// unknownSettings returns the unknown keys under agent.* of the configuration file and of its
+// overlays, described with their location.
+func unknownSettings() ([]string, error) {
+	rawConfig, provenance, err := configuration.LoadLayered(paths.ConfigFile())
+	if err != nil {
+		return nil, err
+	}
+	unknown, err := configuration.UnknownSettings(rawConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	descriptions := make([]string, 0, len(unknown))
+	for _, k := range unknown {
+		if src, ok := provenance.Lookup(k.Path); ok {
+			descriptions = append(descriptions, fmt.Sprintf("%s (%s)", k, src))
+			continue
+		}
+		descriptions = append(descriptions, k.String())
+	}
+	return descriptions, nil
+}
+
+// checkStrictConfig reports the unknown keys under agent.* of the configuration file depending on
+// agent.strict_config, with the error mode Elastic Agent refuses to start.
+func checkStrictConfig(log *logger.Logger, mode configuration.StrictMode) error {
+	if mode == "" || mode == configuration.StrictOff {
+		return nil
+	}
+
+	unknown, err := unknownSettings()
+	if err != nil {
+		return err
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	if mode == configuration.StrictError {
+		for _, k := range unknown {
+			log.Error(k)
+		}
+		return errors.New(
+			fmt.Sprintf("%d unknown settings in the configuration, the first one is %s", len(unknown), unknown[0]),
+			errors.TypeConfig,
+			errors.M(errors.MetaKeyPath, paths.ConfigFile()))
+	}
+	for _, k := range unknown {
+		log.Warn(k)
+	}
+	return nil
+}
@@ -50,11 +50,13 @@ func newEnrollCommandWithArgs(_ []string, streams *cli.IOStreams) *cobra.Command
 func addEnrollFlags(cmd *cobra.Command) {
 	cmd.Flags().StringP("url", "", "", "URL to enroll Agent into Fleet")
 	cmd.Flags().StringP("enrollment-token", "t", "", "Enrollment token to use to enroll Agent into Fleet")
+	cmd.Flags().StringP("enrollment-token-file", "", "", "Path to a file holding the enrollment token; the file is read again to re-enroll when Fleet rejects the Agent API key")
 	cmd.Flags().StringP("fleet-server-es", "", "", "Start and run a Fleet Server along side this Elastic Agent connecting to the provided elasticsearch")
 	cmd.Flags().StringP("fleet-server-es-ca", "", "", "Path to certificate authority to use with communicate with elasticsearch")
 	cmd.Flags().StringP("fleet-server-es-ca-trusted-fingerprint", "", "", "Elasticsearch certificate authority's SHA256 fingerprint")
 	cmd.Flags().BoolP("fleet-server-es-insecure", "", false, "Disables validation of certificates")
 	cmd.Flags().StringP("fleet-server-service-token", "", "", "Service token to use for communication with elasticsearch")
+	cmd.Flags().StringP("fleet-server-service-token-path", "", "", "Path to a file holding the service token to use for communication with elasticsearch")
 	cmd.Flags().StringP("fleet-server-policy", "", "", "Start and run a Fleet Server on this specific policy")
 	cmd.Flags().StringP("fleet-server-host", "", "", "Fleet Server HTTP binding host (overrides the policy)")
 	cmd.Flags().Uint16P("fleet-server-port", "", 0, "Fleet Server HTTP binding port (overrides the policy)")
@@ -92,6 +94,24 @@ func validateEnrollFlags(cmd *cobra.Command) error {
 	if fCertKey != "" && !filepath.IsAbs(fCertKey) {
 		return errors.New("--fleet-server-cert-key must be provided as an absolute path", errors.M("path", fCertKey), errors.TypeConfig)
 	}
+	tokenFile, _ := cmd.Flags().GetString("enrollment-token-file")
+	if tokenFile != "" {
+		if !filepath.IsAbs(tokenFile) {
+			return errors.New("--enrollment-token-file must be provided as an absolute path", errors.M("path", tokenFile), errors.TypeConfig)
+		}
+		if token, _ := cmd.Flags().GetString("enrollment-token"); token != "" {
+			return errors.New("--enrollment-token and --enrollment-token-file cannot be used together", errors.TypeConfig)
+		}
+	}
+	serviceTokenPath, _ := cmd.Flags().GetString("fleet-server-service-token-path")
+	if serviceTokenPath != "" {
+		if !filepath.IsAbs(serviceTokenPath) {
+			return errors.New("--fleet-server-service-token-path must be provided as an absolute path", errors.M("path", serviceTokenPath), errors.TypeConfig)
+		}
+		if token, _ := cmd.Flags().GetString("fleet-server-service-token"); token != "" {
+			return errors.New("--fleet-server-service-token and --fleet-server-service-token-path cannot be used together", errors.TypeConfig)
+		}
+	}
 	return nil
 }
 
@@ -107,6 +127,7 @@ func buildEnrollmentFlags(cmd *cobra.Command, url string, token string) []string
 	fElasticSearchCASHA256, _ := cmd.Flags().GetString("fleet-server-es-ca-trusted-fingerprint")
 	fElasticSearchInsecure, _ := cmd.Flags().GetBool("fleet-server-es-insecure")
 	fServiceToken, _ := cmd.Flags().GetString("fleet-server-service-token")
+	fServiceTokenPath, _ := cmd.Flags().GetString("fleet-server-service-token-path")
 	fPolicy, _ := cmd.Flags().GetString("fleet-server-policy")
 	fHost, _ := cmd.Flags().GetString("fleet-server-host")
 	fPort, _ := cmd.Flags().GetUint16("fleet-server-port")
@@ -125,6 +146,7 @@ func buildEnrollmentFlags(cmd *cobra.Command, url string, token string) []string
 	daemonTimeout, _ := cmd.Flags().GetDuration("daemon-timeout")
 	fTimeout, _ := cmd.Flags().GetDuration("fleet-server-timeout")
 	fTags, _ := cmd.Flags().GetStringSlice("tag")
+	tokenFile, _ := cmd.Flags().GetString("enrollment-token-file")
 	args := []string{}
 	if url != "" {
 		args = append(args, "--url")
@@ -133,6 +155,9 @@ func buildEnrollmentFlags(cmd *cobra.Command, url string, token string) []string
 	if token != "" {
 		args = append(args, "--enrollment-token")
 		args = append(args, token)
+	} else if tokenFile != "" {
+		args = append(args, "--enrollment-token-file")
+		args = append(args, tokenFile)
 	}
 	if fServer != "" {
 		args = append(args, "--fleet-server-es")
@@ -149,6 +174,9 @@ func buildEnrollmentFlags(cmd *cobra.Command, url string, token string) []string
 	if fServiceToken != "" {
 		args = append(args, "--fleet-server-service-token")
 		args = append(args, fServiceToken)
+	} else if fServiceTokenPath != "" {
+		args = append(args, "--fleet-server-service-token-path")
+		args = append(args, fServiceTokenPath)
 	}
 	if fPolicy != "" {
 		args = append(args, "--fleet-server-policy")
@@ -292,12 +320,14 @@ func enroll(streams *cli.IOStreams, cmd *cobra.Command) error {
 	insecure, _ := cmd.Flags().GetBool("insecure")
 	url, _ := cmd.Flags().GetString("url")
 	enrollmentToken, _ := cmd.Flags().GetString("enrollment-token")
+	enrollmentTokenFile, _ := cmd.Flags().GetString("enrollment-token-file")
 	fServer, _ := cmd.Flags().GetString("fleet-server-es")
 	fElasticSearchCA, _ := cmd.Flags().GetString("fleet-server-es-ca")
 	fElasticSearchCASHA256, _ := cmd.Flags().GetString("fleet-server-es-ca-trusted-fingerprint")
 	fElasticSearchInsecure, _ := cmd.Flags().GetBool("fleet-server-es-insecure")
 	fHeaders, _ := cmd.Flags().GetStringSlice("header")
 	fServiceToken, _ := cmd.Flags().GetString("fleet-server-service-token")
+	fServiceTokenPath, _ := cmd.Flags().GetString("fleet-server-service-token-path")
 	fPolicy, _ := cmd.Flags().GetString("fleet-server-policy")
 	fHost, _ := cmd.Flags().GetString("fleet-server-host")
 	fPort, _ := cmd.Flags().GetUint16("fleet-server-port")
@@ -313,6 +343,15 @@ func enroll(streams *cli.IOStreams, cmd *cobra.Command) error {
 	fTimeout, _ := cmd.Flags().GetDuration("fleet-server-timeout")
 	tags, _ := cmd.Flags().GetStringSlice("tag")
 
+	// the service token is read from the file so it does not show in the arguments of the process
+	if fServiceTokenPath != "" {
+		b, err := os.ReadFile(fServiceTokenPath)
+		if err != nil {
+			return errors.New(err, "reading the service token file", errors.TypeFilesystem, errors.M(errors.MetaKeyPath, fServiceTokenPath))
+		}
+		fServiceToken = strings.TrimSpace(string(b))
+	}
+
 	caStr, _ := cmd.Flags().GetString("certificate-authorities")
 	CAs := cli.StringToSlice(caStr)
 	caSHA256str, _ := cmd.Flags().GetString("ca-sha256")
@@ -322,6 +361,7 @@ func enroll(streams *cli.IOStreams, cmd *cobra.Command) error {
 
 	options := enrollCmdOption{
 		EnrollAPIKey:         enrollmentToken,
+		EnrollAPIKeyFile:     enrollmentTokenFile,
 		URL:                  url,
 		CAs:                  CAs,
 		CASha256:             caSHA256,
@@ -332,6 +372,7 @@ func enroll(streams *cli.IOStreams, cmd *cobra.Command) error {
 		ProxyURL:             proxyURL,
 		ProxyDisabled:        proxyDisabled,
 		ProxyHeaders:         mapFromEnvList(proxyHeaders),
+		ProxyRules:           cfg.Settings.Proxy.Rules,
 		DelayEnroll:          delayEnroll,
 		DaemonTimeout:        daemonTimeout,
 		Tags:                 tags,
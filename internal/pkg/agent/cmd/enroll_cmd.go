@@ -25,6 +25,7 @@ import (
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/filelock"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/info"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/reenroll"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/secret"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/configuration"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/control/client"
@@ -40,6 +41,7 @@ import (
 	"github.com/elastic/elastic-agent/internal/pkg/core/process"
 	"github.com/elastic/elastic-agent/internal/pkg/fleetapi"
 	fleetclient "github.com/elastic/elastic-agent/internal/pkg/fleetapi/client"
+	"github.com/elastic/elastic-agent/internal/pkg/proxy"
 	"github.com/elastic/elastic-agent/internal/pkg/release"
 	"github.com/elastic/elastic-agent/internal/pkg/remote"
 	"github.com/elastic/elastic-agent/pkg/core/logger"
@@ -102,10 +104,12 @@ type enrollCmdOption struct {
 	CASha256             []string                   `yaml:"ca_sha256,omitempty"`
 	Insecure             bool                       `yaml:"insecure,omitempty"`
 	EnrollAPIKey         string                     `yaml:"enrollment_key,omitempty"`
+	EnrollAPIKeyFile     string                     `yaml:"enrollment_key_file,omitempty"`
 	Staging              string                     `yaml:"staging,omitempty"`
 	ProxyURL             string                     `yaml:"proxy_url,omitempty"`
 	ProxyDisabled        bool                       `yaml:"proxy_disabled,omitempty"`
 	ProxyHeaders         map[string]string          `yaml:"proxy_headers,omitempty"`
+	ProxyRules           proxy.Rules                `yaml:"-"`
 	DaemonTimeout        time.Duration              `yaml:"daemon_timeout,omitempty"`
 	UserProvidedMetadata map[string]interface{}     `yaml:"-"`
 	FixPermissions       bool                       `yaml:"-"`
@@ -144,6 +148,7 @@ func (e *enrollCmdOption) remoteConfig() (remote.Config, error) {
 	}
 
 	cfg.Transport.Proxy = *proxySettings
+	cfg.ProxyRules = e.ProxyRules
 
 	return cfg, nil
 }
@@ -250,6 +255,15 @@ func (c *enrollCmd) Execute(ctx context.Context, streams *cli.IOStreams) error {
 		return c.writeDelayEnroll(streams)
 	}
 
+	// token file is read only when enrolling, so delayed enrollment does
+	// not persist the token and picks up the latest content of the file
+	if c.options.EnrollAPIKeyFile != "" {
+		c.options.EnrollAPIKey, err = reenroll.ReadTokenFile(c.options.EnrollAPIKeyFile)
+		if err != nil {
+			return err
+		}
+	}
+
 	err = c.enrollWithBackoff(ctx, persistentConfig)
 	if err != nil {
 		return errors.New(err, "fail to enroll")
@@ -524,6 +538,13 @@ func (c *enrollCmd) enroll(ctx context.Context, persistentConfig map[string]inte
 		return err
 	}
 
+	if c.options.EnrollAPIKeyFile != "" {
+		fleetConfig.Enrollment = &configuration.EnrollmentConfig{
+			TokenFile: c.options.EnrollAPIKeyFile,
+			Reenroll:  true,
+		}
+	}
+
 	agentConfig := c.createAgentConfig(resp.Item.ID, persistentConfig, c.options.FleetServer.Headers)
 
 	localFleetServer := c.options.FleetServer.ConnStr != ""
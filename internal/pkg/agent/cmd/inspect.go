@@ -6,8 +6,13 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
@@ -30,6 +35,7 @@ import (
 	"github.com/elastic/elastic-agent/internal/pkg/config/operations"
 	"github.com/elastic/elastic-agent/internal/pkg/core/monitoring/noop"
 	"github.com/elastic/elastic-agent/internal/pkg/core/status"
+	"github.com/elastic/elastic-agent/internal/pkg/dir"
 	"github.com/elastic/elastic-agent/internal/pkg/sorted"
 	"github.com/elastic/elastic-agent/pkg/core/logger"
 	"github.com/elastic/go-sysinfo"
@@ -42,13 +48,18 @@ func newInspectCommandWithArgs(s []string, streams *cli.IOStreams) *cobra.Comman
 		Long:  "Shows current configuration of the agent",
 		Args:  cobra.ExactArgs(0),
 		Run: func(c *cobra.Command, args []string) {
-			if err := inspectConfig(paths.ConfigFile()); err != nil {
+			inspect := inspectConfig
+			if provenance, _ := c.Flags().GetBool("provenance"); provenance {
+				inspect = inspectProvenance
+			}
+			if err := inspect(paths.ConfigFile()); err != nil {
 				fmt.Fprintf(streams.Err, "Error: %v\n%s\n", err, troubleshootMessage())
 				os.Exit(1)
 			}
 		},
 	}
 
+	cmd.Flags().Bool("provenance", false, "Show the file and line each key of the configuration comes from")
 	cmd.AddCommand(newInspectOutputCommandWithArgs(s))
 
 	return cmd
@@ -58,7 +69,7 @@ func newInspectOutputCommandWithArgs(_ []string) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "output",
 		Short: "Displays configuration generated for output",
-		Long:  "Displays configuration generated for output.\nIf no output is specified list of output is displayed",
+		Long:  "Displays configuration generated for output.\nIf no output is specified list of output is displayed.\nAn input using multiple outputs is displayed in the configuration of every output it uses",
 		Args:  cobra.MaximumNArgs(2),
 		RunE: func(c *cobra.Command, args []string) error {
 			outName, _ := c.Flags().GetString("output")
@@ -97,6 +108,132 @@ func inspectConfig(cfgPath string) error {
 	return printConfig(fullCfg)
 }
 
+// inspectProvenance prints every key of the configuration with the file and line it comes from,
+// the keys of the policy of Fleet are reported as such.
+func inspectProvenance(cfgPath string) error {
+	err := tryContainerLoadPaths()
+	if err != nil {
+		return err
+	}
+
+	rawConfig, _, err := configuration.LoadLayered(cfgPath)
+	if err != nil {
+		return err
+	}
+	cfg, err := configuration.NewFromConfig(rawConfig)
+	if err != nil {
+		return err
+	}
+
+	if !configuration.IsStandalone(cfg.Fleet) {
+		fullCfg, res, err := operations.LoadFullAgentConfigWithOverride(cfgPath, true)
+		if err != nil {
+			return err
+		}
+		return printProvenance(fullCfg, nil, res.Applied)
+	}
+
+	l, err := newErrorLogger()
+	if err != nil {
+		return err
+	}
+	inputsGlob := filepath.Join(paths.AgentInputsDPath(), "*.yml")
+	patterns := append([]string{cfgPath}, cfg.Settings.Layers.Patterns()...)
+	if cfg.Settings.Path != "" {
+		patterns = append(patterns, cfg.Settings.Path)
+	}
+	files, err := dir.DiscoverFiles(append(patterns, inputsGlob)...)
+	if err != nil {
+		return err
+	}
+
+	loader := config.NewLoader(l, inputsGlob)
+	loader.SetLayers(cfg.Settings.Layers.Patterns(), cfg.Settings.Layers.Strategies())
+	fullCfg, provenance, err := loader.LoadWithProvenance(files)
+	if err != nil {
+		return err
+	}
+	return printProvenance(fullCfg, provenance, nil)
+}
+
+// printProvenance prints the keys of the configuration with their source, without provenance the
+// keys come from the Fleet policy or, for the overridden paths, from the local override file.
+func printProvenance(cfg *config.Config, provenance config.Provenance, overridden []string) error {
+	mapStr, err := cfg.ToMapStr()
+	if err != nil {
+		return err
+	}
+
+	var lines []string
+	// the overridden paths reference the inputs by their id, idPath is the path with the ids
+	var walk func(path, idPath string, v interface{})
+	walk = func(path, idPath string, v interface{}) {
+		switch t := v.(type) {
+		case map[string]interface{}:
+			if len(t) > 0 {
+				for k, child := range t {
+					walk(joinKey(path, k), joinKey(idPath, k), child)
+				}
+				return
+			}
+		case []interface{}:
+			if len(t) > 0 {
+				for i, child := range t {
+					key := strconv.Itoa(i)
+					if item, ok := child.(map[string]interface{}); ok {
+						if id, ok := item["id"].(string); ok && id != "" {
+							key = id
+						}
+					}
+					walk(joinKey(path, strconv.Itoa(i)), joinKey(idPath, key), child)
+				}
+				return
+			}
+		}
+
+		source := "Fleet policy"
+		if isOverridden(idPath, overridden) {
+			source = paths.AgentOverrideFile()
+		}
+		if provenance != nil {
+			source = "unknown"
+			if src, ok := provenance.Lookup(path); ok {
+				source = src.String()
+			}
+		}
+		value, err := json.Marshal(v)
+		if err != nil {
+			value = []byte(fmt.Sprint(v))
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s # %s", path, value, source))
+	}
+	walk("", "", mapStr)
+
+	sort.Strings(lines)
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(os.Stdout, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isOverridden(path string, overridden []string) bool {
+	for _, p := range overridden {
+		if path == p || strings.HasPrefix(path, p+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
 func printMapStringConfig(mapStr map[string]interface{}) error {
 	l, err := newErrorLogger()
 	if err != nil {
@@ -169,8 +306,13 @@ func listOutputsFromConfig(log *logger.Logger, agentInfo *info.AgentInfo, cfg *c
 
 	}
 
+	outputs := make([]string, 0, len(programsGroup))
 	for k := range programsGroup {
-		_, _ = os.Stdout.WriteString(k)
+		outputs = append(outputs, k)
+	}
+	sort.Strings(outputs)
+	for _, k := range outputs {
+		_, _ = os.Stdout.WriteString(k + "\n")
 	}
 
 	return nil
@@ -263,10 +405,19 @@ func getProgramsFromConfig(log *logger.Logger, agentInfo *info.AgentInfo, cfg *c
 		return nil, err
 	}
 
+	agentCfg, err := configuration.NewFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	streamChecker, err := filters.NewStreamChecker(agentCfg.Settings.DataStreamPolicy)
+	if err != nil {
+		return nil, errors.New(err, "invalid data stream policy")
+	}
+
 	composableWaiter := newWaitForCompose(composableCtrl)
 	configModifiers := &pipeline.ConfigModifiers{
 		Decorators: []pipeline.DecoratorFunc{modifiers.InjectMonitoring},
-		Filters:    []pipeline.FilterFunc{filters.StreamChecker},
+		Filters:    []pipeline.FilterFunc{streamChecker},
 	}
 
 	if !isStandalone {
@@ -37,6 +37,9 @@ would like the Agent to operate.
 
 	cmd.Flags().BoolP("force", "f", false, "Force overwrite the current and do not prompt for confirmation")
 	cmd.Flags().BoolP("non-interactive", "n", false, "Install Elastic Agent in non-interactive mode which will not prompt on missing parameters but fails instead.")
+	cmd.Flags().String("user", "", "User running the service instead of root, created if it does not exist, the binaries stay owned by root (Linux only)")
+	cmd.Flags().String("group", "", "Group running the service with --user, created if it does not exist (default: the name of the user)")
+	cmd.Flags().StringSlice("capability", install.DefaultCapabilities, "Linux capabilities granted to the service running with --user")
 	addEnrollFlags(cmd)
 
 	return cmd
@@ -61,6 +64,17 @@ func installCmd(streams *cli.IOStreams, cmd *cobra.Command) error {
 		return fmt.Errorf("already installed at: %s", paths.InstallPath)
 	}
 
+	serviceUser := install.ServiceUser{}
+	serviceUser.Name, _ = cmd.Flags().GetString("user")
+	serviceUser.Group, _ = cmd.Flags().GetString("group")
+	serviceUser.Capabilities, _ = cmd.Flags().GetStringSlice("capability")
+	if serviceUser.IsRoot() && serviceUser.Group != "" {
+		return fmt.Errorf("--group requires --user")
+	}
+	if !serviceUser.IsRoot() && status == install.PackageInstall {
+		return fmt.Errorf("--user cannot be used when installed as a system package")
+	}
+
 	nonInteractive, _ := cmd.Flags().GetBool("non-interactive")
 	if nonInteractive {
 		fmt.Fprintf(streams.Out, "Installing in non-interactive mode.")
@@ -161,7 +175,7 @@ func installCmd(streams *cli.IOStreams, cmd *cobra.Command) error {
 
 	cfgFile := paths.ConfigFile()
 	if status != install.PackageInstall {
-		err = install.Install(cfgFile)
+		err = install.Install(cfgFile, serviceUser)
 		if err != nil {
 			return err
 		}
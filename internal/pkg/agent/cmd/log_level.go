@@ -0,0 +1,177 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/control/client"
+	"github.com/elastic/elastic-agent/internal/pkg/cli"
+)
+
+var logLevelOutputs = map[string]outputter{
+	"human": humanLogLevelOutput,
+	"json":  jsonOutput,
+	"yaml":  yamlOutput,
+}
+
+func newLogLevelCommandWithArgs(_ []string, streams *cli.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "log-level",
+		Short: "Manage the log level overrides of the running Elastic Agent daemon",
+		Long: `Manage the log level overrides of the named loggers and programs of the running Elastic Agent daemon.
+
+The level of a logger applies immediately to the logger and its children, e.g. composable applies to
+composable.providers.kubernetes, without restarting the agent. The level of a program is passed to the
+program on its next reconfigure. Overrides set with this command are lost when the agent restarts,
+use agent.log_levels in the configuration to persist them.`,
+	}
+
+	cmd.AddCommand(newLogLevelListCommand(streams))
+	cmd.AddCommand(newLogLevelSetCommand(streams))
+	cmd.AddCommand(newLogLevelResetCommand(streams))
+
+	return cmd
+}
+
+func newLogLevelListCommand(streams *cli.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the log level overrides",
+		Args:  cobra.ExactArgs(0),
+		Run: func(c *cobra.Command, args []string) {
+			if err := logLevelListCmd(streams, c); err != nil {
+				fmt.Fprintf(streams.Err, "Error: %v\n%s\n", err, troubleshootMessage())
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().String("output", "human", "Output the log level overrides in either human, json, or yaml (default: human)")
+
+	return cmd
+}
+
+func newLogLevelSetCommand(streams *cli.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set <name> <level>",
+		Short: "Set the log level of a logger or of a program",
+		Long:  "Set the log level of a named logger, or of a program with --program. Available levels are error, warning, info and debug.",
+		Args:  cobra.ExactArgs(2),
+		Run: func(c *cobra.Command, args []string) {
+			if err := logLevelSetCmd(streams, c, args[0], args[1]); err != nil {
+				fmt.Fprintf(streams.Err, "Error: %v\n%s\n", err, troubleshootMessage())
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().Bool("program", false, "Set the log level of a program instead of a logger")
+
+	return cmd
+}
+
+func newLogLevelResetCommand(streams *cli.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reset <name>",
+		Short: "Remove the log level override of a logger or of a program",
+		Args:  cobra.ExactArgs(1),
+		Run: func(c *cobra.Command, args []string) {
+			if err := logLevelSetCmd(streams, c, args[0], ""); err != nil {
+				fmt.Fprintf(streams.Err, "Error: %v\n%s\n", err, troubleshootMessage())
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().Bool("program", false, "Remove the log level override of a program instead of a logger")
+
+	return cmd
+}
+
+func logLevelListCmd(streams *cli.IOStreams, cmd *cobra.Command) error {
+	output, _ := cmd.Flags().GetString("output")
+	outputFunc, ok := logLevelOutputs[output]
+	if !ok {
+		return fmt.Errorf("unsupported output: %s", output)
+	}
+
+	levels, err := changeLogLevels(nil, nil)
+	if err != nil {
+		return err
+	}
+	return outputFunc(streams.Out, levels)
+}
+
+func logLevelSetCmd(streams *cli.IOStreams, cmd *cobra.Command, name string, level string) error {
+	program, _ := cmd.Flags().GetBool("program")
+
+	change := map[string]string{name: level}
+	var err error
+	if program {
+		_, err = changeLogLevels(nil, change)
+	} else {
+		_, err = changeLogLevels(change, nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	kind := "logger"
+	if program {
+		kind = "program"
+	}
+	if level == "" {
+		fmt.Fprintf(streams.Out, "Log level override of %s %s removed\n", kind, name)
+	} else {
+		fmt.Fprintf(streams.Out, "Log level of %s %s set to %s\n", kind, name, level)
+	}
+	return nil
+}
+
+func changeLogLevels(loggers, programs map[string]string) (*client.LogLevels, error) {
+	var levels *client.LogLevels
+	err := withDaemon(daemonTimeout, func(ctx context.Context, c client.Client) error {
+		var err error
+		levels, err = c.LogLevel(ctx, loggers, programs)
+		return err
+	})
+	return levels, err
+}
+
+func humanLogLevelOutput(w io.Writer, obj interface{}) error {
+	levels, ok := obj.(*client.LogLevels)
+	if !ok {
+		return fmt.Errorf("unable to cast %T as *client.LogLevels", obj)
+	}
+	if len(levels.Loggers) == 0 && len(levels.Programs) == 0 {
+		fmt.Fprint(w, "No log level overrides\n")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 4, 1, 2, ' ', 0)
+	fmt.Fprint(tw, "KIND\tNAME\tLEVEL\n")
+	for _, kind := range []struct {
+		name   string
+		levels map[string]string
+	}{{"logger", levels.Loggers}, {"program", levels.Programs}} {
+		names := make([]string, 0, len(kind.levels))
+		for name := range kind.levels {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", kind.name, name, kind.levels[name])
+		}
+	}
+	return tw.Flush()
+}
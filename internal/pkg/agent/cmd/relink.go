@@ -0,0 +1,49 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade"
+	"github.com/elastic/elastic-agent/internal/pkg/cli"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+func newRelinkCommandWithArgs(_ []string, streams *cli.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Hidden: true,
+		Use:    "relink",
+		Short:  "Link the version of Elastic Agent requested by its upgrade",
+		Long: `Relink links the version requested by the upgrade of an Elastic Agent running as a service user,
+which cannot replace the symlink owned by root. The service manager runs it as root before it starts the agent.`,
+		Args: cobra.ExactArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := relinkCmd(); err != nil {
+				fmt.Fprintf(streams.Err, "Error: %v\n%s\n", err, troubleshootMessage())
+				os.Exit(1)
+			}
+		},
+	}
+
+	return cmd
+}
+
+func relinkCmd() error {
+	// logs to the journal, the log files are owned by the service user
+	cfg := logger.DefaultLoggingConfig()
+	cfg.ToFiles = false
+	cfg.ToStderr = true
+	log, err := logger.NewFromConfig("relink", cfg, false)
+	if err != nil {
+		return err
+	}
+
+	return upgrade.ApplyRelink(context.Background(), log)
+}
@@ -110,11 +110,17 @@ func run(override cfgOverrider) error {
 		return err
 	}
 
+	logger.ApplyLevelsConfig(cfg.Settings.LogLevels)
 	logger, err := logger.NewFromConfig("", cfg.Settings.LoggingConfig, true)
 	if err != nil {
 		return err
 	}
 
+	if err := checkStrictConfig(logger, cfg.Settings.StrictConfig); err != nil {
+		logger.Error(err)
+		return err
+	}
+
 	cfg, err = tryDelayEnroll(ctx, logger, cfg, override)
 	if err != nil {
 		err = errors.New(err, "failed to perform delayed enrollment")
@@ -154,6 +160,15 @@ func run(override cfgOverrider) error {
 		return err
 	}
 
+	// Move the vault seed to the configured backend, the vault was created with the seed in a file.
+	if cfg.Settings.Vault != nil {
+		if err := secret.MigrateVaultSeed(cfg.Settings.Vault.SeedBackend()); err != nil {
+			err = errors.New(err, "failed to migrate the vault seed", errors.TypeFilesystem)
+			logger.Error(err)
+			return err
+		}
+	}
+
 	// Check if the fleet.yml or state.yml exists and encrypt them.
 	// This is needed to handle upgrade properly.
 	// On agent upgrade the older version for example 8.2 unpacks the 8.3 agent
@@ -190,7 +205,7 @@ func run(override cfgOverrider) error {
 		return err
 	}
 	rexLogger := logger.Named("reexec")
-	rex := reexec.NewManager(rexLogger, execPath)
+	rex := reexec.NewServiceManager(rexLogger, execPath, upgrade.RelinkRequested)
 
 	statusCtrl := status.NewController(logger)
 	statusCtrl.SetAgentID(agentInfo.AgentID())
@@ -222,6 +237,12 @@ func run(override cfgOverrider) error {
 	}
 
 	control.SetRouteFn(app.Routes)
+	if q, ok := app.(server.ActionQueue); ok {
+		control.SetActionQueue(q)
+	}
+	if p, ok := app.(server.AppActionPerformer); ok {
+		control.SetAppActionPerformer(p)
+	}
 	control.SetMonitoringCfg(cfg.Settings.MonitoringConfig)
 
 	serverStopFn, err := setupMetrics(agentInfo, logger, cfg.Settings.DownloadConfig.OS(), cfg.Settings.MonitoringConfig, app, tracer, statusCtrl)
@@ -236,6 +257,8 @@ func run(override cfgOverrider) error {
 		return err
 	}
 
+	go runScheduledKeyRotation(ctx, logger, cfg.Settings.Vault)
+
 	// listen for signals
 	signals := make(chan os.Signal, 1)
 	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGHUP)
@@ -280,12 +303,9 @@ func run(override cfgOverrider) error {
 
 func loadConfig(override cfgOverrider) (*configuration.Configuration, error) {
 	pathConfigFile := paths.ConfigFile()
-	rawConfig, err := config.LoadFile(pathConfigFile)
+	rawConfig, _, err := configuration.LoadLayered(pathConfigFile)
 	if err != nil {
-		return nil, errors.New(err,
-			fmt.Sprintf("could not read configuration file %s", pathConfigFile),
-			errors.TypeFilesystem,
-			errors.M(errors.MetaKeyPath, pathConfigFile))
+		return nil, err
 	}
 
 	if err := getOverwrites(rawConfig); err != nil {
@@ -0,0 +1,203 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/elastic/elastic-agent-libs/file"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/filelock"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/secret"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/storage/snapshot"
+	"github.com/elastic/elastic-agent/internal/pkg/cli"
+)
+
+func newStateCommandWithArgs(_ []string, streams *cli.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "state",
+		Short: "Export or import the identity and state of Elastic Agent",
+		Long: `Export the identity and the state of Elastic Agent, its configuration, Fleet enrollment and
+persisted state, to a single archive encrypted with a password, and import it to move Elastic Agent
+to another host or to recover it after a disk failure.`,
+	}
+
+	cmd.AddCommand(
+		newStateExportCommand(streams),
+		newStateImportCommand(streams),
+	)
+
+	return cmd
+}
+
+func newStateExportCommand(streams *cli.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the identity and state of Elastic Agent to an encrypted archive",
+		Args:  cobra.ExactArgs(0),
+		Run: func(c *cobra.Command, args []string) {
+			if err := stateExportCmd(streams, c); err != nil {
+				fmt.Fprintf(streams.Err, "Error: %v\n%s\n", err, troubleshootMessage())
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().StringP("output", "o", "", "Path of the archive to write")
+	cmd.Flags().String("password-file", "", "Path to a file holding the password encrypting the archive")
+	_ = cmd.MarkFlagRequired("output")
+	_ = cmd.MarkFlagRequired("password-file")
+
+	return cmd
+}
+
+func newStateImportCommand(streams *cli.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import <archive>",
+		Short: "Import the identity and state of Elastic Agent from an encrypted archive",
+		Long: `Import the identity and state of Elastic Agent from an archive written by "elastic-agent state export".
+The archive is validated before anything is restored, the restored files are encrypted with the
+local agent secret. Elastic Agent must be stopped, an enrolled Elastic Agent is only replaced with --force.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(c *cobra.Command, args []string) {
+			if err := stateImportCmd(streams, c, args[0]); err != nil {
+				fmt.Fprintf(streams.Err, "Error: %v\n%s\n", err, troubleshootMessage())
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().String("password-file", "", "Path to a file holding the password decrypting the archive")
+	cmd.Flags().BoolP("force", "f", false, "Replace the identity and state of an enrolled Elastic Agent")
+	_ = cmd.MarkFlagRequired("password-file")
+
+	return cmd
+}
+
+func stateExportCmd(streams *cli.IOStreams, cmd *cobra.Command) error {
+	output, _ := cmd.Flags().GetString("output")
+	password, err := readPasswordFile(cmd)
+	if err != nil {
+		return err
+	}
+
+	// the archive is written next to the output and replaces it once complete
+	tmp := output + ".tmp"
+	fd, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.New(err,
+			fmt.Sprintf("could not write %s", tmp),
+			errors.TypeFilesystem,
+			errors.M(errors.MetaKeyPath, tmp))
+	}
+	defer os.Remove(tmp)
+
+	manifest, err := snapshot.Export(fd, password, snapshot.AgentFiles())
+	if err != nil {
+		fd.Close()
+		return errors.New(err, "failed to export the state of Elastic Agent")
+	}
+	if err := fd.Sync(); err != nil {
+		fd.Close()
+		return errors.New(err,
+			fmt.Sprintf("could not write %s", tmp),
+			errors.TypeFilesystem,
+			errors.M(errors.MetaKeyPath, tmp))
+	}
+	if err := fd.Close(); err != nil {
+		return errors.New(err,
+			fmt.Sprintf("could not write %s", tmp),
+			errors.TypeFilesystem,
+			errors.M(errors.MetaKeyPath, tmp))
+	}
+	if err := file.SafeFileRotate(output, tmp); err != nil {
+		return errors.New(err,
+			fmt.Sprintf("could not write %s", output),
+			errors.TypeFilesystem,
+			errors.M(errors.MetaKeyPath, output))
+	}
+
+	fmt.Fprintf(streams.Out, "Exported %d files of Elastic Agent %s to %s\n", len(manifest.Files), manifest.AgentID, output)
+	return nil
+}
+
+func stateImportCmd(streams *cli.IOStreams, cmd *cobra.Command, archive string) error {
+	force, _ := cmd.Flags().GetBool("force")
+	password, err := readPasswordFile(cmd)
+	if err != nil {
+		return err
+	}
+
+	fd, err := os.Open(archive)
+	if err != nil {
+		return errors.New(err,
+			fmt.Sprintf("could not open %s", archive),
+			errors.TypeFilesystem,
+			errors.M(errors.MetaKeyPath, archive))
+	}
+	defer fd.Close()
+
+	s, err := snapshot.Read(fd, password)
+	if err != nil {
+		return errors.New(err, fmt.Sprintf("invalid archive %s", archive))
+	}
+
+	// hold the lock for the whole import so Elastic Agent cannot start with a partial state
+	locker := filelock.NewAppLocker(paths.Data(), paths.AgentLockFileName)
+	if err := locker.TryLock(); err != nil {
+		if errors.Is(err, filelock.ErrAppAlreadyRunning) {
+			return fmt.Errorf("cannot import the state as Elastic Agent is running, stop it first")
+		}
+		return err
+	}
+	defer func() {
+		_ = locker.Unlock()
+	}()
+
+	if !force {
+		cfg, err := loadConfiguration()
+		if err != nil {
+			return errors.New(err, "cannot check whether Elastic Agent is already enrolled, use --force to replace its identity and state")
+		}
+		if cfg.Fleet != nil && cfg.Fleet.Enabled {
+			return fmt.Errorf("cannot import the state as Elastic Agent is already enrolled, use --force to replace its identity and state")
+		}
+	}
+
+	if err := secret.CreateAgentSecret(); err != nil {
+		return errors.New(err, "could not create the agent secret")
+	}
+	if err := s.Restore(snapshot.AgentFiles()); err != nil {
+		return errors.New(err, "failed to import the state of Elastic Agent")
+	}
+
+	fmt.Fprintf(streams.Out, "Imported %d files of Elastic Agent %s exported from %s on %s\n",
+		len(s.Manifest.Files), s.Manifest.AgentID, s.Manifest.Hostname, s.Manifest.CreatedAt.Format("2006-01-02 15:04:05 MST"))
+	return nil
+}
+
+// readPasswordFile reads the password of the --password-file flag, without its trailing line break.
+func readPasswordFile(cmd *cobra.Command) ([]byte, error) {
+	passwordFile, _ := cmd.Flags().GetString("password-file")
+	b, err := ioutil.ReadFile(passwordFile)
+	if err != nil {
+		return nil, errors.New(err,
+			fmt.Sprintf("could not read the password file %s", passwordFile),
+			errors.TypeFilesystem,
+			errors.M(errors.MetaKeyPath, passwordFile))
+	}
+	password := bytes.TrimRight(b, "\r\n")
+	if len(password) == 0 {
+		return nil, errors.New(fmt.Sprintf("password file %s is empty", passwordFile), errors.TypeConfig)
+	}
+	return password, nil
+}
@@ -8,12 +8,14 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 
 	"github.com/elastic/elastic-agent/internal/pkg/agent/control"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/control/client"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/internal/pkg/artifact/bundle"
 	"github.com/elastic/elastic-agent/internal/pkg/cli"
 )
 
@@ -21,7 +23,11 @@ func newUpgradeCommandWithArgs(_ []string, streams *cli.IOStreams) *cobra.Comman
 	cmd := &cobra.Command{
 		Use:   "upgrade <version>",
 		Short: "Upgrade the currently running Elastic Agent to the specified version",
-		Args:  cobra.ExactArgs(1),
+		Long: `Upgrade the currently running Elastic Agent to the specified version.
+
+When --bundle is provided the upgrade is performed from an offline bundle created with
+"elastic-agent bundle create", the version defaults to the one contained in the bundle.`,
+		Args: cobra.RangeArgs(0, 1),
 		Run: func(c *cobra.Command, args []string) {
 			if err := upgradeCmd(streams, c, args); err != nil {
 				fmt.Fprintf(streams.Err, "Error: %v\n%s\n", err, troubleshootMessage())
@@ -31,13 +37,48 @@ func newUpgradeCommandWithArgs(_ []string, streams *cli.IOStreams) *cobra.Comman
 	}
 
 	cmd.Flags().StringP("source-uri", "s", "", "Source URI to download the new version from")
+	cmd.Flags().StringP("bundle", "b", "", "Path to an offline upgrade bundle")
 
 	return cmd
 }
 
 func upgradeCmd(streams *cli.IOStreams, cmd *cobra.Command, args []string) error {
-	version := args[0]
 	sourceURI, _ := cmd.Flags().GetString("source-uri")
+	bundlePath, _ := cmd.Flags().GetString("bundle")
+
+	var version string
+	if len(args) > 0 {
+		version = args[0]
+	}
+
+	if bundlePath != "" {
+		if sourceURI != "" {
+			return errors.New("--bundle and --source-uri cannot be used together")
+		}
+
+		absPath, err := filepath.Abs(bundlePath)
+		if err != nil {
+			return errors.New(err, "failed to resolve bundle path")
+		}
+
+		manifest, err := bundle.ReadManifest(absPath)
+		if err != nil {
+			return errors.New(err, "invalid upgrade bundle")
+		}
+
+		if version == "" {
+			version = manifest.Version
+		} else if version != manifest.Version {
+			return fmt.Errorf("bundle contains version %s, requested version %s", manifest.Version, version)
+		}
+
+		// daemon recognizes the bundle by the file:// source URI pointing to a file
+		sourceURI = "file://" + absPath
+	}
+
+	if version == "" {
+		return errors.New("version is required when --bundle is not provided")
+	}
 
 	c := client.New()
 	err := c.Connect(context.Background())
@@ -0,0 +1,124 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/filelock"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/secret"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/configuration"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/storage"
+	"github.com/elastic/elastic-agent/internal/pkg/cli"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+// keyRotationCheckPeriod is the maximum time between two checks of the age of the agent secret.
+const keyRotationCheckPeriod = time.Hour
+
+func newVaultCommandWithArgs(_ []string, streams *cli.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "vault",
+		Short: "Manage the vault holding the Elastic Agent secret",
+		Long:  "Manage the vault holding the secret used by Elastic Agent to encrypt its configuration and state.",
+	}
+
+	cmd.AddCommand(newVaultRotateCommand(streams))
+
+	return cmd
+}
+
+func newVaultRotateCommand(streams *cli.IOStreams) *cobra.Command {
+	return &cobra.Command{
+		Use:   "rotate",
+		Short: "Rotate the vault seed and the agent secret",
+		Long: `Generate a new vault seed and agent secret and re-encrypt the vault entries, the Fleet configuration
+and the state of Elastic Agent with them. The previous key is kept until every file is re-encrypted,
+a rotation interrupted by a crash is rolled back or completed the next time Elastic Agent starts.
+
+Elastic Agent must be stopped, use agent.vault.rotation to rotate the key of a running Elastic Agent.`,
+		Args: cobra.ExactArgs(0),
+		Run: func(c *cobra.Command, args []string) {
+			if err := vaultRotateCmd(streams); err != nil {
+				fmt.Fprintf(streams.Err, "Error: %v\n%s\n", err, troubleshootMessage())
+				os.Exit(1)
+			}
+		},
+	}
+}
+
+func vaultRotateCmd(streams *cli.IOStreams) error {
+	// hold the lock for the whole rotation so Elastic Agent cannot start with a stale key
+	locker := filelock.NewAppLocker(paths.Data(), paths.AgentLockFileName)
+	if err := locker.TryLock(); err != nil {
+		if errors.Is(err, filelock.ErrAppAlreadyRunning) {
+			return fmt.Errorf("cannot rotate the vault key as Elastic Agent is running, stop it or enable agent.vault.rotation")
+		}
+		return err
+	}
+	defer func() {
+		_ = locker.Unlock()
+	}()
+
+	if err := storage.RotateKey(paths.AgentEncryptedFiles()); err != nil {
+		return errors.New(err, "failed to rotate the vault key")
+	}
+
+	fmt.Fprintln(streams.Out, "Vault seed and agent secret rotated")
+	return nil
+}
+
+// runScheduledKeyRotation rotates the agent secret every time it gets older than the configured
+// interval, until ctx is cancelled. The age of the secret survives restarts as it is stored with it.
+func runScheduledKeyRotation(ctx context.Context, log *logger.Logger, cfg *configuration.VaultConfig) {
+	if cfg == nil || cfg.Rotation == nil || !cfg.Rotation.Enabled {
+		return
+	}
+	interval := cfg.Rotation.Interval
+
+	rotate := func() time.Duration {
+		s, err := secret.GetAgentSecret()
+		if err != nil {
+			log.Errorf("Failed to read the agent secret to schedule its rotation: %v", err)
+			return keyRotationCheckPeriod
+		}
+		if wait := time.Until(s.CreatedOn.Add(interval)); wait > 0 {
+			return minDuration(wait, keyRotationCheckPeriod)
+		}
+
+		log.Info("Rotating the vault seed and the agent secret")
+		if err := storage.RotateKey(paths.AgentEncryptedFiles()); err != nil {
+			log.Errorf("Failed to rotate the vault seed and the agent secret: %v", err)
+			return keyRotationCheckPeriod
+		}
+		log.Info("Vault seed and agent secret rotated")
+		return minDuration(interval, keyRotationCheckPeriod)
+	}
+
+	t := time.NewTimer(rotate())
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			t.Reset(rotate())
+		}
+	}
+}
+
+func minDuration(a, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,73 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/install"
+	"github.com/elastic/elastic-agent/internal/pkg/cli"
+)
+
+func newVerifyCommandWithArgs(_ []string, streams *cli.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify the integrity of the files of Elastic Agent",
+		Long: `Verify the files of the running version of Elastic Agent against the SHA256 manifest generated
+when it was packaged, the symlink to its binary and, when installed, the permissions of its files.
+
+With --repair the missing and modified files are extracted from the package of the running version
+cached in the downloads, the symlink and the permissions are fixed, for an agent running as root as
+well as for one running as a service user. The problems which could not be repaired are reported and
+the command fails.
+
+The manifest is not signed and lives next to the files it lists, verify detects accidental corruption,
+e.g. a partial upgrade or a damaged disk, but not a tampering which rewrites the manifest as well.`,
+		Args: cobra.ExactArgs(0),
+		Run: func(c *cobra.Command, _ []string) {
+			if err := verifyCmd(streams, c); err != nil {
+				fmt.Fprintf(streams.Err, "Error: %v\n%s\n", err, troubleshootMessage())
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().Bool("repair", false, "Repair the problems found")
+
+	return cmd
+}
+
+func verifyCmd(streams *cli.IOStreams, cmd *cobra.Command) error {
+	report, err := install.Verify()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(streams.Out, "Verified %d files of %s\n", report.Files, report.Home)
+
+	repair, _ := cmd.Flags().GetBool("repair")
+	if repair && !report.OK() {
+		found := len(report.Problems)
+		report, err = install.Repair(report)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(streams.Out, "Repaired %d of %d problems\n", found-len(report.Problems), found)
+	}
+
+	if report.OK() {
+		fmt.Fprintln(streams.Out, "No problem found")
+		return nil
+	}
+	for _, p := range report.Problems {
+		fmt.Fprintf(streams.Out, "  %s\n", p)
+	}
+	for _, note := range report.Notes {
+		fmt.Fprintf(streams.Out, "%s\n", note)
+	}
+	return fmt.Errorf("%d problems found", len(report.Problems))
+}
@@ -16,7 +16,9 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/filelock"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/info"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/configuration"
@@ -24,6 +26,7 @@ import (
 	"github.com/elastic/elastic-agent/internal/pkg/cli"
 	"github.com/elastic/elastic-agent/internal/pkg/config"
 	"github.com/elastic/elastic-agent/internal/pkg/release"
+	reporting "github.com/elastic/elastic-agent/internal/pkg/reporter"
 	"github.com/elastic/elastic-agent/pkg/core/logger"
 )
 
@@ -99,11 +102,15 @@ func watchCmd(log *logp.Logger) error {
 	ctx := context.Background()
 	if err := watch(ctx, tilGrace, log); err != nil {
 		log.Error("Error detected proceeding to rollback: %v", err)
-		err = upgrade.Rollback(ctx, log, marker.PrevHash, marker.Hash)
-		if err != nil {
-			log.Error("rollback failed", err)
+		// reported before the restart, the service manager stops the watcher with an agent running as a service user
+		rollbackErr := upgrade.Rollback(ctx, log, marker.PrevHash, marker.Hash, func() {
+			reportRollback(ctx, log, marker, err)
+		})
+		if rollbackErr != nil {
+			log.Error("rollback failed", rollbackErr)
+			return rollbackErr
 		}
-		return err
+		return nil
 	}
 
 	// cleanup older versions,
@@ -191,7 +198,56 @@ func gracePeriod(marker *upgrade.UpdateMarker) (bool, time.Duration) {
 	return false, gracePeriodDuration
 }
 
+// reportRollback notifies the reporter backends configured under agent.reporting that the
+// upgrade was rolled back.
+func reportRollback(ctx context.Context, log *logger.Logger, marker *upgrade.UpdateMarker, reason error) {
+	cfg, err := loadConfiguration()
+	if err != nil {
+		log.Warnf("failed to report rollback: %v", err)
+		return
+	}
+
+	backends, err := application.NewReportingBackends(log, cfg.Settings)
+	if err != nil {
+		log.Warnf("failed to report rollback: %v", err)
+		return
+	}
+	if len(backends) == 0 {
+		return
+	}
+
+	agentInfo, err := info.NewAgentInfo(false)
+	if err != nil {
+		log.Warnf("failed to report rollback: %v", err)
+		for _, b := range backends {
+			_ = b.Close()
+		}
+		return
+	}
+
+	r := reporting.NewReporter(ctx, log, agentInfo, backends...)
+	r.OnRollback(marker.PrevHash, marker.Hash, reason)
+	// closing flushes the events still queued
+	r.Close()
+}
+
 func configuredLogger() (*logger.Logger, error) {
+	cfg, err := loadConfiguration()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.Settings.LoggingConfig.Beat = watcherName
+
+	logger, err := logger.NewFromConfig("", cfg.Settings.LoggingConfig, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return logger, nil
+}
+
+func loadConfiguration() (*configuration.Configuration, error) {
 	pathConfigFile := paths.ConfigFile()
 	rawConfig, err := config.LoadFile(pathConfigFile)
 	if err != nil {
@@ -209,12 +265,5 @@ func configuredLogger() (*logger.Logger, error) {
 			errors.M(errors.MetaKeyPath, pathConfigFile))
 	}
 
-	cfg.Settings.LoggingConfig.Beat = watcherName
-
-	logger, err := logger.NewFromConfig("", cfg.Settings.LoggingConfig, false)
-	if err != nil {
-		return nil, err
-	}
-
-	return logger, nil
+	return cfg, nil
 }
@@ -30,6 +30,16 @@ func NewFromConfig(cfg *config.Config) (*Configuration, error) {
 		return nil, errors.New(err, errors.TypeConfig)
 	}
 
+	// the proxy rules apply to every connection of the agent
+	if c.Settings.Proxy != nil {
+		if c.Fleet != nil {
+			c.Fleet.Client.ProxyRules = c.Settings.Proxy.Rules
+		}
+		if c.Settings.DownloadConfig != nil {
+			c.Settings.DownloadConfig.ProxyRules = c.Settings.Proxy.Rules
+		}
+	}
+
 	return c, nil
 }
 
@@ -17,6 +17,23 @@ type FleetAgentConfig struct {
 	Client       remote.Config      `config:",inline" yaml:",inline"`
 	Info         *AgentInfo         `config:"agent" yaml:"agent"`
 	Server       *FleetServerConfig `config:"server" yaml:"server,omitempty"`
+	Enrollment   *EnrollmentConfig  `config:"enrollment" yaml:"enrollment,omitempty"`
+}
+
+// EnrollmentConfig describes how the agent enrolls again with Fleet when its
+// API key is rejected, e.g. after the enrollment token was rotated.
+type EnrollmentConfig struct {
+	// TokenFile is the path to a file holding the enrollment token, the file
+	// is read on every re-enrollment so a rotated token is picked up.
+	TokenFile string `config:"token_file" yaml:"token_file,omitempty"`
+	// Reenroll enables automatic re-enrollment instead of unenrolling when
+	// the API key is repeatedly rejected by Fleet.
+	Reenroll bool `config:"reenroll" yaml:"reenroll"`
+}
+
+// ReenrollEnabled returns true when the agent can re-enroll with the token from file.
+func (e *EnrollmentConfig) ReenrollEnabled() bool {
+	return e != nil && e.Reenroll && e.TokenFile != ""
 }
 
 // Valid validates the required fields for accessing the API.
@@ -0,0 +1,101 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package configuration
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/internal/pkg/config"
+	"github.com/elastic/elastic-agent/internal/pkg/dir"
+)
+
+// LayersConfig configures the overlays merged over the configuration file, in order:
+// conf.d/*.yml, sorted by name, then the file of the environment, e.g. elastic-agent.production.yml.
+type LayersConfig struct {
+	// EnvVar is the environment variable holding the name of the environment, the file of the
+	// environment is not merged when it is empty.
+	EnvVar string `config:"env_var" yaml:"env_var,omitempty"`
+	// Merge sets the merge strategies of the keys, the keys are deep merged by default.
+	Merge config.MergeStrategies `config:"merge" yaml:"merge,omitempty"`
+}
+
+// DefaultLayersConfig creates a config without the file of the environment, it is merged only
+// when env_var is set.
+func DefaultLayersConfig() *LayersConfig {
+	return &LayersConfig{}
+}
+
+// Patterns returns the patterns of the overlays, in the order they are merged.
+func (c *LayersConfig) Patterns() []string {
+	patterns := []string{filepath.Join(paths.AgentConfDPath(), "*.yml")}
+	if c == nil || c.EnvVar == "" {
+		return patterns
+	}
+	if env := os.Getenv(c.EnvVar); env != "" {
+		patterns = append(patterns, paths.AgentEnvConfigFile(env))
+	}
+	return patterns
+}
+
+// Strategies returns the merge strategies of the keys.
+func (c *LayersConfig) Strategies() config.MergeStrategies {
+	if c == nil {
+		return nil
+	}
+	return c.Merge
+}
+
+// LoadLayered loads the configuration file and merges its overlays over it. The overlays and their
+// merge strategies are read from agent.layers of the configuration file only.
+func LoadLayered(pathConfigFile string) (*config.Config, config.Provenance, error) {
+	rawConfig, err := config.LoadFile(pathConfigFile)
+	if err != nil {
+		return nil, nil, errors.New(err,
+			fmt.Sprintf("could not read configuration file %s", pathConfigFile),
+			errors.TypeFilesystem,
+			errors.M(errors.MetaKeyPath, pathConfigFile))
+	}
+
+	layers, err := layersFromConfig(rawConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	overlays, err := dir.DiscoverFiles(layers.Patterns()...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(overlays) == 0 && len(layers.Strategies()) == 0 {
+		// the configuration file is used as loaded, its provenance only locates its keys
+		_, provenance, _ := config.LoadLayers([]string{pathConfigFile}, nil)
+		return rawConfig, provenance, nil
+	}
+
+	cfg, provenance, err := config.LoadLayers(append([]string{pathConfigFile}, overlays...), layers.Strategies())
+	if err != nil {
+		return nil, nil, errors.New(err,
+			fmt.Sprintf("could not merge the overlays of configuration file %s", pathConfigFile),
+			errors.TypeConfig,
+			errors.M(errors.MetaKeyPath, pathConfigFile))
+	}
+	return cfg, provenance, nil
+}
+
+func layersFromConfig(cfg *config.Config) (*LayersConfig, error) {
+	tmp := struct {
+		Layers *LayersConfig `config:"agent.layers"`
+	}{
+		Layers: DefaultLayersConfig(),
+	}
+	if err := cfg.Unpack(&tmp); err != nil {
+		return nil, errors.New(err, "invalid agent.layers configuration", errors.TypeConfig)
+	}
+	return tmp.Layers, nil
+}
@@ -0,0 +1,92 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package configuration
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
+)
+
+func TestLoadLayered(t *testing.T) {
+	configDir := t.TempDir()
+	prevConfig := paths.Config()
+	paths.SetConfig(configDir)
+	defer paths.SetConfig(prevConfig)
+
+	write := func(name, content string) string {
+		p := filepath.Join(configDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		require.NoError(t, ioutil.WriteFile(p, []byte(content), 0600))
+		return p
+	}
+	baseContent := `
+agent.logging.level: info
+agent.layers.merge:
+  - path: agent.monitoring.namespace
+    strategy: replace
+agent.monitoring.namespace: base
+`
+	base := write("elastic-agent.yml", baseContent)
+	write("conf.d/10-logging.yml", "agent.logging.level: warning\nagent.monitoring.namespace: conf\n")
+	write("conf.d/20-logging.yml", "agent.logging.level: error\n")
+	production := write("elastic-agent.production.yml", "agent.monitoring.namespace: production\n")
+
+	load := func() (*Configuration, map[string]string) {
+		rawConfig, provenance, err := LoadLayered(base)
+		require.NoError(t, err)
+		cfg, err := NewFromConfig(rawConfig)
+		require.NoError(t, err)
+		sources := make(map[string]string)
+		for _, k := range []string{"agent.logging.level", "agent.monitoring.namespace"} {
+			src, ok := provenance.Lookup(k)
+			require.True(t, ok)
+			sources[k] = src.String()
+		}
+		return cfg, sources
+	}
+
+	// the file of the environment is opt-in, without env_var it is not merged
+	t.Setenv("DEPLOY_ENV", "production")
+	cfg, sources := load()
+	assert.Equal(t, "error", cfg.Settings.LoggingConfig.Level.String())
+	assert.Equal(t, "conf", cfg.Settings.MonitoringConfig.Namespace)
+	assert.Equal(t, filepath.Join(configDir, "conf.d", "20-logging.yml")+":1", sources["agent.logging.level"])
+
+	write("elastic-agent.yml", baseContent+"agent.layers.env_var: DEPLOY_ENV\n")
+	t.Setenv("DEPLOY_ENV", "")
+	cfg, _ = load()
+	assert.Equal(t, "conf", cfg.Settings.MonitoringConfig.Namespace)
+
+	t.Setenv("DEPLOY_ENV", "production")
+	cfg, sources = load()
+	assert.Equal(t, "production", cfg.Settings.MonitoringConfig.Namespace)
+	assert.Equal(t, production+":1", sources["agent.monitoring.namespace"])
+}
+
+func TestLoadLayeredWithoutOverlays(t *testing.T) {
+	configDir := t.TempDir()
+	prevConfig := paths.Config()
+	paths.SetConfig(configDir)
+	defer paths.SetConfig(prevConfig)
+
+	base := filepath.Join(configDir, "elastic-agent.yml")
+	require.NoError(t, ioutil.WriteFile(base, []byte("agent.logging.level: warning\n"), 0600))
+
+	rawConfig, provenance, err := LoadLayered(base)
+	require.NoError(t, err)
+	cfg, err := NewFromConfig(rawConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "warning", cfg.Settings.LoggingConfig.Level.String())
+	src, ok := provenance.Lookup("agent.logging.level")
+	require.True(t, ok, "the keys of the configuration file are still located")
+	assert.Equal(t, base+":1", src.String())
+}
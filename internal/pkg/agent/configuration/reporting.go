@@ -0,0 +1,24 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package configuration
+
+import (
+	"github.com/elastic/elastic-agent/internal/pkg/reporter/file"
+	"github.com/elastic/elastic-agent/internal/pkg/reporter/webhook"
+)
+
+// ReportingConfig defines the additional backends the agent events are reported to.
+type ReportingConfig struct {
+	File    *file.Config    `config:"file" yaml:"file" json:"file"`
+	Webhook *webhook.Config `config:"webhook" yaml:"webhook" json:"webhook"`
+}
+
+// DefaultReportingConfig creates a default configuration with every backend disabled.
+func DefaultReportingConfig() *ReportingConfig {
+	return &ReportingConfig{
+		File:    file.DefaultConfig(),
+		Webhook: webhook.DefaultConfig(),
+	}
+}
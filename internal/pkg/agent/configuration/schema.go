@@ -0,0 +1,84 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package configuration
+
+import (
+	"fmt"
+
+	"github.com/elastic/elastic-agent/internal/pkg/config"
+)
+
+// StrictMode defines how the unknown keys under `agent.*` are reported.
+type StrictMode string
+
+const (
+	// StrictOff ignores the unknown keys.
+	StrictOff StrictMode = "off"
+	// StrictWarn logs a warning for every unknown key.
+	StrictWarn StrictMode = "warn"
+	// StrictError refuses to start with unknown keys.
+	StrictError StrictMode = "error"
+)
+
+// Unpack the strict mode.
+func (m *StrictMode) Unpack(from string) error {
+	if from == "false" {
+		// YAML reads an unquoted off as false
+		from = string(StrictOff)
+	}
+	switch StrictMode(from) {
+	case StrictOff, StrictWarn, StrictError:
+	default:
+		return fmt.Errorf("invalid strict mode %s, accepted values are 'off', 'warn' and 'error'", from)
+	}
+
+	*m = StrictMode(from)
+	return nil
+}
+
+// settingsExtraKeys are the keys of `agent.*` read outside of SettingsConfig.
+var settingsExtraKeys = map[string]config.Schema{
+	"id":                    {"type": "string"},
+	"version":               {"type": "string"},
+	"headers":               {"type": "object", "additionalProperties": config.Schema{"type": "string"}},
+	"download.source_uri":   {"type": "string"},
+	"monitoring.use_output": {"type": "string"},
+}
+
+// Schema returns the JSON schema of the agent configuration, elastic-agent.yml. The other top
+// level keys, like inputs and outputs, are the policy and are not described.
+func Schema() config.Schema {
+	s := config.SchemaOf(Configuration{})
+	s["$schema"] = config.SchemaDraft
+	s["title"] = "Elastic Agent configuration"
+	// the policy lives next to the settings
+	s["additionalProperties"] = true
+
+	for path, prop := range settingsExtraKeys {
+		if s.Property("agent."+path) != nil {
+			continue
+		}
+		// the paths are known to exist, a failure is a programming error
+		if err := s.SetProperty("agent."+path, prop); err != nil {
+			panic(err)
+		}
+	}
+	s.Property("agent.strict_config")["enum"] = []StrictMode{StrictOff, StrictWarn, StrictError}
+	return s
+}
+
+// UnknownSettings returns the keys under `agent.*` of the configuration which are not part of the
+// agent settings, with a suggestion for the misspelled ones.
+func UnknownSettings(cfg *config.Config) ([]config.UnknownKey, error) {
+	m, err := cfg.ToMapStr()
+	if err != nil {
+		return nil, err
+	}
+	agent, ok := m["agent"]
+	if !ok {
+		return nil, nil
+	}
+	return Schema().Property("agent").UnknownKeys("agent", agent), nil
+}
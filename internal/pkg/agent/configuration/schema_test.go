@@ -0,0 +1,81 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package configuration
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent/internal/pkg/config"
+)
+
+func TestUnknownSettings(t *testing.T) {
+	cfg, err := config.NewConfigFrom(`
+agent:
+  id: 0a8f9c5e
+  logging.level: debug
+  loging.to_stderr: true
+  monitoring:
+    enabled: true
+    use_output: monitoring
+    htp.enabled: true
+  download:
+    source_uri: https://artifacts.elastic.co/downloads/
+    timeout: 20m
+  proxy.rules:
+    - hosts: ["*"]
+      direct: true
+      header: {}
+  strict_config: warn
+  unrelated: true
+inputs:
+  - type: logfile
+`)
+	require.NoError(t, err)
+
+	unknown, err := UnknownSettings(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []config.UnknownKey{
+		{Path: "agent.loging", Suggestion: "agent.logging"},
+		{Path: "agent.monitoring.htp", Suggestion: "agent.monitoring.http"},
+		{Path: "agent.proxy.rules.0.header", Suggestion: "agent.proxy.rules.0.headers"},
+		{Path: "agent.unrelated"},
+	}, unknown)
+}
+
+func TestUnknownSettingsShippedConfigurations(t *testing.T) {
+	for _, name := range []string{"elastic-agent.yml", "elastic-agent.docker.yml", "_meta/elastic-agent.fleet.yml"} {
+		t.Run(name, func(t *testing.T) {
+			cfg, err := config.LoadFile(filepath.Join("..", "..", "..", "..", name))
+			require.NoError(t, err)
+
+			unknown, err := UnknownSettings(cfg)
+			require.NoError(t, err)
+			assert.Empty(t, unknown)
+		})
+	}
+}
+
+func TestStrictModeUnpack(t *testing.T) {
+	cfg, err := config.NewConfigFrom(`agent.strict_config: error`)
+	require.NoError(t, err)
+	c, err := NewFromConfig(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, StrictError, c.Settings.StrictConfig)
+
+	cfg, err = config.NewConfigFrom(`agent.strict_config: off`)
+	require.NoError(t, err)
+	c, err = NewFromConfig(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, StrictOff, c.Settings.StrictConfig)
+
+	cfg, err = config.NewConfigFrom(`agent.strict_config: loud`)
+	require.NoError(t, err)
+	_, err = NewFromConfig(cfg)
+	assert.Error(t, err)
+}
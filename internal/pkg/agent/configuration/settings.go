@@ -5,10 +5,15 @@
 package configuration
 
 import (
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/filters"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/override"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/storage/store"
 	"github.com/elastic/elastic-agent/internal/pkg/artifact"
 	monitoringCfg "github.com/elastic/elastic-agent/internal/pkg/core/monitoring/config"
 	"github.com/elastic/elastic-agent/internal/pkg/core/process"
 	"github.com/elastic/elastic-agent/internal/pkg/core/retry"
+	"github.com/elastic/elastic-agent/internal/pkg/fleetapi/acker/spool"
+	"github.com/elastic/elastic-agent/internal/pkg/proxy"
 	"github.com/elastic/elastic-agent/pkg/core/logger"
 	"github.com/elastic/elastic-agent/pkg/core/server"
 )
@@ -21,6 +26,16 @@ type SettingsConfig struct {
 	RetryConfig      *retry.Config                   `yaml:"retry" config:"retry" json:"retry"`
 	MonitoringConfig *monitoringCfg.MonitoringConfig `yaml:"monitoring" config:"monitoring" json:"monitoring"`
 	LoggingConfig    *logger.Config                  `yaml:"logging,omitempty" config:"logging,omitempty" json:"logging,omitempty"`
+	LogLevels        *logger.LevelsConfig            `yaml:"log_levels" config:"log_levels" json:"log_levels"`
+	ActionAudit      *store.AuditConfig              `yaml:"action_audit" config:"action_audit" json:"action_audit"`
+	DataStreamPolicy *filters.StreamCheckerConfig    `yaml:"data_stream_policy" config:"data_stream_policy" json:"data_stream_policy"`
+	Reporting        *ReportingConfig                `yaml:"reporting" config:"reporting" json:"reporting"`
+	Vault            *VaultConfig                    `yaml:"vault" config:"vault" json:"vault"`
+	Proxy            *proxy.Config                   `yaml:"proxy" config:"proxy" json:"proxy"`
+	StrictConfig     StrictMode                      `yaml:"strict_config" config:"strict_config" json:"strict_config"`
+	Layers           *LayersConfig                   `yaml:"layers" config:"layers" json:"layers"`
+	Override         *override.Config                `yaml:"override" config:"override" json:"override"`
+	AckSpool         *spool.Config                   `yaml:"ack_spool" config:"ack_spool" json:"ack_spool"`
 
 	// standalone config
 	Reload *ReloadConfig `config:"reload" yaml:"reload" json:"reload"`
@@ -34,8 +49,18 @@ func DefaultSettingsConfig() *SettingsConfig {
 		RetryConfig:      retry.DefaultConfig(),
 		DownloadConfig:   artifact.DefaultConfig(),
 		LoggingConfig:    logger.DefaultLoggingConfig(),
+		LogLevels:        &logger.LevelsConfig{},
 		MonitoringConfig: monitoringCfg.DefaultConfig(),
 		GRPC:             server.DefaultGRPCConfig(),
+		ActionAudit:      store.DefaultAuditConfig(),
+		DataStreamPolicy: filters.DefaultStreamCheckerConfig(),
+		Reporting:        DefaultReportingConfig(),
+		Vault:            DefaultVaultConfig(),
+		Proxy:            proxy.DefaultConfig(),
+		StrictConfig:     StrictOff,
+		Layers:           DefaultLayersConfig(),
+		Override:         override.DefaultConfig(),
+		AckSpool:         spool.DefaultConfig(),
 		Reload:           DefaultReloadConfig(),
 	}
 }
@@ -0,0 +1,64 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package configuration
+
+import (
+	"time"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/vault"
+)
+
+// ErrInvalidRotationInterval is returned when the interval of the vault key rotation is not valid.
+var ErrInvalidRotationInterval = errors.New("vault rotation interval must be at least one hour")
+
+// VaultConfig configures the vault holding the agent secret.
+type VaultConfig struct {
+	// Backend holds the seed of the vault, the seed is migrated to it when the agent starts.
+	Backend  string               `config:"backend" yaml:"backend" json:"backend"`
+	Rotation *VaultRotationConfig `config:"rotation" yaml:"rotation" json:"rotation"`
+}
+
+// Validate validates settings of configuration.
+func (c *VaultConfig) Validate() error {
+	_, err := vault.ParseSeedBackend(c.Backend)
+	return err
+}
+
+// SeedBackend returns the configured backend of the vault seed.
+func (c *VaultConfig) SeedBackend() vault.SeedBackend {
+	backend, err := vault.ParseSeedBackend(c.Backend)
+	if err != nil {
+		return vault.SeedBackendFile
+	}
+	return backend
+}
+
+// VaultRotationConfig configures the scheduled rotation of the vault seed and of the agent secret.
+type VaultRotationConfig struct {
+	Enabled bool `config:"enabled" yaml:"enabled" json:"enabled"`
+	// Interval is the age of the agent secret after which it is rotated.
+	Interval time.Duration `config:"interval" yaml:"interval" json:"interval"`
+}
+
+// Validate validates settings of configuration.
+func (r *VaultRotationConfig) Validate() error {
+	if r.Enabled && r.Interval < time.Hour {
+		return ErrInvalidRotationInterval
+	}
+	return nil
+}
+
+// DefaultVaultConfig creates a default configuration with the seed held by a file and the
+// scheduled rotation disabled.
+func DefaultVaultConfig() *VaultConfig {
+	return &VaultConfig{
+		Backend: string(vault.SeedBackendFile),
+		Rotation: &VaultRotationConfig{
+			Enabled:  false,
+			Interval: 90 * 24 * time.Hour,
+		},
+	}
+}
@@ -10,7 +10,9 @@ package control
 import (
 	"crypto/sha256"
 	"fmt"
+	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/info"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
@@ -20,6 +22,10 @@ import (
 func Address() string {
 	// when installed the control address is fixed
 	if info.RunningInstalled() {
+		// a service user cannot create the socket in the root runtime directory
+		if os.Geteuid() != 0 || socketExists(paths.ServiceUserSocketPath) {
+			return paths.ServiceUserSocketPath
+		}
 		return paths.SocketPath
 	}
 
@@ -32,3 +38,8 @@ func Address() string {
 	// for it to be used, but needs to be unique per Agent (in the case that multiple are running)
 	return fmt.Sprintf(`unix:///tmp/elastic-agent/%x.sock`, sha256.Sum256([]byte(path)))
 }
+
+func socketExists(addr string) bool {
+	_, err := os.Stat(strings.TrimPrefix(addr, "unix://"))
+	return err == nil
+}
@@ -7,7 +7,9 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"sync"
 	"time"
 
@@ -80,6 +82,30 @@ type ProcPProf struct {
 	Error    string
 }
 
+// QueuedAction is an action waiting in the action queue of the Elastic Agent.
+type QueuedAction struct {
+	ID         string
+	Type       string
+	StartTime  time.Time
+	Expiration time.Time
+}
+
+// AppActionResult is the result of an application action.
+type AppActionResult struct {
+	ID          string                 `json:"id" yaml:"id"`
+	InputType   string                 `json:"input_type" yaml:"input_type"`
+	StartedAt   string                 `json:"started_at,omitempty" yaml:"started_at,omitempty"`
+	CompletedAt string                 `json:"completed_at,omitempty" yaml:"completed_at,omitempty"`
+	Response    map[string]interface{} `json:"response,omitempty" yaml:"response,omitempty"`
+	Error       string                 `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// LogLevels are the level overrides of the named loggers and programs of the Elastic Agent.
+type LogLevels struct {
+	Loggers  map[string]string `json:"loggers" yaml:"loggers"`
+	Programs map[string]string `json:"programs" yaml:"programs"`
+}
+
 // AgentStatus is the current status of the Elastic Agent.
 type AgentStatus struct {
 	Status       Status
@@ -107,6 +133,15 @@ type Client interface {
 	Pprof(ctx context.Context, d time.Duration, pprofTypes []proto.PprofOption, appName, routeKey string) (map[string][]ProcPProf, error)
 	// ProcMetrics gathers /buffer data and from the agent and each running process and returns the result.
 	ProcMetrics(ctx context.Context) (*proto.ProcMetricsResponse, error)
+	// Actions returns the actions waiting in the action queue of the running agent.
+	Actions(ctx context.Context) ([]QueuedAction, error)
+	// CancelAction removes a queued action from the running agent and returns the number of removed actions.
+	CancelAction(ctx context.Context, id string) (int, error)
+	// AppAction performs an action on the application handling the input type, params is a JSON object.
+	AppAction(ctx context.Context, inputType string, params []byte, timeout time.Duration) (*AppActionResult, error)
+	// LogLevel changes the level overrides of the running agent and returns them, an empty level
+	// removes an override.
+	LogLevel(ctx context.Context, loggers map[string]string, programs map[string]string) (*LogLevels, error)
 }
 
 // client manages the state and communication to the Elastic Agent.
@@ -290,3 +325,105 @@ func (c *client) Pprof(ctx context.Context, d time.Duration, pprofTypes []proto.
 func (c *client) ProcMetrics(ctx context.Context) (*proto.ProcMetricsResponse, error) {
 	return c.client.ProcMetrics(ctx, &proto.Empty{})
 }
+
+// Actions returns the actions waiting in the action queue of the running agent.
+func (c *client) Actions(ctx context.Context) ([]QueuedAction, error) {
+	resp, err := c.client.Actions(ctx, &proto.Empty{})
+	if err != nil {
+		return nil, err
+	}
+
+	actions := make([]QueuedAction, 0, len(resp.Actions))
+	for _, a := range resp.Actions {
+		action := QueuedAction{
+			ID:   a.Id,
+			Type: a.Type,
+		}
+		if a.StartTime != "" {
+			if action.StartTime, err = time.Parse(control.TimeFormat(), a.StartTime); err != nil {
+				return nil, err
+			}
+		}
+		if a.Expiration != "" {
+			if action.Expiration, err = time.Parse(control.TimeFormat(), a.Expiration); err != nil {
+				return nil, err
+			}
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+// CancelAction removes a queued action from the running agent and returns the number of removed actions.
+func (c *client) CancelAction(ctx context.Context, id string) (int, error) {
+	res, err := c.client.CancelAction(ctx, &proto.CancelActionRequest{Id: id})
+	if err != nil {
+		return 0, err
+	}
+	if res.Status == proto.ActionStatus_FAILURE {
+		return int(res.Cancelled), errors.New(res.Error)
+	}
+	return int(res.Cancelled), nil
+}
+
+// AppAction performs an action on the application handling the input type, params is a JSON object.
+// The result is returned even when the application reports that the action failed.
+func (c *client) AppAction(ctx context.Context, inputType string, params []byte, timeout time.Duration) (*AppActionResult, error) {
+	res, err := c.client.AppAction(ctx, &proto.AppActionRequest{
+		InputType: inputType,
+		Params:    string(params),
+		Timeout:   int64(math.Ceil(timeout.Seconds())),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AppActionResult{
+		ID:          res.Id,
+		InputType:   inputType,
+		StartedAt:   res.StartedAt,
+		CompletedAt: res.CompletedAt,
+		Error:       res.Error,
+	}
+	if res.Response != "" {
+		if err := json.Unmarshal([]byte(res.Response), &result.Response); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// LogLevel changes the level overrides of the running agent and returns them, an empty level
+// removes an override.
+func (c *client) LogLevel(ctx context.Context, loggers map[string]string, programs map[string]string) (*LogLevels, error) {
+	res, err := c.client.LogLevel(ctx, &proto.LogLevelRequest{
+		Loggers:  fromLogLevels(loggers),
+		Programs: fromLogLevels(programs),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.Status == proto.ActionStatus_FAILURE {
+		return nil, errors.New(res.Error)
+	}
+	return &LogLevels{
+		Loggers:  toLogLevels(res.Loggers),
+		Programs: toLogLevels(res.Programs),
+	}, nil
+}
+
+func fromLogLevels(levels map[string]string) []*proto.LogLevelOverride {
+	overrides := make([]*proto.LogLevelOverride, 0, len(levels))
+	for name, level := range levels {
+		overrides = append(overrides, &proto.LogLevelOverride{Name: name, Level: level})
+	}
+	return overrides
+}
+
+func toLogLevels(overrides []*proto.LogLevelOverride) map[string]string {
+	levels := make(map[string]string, len(overrides))
+	for _, o := range overrides {
+		levels[o.Name] = o.Level
+	}
+	return levels
+}
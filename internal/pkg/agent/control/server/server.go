@@ -12,14 +12,17 @@ import (
 	"net"
 	"net/http"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/gofrs/uuid"
 	"go.elastic.co/apm"
 	"go.elastic.co/apm/module/apmgrpc"
 	"google.golang.org/grpc"
 
+	"github.com/elastic/elastic-agent-libs/logp"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/reexec"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/control"
@@ -47,6 +50,8 @@ type Server struct {
 	statusCtrl    status.Controller
 	up            *upgrade.Upgrader
 	routeFn       func() *sorted.Set
+	actionQueue   ActionQueue
+	appActions    AppActionPerformer
 	monitoringCfg *monitoringCfg.MonitoringConfig
 	listener      net.Listener
 	server        *grpc.Server
@@ -54,6 +59,20 @@ type Server struct {
 	lock          sync.RWMutex
 }
 
+// ActionQueue gives access to the actions queued by the running agent.
+type ActionQueue interface {
+	// QueuedActions returns the actions waiting in the queue.
+	QueuedActions() []fleetapi.Action
+	// CancelAction removes the actions with the given ID from the queue and returns how many were removed.
+	CancelAction(id string) (int, error)
+}
+
+// AppActionPerformer performs actions on the applications run by the agent.
+type AppActionPerformer interface {
+	// PerformAppAction performs the action and records its outcome in the action.
+	PerformAppAction(action *fleetapi.ActionApp)
+}
+
 type specer interface {
 	Specs() map[string]program.Spec
 }
@@ -89,6 +108,20 @@ func (s *Server) SetRouteFn(routesFetchFn func() *sorted.Set) {
 	s.routeFn = routesFetchFn
 }
 
+// SetActionQueue sets the queue of actions of the running agent.
+func (s *Server) SetActionQueue(q ActionQueue) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.actionQueue = q
+}
+
+// SetAppActionPerformer sets the performer of application actions.
+func (s *Server) SetAppActionPerformer(p AppActionPerformer) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.appActions = p
+}
+
 // SetMonitoringCfg sets a reference to the monitoring config used by the running agent.
 // the controller references this config to find out if pprof is enabled for the agent or not
 func (s *Server) SetMonitoringCfg(cfg *monitoringCfg.MonitoringConfig) {
@@ -200,6 +233,139 @@ func (s *Server) Upgrade(ctx context.Context, request *proto.UpgradeRequest) (*p
 	}, nil
 }
 
+// Actions returns the actions queued by the agent.
+func (s *Server) Actions(_ context.Context, _ *proto.Empty) (*proto.ActionsResponse, error) {
+	s.lock.RLock()
+	q := s.actionQueue
+	s.lock.RUnlock()
+	if q == nil {
+		return nil, errors.New("actions are only queued when managed by Fleet")
+	}
+
+	queued := q.QueuedActions()
+	resp := &proto.ActionsResponse{
+		Actions: make([]*proto.QueuedAction, 0, len(queued)),
+	}
+	for _, a := range queued {
+		qa := &proto.QueuedAction{
+			Id:   a.ID(),
+			Type: a.Type(),
+		}
+		if start, err := a.StartTime(); err == nil {
+			qa.StartTime = start.Format(control.TimeFormat())
+		}
+		if exp, err := a.Expiration(); err == nil {
+			qa.Expiration = exp.Format(control.TimeFormat())
+		}
+		resp.Actions = append(resp.Actions, qa)
+	}
+
+	sort.Slice(resp.Actions, func(i, j int) bool {
+		return resp.Actions[i].StartTime < resp.Actions[j].StartTime
+	})
+	return resp, nil
+}
+
+// CancelAction removes a queued action.
+func (s *Server) CancelAction(_ context.Context, request *proto.CancelActionRequest) (*proto.CancelActionResponse, error) {
+	s.lock.RLock()
+	q := s.actionQueue
+	s.lock.RUnlock()
+	if q == nil {
+		return &proto.CancelActionResponse{
+			Status: proto.ActionStatus_FAILURE,
+			Error:  "actions are only queued when managed by Fleet",
+		}, nil
+	}
+
+	n, err := q.CancelAction(request.Id)
+	if err != nil {
+		s.logger.Errorw("Cancelling queued action failed", "error.message", err, "action_id", request.Id)
+		return &proto.CancelActionResponse{
+			Status:    proto.ActionStatus_FAILURE,
+			Cancelled: int32(n),
+			Error:     err.Error(),
+		}, nil
+	}
+	if n == 0 {
+		return &proto.CancelActionResponse{
+			Status: proto.ActionStatus_FAILURE,
+			Error:  fmt.Sprintf("no queued action with id %s", request.Id),
+		}, nil
+	}
+
+	s.logger.Infow("Queued action cancelled", "action_id", request.Id, "cancelled", n)
+	return &proto.CancelActionResponse{
+		Status:    proto.ActionStatus_SUCCESS,
+		Cancelled: int32(n),
+	}, nil
+}
+
+// AppAction performs an action on the application handling the input type of the request.
+func (s *Server) AppAction(_ context.Context, request *proto.AppActionRequest) (*proto.AppActionResponse, error) {
+	s.lock.RLock()
+	p := s.appActions
+	s.lock.RUnlock()
+	if p == nil {
+		return &proto.AppActionResponse{
+			Status: proto.ActionStatus_FAILURE,
+			Error:  "application actions are not supported by the running agent",
+		}, nil
+	}
+
+	if request.InputType == "" {
+		return &proto.AppActionResponse{
+			Status: proto.ActionStatus_FAILURE,
+			Error:  "input type is required",
+		}, nil
+	}
+	params := request.Params
+	if params == "" {
+		params = "{}"
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(params), &data); err != nil {
+		return &proto.AppActionResponse{
+			Status: proto.ActionStatus_FAILURE,
+			Error:  fmt.Sprintf("action parameters must be a JSON object: %v", err),
+		}, nil
+	}
+
+	id, err := uuid.NewV4()
+	if err != nil {
+		return nil, err
+	}
+	action := &fleetapi.ActionApp{
+		ActionID:   id.String(),
+		ActionType: fleetapi.ActionTypeInputAction,
+		InputType:  request.InputType,
+		Timeout:    request.Timeout,
+		Data:       json.RawMessage(params),
+	}
+
+	s.logger.Infow("Performing application action", "action_id", action.ActionID, "input_type", action.InputType)
+	p.PerformAppAction(action)
+
+	resp := &proto.AppActionResponse{
+		Status:      proto.ActionStatus_SUCCESS,
+		Id:          action.ActionID,
+		StartedAt:   action.StartedAt,
+		CompletedAt: action.CompletedAt,
+		Error:       action.Error,
+	}
+	if action.Error != "" {
+		resp.Status = proto.ActionStatus_FAILURE
+	}
+	if len(action.Response) > 0 {
+		b, err := json.Marshal(action.Response)
+		if err != nil {
+			return nil, err
+		}
+		resp.Response = string(b)
+	}
+	return resp, nil
+}
+
 // BeatInfo is the metadata response a beat will provide when the root ("/") is queried.
 type BeatInfo struct {
 	Beat            string `json:"beat"`
@@ -217,6 +383,71 @@ type BeatInfo struct {
 	ElasticLicensed bool   `json:"elastic_licensed"`
 }
 
+// LogLevel changes the level overrides of the named loggers and of the programs and returns them.
+// Loggers are updated immediately, programs receive their level on their next reconfigure.
+func (s *Server) LogLevel(_ context.Context, request *proto.LogLevelRequest) (*proto.LogLevelResponse, error) {
+	// validate every level before applying any
+	for _, o := range append(request.Loggers, request.Programs...) {
+		if o.Name == "" {
+			return &proto.LogLevelResponse{
+				Status: proto.ActionStatus_FAILURE,
+				Error:  "log level override requires a name",
+			}, nil
+		}
+		if _, err := parseLogLevel(o.Level); err != nil {
+			return &proto.LogLevelResponse{
+				Status: proto.ActionStatus_FAILURE,
+				Error:  fmt.Sprintf("invalid log level for %s: %v", o.Name, err),
+			}, nil
+		}
+	}
+
+	for _, o := range request.Loggers {
+		if o.Level == "" {
+			logger.ResetLoggerLevel(o.Name)
+			s.logger.Infow("Log level override removed", "logger", o.Name)
+			continue
+		}
+		level, _ := parseLogLevel(o.Level)
+		logger.SetLoggerLevel(o.Name, level)
+		s.logger.Infow("Log level override set", "logger", o.Name, "level", level.String())
+	}
+	for _, o := range request.Programs {
+		if o.Level == "" {
+			logger.ResetProgramLevel(o.Name)
+			s.logger.Infow("Log level override removed", "program", o.Name)
+			continue
+		}
+		level, _ := parseLogLevel(o.Level)
+		logger.SetProgramLevel(o.Name, level)
+		s.logger.Infow("Log level override set", "program", o.Name, "level", level.String())
+	}
+
+	return &proto.LogLevelResponse{
+		Status:   proto.ActionStatus_SUCCESS,
+		Loggers:  toLogLevelOverrides(logger.LoggerLevels()),
+		Programs: toLogLevelOverrides(logger.ProgramLevels()),
+	}, nil
+}
+
+// parseLogLevel parses a log level, an empty level is valid and removes the override.
+func parseLogLevel(level string) (logp.Level, error) {
+	var l logp.Level
+	if level == "" {
+		return l, nil
+	}
+	err := l.Unpack(level)
+	return l, err
+}
+
+func toLogLevelOverrides(overrides []logger.LevelOverride) []*proto.LogLevelOverride {
+	res := make([]*proto.LogLevelOverride, 0, len(overrides))
+	for _, o := range overrides {
+		res = append(res, &proto.LogLevelOverride{Name: o.Name, Level: o.Level.String()})
+	}
+	return res
+}
+
 // ProcMeta returns version and beat inforation for all running processes.
 func (s *Server) ProcMeta(ctx context.Context, _ *proto.Empty) (*proto.ProcMetaResponse, error) {
 	if s.routeFn == nil {
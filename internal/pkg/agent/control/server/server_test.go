@@ -0,0 +1,126 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/control/proto"
+	"github.com/elastic/elastic-agent/internal/pkg/fleetapi"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+type fakeAppActions struct {
+	received *fleetapi.ActionApp
+}
+
+func (f *fakeAppActions) PerformAppAction(action *fleetapi.ActionApp) {
+	f.received = action
+	action.StartedAt = "2022-01-01T00:00:00Z"
+	action.CompletedAt = "2022-01-01T00:00:01Z"
+	if action.InputType == "broken" {
+		action.Error = "action failed"
+		return
+	}
+	action.Response = map[string]interface{}{action.InputType: map[string]interface{}{"count": 1}}
+}
+
+func TestAppAction(t *testing.T) {
+	log, err := logger.New("", false)
+	require.NoError(t, err)
+
+	t.Run("no performer", func(t *testing.T) {
+		s := New(log, nil, nil, nil, nil)
+		resp, err := s.AppAction(context.Background(), &proto.AppActionRequest{InputType: "osquery"})
+		require.NoError(t, err)
+		assert.Equal(t, proto.ActionStatus_FAILURE, resp.Status)
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		s := New(log, nil, nil, nil, nil)
+		f := &fakeAppActions{}
+		s.SetAppActionPerformer(f)
+		resp, err := s.AppAction(context.Background(), &proto.AppActionRequest{InputType: "osquery", Params: "[1]"})
+		require.NoError(t, err)
+		assert.Equal(t, proto.ActionStatus_FAILURE, resp.Status)
+		assert.Nil(t, f.received)
+	})
+
+	t.Run("performed", func(t *testing.T) {
+		s := New(log, nil, nil, nil, nil)
+		f := &fakeAppActions{}
+		s.SetAppActionPerformer(f)
+		resp, err := s.AppAction(context.Background(), &proto.AppActionRequest{
+			InputType: "osquery",
+			Params:    `{"query":"select 1"}`,
+			Timeout:   30,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, proto.ActionStatus_SUCCESS, resp.Status)
+		assert.NotEmpty(t, resp.Id)
+		assert.Equal(t, "2022-01-01T00:00:00Z", resp.StartedAt)
+
+		require.NotNil(t, f.received)
+		assert.Equal(t, resp.Id, f.received.ActionID)
+		assert.Equal(t, fleetapi.ActionTypeInputAction, f.received.ActionType)
+		assert.Equal(t, int64(30), f.received.Timeout)
+		assert.JSONEq(t, `{"query":"select 1"}`, string(f.received.Data))
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(resp.Response), &response))
+		assert.Contains(t, response, "osquery")
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		s := New(log, nil, nil, nil, nil)
+		s.SetAppActionPerformer(&fakeAppActions{})
+		resp, err := s.AppAction(context.Background(), &proto.AppActionRequest{InputType: "broken"})
+		require.NoError(t, err)
+		assert.Equal(t, proto.ActionStatus_FAILURE, resp.Status)
+		assert.Equal(t, "action failed", resp.Error)
+		assert.Empty(t, resp.Response)
+	})
+}
+
+func TestLogLevel(t *testing.T) {
+	log, err := logger.New("", false)
+	require.NoError(t, err)
+	defer logger.ApplyLevelsConfig(nil)
+
+	s := New(log, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	resp, err := s.LogLevel(ctx, &proto.LogLevelRequest{
+		Loggers: []*proto.LogLevelOverride{{Name: "composable", Level: "verbose"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, proto.ActionStatus_FAILURE, resp.Status)
+
+	resp, err = s.LogLevel(ctx, &proto.LogLevelRequest{
+		Loggers:  []*proto.LogLevelOverride{{Name: "composable", Level: "debug"}, {Name: "fleet_gateway", Level: "warning"}},
+		Programs: []*proto.LogLevelOverride{{Name: "filebeat", Level: "debug"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, proto.ActionStatus_SUCCESS, resp.Status)
+	require.Len(t, resp.Loggers, 2)
+	assert.Equal(t, "composable", resp.Loggers[0].Name)
+	assert.Equal(t, "debug", resp.Loggers[0].Level)
+	require.Len(t, resp.Programs, 1)
+	assert.Equal(t, "filebeat", resp.Programs[0].Name)
+
+	// an empty level removes the override
+	resp, err = s.LogLevel(ctx, &proto.LogLevelRequest{
+		Loggers: []*proto.LogLevelOverride{{Name: "composable"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, proto.ActionStatus_SUCCESS, resp.Status)
+	require.Len(t, resp.Loggers, 1)
+	assert.Equal(t, "fleet_gateway", resp.Loggers[0].Name)
+}
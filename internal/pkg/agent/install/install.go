@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"runtime"
 
+	"github.com/kardianos/service"
 	"github.com/otiai10/copy"
 
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
@@ -21,7 +22,10 @@ const (
 )
 
 // Install installs Elastic Agent persistently on the system including creating and starting its service.
-func Install(cfgFile string) error {
+//
+// The service runs as the service user, created if it does not exist, unless its name is empty and
+// the service runs as root.
+func Install(cfgFile string, serviceUser ServiceUser) error {
 	dir, err := findDirectory()
 	if err != nil {
 		return errors.New(err, "failed to discover the source directory for installation", errors.TypeFilesystem)
@@ -54,6 +58,19 @@ func Install(cfgFile string) error {
 			errors.M("source", dir), errors.M("destination", paths.InstallPath))
 	}
 
+	serviceUser, err = ensureServiceUser(serviceUser)
+	if err == nil {
+		err = saveServiceUser(paths.InstallPath, serviceUser)
+	}
+	if err != nil {
+		// remove what was created before failing, the uninstall does not know about it
+		_ = removeServiceUser(serviceUser)
+		return errors.New(
+			err,
+			fmt.Sprintf("failed to setup the service user (%s)", serviceUser.Name),
+			errors.M("user", serviceUser.Name))
+	}
+
 	// place shell wrapper, if present on platform
 	if paths.ShellWrapperPath != "" {
 		// Install symlink for darwin instead of the wrapper script.
@@ -99,7 +116,7 @@ func Install(cfgFile string) error {
 	}
 
 	// fix permissions
-	err = FixPermissions()
+	err = fixPermissions(serviceUser)
 	if err != nil {
 		return errors.New(
 			err,
@@ -108,7 +125,7 @@ func Install(cfgFile string) error {
 	}
 
 	// install service
-	svc, err := newService()
+	svc, err := newServiceAs(serviceUser)
 	if err != nil {
 		return err
 	}
@@ -156,9 +173,37 @@ func StopService() error {
 	return nil
 }
 
+// ServiceRunning returns true when the installed service is running.
+func ServiceRunning() bool {
+	svc, err := newService()
+	if err != nil {
+		return false
+	}
+	status, err := svc.Status()
+	return err == nil && status == service.StatusRunning
+}
+
 // FixPermissions fixes the permissions on the installed system.
 func FixPermissions() error {
-	return fixPermissions()
+	serviceUser, err := LoadServiceUser()
+	if err != nil {
+		return err
+	}
+	return fixPermissions(serviceUser)
+}
+
+// FixPermissionsOf fixes the permissions of files added to the installed system, e.g. by a rollback,
+// when the service does not run as root. A service running as root creates its files with the
+// right owner already.
+func FixPermissionsOf(files ...string) error {
+	serviceUser, err := LoadServiceUser()
+	if err != nil {
+		return err
+	}
+	if serviceUser.IsRoot() {
+		return nil
+	}
+	return fixPermissionsOf(serviceUser, paths.Top(), files...)
 }
 
 // findDirectory returns the directory to copy into the installation location.
@@ -9,31 +9,223 @@ package install
 
 import (
 	"errors"
+	"fmt"
 	"io/fs"
 	"os"
+	"os/user"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
 
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
 )
 
-// fixPermissions fixes the permissions so only root:root is the owner and no world read-able permissions
-func fixPermissions() error {
-	return recursiveRootPermissions(paths.InstallPath)
+// fixPermissions fixes the permissions so only root:root is the owner and no world read-able permissions.
+//
+// When the service does not run as root the files stay owned by root, readable by the group of the
+// service user but not writable by it, the service user only owns the state it writes, see serviceUserAccess.
+func fixPermissions(u ServiceUser) error {
+	return fixPermissionsOf(u, paths.InstallPath, paths.InstallPath)
 }
 
-func recursiveRootPermissions(path string) error {
+// fixPermissionsOf fixes the permissions of the paths of the installation at top.
+func fixPermissionsOf(u ServiceUser, top string, paths ...string) error {
+	owner, err := ownership(u, top)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if err := recursivePermissions(path, owner); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// permissionsChecker returns a function describing how the permissions of a file differ from the
+// ones set by fixPermissions, an empty description when they match.
+func permissionsChecker(u ServiceUser, top string) (func(string, fs.FileInfo) string, error) {
+	owner, err := ownership(u, top)
+	if err != nil {
+		return nil, err
+	}
+	if u.IsRoot() {
+		// the upgrades of a service running as root keep the modes of the package, readable by
+		// anyone but only writable by root
+		owner = func(_ string, info fs.FileInfo) (int, int, os.FileMode) {
+			return 0, 0, info.Mode().Perm() & 0775
+		}
+	}
+	return func(name string, info fs.FileInfo) string {
+		if info.Mode()&os.ModeSymlink != 0 {
+			return ""
+		}
+		uid, gid, mode := owner(name, info)
+		if extra := info.Mode().Perm() &^ mode.Perm(); extra != 0 {
+			return fmt.Sprintf("mode %v grants %v to other users", info.Mode().Perm(), extra)
+		}
+		if mode&os.ModeSticky != 0 && info.Mode()&os.ModeSticky == 0 {
+			return fmt.Sprintf("mode %v lets the service user remove the files of root", info.Mode().Perm())
+		}
+		if stat, ok := info.Sys().(*syscall.Stat_t); ok && (int(stat.Uid) != uid || int(stat.Gid) != gid) {
+			return fmt.Sprintf("owned by %d:%d instead of %d:%d", stat.Uid, stat.Gid, uid, gid)
+		}
+		return ""
+	}, nil
+}
+
+// ownerFunc returns the owner and the mode of a file.
+type ownerFunc func(name string, info fs.FileInfo) (uid int, gid int, mode os.FileMode)
+
+// ownership returns the owner and the mode of the files of the installation at top.
+func ownership(u ServiceUser, top string) (ownerFunc, error) {
+	if u.IsRoot() {
+		return func(_ string, info fs.FileInfo) (int, int, os.FileMode) {
+			return 0, 0, info.Mode().Perm() & 0770
+		}, nil
+	}
+	uid, gid, err := lookupServiceUser(u)
+	if err != nil {
+		return nil, err
+	}
+	return func(name string, info fs.FileInfo) (int, int, os.FileMode) {
+		switch serviceUserAccess(top, name, info) {
+		case sharedAccess:
+			// the service user creates its files in the directory, the sticky bit keeps it from
+			// removing or replacing the ones of root
+			return 0, gid, os.ModeSticky | 0770
+		case ownedAccess:
+			return uid, gid, info.Mode().Perm() & 0750
+		default:
+			return 0, gid, info.Mode().Perm() & 0750
+		}
+	}, nil
+}
+
+// access is the access of the service user to a file of the installation.
+type access int
+
+const (
+	// readAccess is a file owned by root the service user can only read.
+	readAccess access = iota
+	// sharedAccess is a directory owned by root the service user creates its files in.
+	sharedAccess
+	// ownedAccess is a file or a directory owned by the service user.
+	ownedAccess
+)
+
+var (
+	// the directories owned by the service user, the vault at the top of the installation, the
+	// temporary files in the data directory and the logs, the data of the processes and the
+	// downloads in the versioned home
+	serviceUserTopDirs  = map[string]bool{"vault": true}
+	serviceUserDataDirs = map[string]bool{"tmp": true}
+	serviceUserHomeDirs = map[string]bool{"logs": true, "run": true, "downloads": true}
+
+	// serviceUserFiles are the state files the service writes next to the files of root.
+	serviceUserFiles = map[string]bool{
+		"fleet.enc":        true,
+		"fleet.enc.lock":   true,
+		"fleet.yml":        true,
+		"fleet.yml.lock":   true,
+		"agent.lock":       true,
+		"action_store.yml": true,
+		"state.yml":        true,
+		"state.enc":        true,
+		"ack_spool.enc":    true,
+		// the upgrade marker and the relink request in the data directory, the lock of the watcher
+		".update-marker":  true,
+		".relink-request": true,
+		"watcher.lock":    true,
+	}
+)
+
+// serviceUserAccess returns the access of the service user to a file of the installation at top.
+//
+// The binaries and the configuration are owned by root. The service user owns its state, the
+// vault and the files it writes at the top, in the data directory and in the versioned home,
+// and the directories of the logs, the temporary files, the data of the processes and the downloads.
+func serviceUserAccess(top, name string, info fs.FileInfo) access {
+	rel, err := filepath.Rel(top, name)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return readAccess
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	switch {
+	case rel == ".":
+		return sharedAccess
+	case serviceUserTopDirs[parts[0]]:
+		return ownedAccess
+	case len(parts) == 1 && parts[0] == "data" && info.IsDir():
+		return sharedAccess
+	case len(parts) == 1:
+		return fileAccess(parts[0], info)
+	case parts[0] != "data":
+		return readAccess
+	case serviceUserDataDirs[parts[1]]:
+		return ownedAccess
+	case len(parts) == 2 && info.IsDir():
+		// the versioned home
+		return sharedAccess
+	case len(parts) == 2:
+		return fileAccess(parts[1], info)
+	case serviceUserHomeDirs[parts[2]]:
+		return ownedAccess
+	case len(parts) == 3:
+		return fileAccess(parts[2], info)
+	}
+	return readAccess
+}
+
+func fileAccess(name string, info fs.FileInfo) access {
+	if !info.IsDir() && serviceUserFiles[strings.TrimSuffix(name, ".tmp")] {
+		return ownedAccess
+	}
+	return readAccess
+}
+
+func recursivePermissions(path string, owner ownerFunc) error {
 	return filepath.Walk(path, func(name string, info fs.FileInfo, err error) error {
 		if err == nil {
-			// all files should be owned by root:root
-			err = os.Chown(name, 0, 0)
+			uid, gid, mode := owner(name, info)
+			if info.Mode()&os.ModeSymlink != 0 {
+				// the mode of a symlink is not used, only its owner can be changed without following it
+				return os.Lchown(name, uid, gid)
+			}
+			err = os.Chown(name, uid, gid)
 			if err != nil {
 				return err
 			}
 			// remove any world permissions from the file
-			err = os.Chmod(name, info.Mode().Perm()&0770)
+			err = os.Chmod(name, mode)
 		} else if errors.Is(err, fs.ErrNotExist) {
 			return nil
 		}
 		return err
 	})
 }
+
+func lookupServiceUser(u ServiceUser) (int, int, error) {
+	usr, err := user.Lookup(u.Name)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to lookup service user %s: %w", u.Name, err)
+	}
+	uid, err := strconv.Atoi(usr.Uid)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid uid %s of service user %s: %w", usr.Uid, u.Name, err)
+	}
+	gidStr := usr.Gid
+	if u.Group != "" {
+		grp, err := user.LookupGroup(u.Group)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to lookup service group %s: %w", u.Group, err)
+		}
+		gidStr = grp.Gid
+	}
+	gid, err := strconv.Atoi(gidStr)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid gid %s of service group %s: %w", gidStr, u.Group, err)
+	}
+	return uid, gid, nil
+}
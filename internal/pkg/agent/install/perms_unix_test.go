@@ -0,0 +1,125 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+//go:build !windows
+// +build !windows
+
+package install
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
+)
+
+func TestFixPermissionsServiceUser(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("changing the owner of the files requires root")
+	}
+	nobody, err := user.Lookup("nobody")
+	if err != nil {
+		t.Skip("the nobody user is missing")
+	}
+	uid, _ := strconv.Atoi(nobody.Uid)
+	gid, _ := strconv.Atoi(nobody.Gid)
+
+	dir := t.TempDir()
+	home := filepath.Join(dir, "data", "elastic-agent-abcdef")
+	binary := filepath.Join(home, "elastic-agent")
+	log := filepath.Join(home, "logs", "elastic-agent.ndjson")
+	seed := filepath.Join(dir, "vault", "seed")
+	for _, d := range []string{filepath.Dir(log), filepath.Dir(seed)} {
+		require.NoError(t, os.MkdirAll(d, 0777))
+	}
+	for _, f := range []string{binary, log, seed} {
+		require.NoError(t, os.WriteFile(f, []byte{}, 0777))
+	}
+	require.NoError(t, saveServiceUser(dir, ServiceUser{Name: "nobody"}))
+	fleet := filepath.Join(dir, "fleet.enc")
+	require.NoError(t, os.WriteFile(fleet, []byte{}, 0600))
+	link := filepath.Join(dir, "elastic-agent")
+	require.NoError(t, os.Symlink(binary, link))
+
+	require.NoError(t, fixPermissionsOf(ServiceUser{Name: "nobody"}, dir, dir))
+
+	type expected struct {
+		uid  int
+		mode os.FileMode
+	}
+	for path, e := range map[string]expected{
+		// the service user creates its files next to the ones of root without replacing them
+		dir:                                 {0, os.ModeSticky | 0770},
+		filepath.Dir(home):                  {0, os.ModeSticky | 0770},
+		home:                                {0, os.ModeSticky | 0770},
+		binary:                              {0, 0750},
+		filepath.Join(dir, serviceUserFile): {0, 0640},
+		fleet:                               {uid, 0600},
+		filepath.Dir(log):                   {uid, 0750},
+		log:                                 {uid, 0750},
+		filepath.Dir(seed):                  {uid, 0750},
+		seed:                                {uid, 0750},
+	} {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, e.mode, info.Mode()&(os.ModePerm|os.ModeSticky), path)
+		stat := info.Sys().(*syscall.Stat_t)
+		assert.Equal(t, uint32(e.uid), stat.Uid, path)
+		assert.Equal(t, uint32(gid), stat.Gid, path)
+	}
+	info, err := os.Lstat(link)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(0), info.Sys().(*syscall.Stat_t).Uid, "the symlink must stay owned by root")
+
+	check, err := permissionsChecker(ServiceUser{Name: "nobody"}, dir)
+	require.NoError(t, err)
+	require.NoError(t, filepath.Walk(dir, func(name string, info os.FileInfo, err error) error {
+		require.NoError(t, err)
+		assert.Empty(t, check(name, info), name)
+		return nil
+	}))
+	require.NoError(t, os.Chown(binary, uid, gid))
+	info, err = os.Lstat(binary)
+	require.NoError(t, err)
+	assert.NotEmpty(t, check(binary, info), "the binaries must be owned by root")
+}
+
+func TestRepairPermissionsRoot(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("changing the owner of the files requires root")
+	}
+	nobody, err := user.Lookup("nobody")
+	if err != nil {
+		t.Skip("the nobody user is missing")
+	}
+	uid, _ := strconv.Atoi(nobody.Uid)
+	gid, _ := strconv.Atoi(nobody.Gid)
+
+	top, home, downloads := newTestInstall(t)
+	binary := filepath.Join(home, paths.BinaryName)
+	require.NoError(t, os.Chown(binary, uid, gid))
+	require.NoError(t, os.Chmod(binary, 0777))
+
+	r, err := verify(top, home, &ServiceUser{})
+	require.NoError(t, err)
+	require.Len(t, r.Problems, 1)
+	assert.Equal(t, ProblemPermissions, r.Problems[0].Kind)
+
+	r, err = repair(r, downloads)
+	require.NoError(t, err)
+	assert.True(t, r.OK(), "%v", r.Problems)
+	info, err := os.Stat(binary)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0770), info.Mode().Perm())
+	stat := info.Sys().(*syscall.Stat_t)
+	assert.Equal(t, uint32(0), stat.Uid)
+	assert.Equal(t, uint32(0), stat.Gid)
+}
@@ -19,8 +19,23 @@ import (
 )
 
 // fixPermissions fixes the permissions so only SYSTEM and Administrators have access to the files in the install path
-func fixPermissions() error {
-	return recursiveSystemAdminPermissions(paths.InstallPath)
+func fixPermissions(u ServiceUser) error {
+	return fixPermissionsOf(u, paths.InstallPath, paths.InstallPath)
+}
+
+// fixPermissionsOf fixes the permissions of the paths, the service always runs as SYSTEM on Windows.
+func fixPermissionsOf(_ ServiceUser, _ string, paths ...string) error {
+	for _, path := range paths {
+		if err := recursiveSystemAdminPermissions(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// permissionsChecker returns no checker, the access control lists set by fixPermissions are not verified.
+func permissionsChecker(ServiceUser, string) (func(string, fs.FileInfo) string, error) {
+	return nil, nil
 }
 
 func recursiveSystemAdminPermissions(path string) error {
@@ -0,0 +1,90 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package install
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+)
+
+// serviceUserFile is the file of the install path recording the user running the service.
+const serviceUserFile = "service-user.yml"
+
+// DefaultCapabilities are the Linux capabilities granted to a service not running as root, they
+// let the agent and its processes read the files of the system, e.g. the logs, without owning them.
+var DefaultCapabilities = []string{"CAP_DAC_READ_SEARCH"}
+
+// ServiceUser is the user and the group running the service, the service runs as root when the
+// name is empty.
+type ServiceUser struct {
+	Name  string `yaml:"user"`
+	Group string `yaml:"group"`
+	// Capabilities are the capabilities granted to the service.
+	Capabilities []string `yaml:"capabilities,omitempty"`
+	// CreatedUser and CreatedGroup are set when the install created them, the uninstall removes
+	// them as well.
+	CreatedUser  bool `yaml:"created_user,omitempty"`
+	CreatedGroup bool `yaml:"created_group,omitempty"`
+}
+
+// IsRoot returns true when the service runs as root.
+func (u ServiceUser) IsRoot() bool {
+	return u.Name == ""
+}
+
+// LoadServiceUser returns the user running the installed service, the zero value when it runs as root.
+func LoadServiceUser() (ServiceUser, error) {
+	return loadServiceUser(paths.InstallPath)
+}
+
+func loadServiceUser(dir string) (ServiceUser, error) {
+	var u ServiceUser
+	p := filepath.Join(dir, serviceUserFile)
+	b, err := ioutil.ReadFile(p)
+	if os.IsNotExist(err) {
+		return u, nil
+	}
+	if err != nil {
+		return u, errors.New(err,
+			fmt.Sprintf("failed to read the service user (%s)", p),
+			errors.TypeFilesystem,
+			errors.M(errors.MetaKeyPath, p))
+	}
+	if err := yaml.Unmarshal(b, &u); err != nil {
+		return u, errors.New(err,
+			fmt.Sprintf("failed to parse the service user (%s)", p),
+			errors.TypeConfig,
+			errors.M(errors.MetaKeyPath, p))
+	}
+	return u, nil
+}
+
+func saveServiceUser(dir string, u ServiceUser) error {
+	p := filepath.Join(dir, serviceUserFile)
+	if u.IsRoot() {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return errors.New(err, fmt.Sprintf("failed to remove (%s)", p), errors.TypeFilesystem, errors.M(errors.MetaKeyPath, p))
+		}
+		return nil
+	}
+	b, err := yaml.Marshal(u)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(p, b, 0640); err != nil {
+		return errors.New(err,
+			fmt.Sprintf("failed to write the service user (%s)", p),
+			errors.TypeFilesystem,
+			errors.M(errors.MetaKeyPath, p))
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+//go:build linux
+// +build linux
+
+package install
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"os/user"
+	"strings"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
+)
+
+// ensureServiceUser creates the user and the group running the service when they do not exist,
+// the group defaults to the name of the user.
+func ensureServiceUser(u ServiceUser) (ServiceUser, error) {
+	if u.IsRoot() {
+		return u, nil
+	}
+	if u.Name == "root" {
+		return u, fmt.Errorf("the service user cannot be root, omit --user to run the service as root")
+	}
+	if u.Group == "" {
+		u.Group = u.Name
+	}
+	if u.Capabilities == nil {
+		u.Capabilities = DefaultCapabilities
+	}
+
+	if _, err := user.LookupGroup(u.Group); err != nil {
+		var unknown user.UnknownGroupError
+		if !errors.As(err, &unknown) {
+			return u, fmt.Errorf("failed to lookup group %s: %w", u.Group, err)
+		}
+		if err := run("groupadd", "--system", u.Group); err != nil {
+			return u, fmt.Errorf("failed to create group %s: %w", u.Group, err)
+		}
+		u.CreatedGroup = true
+	}
+
+	if _, err := user.Lookup(u.Name); err != nil {
+		var unknown user.UnknownUserError
+		if !errors.As(err, &unknown) {
+			return u, fmt.Errorf("failed to lookup user %s: %w", u.Name, err)
+		}
+		err = run("useradd", "--system", "--no-create-home",
+			"--home-dir", paths.InstallPath,
+			"--shell", "/usr/sbin/nologin",
+			"--gid", u.Group,
+			u.Name)
+		if err != nil {
+			return u, fmt.Errorf("failed to create user %s: %w", u.Name, err)
+		}
+		u.CreatedUser = true
+	}
+	return u, nil
+}
+
+// removeServiceUser removes the user and the group created by the install.
+func removeServiceUser(u ServiceUser) error {
+	if u.CreatedUser {
+		if err := run("userdel", u.Name); err != nil {
+			return fmt.Errorf("failed to remove user %s: %w", u.Name, err)
+		}
+	}
+	if u.CreatedGroup {
+		if err := run("groupdel", u.Group); err != nil {
+			return fmt.Errorf("failed to remove group %s: %w", u.Group, err)
+		}
+	}
+	return nil
+}
+
+func run(name string, args ...string) error {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s: %w: %s", name, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+//go:build !linux
+// +build !linux
+
+package install
+
+import "fmt"
+
+// ensureServiceUser fails for any user but root, the service runs as root outside of Linux.
+func ensureServiceUser(u ServiceUser) (ServiceUser, error) {
+	if !u.IsRoot() {
+		return u, fmt.Errorf("running the service as a user other than root is only supported on Linux")
+	}
+	return u, nil
+}
+
+func removeServiceUser(ServiceUser) error {
+	return nil
+}
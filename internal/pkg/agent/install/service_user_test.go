@@ -0,0 +1,36 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package install
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceUserPersistence(t *testing.T) {
+	dir := t.TempDir()
+
+	u, err := loadServiceUser(dir)
+	require.NoError(t, err)
+	assert.True(t, u.IsRoot(), "an installation without a service user runs as root")
+
+	expected := ServiceUser{
+		Name:         "elastic-agent",
+		Group:        "elastic-agent",
+		Capabilities: DefaultCapabilities,
+		CreatedUser:  true,
+	}
+	require.NoError(t, saveServiceUser(dir, expected))
+	u, err = loadServiceUser(dir)
+	require.NoError(t, err)
+	assert.Equal(t, expected, u)
+
+	require.NoError(t, saveServiceUser(dir, ServiceUser{}))
+	u, err = loadServiceUser(dir)
+	require.NoError(t, err)
+	assert.True(t, u.IsRoot())
+}
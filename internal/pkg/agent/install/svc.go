@@ -7,6 +7,7 @@ package install
 import (
 	"path/filepath"
 	"runtime"
+	"strings"
 
 	"github.com/kardianos/service"
 
@@ -37,6 +38,14 @@ func ExecutablePath() string {
 }
 
 func newService() (service.Service, error) {
+	serviceUser, err := LoadServiceUser()
+	if err != nil {
+		return nil, err
+	}
+	return newServiceAs(serviceUser)
+}
+
+func newServiceAs(serviceUser ServiceUser) (service.Service, error) {
 	cfg := &service.Config{
 		Name:             paths.ServiceName,
 		DisplayName:      ServiceDisplayName,
@@ -62,9 +71,58 @@ func newService() (service.Service, error) {
 		cfg.Option["ExitTimeOut"] = darwinServiceExitTimeout
 	}
 
+	if runtime.GOOS == "linux" && !serviceUser.IsRoot() {
+		// The prebuilt systemd template has no group nor capabilities, the service user gets
+		// only the capabilities it is granted and a runtime directory for its control socket.
+		cfg.UserName = serviceUser.Name
+		cfg.Option["SystemdScript"] = linuxSystemdScript
+		cfg.Option["Group"] = serviceUser.Group
+		cfg.Option["Capabilities"] = strings.Join(serviceUser.Capabilities, " ")
+		cfg.Option["RuntimeDirectory"] = paths.ServiceName
+	}
+
 	return service.New(nil, cfg)
 }
 
+// A copy of the systemd unit template from github.com/kardianos/service
+// with added Group, capabilities and RuntimeDirectory options, the relink
+// requested by an upgrade is performed as root before the service starts
+const linuxSystemdScript = `[Unit]
+Description={{.Description}}
+ConditionFileIsExecutable={{.Path|cmdEscape}}
+{{range $i, $dep := .Dependencies}} 
+{{$dep}} {{end}}
+
+[Service]
+StartLimitInterval=5
+StartLimitBurst=10
+ExecStartPre=-+{{.Path|cmdEscape}} relink
+ExecStart={{.Path|cmdEscape}}{{range .Arguments}} {{.|cmd}}{{end}}
+{{if .ChRoot}}RootDirectory={{.ChRoot|cmd}}{{end}}
+{{if .WorkingDirectory}}WorkingDirectory={{.WorkingDirectory|cmdEscape}}{{end}}
+{{if .UserName}}User={{.UserName}}{{end}}
+{{if .Option.Group}}Group={{.Option.Group}}{{end}}
+{{if .Option.RuntimeDirectory}}RuntimeDirectory={{.Option.RuntimeDirectory}}
+RuntimeDirectoryMode=0750{{end}}
+AmbientCapabilities={{.Option.Capabilities}}
+CapabilityBoundingSet={{.Option.Capabilities}}
+NoNewPrivileges=true
+{{if .ReloadSignal}}ExecReload=/bin/kill -{{.ReloadSignal}} "$MAINPID"{{end}}
+{{if .PIDFile}}PIDFile={{.PIDFile|cmd}}{{end}}
+{{if and .LogOutput .HasOutputFileSupport -}}
+StandardOutput=file:/var/log/{{.Name}}.out
+StandardError=file:/var/log/{{.Name}}.err
+{{- end}}
+{{if gt .LimitNOFILE -1 }}LimitNOFILE={{.LimitNOFILE}}{{end}}
+{{if .Restart}}Restart={{.Restart}}{{end}}
+{{if .SuccessExitStatus}}SuccessExitStatus={{.SuccessExitStatus}}{{end}}
+RestartSec=120
+EnvironmentFile=-/etc/sysconfig/{{.Name}}
+
+[Install]
+WantedBy=multi-user.target
+`
+
 // A copy of the launchd plist template from github.com/kardianos/service
 // with added .Config.Option.ExitTimeOut option
 const darwinLaunchdConfig = `<?xml version='1.0' encoding='UTF-8'?>
@@ -42,8 +42,14 @@ const (
 
 // Uninstall uninstalls persistently Elastic Agent on the system.
 func Uninstall(cfgFile string) error {
+	// the service user is recorded in the install path, read it before removing it
+	serviceUser, err := LoadServiceUser()
+	if err != nil {
+		return err
+	}
+
 	// uninstall the current service
-	svc, err := newService()
+	svc, err := newServiceAs(serviceUser)
 	if err != nil {
 		return err
 	}
@@ -88,6 +94,14 @@ func Uninstall(cfgFile string) error {
 			errors.M("directory", paths.InstallPath))
 	}
 
+	// remove the service user once nothing is owned by it anymore
+	if err := removeServiceUser(serviceUser); err != nil {
+		return errors.New(
+			err,
+			fmt.Sprintf("failed to remove the service user (%s)", serviceUser.Name),
+			errors.M("user", serviceUser.Name))
+	}
+
 	return nil
 }
 
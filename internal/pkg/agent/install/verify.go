@@ -0,0 +1,323 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package install
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/info"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+)
+
+// ChecksumsFile is the manifest of the SHA256 hashes of the files of a version, it is generated
+// when packaging the version, in the format of sha256sum.
+//
+// The manifest is not signed and is installed with the files it lists, it detects an accidental
+// corruption of the files, not a tampering which can rewrite the manifest as well.
+const ChecksumsFile = "manifest.sha256"
+
+// ProblemKind is the kind of problem found by the verification of an installation.
+type ProblemKind string
+
+const (
+	// ProblemMissing is a file of the manifest missing from the installation.
+	ProblemMissing ProblemKind = "missing"
+	// ProblemModified is a file not matching its hash in the manifest.
+	ProblemModified ProblemKind = "modified"
+	// ProblemSymlink is a symlink not pointing to the running version.
+	ProblemSymlink ProblemKind = "symlink"
+	// ProblemPermissions is a file with permissions other than the ones set by the installation.
+	ProblemPermissions ProblemKind = "permissions"
+)
+
+// Problem is a problem found by the verification of an installation.
+type Problem struct {
+	Kind   ProblemKind
+	Path   string
+	Detail string
+}
+
+// String returns the description of the problem.
+func (p Problem) String() string {
+	if p.Detail == "" {
+		return fmt.Sprintf("%s: %s", p.Kind, p.Path)
+	}
+	return fmt.Sprintf("%s: %s (%s)", p.Kind, p.Path, p.Detail)
+}
+
+// VerifyReport is the report of the verification of the running version.
+type VerifyReport struct {
+	// Home is the directory of the running version.
+	Home string
+	// Files is the number of files of the manifest.
+	Files    int
+	Problems []Problem
+	// Notes explain why the problems could not be repaired.
+	Notes []string
+
+	top         string
+	serviceUser *ServiceUser
+	checksums   map[string]string
+}
+
+// OK returns true when no problem was found.
+func (r *VerifyReport) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// Verify verifies the files of the running version against the manifest generated when it was
+// packaged, the symlink to its binary and, when installed, the permissions of its files.
+func Verify() (*VerifyReport, error) {
+	var serviceUser *ServiceUser
+	if info.RunningInstalled() {
+		u, err := LoadServiceUser()
+		if err != nil {
+			return nil, err
+		}
+		serviceUser = &u
+	}
+	return verify(paths.Top(), paths.Home(), serviceUser)
+}
+
+// Repair repairs the problems of the report: the missing and modified files are extracted from the
+// package of the running version cached in the downloads, the symlink is pointed back to the running
+// version and the permissions are fixed, to the ones of the installation for a service running as
+// root as well as for a service user. It returns the report of a new verification with the
+// problems which could not be repaired.
+func Repair(report *VerifyReport) (*VerifyReport, error) {
+	return repair(report, paths.Downloads())
+}
+
+func verify(top, home string, serviceUser *ServiceUser) (*VerifyReport, error) {
+	manifest := filepath.Join(home, ChecksumsFile)
+	checksums, err := readChecksums(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &VerifyReport{
+		Home:        home,
+		Files:       len(checksums),
+		top:         top,
+		serviceUser: serviceUser,
+		checksums:   checksums,
+	}
+
+	names := make([]string, 0, len(checksums))
+	for name := range checksums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p := filepath.Join(home, filepath.FromSlash(name))
+		sum, err := sha256File(p)
+		switch {
+		case os.IsNotExist(err):
+			r.Problems = append(r.Problems, Problem{Kind: ProblemMissing, Path: p})
+		case err != nil:
+			r.Problems = append(r.Problems, Problem{Kind: ProblemModified, Path: p, Detail: err.Error()})
+		case sum != checksums[name]:
+			r.Problems = append(r.Problems, Problem{Kind: ProblemModified, Path: p, Detail: "sha256 " + sum + " instead of " + checksums[name]})
+		}
+	}
+
+	if problem, ok := verifySymlink(top, home); !ok {
+		r.Problems = append(r.Problems, problem)
+	}
+
+	if serviceUser != nil {
+		problems, err := verifyPermissions(*serviceUser, top, home, symlinkPath(top))
+		if err != nil {
+			return nil, err
+		}
+		r.Problems = append(r.Problems, problems...)
+	}
+	return r, nil
+}
+
+func symlinkPath(top string) string {
+	return filepath.Join(top, paths.BinaryName)
+}
+
+// verifySymlink verifies the binary at the top of the installation points to the running version,
+// a binary which is not a symlink was never relinked by an upgrade and is left alone.
+func verifySymlink(top, home string) (Problem, bool) {
+	link := symlinkPath(top)
+	fi, err := os.Lstat(link)
+	if os.IsNotExist(err) {
+		return Problem{Kind: ProblemMissing, Path: link}, false
+	}
+	if err != nil {
+		return Problem{Kind: ProblemSymlink, Path: link, Detail: err.Error()}, false
+	}
+	if fi.Mode()&os.ModeSymlink == 0 {
+		return Problem{}, true
+	}
+
+	target, err := filepath.EvalSymlinks(link)
+	if err != nil {
+		dest, _ := os.Readlink(link)
+		return Problem{Kind: ProblemSymlink, Path: link, Detail: "broken, points to " + dest}, false
+	}
+	expected := paths.BinaryPath(home, paths.BinaryName)
+	if resolved, err := filepath.EvalSymlinks(expected); err == nil {
+		expected = resolved
+	}
+	if !paths.ArePathsEqual(target, expected) {
+		return Problem{Kind: ProblemSymlink, Path: link, Detail: "points to " + target + " instead of " + expected}, false
+	}
+	return Problem{}, true
+}
+
+func verifyPermissions(serviceUser ServiceUser, top string, roots ...string) ([]Problem, error) {
+	check, err := permissionsChecker(serviceUser, top)
+	if err != nil || check == nil {
+		return nil, err
+	}
+	var problems []Problem
+	for _, root := range roots {
+		err := filepath.Walk(root, func(name string, info fs.FileInfo, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if detail := check(name, info); detail != "" {
+				problems = append(problems, Problem{Kind: ProblemPermissions, Path: name, Detail: detail})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return problems, nil
+}
+
+func repair(r *VerifyReport, downloads string) (*VerifyReport, error) {
+	wanted := map[string]string{}
+	var relink, fix bool
+	for _, p := range r.Problems {
+		switch p.Kind {
+		case ProblemMissing, ProblemModified:
+			if rel, err := filepath.Rel(r.Home, p.Path); err == nil && !strings.HasPrefix(rel, "..") {
+				name := filepath.ToSlash(rel)
+				wanted[name] = r.checksums[name]
+			} else {
+				relink = true
+			}
+		case ProblemSymlink:
+			relink = true
+		case ProblemPermissions:
+			fix = true
+		}
+	}
+
+	var notes []string
+	if len(wanted) > 0 {
+		var err error
+		notes, err = extractFromDownloads(downloads, r.Home, wanted)
+		if err != nil {
+			return nil, err
+		}
+		fix = true
+	}
+
+	link := symlinkPath(r.top)
+	if relink {
+		binary := paths.BinaryPath(r.Home, paths.BinaryName)
+		if _, err := os.Stat(binary); err == nil {
+			tmp := link + ".repair"
+			_ = os.Remove(tmp)
+			if err := os.Symlink(binary, tmp); err != nil {
+				return nil, errors.New(err, "failed to create the agent symlink", errors.TypeFilesystem, errors.M(errors.MetaKeyPath, link))
+			}
+			if err := os.Rename(tmp, link); err != nil {
+				_ = os.Remove(tmp)
+				return nil, errors.New(err, "failed to replace the agent symlink", errors.TypeFilesystem, errors.M(errors.MetaKeyPath, link))
+			}
+		}
+	}
+
+	// the service user is only known when installed, the zero service user runs the service as root
+	if fix && r.serviceUser != nil {
+		if err := fixPermissionsOf(*r.serviceUser, r.top, r.Home, link); err != nil {
+			return nil, errors.New(err, "failed to fix the permissions", errors.TypeFilesystem, errors.M(errors.MetaKeyPath, r.Home))
+		}
+	}
+
+	repaired, err := verify(r.top, r.Home, r.serviceUser)
+	if err != nil {
+		return nil, err
+	}
+	if !repaired.OK() {
+		repaired.Notes = notes
+	}
+	return repaired, nil
+}
+
+// readChecksums reads a manifest, the hashes by path relative to the directory of the manifest.
+func readChecksums(manifest string) (map[string]string, error) {
+	f, err := os.Open(manifest)
+	if os.IsNotExist(err) {
+		return nil, errors.New(err,
+			fmt.Sprintf("missing manifest %s, the running version was packaged without one or the manifest was removed", manifest),
+			errors.TypeFilesystem,
+			errors.M(errors.MetaKeyPath, manifest))
+	}
+	if err != nil {
+		return nil, errors.New(err, fmt.Sprintf("failed to open manifest %s", manifest), errors.TypeFilesystem, errors.M(errors.MetaKeyPath, manifest))
+	}
+	defer f.Close()
+
+	checksums := map[string]string{}
+	s := bufio.NewScanner(f)
+	for line := 1; s.Scan(); line++ {
+		text := strings.TrimSpace(s.Text())
+		if text == "" {
+			continue
+		}
+		parts := strings.SplitN(text, " ", 2)
+		if len(parts) != 2 || len(parts[0]) != sha256.Size*2 {
+			return nil, errors.New(fmt.Sprintf("invalid manifest %s on line %d", manifest, line), errors.TypeConfig, errors.M(errors.MetaKeyPath, manifest))
+		}
+		// the name is prefixed with a space or an asterisk for the text and binary modes
+		name := strings.TrimPrefix(strings.TrimPrefix(parts[1], " "), "*")
+		if name == "" || path.IsAbs(name) || strings.HasPrefix(path.Clean(name), "..") {
+			return nil, errors.New(fmt.Sprintf("invalid path %q in manifest %s on line %d", name, manifest, line), errors.TypeConfig, errors.M(errors.MetaKeyPath, manifest))
+		}
+		checksums[path.Clean(name)] = strings.ToLower(parts[0])
+	}
+	if err := s.Err(); err != nil {
+		return nil, errors.New(err, fmt.Sprintf("failed to read manifest %s", manifest), errors.TypeFilesystem, errors.M(errors.MetaKeyPath, manifest))
+	}
+	return checksums, nil
+}
+
+func sha256File(p string) (string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
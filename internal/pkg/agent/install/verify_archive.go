@@ -0,0 +1,182 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package install
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+)
+
+// extractFromDownloads extracts the wanted files of the home directory from the packages of Elastic
+// Agent cached in the downloads, a file is only written when it matches its hash. The files found
+// are removed from wanted, the notes explain why the others could not be extracted.
+func extractFromDownloads(downloads, home string, wanted map[string]string) ([]string, error) {
+	entries, err := os.ReadDir(downloads)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.New(err, fmt.Sprintf("failed to read the downloads (%s)", downloads), errors.TypeFilesystem, errors.M(errors.MetaKeyPath, downloads))
+	}
+
+	var notes []string
+	found := false
+	prefix := "data/" + filepath.Base(home) + "/"
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, "elastic-agent-") {
+			continue
+		}
+		archive := filepath.Join(downloads, name)
+		var n []string
+		var ok bool
+		switch {
+		case strings.HasSuffix(name, ".tar.gz"):
+			ok, n, err = extractFromTarGz(archive, prefix, home, wanted)
+		case strings.HasSuffix(name, ".zip"):
+			ok, n, err = extractFromZip(archive, prefix, home, wanted)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, errors.New(err, fmt.Sprintf("failed to extract from %s", archive), errors.TypeFilesystem, errors.M(errors.MetaKeyPath, archive))
+		}
+		found = found || ok
+		notes = append(notes, n...)
+		if len(wanted) == 0 {
+			break
+		}
+	}
+	if !found {
+		notes = append(notes, fmt.Sprintf("no package of the running version is cached in %s", downloads))
+	}
+	return notes, nil
+}
+
+// archiveEntry returns the path of an entry of a package relative to the home directory, the
+// entries are prefixed with the name of the package.
+func archiveEntry(name, prefix string) (string, bool) {
+	i := strings.Index(name, "/")
+	if i < 0 {
+		return "", false
+	}
+	rest := name[i+1:]
+	if !strings.HasPrefix(rest, prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(rest, prefix), true
+}
+
+func extractFromTarGz(archive, prefix, home string, wanted map[string]string) (bool, []string, error) {
+	f, err := os.Open(archive)
+	if err != nil {
+		return false, nil, err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return false, nil, err
+	}
+
+	var notes []string
+	found := false
+	tr := tar.NewReader(zr)
+	for len(wanted) > 0 {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return found, notes, err
+		}
+		rel, ok := archiveEntry(h.Name, prefix)
+		if !ok {
+			continue
+		}
+		found = true
+		if sum, ok := wanted[rel]; ok && h.Typeflag == tar.TypeReg {
+			note, err := writeVerified(filepath.Join(home, filepath.FromSlash(rel)), tr, h.FileInfo().Mode().Perm(), sum)
+			if err != nil {
+				return found, notes, err
+			}
+			if note != "" {
+				notes = append(notes, note)
+			}
+			delete(wanted, rel)
+		}
+	}
+	return found, notes, nil
+}
+
+func extractFromZip(archive, prefix, home string, wanted map[string]string) (bool, []string, error) {
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		return false, nil, err
+	}
+	defer r.Close()
+
+	var notes []string
+	found := false
+	for _, f := range r.File {
+		rel, ok := archiveEntry(f.Name, prefix)
+		if !ok {
+			continue
+		}
+		found = true
+		sum, ok := wanted[rel]
+		if !ok || f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return found, notes, err
+		}
+		note, err := writeVerified(filepath.Join(home, filepath.FromSlash(rel)), rc, f.Mode().Perm(), sum)
+		rc.Close()
+		if err != nil {
+			return found, notes, err
+		}
+		if note != "" {
+			notes = append(notes, note)
+		}
+		delete(wanted, rel)
+	}
+	return found, notes, nil
+}
+
+// writeVerified replaces the file with the content read when its hash matches, otherwise it leaves
+// the file untouched and returns a note.
+func writeVerified(p string, r io.Reader, mode os.FileMode, sum string) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(p), 0750); err != nil {
+		return "", err
+	}
+	tmp := p + ".repair"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	//nolint:gosec // the size is bounded by the package downloaded by the agent
+	_, err = io.Copy(io.MultiWriter(f, h), r)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return "", err
+	}
+	if actual := hex.EncodeToString(h.Sum(nil)); actual != sum {
+		_ = os.Remove(tmp)
+		return fmt.Sprintf("%s in the cached package does not match the manifest", p), nil
+	}
+	return "", os.Rename(tmp, p)
+}
@@ -0,0 +1,171 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package install
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
+)
+
+var testVersionFiles = map[string]string{
+	paths.BinaryName:                  "binary",
+	"downloads/filebeat-8.5.0.tar.gz": "filebeat",
+}
+
+// newTestInstall lays out the files of a version, the symlink to its binary and the package of the
+// version in the downloads.
+func newTestInstall(t *testing.T) (string, string, string) {
+	t.Helper()
+	if runtime.GOOS == "darwin" {
+		t.Skip("the binary of the version is in the app bundle on macOS")
+	}
+	top := t.TempDir()
+	home := filepath.Join(top, "data", "elastic-agent-abcdef")
+	downloads := filepath.Join(top, "data", "downloads")
+	require.NoError(t, os.MkdirAll(downloads, 0750))
+
+	var manifest bytes.Buffer
+	names := make([]string, 0, len(testVersionFiles))
+	for name := range testVersionFiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		content := testVersionFiles[name]
+		p := filepath.Join(home, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0750))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0640))
+		sum := sha256.Sum256([]byte(content))
+		fmt.Fprintf(&manifest, "%s  %s\n", hex.EncodeToString(sum[:]), name)
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(home, ChecksumsFile), manifest.Bytes(), 0640))
+	require.NoError(t, os.Symlink(filepath.Join(home, paths.BinaryName), filepath.Join(top, paths.BinaryName)))
+
+	writeTestPackage(t, filepath.Join(downloads, "elastic-agent-8.5.0-linux-x86_64.tar.gz"), "elastic-agent-8.5.0-linux-x86_64/data/elastic-agent-abcdef/", testVersionFiles)
+	return top, home, downloads
+}
+
+func writeTestPackage(t *testing.T, archive, prefix string, files map[string]string) {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: prefix + name, Mode: 0640, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, zw.Close())
+	require.NoError(t, os.WriteFile(archive, buf.Bytes(), 0640))
+}
+
+func TestVerify(t *testing.T) {
+	top, home, _ := newTestInstall(t)
+
+	r, err := verify(top, home, nil)
+	require.NoError(t, err)
+	assert.True(t, r.OK(), "%v", r.Problems)
+	assert.Equal(t, 2, r.Files)
+
+	require.NoError(t, os.Remove(filepath.Join(home, "downloads", "filebeat-8.5.0.tar.gz")))
+	require.NoError(t, os.WriteFile(filepath.Join(home, paths.BinaryName), []byte("tampered"), 0640))
+	r, err = verify(top, home, nil)
+	require.NoError(t, err)
+	require.Len(t, r.Problems, 2)
+	assert.Equal(t, ProblemMissing, r.Problems[0].Kind)
+	assert.Equal(t, filepath.Join(home, "downloads", "filebeat-8.5.0.tar.gz"), r.Problems[0].Path)
+	assert.Equal(t, ProblemModified, r.Problems[1].Kind)
+	assert.Equal(t, filepath.Join(home, paths.BinaryName), r.Problems[1].Path)
+}
+
+func TestVerifySymlink(t *testing.T) {
+	top, home, _ := newTestInstall(t)
+	link := filepath.Join(top, paths.BinaryName)
+
+	require.NoError(t, os.Remove(link))
+	require.NoError(t, os.Symlink(filepath.Join(top, "data", "elastic-agent-123456", paths.BinaryName), link))
+	r, err := verify(top, home, nil)
+	require.NoError(t, err)
+	require.Len(t, r.Problems, 1)
+	assert.Equal(t, ProblemSymlink, r.Problems[0].Kind)
+	assert.Contains(t, r.Problems[0].Detail, "broken")
+
+	r, err = repair(r, t.TempDir())
+	require.NoError(t, err)
+	assert.True(t, r.OK(), "%v", r.Problems)
+}
+
+func TestRepair(t *testing.T) {
+	top, home, downloads := newTestInstall(t)
+
+	require.NoError(t, os.RemoveAll(filepath.Join(home, "downloads")))
+	require.NoError(t, os.WriteFile(filepath.Join(home, paths.BinaryName), []byte("tampered"), 0640))
+	r, err := verify(top, home, nil)
+	require.NoError(t, err)
+	require.Len(t, r.Problems, 2)
+
+	r, err = repair(r, downloads)
+	require.NoError(t, err)
+	assert.True(t, r.OK(), "%v", r.Problems)
+	b, err := os.ReadFile(filepath.Join(home, paths.BinaryName))
+	require.NoError(t, err)
+	assert.Equal(t, "binary", string(b))
+}
+
+func TestRepairFailures(t *testing.T) {
+	t.Run("no package", func(t *testing.T) {
+		top, home, _ := newTestInstall(t)
+		require.NoError(t, os.Remove(filepath.Join(home, paths.BinaryName)))
+		r, err := verify(top, home, nil)
+		require.NoError(t, err)
+
+		empty := t.TempDir()
+		r, err = repair(r, empty)
+		require.NoError(t, err)
+		assert.False(t, r.OK())
+		assert.Equal(t, []string{"no package of the running version is cached in " + empty}, r.Notes)
+	})
+
+	t.Run("package not matching the manifest", func(t *testing.T) {
+		top, home, downloads := newTestInstall(t)
+		require.NoError(t, os.Remove(filepath.Join(home, paths.BinaryName)))
+		writeTestPackage(t, filepath.Join(downloads, "elastic-agent-8.5.0-linux-x86_64.tar.gz"),
+			"elastic-agent-8.5.0-linux-x86_64/data/elastic-agent-abcdef/", map[string]string{paths.BinaryName: "corrupted"})
+		r, err := verify(top, home, nil)
+		require.NoError(t, err)
+
+		r, err = repair(r, downloads)
+		require.NoError(t, err)
+		require.Len(t, r.Problems, 2, "the symlink to the missing binary is broken")
+		assert.Equal(t, ProblemMissing, r.Problems[0].Kind)
+		assert.Equal(t, ProblemSymlink, r.Problems[1].Kind)
+		assert.Equal(t, []string{filepath.Join(home, paths.BinaryName) + " in the cached package does not match the manifest"}, r.Notes)
+		assert.NoFileExists(t, filepath.Join(home, paths.BinaryName)+".repair")
+	})
+}
+
+func TestReadChecksumsInvalidPath(t *testing.T) {
+	manifest := filepath.Join(t.TempDir(), ChecksumsFile)
+	sum := sha256.Sum256(nil)
+	require.NoError(t, os.WriteFile(manifest, []byte(hex.EncodeToString(sum[:])+"  ../../etc/passwd\n"), 0640))
+
+	_, err := readChecksums(manifest)
+	assert.Error(t, err)
+}
@@ -13,6 +13,15 @@ import (
 	"github.com/elastic/elastic-agent/internal/pkg/eql"
 )
 
+const (
+	outputsKey        = "outputs"
+	outputKey         = "output"
+	inputsKey         = "inputs"
+	typeKey           = "type"
+	useOutputKey      = "use_output"
+	defaultOutputName = "default"
+)
+
 // Program represents a program that must be started or must run.
 type Program struct {
 	Spec   Spec
@@ -53,6 +62,10 @@ func Programs(agentInfo transpiler.AgentInfo, singleConfig *transpiler.AST) (map
 		return nil, errors.New(err, errors.TypeConfig, "fail to extract program configuration")
 	}
 
+	if err := validateFanOut(agentInfo, singleConfig); err != nil {
+		return nil, errors.New(err, errors.TypeConfig, "fail to generate program configuration")
+	}
+
 	groupedPrograms := make(map[string][]Program)
 	for k, config := range grouped {
 		programs, err := DetectPrograms(agentInfo, config)
@@ -131,14 +144,60 @@ func KnownProgramNames() []string {
 	return names
 }
 
-func groupByOutputs(single *transpiler.AST) (map[string]*transpiler.AST, error) {
-	const (
-		outputsKey = "outputs"
-		outputKey  = "output"
-		inputsKey  = "inputs"
-		typeKey    = "type"
-	)
+// validateFanOut ensures that the programs running the inputs using multiple outputs support
+// running one instance per output.
+func validateFanOut(agentInfo transpiler.AgentInfo, single *transpiler.AST) error {
+	normMap, err := single.Map()
+	if err != nil {
+		return errors.New(err, "could not read configuration")
+	}
 
+	list, _ := normMap[inputsKey].([]interface{})
+	for _, item := range list {
+		stream, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		names, err := findOutputNames(stream)
+		if err != nil {
+			return err
+		}
+		if len(names) < 2 {
+			continue
+		}
+
+		// detect the programs running the input alone
+		clone := cloneMap(normMap)
+		clone[inputsKey] = []interface{}{stream}
+		ast, err := transpiler.NewAST(clone)
+		if err != nil {
+			return errors.New(err, "could not read configuration")
+		}
+		grouped, err := groupByOutputs(ast)
+		if err != nil {
+			return err
+		}
+		for _, config := range grouped {
+			programs, err := DetectPrograms(agentInfo, config)
+			if err != nil {
+				return err
+			}
+			for _, p := range programs {
+				if !p.Spec.FanOut {
+					return fmt.Errorf(
+						"input %s uses multiple outputs %v but %s can only publish to a single output, define one input per output instead",
+						inputName(stream), names, p.Spec.Name,
+					)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// groupByOutputs splits the configuration by the outputs used by the inputs, each group is run
+// by its own operator. An input using multiple outputs is added to the group of every output.
+func groupByOutputs(single *transpiler.AST) (map[string]*transpiler.AST, error) {
 	if _, found := transpiler.Select(single, outputsKey); !found {
 		return nil, errors.New("invalid configuration missing outputs configuration")
 	}
@@ -219,19 +278,31 @@ func groupByOutputs(single *transpiler.AST) (map[string]*transpiler.AST, error)
 				item,
 			)
 		}
-		targetName := findOutputName(stream)
-
-		// Do we have configuration for that specific outputs if not we fail to load the configuration.
-		config, ok := grouped[targetName]
-		if !ok {
-			return nil, fmt.Errorf("unknown configuration output with name %s", targetName)
+		targetNames, err := findOutputNames(stream)
+		if err != nil {
+			return nil, err
 		}
 
-		streams := config.config[inputsKey].([]map[string]interface{})
-		streams = append(streams, stream)
-
-		config.config[inputsKey] = streams
-		grouped[targetName] = config
+		for _, targetName := range targetNames {
+			// Do we have configuration for that specific outputs if not we fail to load the configuration.
+			config, ok := grouped[targetName]
+			if !ok {
+				return nil, fmt.Errorf("unknown configuration output with name %s", targetName)
+			}
+
+			// Each output receives its own copy of an input using multiple outputs.
+			target := stream
+			if len(targetNames) > 1 {
+				target = cloneMap(stream)
+				target[useOutputKey] = targetName
+			}
+
+			streams := config.config[inputsKey].([]map[string]interface{})
+			streams = append(streams, target)
+
+			config.config[inputsKey] = streams
+			grouped[targetName] = config
+		}
 	}
 
 	transpiled := make(map[string]*transpiler.AST)
@@ -271,18 +342,45 @@ func isEnabled(m map[string]interface{}) (bool, error) {
 	return false, fmt.Errorf("invalid type received for enabled %T and expecting a boolean", enabled)
 }
 
-func findOutputName(m map[string]interface{}) string {
-	const (
-		defaultOutputName = "default"
-		useOutputKey      = "use_output"
-	)
-
+// findOutputNames returns the names of the outputs used by an input, use_output is either the
+// name of an output or a list of names.
+func findOutputNames(m map[string]interface{}) ([]string, error) {
 	output, ok := m[useOutputKey]
 	if !ok {
-		return defaultOutputName
+		return []string{defaultOutputName}, nil
 	}
 
-	return output.(string)
+	switch o := output.(type) {
+	case string:
+		return []string{o}, nil
+	case []interface{}:
+		seen := make(map[string]bool, len(o))
+		names := make([]string, 0, len(o))
+		for _, v := range o {
+			name, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid type received %T for %s and expecting a string", v, useOutputKey)
+			}
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+		if len(names) == 0 {
+			return nil, fmt.Errorf("%s must not be an empty list", useOutputKey)
+		}
+		return names, nil
+	}
+	return nil, fmt.Errorf("invalid type received %T for %s and expecting a string or a list of strings", output, useOutputKey)
+}
+
+// inputName returns the ID of an input, or its type when it has none.
+func inputName(m map[string]interface{}) string {
+	if id, ok := m["id"].(string); ok && id != "" {
+		return id
+	}
+	return fmt.Sprintf("%v", m[typeKey])
 }
 
 func cloneMap(m map[string]interface{}) map[string]interface{} {
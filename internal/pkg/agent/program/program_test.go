@@ -377,6 +377,164 @@ func TestGroupBy(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, 0, len(grouped))
 	})
+
+	t.Run("inputs using multiple outputs are copied to every output", func(t *testing.T) {
+		sConfig := map[string]interface{}{
+			"outputs": map[string]interface{}{
+				"default": map[string]interface{}{
+					"type":  "elasticsearch",
+					"hosts": "xxx",
+				},
+				"archive": map[string]interface{}{
+					"type":  "logstash",
+					"hosts": "yyy",
+				},
+				"disabled": map[string]interface{}{
+					"type":    "elasticsearch",
+					"hosts":   "zzz",
+					"enabled": false,
+				},
+			},
+			"inputs": []map[string]interface{}{
+				{
+					"type":       "log",
+					"use_output": []string{"default", "archive", "default", "disabled"},
+				},
+				{
+					"type":       "system/metrics",
+					"use_output": "archive",
+				},
+			},
+		}
+
+		ast, err := transpiler.NewAST(sConfig)
+		require.NoError(t, err)
+
+		grouped, err := groupByOutputs(ast)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(grouped))
+
+		defaultConfig := transpiler.MustNewAST(map[string]interface{}{
+			"output": map[string]interface{}{
+				"elasticsearch": map[string]interface{}{
+					"hosts": "xxx",
+				},
+			},
+			"inputs": []map[string]interface{}{
+				{
+					"type":       "log",
+					"use_output": "default",
+				},
+			},
+		})
+		require.Equal(t, defaultConfig.Hash(), grouped["default"].Hash())
+
+		archiveConfig := transpiler.MustNewAST(map[string]interface{}{
+			"output": map[string]interface{}{
+				"logstash": map[string]interface{}{
+					"hosts": "yyy",
+				},
+			},
+			"inputs": []map[string]interface{}{
+				{
+					"type":       "log",
+					"use_output": "archive",
+				},
+				{
+					"type":       "system/metrics",
+					"use_output": "archive",
+				},
+			},
+		})
+		require.Equal(t, archiveConfig.Hash(), grouped["archive"].Hash())
+	})
+
+	t.Run("fail when use_output is invalid", func(t *testing.T) {
+		for name, useOutput := range map[string]interface{}{
+			"empty list":   []string{},
+			"unknown name": []string{"default", "unknown"},
+			"not a string": []interface{}{"default", 1},
+			"map":          map[string]interface{}{"default": true},
+		} {
+			t.Run(name, func(t *testing.T) {
+				sConfig := map[string]interface{}{
+					"outputs": map[string]interface{}{
+						"default": map[string]interface{}{
+							"type":  "elasticsearch",
+							"hosts": "xxx",
+						},
+					},
+					"inputs": []map[string]interface{}{
+						{
+							"type":       "log",
+							"use_output": useOutput,
+						},
+					},
+				}
+
+				ast, err := transpiler.NewAST(sConfig)
+				require.NoError(t, err)
+
+				_, err = groupByOutputs(ast)
+				require.Error(t, err)
+			})
+		}
+	})
+}
+
+func TestProgramsFanOut(t *testing.T) {
+	outputs := map[string]interface{}{
+		"default": map[string]interface{}{
+			"type":  "elasticsearch",
+			"hosts": "xxx",
+		},
+		"archive": map[string]interface{}{
+			"type":  "elasticsearch",
+			"hosts": "yyy",
+		},
+	}
+
+	t.Run("one program instance per output", func(t *testing.T) {
+		ast, err := transpiler.NewAST(map[string]interface{}{
+			"outputs": outputs,
+			"inputs": []map[string]interface{}{
+				{
+					"type":       "logfile",
+					"use_output": []string{"default", "archive"},
+					"streams": []map[string]interface{}{
+						{"paths": []string{"/var/log/hello.log"}},
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		programs, err := Programs(&fakeAgentInfo{}, ast)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(programs))
+		for _, name := range []string{"default", "archive"} {
+			require.Equal(t, 1, len(programs[name]), name)
+			assert.Equal(t, "filebeat", programs[name][0].Identifier())
+		}
+	})
+
+	t.Run("fail when the program cannot fan out", func(t *testing.T) {
+		ast, err := transpiler.NewAST(map[string]interface{}{
+			"outputs": outputs,
+			"inputs": []map[string]interface{}{
+				{
+					"id":         "network-traffic",
+					"type":       "packet",
+					"use_output": []string{"default", "archive"},
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		_, err = Programs(&fakeAgentInfo{}, ast)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "input network-traffic uses multiple outputs [default archive] but Packetbeat can only publish to a single output")
+	})
 }
 
 func TestConfiguration(t *testing.T) {
@@ -422,6 +580,11 @@ func TestConfiguration(t *testing.T) {
 				"default": {"filebeat"},
 			},
 		},
+		"proxy_rules": {
+			programs: map[string][]string{
+				"default": {"filebeat"},
+			},
+		},
 		"enabled_false": {
 			programs: map[string][]string{
 				"default": {},
@@ -41,6 +41,7 @@ type Spec struct {
 	When                  string               `yaml:"when"`
 	Constraints           string               `yaml:"constraints"`
 	RestartOnOutputChange bool                 `yaml:"restart_on_output_change,omitempty"`
+	FanOut                bool                 `yaml:"fan_out,omitempty"`
 	ExportedMetrics       []string             `yaml:"exported_metrics,omitempty"`
 	Process               *ProcessSettings     `yaml:"process,omitempty"`
 }
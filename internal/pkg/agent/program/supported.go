@@ -27,7 +27,7 @@ func init() {
 	// internal/spec/metricbeat.yml
 	// internal/spec/osquerybeat.yml
 	// internal/spec/packetbeat.yml
-	unpacked := packer.MustUnpack("eJzcfFmToziX9v33M/r2W16WdHbxRbwXhkwE2Em2cSUC3SHJCbYFdqeNbZiY/z4hsRgwuVRVT887c5ERXTJoOTrnOc9Z6H/7bZ0dV29ZxP5x2K/IP6J9+n8Pq7fT6u3/FSn77f//hlPziL7v4oWvu3PfZSRDjMT7DYaLexuYZ7yUSxQ4CgrsWRg4UgRREqqjv2Wk3MXwvIttwz56S/tgG84xhJMEKf4RwYk0T/08hM4BwYVGLUdGS/tgrKexvZZNe32O7ZRuAlVnJHUZzhaaYx31l0f5u+c70POdV0/SrEW5uzw96Jod76mR+ncEaAUF/jZQZEYtZx+qT/e2eZjZxnQdBvpxHtRnWtsHg0kzkvkHFDzd83XnS32DVX0SqN4pUC57oi7EuG1MYxswCUHp3gbogKAvteOWd3pe63uc6TK1nmZizJjGWJm8hoqWo/Syr+QzOWF1yn8/2kBOyMOufZYAU4oedjFKLwwFi+t4Z2/N2HypFwjKJ5r6r5HiT57jXftb9ae/oWDL72MTKn5JZC0hgIlnf2oey2GVTFmOzt1npJik/hGriAXKka2+X8/T/Il51zq/75xOd+IdlLK7QHUlkvoJ/r6LV6pUywTtseUxwjQlhBe5d27LZRj4Gwq0YkzW9TrSKtDZ9R2UYMtnpOzt6yj0dNHu5UCBX1zPrpcIXlioeieS3cj9Zt1qPk2mli5X57vKpnOXRxuwPEr9DTW1HYLmFgVO+bzWf39d7NUI+PnzWj8gOMkoiHeOdazXcbXZcvq/7YdpHMLJ1gZJQqQjWy3j7Uqp17Skg21QhoFZUsA2RPETkro7pzjHjuowBFjpFGe+hyxSzDRSHrO5Mc0w0DKieglR4my22P3zt/8zRIWcro94FR2HoBD42yjw1giakpG6R/Swi8PemFmgjsHN4dN6Lgzv+sw89Q9h4EoRfDqGkHLDzldQPtjrc/XOy4t4hyi+RINpHsHLsQsUKDUPRHlZz6c9YClR4MnEmEgIymcMTAktJwyn5hoDf/sH5MrgsuEaGJq52AP0c2p0ng+8gsKbNQ5YoVkEJ9k8vTCa+oc/oMfCzM+G8yLFfSXAL0Ku+A/SdiWZvvfIXhZbX/dNzfou0YfnzeP5yZKGayQUensKHBYGi7wCEJbNU/mELAEGOYKTJOTK9SCnIbyUaGnPgsU+IZm3R6m5ofxOUj+h1tOpdzeZw8SlZ94rByes+hKxfClQ3F0IJxm/XwHCi10HFMcN1lg/xQt4OdiPpowAk8R6Rm0gRmWIpNDXCNI9XutqFHg726DV2Yxv2TzejRi8+0oVJkWmViBI2cqa1gbYAJE3IeClMj7LYc/raRak1frz4u7NqcGJKP4BQVfCqn3PDY+fkZx3M7vQdQS80jboHqceWz3sYmEIhfw7AqYU+lpJLYeFUMrFvwOXUUs6rhQvocB8JapXIGge5+kkwdAvCTA3KJAy7gBs4CehEscRnJxpsMi53CI4+ZPvI1BYjoB/14AMtdiZy1qs3d2X5am40BMEvFeUMobFmF5gxWVEdTkgcRA64fQlRqlW2MBXKicq9leiYFEDhZajpZ5i1eZzb8PAS9r7W07Ev7ltzY2b38S9zQ09wekiHspEYEDgnkPosur+WlCrn5FfieWchNNRtBZUbx0hy4niF9TUEpR5jLwvlwesTBQUOFJzj1CW8uYeiHykfJyCb7XcvZL/LnQ2SCTCbcHUDlEg7oDjQtnsqXG4zXu1nu0p8I/Pa705TzMu4bLzrDh7x4FbekJB3NW1Mee9sR+r3ys99ksboBNZ63rH3rndZhG8i4UdGNOsst/FySnuYi9AjGRMirgj5nrCZbfWpQiYXD5lu44l5CMhuIjD4CmmIGE2qPFjqechlPn9NU6NyyknyiWhwH/H/vokRdzTouPsbmwxzmfFNuu+K+Z92MXOw+OsQx7EXnrEA6ACK1Lf1o3rHWCobSm8sI5MUxs8xq29GvqeFHpJgL+JINoLGShaTlO/4LhUy0rojKM0duNxZ4wdQfBk6hR37R4xNN+QryUkc5I+wWl1pbWn3nnBt3sbXOevfztyWWGoKcjXBKZ136lJzHC+r7zT+q0fXae1/cXuc1IIBMkT8uT3HEEtb+/BdBgJ/D1JX+KQ+wXgnnCK9qjQJVzoG6zIzLa8HUk1GSlxpZOPZsbxyzaSHBf6AStugg09jeCFkWKb/8BZKr9iagXXjUBBewz82k7fI5Udore5wYGaQNc2U8uGpuaBQr/syISTSaHzaNodE+u/RpBzEVpEgdch3lJMVFZy3THi3cZ+NLfIeoqpRU82MLfI1NII+gc+1sVSUgii2eB63PCmCI5ifksIA6WS8Txl27kxMp65eRjoZQQ0afx3jjnuHit3A59W/S58W/rt3raOWiVv90Ss5o70lKTa8YawVwFTl+fM+NxDPECZc+J8o88d+xhb2VnLjWY1X+mR+isfcNq7my/767f2XnOiVve4zgfiXVbdV+3zDL0l8g2eVDhbE3OD6/wkjSCVSWoKWxn4zEEgsJtRJWF4w/2fnyPV280M7/f+2Ztz/lJgIIIBkvJ5n0Qw8EOBAWG7nI4FBsrlROEgMOiNDQKDfymSv6dfJ8cDot09Y7wXAFRHnAIIVi05kiojWdeRZLrn4MNBYouCuBPpCsd3b4PLCalcofvPN+QnUDvEcLP7IOswTrbsmqTzyBMrlIOlIKIYnHt32ZCaK2lxGFJYblt0T0EcO4p7wKq/rQIAktf75SAuocB5bUAzUPnzyStJ/QwFybkTcd+Se2606cu9bdBVV77z9S77LCMwlFcFlELOKScQVfDS3aM9mmW4zRr0gecGMOK/JZKPHeWYoPSYVP/NdcTlxGfnFNvZjbGuMrrfrbOhrXpwsiVA2+NsEb8o/oYGzp5a21moyNuKTHhnrLCcGnKJoCuTlEmrgd6j6xxHBHzFyERWbR8qL/f2Q6g+P8Sz1oG1suRBI+Iyj/l5QpU7N3ZqA8RHP0eWfuKO07Y8bjMVqFocgDnYsyOCmtyQPB7wcOB9UqRDS0QAy22gFQj4RRWY0T3lAROcnCikO9vy9hjWAZTl7dBSN6/nFWSlpEA724AWPNBBmX/A1vZKdEAiUUsvn9ffToiTWstfz1P3hJdae3dz5SbBcYoCt+R2MC8fC3dj146Qn0t/bc/1sONnmYXQFQ6DO1eBQWple8/CgVxlZKSXE5K1cxh4u0rGnNQ5agTv7m3DPn0HbE1Ss1gtNbPdm3R9fy50x2NzrkeF1rl36c85n7vg+9QPEZzIPFi219qJWIuTBy8JUb19WGgd2WklFXisHbBCTl39mK0nfGzNZUhFMKyppNzF9vbpPjAvC5JqGUnNo/1YEbfAvLT7Ff/drGFeCMdoCnwScHyUL2R0ndTdIei+CfmpXoLB+b4OClkoa4JgDgNBO+3IJXBZqPqCuNn1c3U2uyUKNidGKUtXS/s6tpaO3IHaLcmYronqcSdfNGO9uy6nMwK0kpp8/67EiXN9x3cIuq+cyKDvgyDTcMbts9kHqPxsS2AMp527u6/5Um7vpH6upMBjJLM7Y/ZxHvhnpDoJAi+DcYcRRZNJ6jJSdGTwjhz7z0/uo2C6boLrCMqM4+jzeqo8PUw5brBA9fMITrhOHfDDbjZf6mwF/E1F7l8agil0/3k9XXf1gFwxrVkjISntE3fDkXHa6se6S9CH9zgun5F9f5o0u5LTQB1kpz/KcNeBVzfwHgYjXD6tXkyFvG588C0htm+SFuMBpT7MyB9tS3AncSYMzfPQnnqVDMuRMejt9dMgU3CW1C96QdzmVlZdm+xXT6RBUHebqRf7CNBe+KB4nIBXc3oD/31hOKVSZHD/XctPlfb2w138VCfPImCWS8Wf8DkajvC65D678Q1uiaBZhEo8Qrz3PHjgvDHhdsSxEqeaZPP1VEfGmfBheRg4m8iS4j++S7GjmAX+HkpOUa3vWMeCwonQ0XmKEgzZYRXUzwo+kXC+Vp3H8H4nmZ8LbFpOjiHcn0hWP1uSbLac3nKL1zVbjcQBHscsWAVVNZ8QeCv44XRf2eFax70qX+YyavnnecoO+At8vokZeBBNwCWh4GUsKT6oJMoJTnnQKovAufO8RDL/NmYAWsZ5LiomBxQghh/kLYKOjIpPK5Rg+XIxv5Dov8rFmNTBIsmbBBcpRuQgKpxmQQFLOUd4XuuCF5NC68q4QAH3644UKJxD1DpvapsImDni/Ky23c/w6v045SZ2+DuSwU5dXGhwbY9TEuPUZyKBAr5xmzsT4OciRkm1Ai31BGfbGKvejnPOOjnP47gEZ26KVecYBt6e29qcB/emy9BS31HLO5Nyd5orImaRwzW/H+mErrznyvVgnShRWElUn5Him/S0Ifl/MtYnVH061rGRGgbeJpr2fyPlU4uDYbCXSfpyFHqceTsKr/66niPFKvfbzuSKle4JZx7Hn/ZO5kt9ixX3DQXX96s5FyfBVa5JREZTv+aE3h7HN+NSWFXCe3NQ6J2v61dcsI6dr88q7nke6HKYuXJ4nVfcWaB0uHE7j+DrfxJFuyYUwf5EAycP4WXbSfSIuOr678upqXbPl3rFaYG2iRS/6D1TaAWC3p7IWomBpnJ9HfxergKXv39NMnFs6cvrQKG8RoHzZwjdt+v78oVaneRU6ksovZzodV+npzKU6yJBN7G+jYLr/XMOGsG7WTcB2dun5W3IVX9kZF3vhsLJfnX9TY2g4Njv+v1BIrWbD6n1TTuvfC3B4PJKgfaKASvpw+4m0d8WebrxNuB4JhLLh6jCvxwr2rmbEEZBskGBzn/DdlbjWVAVoVvMNwT+nkmqbVDgllh1SptJwz1KWK6KR31+UneB9AtzP7t/zs0ZybzXMPXTOslYJbwrfzrrFUXAWPKz4R/tWWc/UwAb7jcKPMb9wHC8t+ZZ8MxjGEwHfHyY8L3hf52i08vXikxAToils26uqxq/nFAdE3Rt9qoPHXyq/Ul3PdsYYNVmN7CXZp262Kp6G8L3B9zzO/Nw+7m3LX9Lpv298LXnincKlSM/RzzAk2YegTWVDbuvhOMzj8+5TvXsunP+K8bc25Y74e90sOtTrk0Dl7U8Yfr19wYF4i7+vNMV1BRWPiio/0Ah6t2i/d8c//yFRZEOj+uewb4tWmWdvVS+sspBvx/rvKGAlZxzoMWuz+2AnyDg81iqilMzukPw7r4f51zXaIswvxjztEUIsUf6FkL0Fi7JwTao4JUUmGVkkL0R//O2EPHKVqvjeOeiV8WB8UsTQ9b1iDo+PLax5bUmsZ4v5apLzZCPWPEY9wf97saq66/tHvxKrj/ud5nd8MBPdPG93PhIIbIb/x47sfNfsz5o6zKf7qFpIPqwcFvb7zV3Xu2z2Qu3H+5n3snLx7DQUwx8Ro1J022aN3Ndm8taXYuD1o+0/ulEA4/HAk0toLErUbTFo/IRhTrc6kHWdJFOzljhscxWFGNH1mrsPH8y2mevdYra/yPgp2HgH6g13vV4W4+42ccOq6406Fi8kZPo5BzvVMwbveH2zfUFp+YhUPUTyRafrV0S5XzTzdpwi/lmmg91sssdO7Kq9tvub8gfO3jXzQd1/saKvf0/qSme37xbxwsnorZ60eE27zf6vLvPDxoXPvO/n/vSaddfjTZz/NIcy4koxhNVT0Ll5afO9YF//pn9XZuy4p/q3h3o1nQsR9g8O8SHH/WVwj5el9v4j/X0bAMzR8ZfXRccqfclq+htrGt3CfyEZP3ifNQb6zjCrxbmfyIh9yOfAXyp6/bGSTss5ICjJIyP/3xCrC4QB0lLUusujXGHKmuigBmoDkOKf9dt7R9vTXfY81pfEZUDbcLExRbf8tl5RJEH3UkfEduPu5o6htnczRVob1vum0At89lXiurvAfP7gPy58fWBudOJM2p0v05KZ8vpxn7s6I6hn3B6mdiWKxOLO0KvtB/976OENF0d39ZkxPq+Q18iKdvU2lh/SFN/nKLUqfLxj2Wadpc9BtLnKe4mjZ55DAf6QZR2P2+p+cEPcni4Tfc4JTkWIfFZQ8BfU0iG82ahrJ1R4Gz4vH8svd+/v/gvL1v28IXU+BEFXhFBtw43ml7CCafmIpUwdi7jXTToy5qkPg8tCmpqJ8y0Wtu811BJEpxSbpmV1mdt2uKdVp4v9HjXd1+nSr7WxvNRmqhqn2hCxrpVqP3Q6cM+ukFqrtfT3EnzfJLScYe90f9SKb4ILv57pfqAf0ctJ+E6IeRkaqKv+FoqGFDOTpqvv8fB+OCjtNFyxHtea6QEIdqxzLt8VmiNbZbO9OPvLf6rS9CfpaLrMpDAyffb0zp3cNuTXnAbD9bxP14eLsLD/7G+e5stb2VUzSNa5u5tw+uygdt0r9FLE/WZw7Dn9ZouO4bwyALFLEhqTkb1uMWJAQWvdKXd8w/2q3feW/xACnCYUvjxtOFP97p3voH5hTlGwqMvnMHyS+RrnfDiL00f9vzmx6WEnk/Mx3xDW4o+/0gqsu9rG7l82Ffdk0HCQugxPmd1/tG2ygE/+Bfoi94d/sxXb8UY/VPdC4V+sep3RZyIasoocCbDzogf6Ir4cer3I13RX+qGcH6+c7r+RBEP5fNh96NWksBnJNvOfq7Lr3mfVd3NX+3uE12j/okGi3v74fHc7fR+J1DsdkQ0nxsOaFvT4d1R/wZefK1DWVqT43RVdIpgwIQMIygyexw6Uu7a3+/O+IXPYHqfXQ4zyNy06/sbVBXGO6+//HnG4Fvrv73T+pNvpm87tPodVzygTDTbeNRsg5TPD2E2GjTuI7JdjaVsXoC5iRRf6gWNFnc2R9Z8tNIGjQU5elXd5JOAkT9z8+yH31IIqRSyWfWbf9xD1X/23UAxe+9jZdI/88+nbX4xPdKvPb+bGjmH0H1DIznC/2HfHHz2fw+Y/fbv/+s/AgAA//8EnQoK")
+	unpacked := packer.MustUnpack("eJzcXFmTq0pyfvfPuK9je1hafQ+OmAdBNwVIoo9QN0u9QSEBUoE0LZAEDv93R7IJEL2e44kZPyjuaZZasnL58svk/vcfUZKuXxOX/vV4WJO/uof4P47r19P69T/zmP7xX394sZzi532wNEV9buqUJJiS4LD1rOW9iuSzt2ILbGscttWZY2uMa+HQ4UfvJaTYB9Z5H6iSmhor9ahKWupYkxBzZoqtCTOPzcyxtCO2loKvaCxeqUcpmgZqxMpqdA7U2N/avEhJrFMvWQqakoovj+yzYWqWYWobgxGUZbG/LB5EQQ0OvhSbdwQJuY/Mnc2x1Fe0g8Mv7lX5OFOlaeTYYjq36z1F6lGizIwk5hHbi3uYd74Stx4vTmzeONnc5UD4ZXldlaaBiiiDLeZeRfiILZNpryvG6SkSD14isr6ygHkC+HncZONwQobjy6GSz+Tk8VO4n6qIDcnDvn2WIJlxH/YBji8U28vr9c7ammvzlZhjiz35sblxOXPyFIB8r/dVSXzF9g7OY+twZkFYISSIls9+axxFo5VMaYbP3WeYgMRm6vGY2lxK18/X/TS/ctxIhPPO/Cm8ywQ4pnc2rzMkNkPveR+seaaWCT54ikEJFTjHurC9fSs69ZC59ZGQj8m6nodZ2yK9voNDTzEpKXrrSks9XVZrca3J37GlMzYv5h4fTp4i8c9NfQ/H9OgjM7/KRSywdaEOb5xIcnMmN2uq5hJYXxHZau/NdaZ7zqmKaObG5taXhT225B22taJax4F3kZk9ReIRW5PER8FeU9J6Hl2YraZ/UR+mgWNNdioKQ8KkdL0KdmuunlNhjqrkUw/JhY/olnBmSGJ9r+XnQOM1ihEttPwM601cTo5d7jGZS9PEQ0JCeCMkXJDMlvu//fHvQ4+R+VHqrd106DBsc+faRoQtmZFiPcUP+8DpXZNz3DHGubWI5qVRXp+Zx+bRsXXGtRapY/lg9NnaYo9qVC40mr+8lO8QzmR8e5q51iXtOhEcy0fCvUTzac/pFNg2WCJNGGyxZw/JDF5NqBfLkYfM3U8LFEWnwzk8S87KNVhm5kud520j962bOY4e5yeuNUnm8YX6sXn8aRnUScxkOC7m9A1BZu5wZuY/MLs1I5vGI31Z7kzRlAXlmfEfnraP54XCDOcIfcs4+Eijjr3MKudCk3nMnrBSOooMW5PQASfywMaOdSnwSp3Zy0NIEuOAY3nrw5nEZugri1PvbBKNloeeGBtwXB5vMkQxGZvT9441SfDzPigd9HLfcZjjxixFi2BpXY7qo8xiRJlyPqk2WqkyUpKLEbb8gxeJvGsbe1Xyq71JP5J5sB9xBvrG5yjjykKOLZ+ulWltgI2TMiYEvYCOBKqi0adomthxNf88v3vVasdFOPMIBu/x6j0YHuyRnPczNRdFjIxClfyDFxt0/bAPSkPI2T8xkhnHFApf0ahjMVn5t61TX2HSNWeEPpI3hDdybMnpPJ6EnmUWBMlbbDOJqhgnFZmhwwWBa03Ovr3MpKjjeDiaYWTeNU7GV+gZZF3O3V2XYvBeLoYYGRscU+qV18Tc43RKeB0cUjBfiScvfglwLOQqMrkqwJbrK7C9rB2FkOGVGHu8CmPvHNsI2/NbTcq/wbbm0s298tzmkhh68TIYyqQEDbZ+diydVufXOrX6GXZDFO1UBiROaJ3qbZCkGeHM3JeFECcGJW/L5cHjJhy2NaY5R4tlsuYcCJv6cN1HP2q5GwXcB9ljO2QI2IIsHF27PAPwC0WzpiYYN+/VenbwkZk+RWKzn+Y64xWdZ4N9P7grYuijoKtrY4F9qz5We6j02CxUhE8kEsWOvYPdJq51F5R2IE2Tyn6XJy2/CwwbU5JQxkU0w6AnILtIZFwkg3zqtU63qlLKh8HWMnDsReCjkKqo9h8rMXMsFs6vCWogp4xwl9BH5hv21wcwpf4uO8HuxhaDbJbvku675bgP+0B7eKyDZwksyrU0Z1L+EM49junbunQ9A88Sdr51aQAAyDRW0WPQ2qskHkguFgSZW9fCh1IGnJD5sZn7UiurUmc0rrEbo3iKRE8rwR/ra/ldu0bPkl+xKYQk0cI++Gl1pbWn3n7Rj3sVXcev76XgtzxL4LAplD5Nim6A1HC8z7zTxq0vvNO3/eX+Y8CISgAYlLaMKONaQlaPu1VljRLbPJD4JXAgLiD95MX4gHOR8XJx63EsVRVjT2KBxVxQ6eSjnID/UqUw83Lx6HF66Eli7FoXSvJd9oW9VHFFFnLQDZvDBw+ZhTQaYxp77AC97Y0fqMF1bTO1bPxYPvqW2fqQGmiWOo+v4K+aE5kb1wIs4ueubUyua2YCwtMCdEcK9lv1Ud5hZRH4in9SkbzDshC7lnmEa11fSvJp2vHrQYObXGvU57eA0OYqGc9juptLI9cTPXNssXCRwIzfB5+jHzzubhDTqvtlbIt/3KtKKlTy1k9Eac5IjEkspDdgvkqmujhnJkW3/gAn2gnwRh879n2sj35cz8lezKToTcB/1YXtfjZMOK4JBNPHs9E4yJ+vAJPAOQo5XpYxXPPiA3X4ZeAiM8TIzEkOeMg4gd7jlXjybQOwQdBgOt/WQh/Rkxfdzcrkonzf2LTvFzXWSvw9tu7uVXShXuwzrgQJQBd3TVKPM1jQ+c1qF/yMpmcVyRmWxL1j63Ns7/aaktbzGwIkANi6hIQ3Dg6vU8fWtq5EyqS8kzQEGpeGOE7D6t+XE+Z18LF7Ld/NbhIGQveZP5YwcJeTbw0Sht61QcLwTwX+D/7nQfMAgHf3GBxKx1QrWamI6xY0QbBLhZqZuCqBNdlhO6gNCZQVQMMLKMEJ84vZ8PkGFNl8BzBu9+8wFeMgTK3BO2SkHueDEwXlFj107p2lioZgRqOYo5mq+AcfBYHG6UePN3eVgpKsXi84dwbb2qZxpjYPz4cbEpsJtsPz1bmOgH4AVfHLvSr5a6ezlnm0Tz5iEYbyqhxoKefYawxtKFNFO62VXfd86vWr92PzXZ1I4zT6zqpxMI0OScE/JPv/miGvE/+wj5KhHRvWZEeQcPCSZfDCmVvf1g6+sps5HLurAIhx9jia+RJbYEtnSUyZ9SApxdcxUoxMTkpK5vHgcC/36oPDPz0EszbotbKERBODzAPYj8NDQKSnNql8NDOsiCcItqpigD1V4EKBBBACBE2xJbANMIQkCcDfgmOO9Rlu4ZxUJOTgdKtkzj/4kGRZk5Nv+XtVMQ6eVSddlTOXr/stAU7hI+GsIj+H5Agn5tFTWgCzVVHI+IpYPEU/ThiAsGJG81g/eSuhPbs5d0OKnFxbL8BG5sVjrm/VJmgxHi9u2n097IMFx8wcC94VMgjIoFs2D3ZTgpVURVcZSfHlhFnh7NjGvtJXSAI13oUAI6mnZ0QjEsv5eiXI7dqY6/vzUncMOgc9yoXOuTN/n8PYOaxTPLrWhIUEW42EE1GWJ6MJOLnQkZ1Q+KWvFo4eR05d/ZhFE7gWgQx9ABlI4IFpVneLe1u+LEksJCSWU/WxAnu2fGnXW/67mUO+ECA9fGQSG3wneyGj88T6Hlv6ayk/3gg9dL6vE0nqsALYPR0mj2rckYutU4c3S7Cn1s/V7Hitx0ygAiMd03i9qs9SYgI1YlIPCUnzznw1jQhvAEOYN9d6Z11MZwQJhS/D+nUGwHZ9xnfY0oE4OOLnQWIqaeP22awDVTG41tdUlbR27O665iu2PZP6ucJHBiWJ2rmmpnPbPGNeCzF6GVzXKOEEFph+kndk8IYc+89P7l17Wo8nMq7FUvCjT9GUWzxMwW9Qmzcz15qATh29hz2AKrpG5rZKCF7q/Yml7j9F06irB+Tq05o5QhL7fbAvaawXt/pRPzd+juPyGVn3h0TblaW2+QHb/Uas6yZr3WR9mMCAfFq9mO5n1/imbYbPvkGqdZOwFj/Vex0kXJCUlqRquSfPks9De+pVRhStBLRPwecT0xLPxGYuBZ8H+705JWaQCI4C/xzb+FDGoGA/87mQelsg88wM88Z+Jhl/VmMag/jdBe+1/HjmoD7cBYuacHORXKw4cwJjNBhhswLw3cQGvcCWnDtcMMLiH7YeNwFMGYI/Al/pxQKjQrLAa6yXlDEsK8G+wgQ/n5lA4+Tce3YYLa/m15Q0961JqaPzGIeeRY9ru362TAzC0JdItR/J+JMkZlb6ptUkdazDiST1swVJZqvpLbbYRHQ9kiMY4LOskgBr8ETpb0vsOD1UdhiJXq9qmAA5a57nMT16n8D6TT4BiTdBQHa9jBHpg8okG3oxJLpsWaToPM+QxLyZA+wfMDDOJ0dsY+o9sDtsaSzOP6x4otXLRf5EceAqF2kC5x27FskaUozkI3IoK6Zy7iMaA0Z4ioCUlxmSC10Z59iGuK4xNgcYotZ5Wdi6kEgCPqttF8dydf+hzXva3Kb0H3bY5iLXxLslihvifTae03ST99oPmsKZxMIW23oBvqf2LSePCuX+PURLbAl+H5frl2PwlVI0mstcSdjGP8RwXmKGLXNHKrJq79jiGVt3QMbGHq9RSKbXkMCjEAjXtshREaf4RGK2SuSTRSZFC8DH9Ncx3zLX87tf9cmdaumIP0YyT3J26yGBxYof+kjfD+4Vi3q8+UoM14mZ4xULha/MRyHr1OsoK+owBsInH2Jqsmv9KZAdPpIPXtycCYxtnBwubXNOiEPlmFKlk9fn6gIHrzMY0ez2urEl05sxWKxM2/khdmL7huRMHH6aEsWMCG82cTytz2zTwQXtOK5iMAQdCo+7ksNuDNhkEnqoEz+qKmv7N/iE6/iAj4Wdx+mv2L7iH3hmXudrNg95sw84a3BfnxDA3NfzgGLkQF4X1rM0SuJD6HDH6/u2zHTiV4ZtI/aQwF/f+1HoW7Mi5K7P5dgyDo0+zVciYL2Ddz2zYm3rvXX6SD+3YyrmrnM2gJvurvegAFfuBfQ7dezpAJsNsMwtFqg6ODj56MkC47FVkae5X/uW020h58OujfoZka6RTomyvFflY9T4M5KXPrj1saUvT3oFusi+6tjbXSaAmWyDes/7Psl7/u76Wy4nwhb4o5d3CdUB2d0rbrV7Pdf4WTEgp2WqThljU67bXrxxXvpgvW2R5P+ioFbiSceaFGWxv1+cadbV7xYB2VnymfQ6PurrELvBV9QFyO47qtT3T1U8afS+/g181VPUt5fmV+KCZHkCWwH/7PDTN8YxdwRirGU0NlX/yrlPGAlblzMB9w/8Sf2DmJpDId04EFYowN7BzzxFfbtufj0fU+zLIkXPd31chGEJZ7b86D+iEPVegfrb83cK5L9eQGp561qvB/mbxLQNFx9zkwwUDs5tEbIplA5spCqIQL5r1txzD38Blrpb17nlLZ852v3UFLS+VBD5ekHjOsc8/j35EYmhcLRIKgztvzoWfnVWBDqmIHYCT1C4EjlIwd9uO6A2dL1Ox7smDcgZ7JIHzaGw3NQ16lwybfPQa20jmq/YqkNOYqFYQ1XKDDorq47DtnPxMzWDoN/FdsMPfIAR3+LYhzhzkCtf9/e75h/ayTtraOzlXduu/UPri+p1NmsBjg/spH2+zw0EVi7GHjKpL02aTtesGWse3+ha0MT8Ttxpin1NTaGxK6g3MN6ofMqOQK/Vg6TWg2hy9jjAKbvMtZZjc9Wxd5EtpPbZay2jHMfYYGTGjm0efWXx1r7bNdX6NlzH3uP1xv4bm7+RU9lFOu2fWa03WaM3YN+Qo3mxfLR58USS5UdzF4Q7t880jSiNz51vp9lQJ7v8UkdW1Xrb9YldG+sX8LvcUefXO6+ebjY/pinO37xb5xgnwrd60cG+b+OeN9f5Tmzr2oFv67TN6aff6u4djdG/NMZqUmJTwouhw718a19vNu19b4/1OGzR6NAbjQSzt3Tkbd1obeCL9cHBOriQOhbwyYva/4/WDysdV/qxs8tPNnbzC80C4zEzXLuvY13DK2RCIbDXBOD2rnUC5WcbAL5B7nWItA8/UfhU1+9NEAcS0yjmcFC2xnyVXOsmOG+QaeMBlxXKYqgNJAJn3rWJ1CgR1HTsiWvCgyMOKZBRJP+Rza4O501w+x4h9d57PcNtzqY11BtyqgkwJdFaz/suQG4Magzofsdgb9ZeracN7NdnYeyUru1yHDoObn8d0M5W06362NErCTqBLxNV0VmiQBA1CvXRfB41zHidvkZkxDKfLZMhMd3Wmlp/AFR/VMPVlPz4Rz5Ny83BQ8zHVHpD1ydAH4hHxzboJ9p6PrTSPq1+OWEOqCWSeWUafRYwMiPfIsNxE4cVztjWtkAv/lwZfz6/mC8vO/rwCQo+xbaRu5ZepypNn+O1B2tsX9KbnqIvaxKbkJZAbzRQ3I0b3jhcGHqxT4EyKC0CyjPl/eWI9xgvJb7dXtTS5M3eikHa+Hv7u2udg578Ks2HfvqXz/QM3tJMH1FHjQzsf0oq8YClfy1K8at99e1+z/v+GgfXIR3rwKHZV1rFbiOcmAPqsKO719a++MVOit6JUP9Yquc7lPdv6tMX17MHYfmzKr39ZR4dD7cyGvTs9+iB39K3X9qsy5kbx9Zyx96N6nHrJ0Z765s167SV2btQvzmnznvTL1CNA8TyDXryC2scfm/UfEew/IUxOt8sLT+/h0HqOPsIhX2NpuzHvvdKFo0u1KXvga2Ufq2Nv7+H8uyv7SY1i0aQX/TlVG2AJZo+9l9q/ZypXerzqx9+7o9/z9av+RhU5PWLb5n5ut+pcSK8zGJbm9QQsu3W+EKnxtdh4le6uD/VoaF9v9O7/tTSG8rn3Y5MoSC2SUmym32v87B5n5aq8+mOw7KT1axg48PjWQr+pbo3fi1Ed0PTSHhuz++T1ZjrXr/3mck7nWajXWW/gbWBykdV/cvrzz3e6QwHOAjhw7FoBqzxoOOr/M58s5qGP1fTZFEEnHYe+Y784JLdeowSekEy1C8biqRKPBUxhL6Q5qOgWpsDKyepUdVtPkg64ZmbZ9/9JqTsj89Zufwv936/V//ZN5PN5K2PsUl/z9+nhX6RfukDwzepl7NjQZ9Ky1V/YBH/L7+d+Oj/nDD743/+7X8HAODMZpc=")
 	SupportedMap = make(map[string]Spec)
 
 	for f, v := range unpacked {
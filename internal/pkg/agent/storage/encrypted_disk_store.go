@@ -75,13 +75,16 @@ func (d *EncryptedDiskStore) Exists() (bool, error) {
 	return true, nil
 }
 
+// ensureKey loads the agent key, it is reloaded after the key is rotated.
+// Must be called with keyMx held.
 func (d *EncryptedDiskStore) ensureKey() error {
-	if d.key == nil {
+	if d.key == nil || d.keyGen != keyGeneration {
 		key, err := secret.GetAgentSecret(secret.WithVaultPath(d.vaultPath))
 		if err != nil {
 			return fmt.Errorf("could not get agent key: %w", err)
 		}
 		d.key = key.Value
+		d.keyGen = keyGeneration
 	}
 	return nil
 }
@@ -89,6 +92,9 @@ func (d *EncryptedDiskStore) ensureKey() error {
 // Save will write the encrypted storage to disk.
 // Specifically it will write to a .tmp file then rotate the file to the target name to ensure that an error does not corrupt the previously written file.
 func (d *EncryptedDiskStore) Save(in io.Reader) error {
+	keyMx.RLock()
+	defer keyMx.RUnlock()
+
 	// Ensure has agent key
 	err := d.ensureKey()
 	if err != nil {
@@ -159,6 +165,9 @@ func (d *EncryptedDiskStore) Save(in io.Reader) error {
 
 // Load returns an io.ReadCloser for the target.
 func (d *EncryptedDiskStore) Load() (rc io.ReadCloser, err error) {
+	keyMx.RLock()
+	defer keyMx.RUnlock()
+
 	fd, err := os.OpenFile(d.target, os.O_RDONLY, perms)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
@@ -0,0 +1,87 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package storage
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/secret"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/vault"
+	"github.com/elastic/elastic-agent/internal/pkg/crypto"
+)
+
+var (
+	// keyMx is held by the encrypted disk stores while they use the agent key and exclusively
+	// while the key is rotated.
+	keyMx sync.RWMutex
+	// keyGeneration is incremented every time the agent key is rotated, the encrypted disk
+	// stores reload the key when it changes.
+	keyGeneration uint64
+)
+
+// RotateKey rotates the seed of the vault and the agent key and re-encrypts the encrypted disk
+// store targets with the new key. The targets are replaced only once the new seed is committed,
+// an interrupted rotation is rolled back or completed the next time the vault is opened.
+func RotateKey(targets []string, opts ...secret.OptionFunc) error {
+	if encryptionDisabled {
+		return nil
+	}
+
+	keyMx.Lock()
+	defer keyMx.Unlock()
+
+	err := secret.RotateAgentSecret(func(r *vault.Rotation, prev, next secret.Secret) error {
+		for _, target := range targets {
+			if err := reencrypt(r, target, prev.Value, next.Value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, opts...)
+	if err == nil || errors.Is(err, vault.ErrRotationIncomplete) {
+		// the new key is committed, the stores must not use the previous key anymore
+		keyGeneration++
+	}
+	return err
+}
+
+func reencrypt(r *vault.Rotation, target string, prevKey, nextKey []byte) error {
+	fd, err := os.Open(target)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return errors.New(err,
+			fmt.Sprintf("could not open %s", target),
+			errors.TypeFilesystem,
+			errors.M(errors.MetaKeyPath, target))
+	}
+	defer fd.Close()
+
+	reader, err := crypto.NewReaderWithDefaults(fd, prevKey)
+	if err != nil {
+		return err
+	}
+
+	err = r.ReplaceFile(target, perms, func(w io.Writer) error {
+		writer, err := crypto.NewWriterWithDefaults(w, nextKey)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(writer, reader)
+		return err
+	})
+	if err != nil {
+		return errors.New(err,
+			fmt.Sprintf("could not re-encrypt %s", target),
+			errors.TypeFilesystem,
+			errors.M(errors.MetaKeyPath, target))
+	}
+	return nil
+}
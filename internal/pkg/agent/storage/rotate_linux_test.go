@@ -0,0 +1,84 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+//go:build linux
+// +build linux
+
+package storage
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/secret"
+)
+
+func TestRotateKey(t *testing.T) {
+	dir := t.TempDir()
+	vaultPath := filepath.Join(dir, vaultDir)
+	targets := []string{filepath.Join(dir, "fleet.enc"), filepath.Join(dir, "state.enc")}
+
+	if err := secret.CreateAgentSecret(secret.WithVaultPath(vaultPath)); err != nil {
+		t.Fatal(err)
+	}
+	prevSecret, err := secret.GetAgentSecret(secret.WithVaultPath(vaultPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stores := make([]Storage, 0, len(targets))
+	for _, target := range targets {
+		s := NewEncryptedDiskStore(target, WithVaultPath(vaultPath))
+		if err := s.Save(bytes.NewBufferString("content of " + filepath.Base(target))); err != nil {
+			t.Fatal(err)
+		}
+		stores = append(stores, s)
+	}
+	prevContent, err := ioutil.ReadFile(targets[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a missing target is skipped
+	if err := RotateKey(append(targets, filepath.Join(dir, "missing.enc")), secret.WithVaultPath(vaultPath)); err != nil {
+		t.Fatal(err)
+	}
+
+	nextSecret, err := secret.GetAgentSecret(secret.WithVaultPath(vaultPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(prevSecret.Value, nextSecret.Value) {
+		t.Fatal("agent secret was not rotated")
+	}
+	content, err := ioutil.ReadFile(targets[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(prevContent, content) {
+		t.Fatal("target was not re-encrypted")
+	}
+
+	// existing stores reload the key, new stores use the new key
+	for i, target := range targets {
+		for _, s := range []Storage{stores[i], NewEncryptedDiskStore(target, WithVaultPath(vaultPath))} {
+			r, err := s.Load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := ioutil.ReadAll(r)
+			r.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff("content of "+filepath.Base(target), string(b)); diff != "" {
+				t.Fatal(diff)
+			}
+		}
+	}
+}
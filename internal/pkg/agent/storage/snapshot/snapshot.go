@@ -0,0 +1,339 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+// Package snapshot exports the identity and the state of an agent in a single archive encrypted
+// with a password and restores them on another host, encrypted with its own agent secret.
+package snapshot
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/storage"
+	"github.com/elastic/elastic-agent/internal/pkg/config"
+	"github.com/elastic/elastic-agent/internal/pkg/crypto"
+)
+
+const (
+	// Version is the version of the format of the snapshots.
+	Version = 1
+
+	manifestName = "manifest.json"
+	// fleetName is the name of the file holding the agent ID.
+	fleetName = "fleet.yml"
+	// maxFileSize limits the size of the files read from a snapshot.
+	maxFileSize = 64 * 1024 * 1024
+	// restoreSuffix is appended to the files written by a restore until they replace their target.
+	restoreSuffix = ".import"
+)
+
+// File is a file of the agent saved in a snapshot.
+type File struct {
+	// Name is the name of the file in the snapshot.
+	Name string
+	// Path is the path of the file on disk.
+	Path string
+	// Encrypted is true when the file is encrypted with the agent secret on disk.
+	Encrypted bool
+}
+
+// AgentFiles returns the files holding the identity and the state of the agent.
+func AgentFiles() []File {
+	return []File{
+		{Name: "elastic-agent.yml", Path: paths.ConfigFile()},
+		{Name: fleetName, Path: paths.AgentConfigFile(), Encrypted: true},
+		{Name: "state.yml", Path: paths.AgentStateStoreFile(), Encrypted: true},
+	}
+}
+
+// Manifest describes the content of a snapshot.
+type Manifest struct {
+	Version   int            `json:"version"`
+	AgentID   string         `json:"agent_id,omitempty"`
+	Hostname  string         `json:"hostname,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	Files     []ManifestFile `json:"files"`
+}
+
+// ManifestFile describes a file of a snapshot.
+type ManifestFile struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Snapshot is a decrypted and validated snapshot.
+type Snapshot struct {
+	Manifest Manifest
+	contents map[string][]byte
+}
+
+// Export writes a snapshot of the files to w, encrypted with the password. The files missing on
+// disk are not part of the snapshot.
+func Export(w io.Writer, password []byte, files []File, opts ...storage.OptionFunc) (*Manifest, error) {
+	if len(password) == 0 {
+		return nil, errors.New("snapshot password cannot be empty", errors.TypeConfig)
+	}
+
+	contents := make(map[string][]byte, len(files))
+	manifest := &Manifest{
+		Version:   Version,
+		CreatedAt: time.Now().UTC(),
+	}
+	manifest.Hostname, _ = os.Hostname()
+	for _, f := range files {
+		content, ok, err := load(f, opts...)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		contents[f.Name] = content
+		manifest.Files = append(manifest.Files, describe(f.Name, content))
+	}
+	if content, ok := contents[fleetName]; ok {
+		manifest.AgentID = agentID(content)
+	}
+
+	cw, err := crypto.NewWriterWithDefaults(w, password)
+	if err != nil {
+		return nil, err
+	}
+	// buffered to encrypt the archive in blocks rather than in every small write of the archive
+	bw := bufio.NewWriterSize(cw, 32*1024)
+	gw := gzip.NewWriter(bw)
+	tw := tar.NewWriter(gw)
+
+	b, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeEntry(tw, manifestName, b); err != nil {
+		return nil, err
+	}
+	for _, f := range manifest.Files {
+		if err := writeEntry(tw, f.Name, contents[f.Name]); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+	if err := bw.Flush(); err != nil {
+		return nil, errors.New(err, "could not write snapshot", errors.TypeFilesystem)
+	}
+	return manifest, nil
+}
+
+// Read decrypts a snapshot with the password and validates its content against its manifest.
+func Read(r io.Reader, password []byte) (*Snapshot, error) {
+	cr, err := crypto.NewReaderWithDefaults(r, password)
+	if err != nil {
+		return nil, err
+	}
+	gr, err := gzip.NewReader(cr)
+	if err != nil {
+		return nil, errors.New(err, "could not decrypt snapshot, check the password", errors.TypeSecurity)
+	}
+	tr := tar.NewReader(gr)
+
+	s := &Snapshot{contents: map[string][]byte{}}
+	var manifest []byte
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.New(err, "could not read snapshot", errors.TypeSecurity)
+		}
+		if hdr.Typeflag != tar.TypeReg || hdr.Size > maxFileSize {
+			return nil, errors.New(fmt.Sprintf("invalid snapshot entry %s", hdr.Name), errors.TypeSecurity)
+		}
+		b, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, errors.New(err, "could not read snapshot", errors.TypeSecurity)
+		}
+		if hdr.Name == manifestName {
+			manifest = b
+			continue
+		}
+		s.contents[hdr.Name] = b
+	}
+
+	if manifest == nil {
+		return nil, errors.New("snapshot has no manifest", errors.TypeSecurity)
+	}
+	if err := json.Unmarshal(manifest, &s.Manifest); err != nil {
+		return nil, errors.New(err, "could not parse snapshot manifest", errors.TypeSecurity)
+	}
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Snapshot) validate() error {
+	if s.Manifest.Version != Version {
+		return errors.New(fmt.Sprintf("unsupported snapshot version %d, expected %d", s.Manifest.Version, Version), errors.TypeConfig)
+	}
+	if len(s.Manifest.Files) != len(s.contents) {
+		return errors.New("snapshot files do not match its manifest", errors.TypeSecurity)
+	}
+	for _, f := range s.Manifest.Files {
+		content, ok := s.contents[f.Name]
+		if !ok {
+			return errors.New(fmt.Sprintf("snapshot is missing %s", f.Name), errors.TypeSecurity)
+		}
+		if describe(f.Name, content) != f {
+			return errors.New(fmt.Sprintf("snapshot file %s does not match its checksum", f.Name), errors.TypeSecurity)
+		}
+	}
+	return nil
+}
+
+// Restore writes the files of the snapshot, the encrypted ones are encrypted with the local agent
+// secret. The files missing in the snapshot are removed so no state of the local agent is mixed
+// with the restored one. Every file is written next to its target first, the targets are replaced
+// only once all the files are written.
+func (s *Snapshot) Restore(files []File, opts ...storage.OptionFunc) error {
+	var staged, removed []File
+	for _, f := range files {
+		content, ok := s.contents[f.Name]
+		if !ok {
+			removed = append(removed, f)
+			continue
+		}
+
+		tmp := f.Path + restoreSuffix
+		var store storage.Storage
+		if f.Encrypted {
+			store = storage.NewEncryptedDiskStore(tmp, opts...)
+		} else {
+			store = storage.NewDiskStore(tmp)
+		}
+		if err := store.Save(bytes.NewReader(content)); err != nil {
+			removeStaged(staged)
+			return err
+		}
+		staged = append(staged, f)
+	}
+
+	for i, f := range staged {
+		if err := os.Rename(f.Path+restoreSuffix, f.Path); err != nil {
+			removeStaged(staged[i:])
+			return errors.New(err,
+				fmt.Sprintf("could not replace %s", f.Path),
+				errors.TypeFilesystem,
+				errors.M(errors.MetaKeyPath, f.Path))
+		}
+	}
+	for _, f := range removed {
+		if err := os.Remove(f.Path); err != nil && !os.IsNotExist(err) {
+			return errors.New(err,
+				fmt.Sprintf("could not remove %s", f.Path),
+				errors.TypeFilesystem,
+				errors.M(errors.MetaKeyPath, f.Path))
+		}
+	}
+	return nil
+}
+
+// removeStaged removes the files written by an interrupted restore.
+func removeStaged(files []File) {
+	for _, f := range files {
+		_ = os.Remove(f.Path + restoreSuffix)
+	}
+}
+
+func load(f File, opts ...storage.OptionFunc) ([]byte, bool, error) {
+	if _, err := os.Stat(f.Path); err != nil {
+		if os.IsNotExist(err) {
+			return nil, false, nil
+		}
+		return nil, false, errors.New(err,
+			fmt.Sprintf("could not read %s", f.Path),
+			errors.TypeFilesystem,
+			errors.M(errors.MetaKeyPath, f.Path))
+	}
+
+	var store storage.Storage
+	if f.Encrypted {
+		store = storage.NewEncryptedDiskStore(f.Path, opts...)
+	} else {
+		store = storage.NewDiskStore(f.Path)
+	}
+	rc, err := store.Load()
+	if err != nil {
+		return nil, false, err
+	}
+	defer rc.Close()
+
+	content, err := ioutil.ReadAll(rc)
+	if err != nil {
+		return nil, false, errors.New(err,
+			fmt.Sprintf("could not read %s", f.Path),
+			errors.TypeFilesystem,
+			errors.M(errors.MetaKeyPath, f.Path))
+	}
+	return content, true, nil
+}
+
+func writeEntry(tw *tar.Writer, name string, content []byte) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(content)),
+		ModTime: time.Now().UTC(),
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return errors.New(err, "could not write snapshot", errors.TypeFilesystem)
+	}
+	if _, err := tw.Write(content); err != nil {
+		return errors.New(err, "could not write snapshot", errors.TypeFilesystem)
+	}
+	return nil
+}
+
+func describe(name string, content []byte) ManifestFile {
+	sum := sha256.Sum256(content)
+	return ManifestFile{
+		Name:   name,
+		Size:   int64(len(content)),
+		SHA256: hex.EncodeToString(sum[:]),
+	}
+}
+
+func agentID(fleetConfig []byte) string {
+	cfg, err := config.NewConfigFrom(fleetConfig)
+	if err != nil {
+		return ""
+	}
+	var c struct {
+		Agent struct {
+			ID string `config:"id"`
+		} `config:"agent"`
+	}
+	if err := cfg.Unpack(&c); err != nil {
+		return ""
+	}
+	return c.Agent.ID
+}
@@ -0,0 +1,207 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+//go:build linux
+// +build linux
+
+package snapshot
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/secret"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/storage"
+)
+
+const fleetConfig = `agent:
+  id: 1ab2c3d4
+fleet:
+  enabled: true
+`
+
+type testAgent struct {
+	vaultPath string
+	files     []File
+}
+
+func newTestAgent(t *testing.T) *testAgent {
+	t.Helper()
+	dir := t.TempDir()
+	a := &testAgent{
+		vaultPath: filepath.Join(dir, "vault"),
+		files: []File{
+			{Name: "elastic-agent.yml", Path: filepath.Join(dir, "elastic-agent.yml")},
+			{Name: fleetName, Path: filepath.Join(dir, "fleet.enc"), Encrypted: true},
+			{Name: "state.yml", Path: filepath.Join(dir, "state.enc"), Encrypted: true},
+		},
+	}
+	if err := secret.CreateAgentSecret(secret.WithVaultPath(a.vaultPath)); err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func (a *testAgent) save(t *testing.T, f File, content string) {
+	t.Helper()
+	var s storage.Storage = storage.NewDiskStore(f.Path)
+	if f.Encrypted {
+		s = storage.NewEncryptedDiskStore(f.Path, storage.WithVaultPath(a.vaultPath))
+	}
+	if err := s.Save(bytes.NewBufferString(content)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (a *testAgent) load(t *testing.T, f File) string {
+	t.Helper()
+	content, ok, err := load(f, storage.WithVaultPath(a.vaultPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatalf("%s does not exist", f.Path)
+	}
+	return string(content)
+}
+
+func export(t *testing.T, a *testAgent, password string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if _, err := Export(&buf, []byte(password), a.files, storage.WithVaultPath(a.vaultPath)); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestExportRestore(t *testing.T) {
+	src := newTestAgent(t)
+	src.save(t, src.files[0], "fleet:\n  enabled: true\n")
+	src.save(t, src.files[1], fleetConfig)
+	src.save(t, src.files[2], "action: {}\n")
+
+	archive := export(t, src, "changeme")
+	if bytes.Contains(archive, []byte("1ab2c3d4")) {
+		t.Fatal("snapshot is not encrypted")
+	}
+
+	s, err := Read(bytes.NewReader(archive), []byte("changeme"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff("1ab2c3d4", s.Manifest.AgentID); diff != "" {
+		t.Fatal(diff)
+	}
+	if len(s.Manifest.Files) != 3 {
+		t.Fatalf("expected 3 files in the snapshot, got %d", len(s.Manifest.Files))
+	}
+
+	dst := newTestAgent(t)
+	if err := s.Restore(dst.files, storage.WithVaultPath(dst.vaultPath)); err != nil {
+		t.Fatal(err)
+	}
+	for i, f := range dst.files {
+		if diff := cmp.Diff(src.load(t, src.files[i]), dst.load(t, f)); diff != "" {
+			t.Fatal(diff)
+		}
+	}
+
+	// the restored files are encrypted with the secret of the destination
+	prev, err := ioutil.ReadFile(src.files[1].Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := ioutil.ReadFile(dst.files[1].Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(prev, restored) {
+		t.Fatal("restored file was not re-encrypted")
+	}
+}
+
+func TestRestoreRemovesMissingFiles(t *testing.T) {
+	src := newTestAgent(t)
+	src.save(t, src.files[1], fleetConfig)
+
+	s, err := Read(bytes.NewReader(export(t, src, "changeme")), []byte("changeme"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst := newTestAgent(t)
+	dst.save(t, dst.files[2], "stale state")
+	if err := s.Restore(dst.files, storage.WithVaultPath(dst.vaultPath)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dst.files[2].Path); !os.IsNotExist(err) {
+		t.Fatalf("expected the state to be removed, got %v", err)
+	}
+}
+
+func TestRestoreIsAtomic(t *testing.T) {
+	src := newTestAgent(t)
+	src.save(t, src.files[0], "fleet:\n  enabled: true\n")
+	src.save(t, src.files[1], fleetConfig)
+	src.save(t, src.files[2], "action: {}\n")
+
+	s, err := Read(bytes.NewReader(export(t, src, "changeme")), []byte("changeme"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst := newTestAgent(t)
+	dst.save(t, dst.files[0], "local config")
+	dst.save(t, dst.files[1], "local fleet")
+	// the state cannot be written, nothing is replaced
+	if err := os.MkdirAll(filepath.Join(dst.files[2].Path+restoreSuffix, "dir"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Restore(dst.files, storage.WithVaultPath(dst.vaultPath)); err == nil {
+		t.Fatal("expected the restore to fail")
+	}
+
+	if diff := cmp.Diff("local config", dst.load(t, dst.files[0])); diff != "" {
+		t.Fatal(diff)
+	}
+	if diff := cmp.Diff("local fleet", dst.load(t, dst.files[1])); diff != "" {
+		t.Fatal(diff)
+	}
+	for _, f := range dst.files[:2] {
+		if _, err := os.Stat(f.Path + restoreSuffix); !os.IsNotExist(err) {
+			t.Fatalf("expected the staged %s to be removed, got %v", f.Name, err)
+		}
+	}
+}
+
+func TestReadInvalidSnapshot(t *testing.T) {
+	src := newTestAgent(t)
+	src.save(t, src.files[1], fleetConfig)
+	archive := export(t, src, "changeme")
+
+	t.Run("wrong password", func(t *testing.T) {
+		if _, err := Read(bytes.NewReader(archive), []byte("wrong")); err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		tampered := append([]byte(nil), archive...)
+		tampered[len(tampered)-1] ^= 0xff
+		if _, err := Read(bytes.NewReader(tampered), []byte("changeme")); err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+
+	t.Run("empty password", func(t *testing.T) {
+		if _, err := Export(ioutil.Discard, nil, src.files, storage.WithVaultPath(src.vaultPath)); err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+}
@@ -39,4 +39,5 @@ type EncryptedDiskStore struct {
 	target    string
 	vaultPath string
 	key       []byte
+	keyGen    uint64
 }
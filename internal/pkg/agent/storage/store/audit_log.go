@@ -0,0 +1,146 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/elastic/elastic-agent-libs/file"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/internal/pkg/fleetapi"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+// Sources of the audited actions.
+const (
+	// AuditSourceFleet is used for actions received from Fleet.
+	AuditSourceFleet = "fleet"
+	// AuditSourceReplay is used for actions replayed from the state store at startup.
+	AuditSourceReplay = "replay"
+	// AuditSourceQueue is used for scheduled actions removed from the action queue.
+	AuditSourceQueue = "queue"
+	// AuditSourceLocal is used for application actions requested through the control socket.
+	AuditSourceLocal = "local"
+)
+
+// Results of the audited actions.
+const (
+	AuditResultSuccess = "success"
+	AuditResultFailure = "failure"
+	AuditResultExpired = "expired"
+)
+
+// AuditEntry is a single entry of the audit log.
+type AuditEntry struct {
+	Timestamp  time.Time `json:"@timestamp"`
+	ActionID   string    `json:"action_id"`
+	ActionType string    `json:"action_type"`
+	InputType  string    `json:"input_type,omitempty"`
+	Source     string    `json:"source"`
+	Handler    string    `json:"handler,omitempty"`
+	Result     string    `json:"result"`
+	Error      string    `json:"error,omitempty"`
+	Acked      bool      `json:"acked"`
+	AckError   string    `json:"ack_error,omitempty"`
+}
+
+// NewAuditEntry creates an entry for the action with the source found in ctx.
+func NewAuditEntry(ctx context.Context, a fleetapi.Action, result string) AuditEntry {
+	return AuditEntry{
+		Timestamp:  time.Now().UTC(),
+		ActionID:   a.ID(),
+		ActionType: a.Type(),
+		Source:     AuditSource(ctx),
+		Result:     result,
+	}
+}
+
+// Auditor records what happened to actions handled by the agent.
+type Auditor interface {
+	Audit(AuditEntry)
+}
+
+type auditSourceKey struct{}
+
+// WithAuditSource returns a context carrying the source of the dispatched actions.
+func WithAuditSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, auditSourceKey{}, source)
+}
+
+// AuditSource returns the source of the dispatched actions carried by ctx, defaults
+// to AuditSourceFleet.
+func AuditSource(ctx context.Context) string {
+	if source, ok := ctx.Value(auditSourceKey{}).(string); ok {
+		return source
+	}
+	return AuditSourceFleet
+}
+
+// AuditConfig configures the audit log of the actions.
+type AuditConfig struct {
+	Enabled bool `config:"enabled" yaml:"enabled"`
+	// MaxSize is the size in bytes after which the audit log is rotated.
+	MaxSize uint `config:"max_size" yaml:"max_size"`
+	// MaxBackups is the number of rotated audit log files to keep.
+	MaxBackups uint `config:"max_backups" yaml:"max_backups"`
+}
+
+// DefaultAuditConfig creates a config with pre-set default values.
+func DefaultAuditConfig() *AuditConfig {
+	return &AuditConfig{
+		Enabled:    true,
+		MaxSize:    10 * 1024 * 1024, // 10 MiB
+		MaxBackups: 5,
+	}
+}
+
+// AuditLog is an append-only log of the actions handled by the agent, one JSON
+// document per line. The files are rotated by size and only a limited number
+// of rotated files is kept.
+type AuditLog struct {
+	log     *logger.Logger
+	rotator *file.Rotator
+}
+
+// NewAuditLog creates a new audit log writing to files named after path.
+func NewAuditLog(log *logger.Logger, path string, cfg *AuditConfig) (*AuditLog, error) {
+	if cfg == nil {
+		cfg = DefaultAuditConfig()
+	}
+
+	rotator, err := file.NewFileRotator(
+		path,
+		file.MaxSizeBytes(cfg.MaxSize),
+		file.MaxBackups(cfg.MaxBackups),
+		file.Permissions(0600),
+		file.RotateOnStartup(false),
+	)
+	if err != nil {
+		return nil, errors.New(err, "creating action audit log", errors.TypeFilesystem, errors.M(errors.MetaKeyPath, path))
+	}
+
+	return &AuditLog{log: log, rotator: rotator}, nil
+}
+
+// Audit appends the entry to the log, failures are logged as they must not
+// prevent the action from being handled.
+func (a *AuditLog) Audit(e AuditEntry) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		a.log.Errorw("Failed to encode action audit entry", "action_id", e.ActionID, "error.message", err)
+		return
+	}
+
+	if _, err := a.rotator.Write(append(b, '\n')); err != nil {
+		a.log.Errorw("Failed to write action audit entry", "action_id", e.ActionID, "error.message", err)
+	}
+}
+
+// Close closes the audit log.
+func (a *AuditLog) Close() error {
+	return a.rotator.Close()
+}
@@ -0,0 +1,89 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package store
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent/internal/pkg/fleetapi"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+func TestAuditLog(t *testing.T) {
+	log, _ := logger.New("", false)
+
+	t.Run("entries are appended", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "actions")
+
+		auditLog, err := NewAuditLog(log, path, DefaultAuditConfig())
+		require.NoError(t, err)
+
+		ctx := WithAuditSource(context.Background(), AuditSourceReplay)
+		auditLog.Audit(NewAuditEntry(ctx, &fleetapi.ActionUnknown{ActionID: "id1", ActionType: "UNKNOWN"}, AuditResultSuccess))
+		auditLog.Audit(NewAuditEntry(context.Background(), &fleetapi.ActionUnknown{ActionID: "id2", ActionType: "UNKNOWN"}, AuditResultExpired))
+		require.NoError(t, auditLog.Close())
+
+		entries := readAuditEntries(t, dir)
+		require.Len(t, entries, 2)
+		assert.Equal(t, "id1", entries[0].ActionID)
+		assert.Equal(t, AuditSourceReplay, entries[0].Source)
+		assert.Equal(t, AuditResultSuccess, entries[0].Result)
+		assert.Equal(t, "id2", entries[1].ActionID)
+		assert.Equal(t, AuditSourceFleet, entries[1].Source)
+		assert.Equal(t, AuditResultExpired, entries[1].Result)
+
+		// reopening appends to the existing file
+		auditLog, err = NewAuditLog(log, path, DefaultAuditConfig())
+		require.NoError(t, err)
+		auditLog.Audit(NewAuditEntry(context.Background(), &fleetapi.ActionUnknown{ActionID: "id3", ActionType: "UNKNOWN"}, AuditResultSuccess))
+		require.NoError(t, auditLog.Close())
+		require.Len(t, readAuditEntries(t, dir), 3)
+	})
+
+	t.Run("retention limits the number of files", func(t *testing.T) {
+		dir := t.TempDir()
+		auditLog, err := NewAuditLog(log, filepath.Join(dir, "actions"), &AuditConfig{Enabled: true, MaxSize: 300, MaxBackups: 1})
+		require.NoError(t, err)
+
+		for i := 0; i < 20; i++ {
+			auditLog.Audit(NewAuditEntry(context.Background(), &fleetapi.ActionUnknown{ActionID: "id", ActionType: "UNKNOWN"}, AuditResultSuccess))
+		}
+		require.NoError(t, auditLog.Close())
+
+		files, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Len(t, files, 2, "active file and a single backup are kept")
+	})
+}
+
+func readAuditEntries(t *testing.T, dir string) []AuditEntry {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "actions*.ndjson"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	f, err := os.Open(files[0])
+	require.NoError(t, err)
+	defer f.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e AuditEntry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		entries = append(entries, e)
+	}
+	require.NoError(t, scanner.Err())
+	return entries
+}
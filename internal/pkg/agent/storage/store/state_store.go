@@ -355,6 +355,7 @@ func ReplayActions(
 ) error {
 	log.Info("restoring current policy from disk")
 
+	ctx = WithAuditSource(ctx, AuditSourceReplay)
 	if err := dispatcher.Dispatch(ctx, acker, actions...); err != nil {
 		return err
 	}
@@ -6,12 +6,17 @@ package transpiler
 
 import (
 	"fmt"
+	"net/url"
 	"reflect"
 	"regexp"
+	"strings"
 
 	"gopkg.in/yaml.v2"
 
 	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/internal/pkg/config"
+	"github.com/elastic/elastic-agent/internal/pkg/eql"
+	"github.com/elastic/elastic-agent/internal/pkg/proxy"
 )
 
 // AgentInfo is an interface to get the agent info.
@@ -93,8 +98,14 @@ func (r *RuleList) MarshalYAML() (interface{}, error) {
 			name = "insert_defaults"
 		case *InjectHeadersRule:
 			name = "inject_headers"
+		case *InjectProxyRule:
+			name = "inject_proxy"
 		case *InjectQueueRule:
 			name = "inject_queue"
+		case *WhenRule:
+			name = "when"
+		case *SetRule:
+			name = "set"
 		default:
 			return nil, fmt.Errorf("unknown rule of type %T", rule)
 		}
@@ -182,8 +193,14 @@ func (r *RuleList) UnmarshalYAML(unmarshal func(interface{}) error) error {
 			r = &InsertDefaultsRule{}
 		case "inject_headers":
 			r = &InjectHeadersRule{}
+		case "inject_proxy":
+			r = &InjectProxyRule{}
 		case "inject_queue":
 			r = &InjectQueueRule{}
+		case "when":
+			r = &WhenRule{}
+		case "set":
+			r = &SetRule{}
 		default:
 			return fmt.Errorf("unknown rule of type %s", name)
 		}
@@ -1739,6 +1756,296 @@ func InjectHeaders() *InjectHeadersRule {
 	return &InjectHeadersRule{}
 }
 
+// InjectProxyRule sets the proxy of the outputs from the proxy rules of the agent, `agent.proxy.rules`,
+// an output with a proxy already configured is left untouched. The hosts of an output must all be
+// routed by the same rule as an output uses a single proxy.
+type InjectProxyRule struct{}
+
+// Apply sets the proxy of the outputs.
+func (r *InjectProxyRule) Apply(_ AgentInfo, ast *AST) (err error) {
+	defer func() {
+		if err != nil {
+			err = errors.New(err, "failed to inject proxy into configuration")
+		}
+	}()
+
+	rules, err := proxyRulesFromAST(ast)
+	if err != nil || len(rules) == 0 {
+		return err
+	}
+
+	if outputNode, found := Lookup(ast, "output"); found {
+		if outputDict, ok := outputNode.Value().(*Dict); ok {
+			for _, node := range outputDict.value {
+				key, ok := node.(*Key)
+				if !ok {
+					continue
+				}
+				if err := injectOutputProxy(rules, key.name, key.name, key.value); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if outputsNode, found := Lookup(ast, "outputs"); found {
+		if outputsDict, ok := outputsNode.Value().(*Dict); ok {
+			for _, node := range outputsDict.value {
+				key, ok := node.(*Key)
+				if !ok || key.value == nil {
+					continue
+				}
+				typeNode, ok := key.value.Find("type")
+				if !ok {
+					continue
+				}
+				typeKey, ok := typeNode.(*Key)
+				if !ok {
+					continue
+				}
+				outputType := typeKey.value.String()
+				if err := injectOutputProxy(rules, key.name, outputType, key.value); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// InjectProxy creates a InjectProxyRule
+func InjectProxy() *InjectProxyRule {
+	return &InjectProxyRule{}
+}
+
+func proxyRulesFromAST(ast *AST) (proxy.Rules, error) {
+	node, found := Lookup(ast, "agent.proxy")
+	if !found {
+		return nil, nil
+	}
+	dict, ok := node.Value().(*Dict)
+	if !ok {
+		return nil, errors.New("agent.proxy is not a dictionary")
+	}
+	m, err := (&AST{root: dict}).Map()
+	if err != nil {
+		return nil, err
+	}
+	c, err := config.NewConfigFrom(m)
+	if err != nil {
+		return nil, err
+	}
+	cfg := proxy.DefaultConfig()
+	if err := c.Unpack(cfg); err != nil {
+		return nil, err
+	}
+	return cfg.Rules, nil
+}
+
+// injectOutputProxy sets the proxy of an output. The elasticsearch output is routed through any
+// proxy or directly, the other outputs only support SOCKS5 proxies.
+func injectOutputProxy(rules proxy.Rules, name, outputType string, node Node) error {
+	output, ok := node.(*Dict)
+	if !ok {
+		return nil
+	}
+	for _, k := range []string{"proxy_url", "proxy_disable"} {
+		if _, found := output.Find(k); found {
+			return nil
+		}
+	}
+
+	var hosts []string
+	if hostsNode, found := output.Find("hosts"); found {
+		if list, ok := hostsNode.Value().(*List); ok {
+			for _, n := range list.value {
+				hosts = append(hosts, n.String())
+			}
+		}
+	}
+	if hostNode, found := output.Find("host"); found {
+		if hostKey, ok := hostNode.(*Key); ok {
+			hosts = append(hosts, hostKey.value.String())
+		}
+	}
+
+	var matched *proxy.Rule
+	for i, host := range hosts {
+		rule, _ := rules.Match(outputHostname(host))
+		if i > 0 && rule != matched {
+			return fmt.Errorf("the hosts of the output %s are routed by different proxy rules", name)
+		}
+		matched = rule
+	}
+	if matched == nil {
+		return nil
+	}
+
+	if outputType != "elasticsearch" {
+		if u := matched.ProxyURL(); u != nil && u.Scheme == "socks5" {
+			output.Insert(&Key{name: "proxy_url", value: &StrVal{value: matched.URL}})
+		}
+		return nil
+	}
+
+	if matched.Direct {
+		output.Insert(&Key{name: "proxy_disable", value: &BoolVal{value: true}})
+		return nil
+	}
+	output.Insert(&Key{name: "proxy_url", value: &StrVal{value: matched.URL}})
+	if len(matched.Headers) > 0 {
+		headers := make([]Node, 0, len(matched.Headers))
+		for k, v := range matched.Headers {
+			headers = append(headers, &Key{name: k, value: &StrVal{value: v}})
+		}
+		output.Insert(&Key{name: "proxy_headers", value: NewDict(headers)})
+	}
+	return nil
+}
+
+// outputHostname returns the host name of a host of an output, with or without scheme and port.
+func outputHostname(host string) string {
+	if !strings.Contains(host, "://") {
+		host = "//" + host
+	}
+	u, err := url.Parse(host)
+	if err != nil {
+		return host
+	}
+	return u.Hostname()
+}
+
+// WhenRule applies its rules only when its condition evaluates to true against the tree.
+type WhenRule struct {
+	Condition string
+	Rules     []Rule
+}
+
+// When creates a when rule.
+func When(condition string, rules ...Rule) *WhenRule {
+	return &WhenRule{Condition: condition, Rules: rules}
+}
+
+// Apply evaluates the EQL condition against the tree and applies the rules when it holds.
+func (r *WhenRule) Apply(agentInfo AgentInfo, ast *AST) (err error) {
+	defer func() {
+		if err != nil {
+			err = errors.New(err, "failed to apply conditional rules on configuration")
+		}
+	}()
+
+	expression, err := eql.New(r.Condition)
+	if err != nil {
+		return err
+	}
+	cond, err := expression.Eval(ast)
+	if err != nil {
+		return fmt.Errorf(`condition "%s" evaluation failed: %w`, r.Condition, err)
+	}
+	if !cond {
+		return nil
+	}
+	return NewRuleList(r.Rules...).Apply(agentInfo, ast)
+}
+
+// MarshalYAML marshal a WhenRule into a YAML document.
+func (r *WhenRule) MarshalYAML() (interface{}, error) {
+	rules, err := NewRuleList(r.Rules...).MarshalYAML()
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"condition": r.Condition,
+		"rules":     rules,
+	}, nil
+}
+
+// UnmarshalYAML unmarshal a YAML document into a WhenRule.
+func (r *WhenRule) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	tmp := struct {
+		Condition string
+		Rules     RuleList
+	}{}
+
+	if err := unmarshal(&tmp); err != nil {
+		return errors.New(err, "cannot unmarshal into a WhenRule")
+	}
+	if _, err := eql.New(tmp.Condition); err != nil {
+		return errors.New(err, fmt.Sprintf("invalid condition %q of a WhenRule", tmp.Condition))
+	}
+
+	*r = WhenRule{
+		Condition: tmp.Condition,
+		Rules:     tmp.Rules.Rules,
+	}
+	return nil
+}
+
+// SetRule sets a key to a value, the ${path} of the strings of the value are replaced by the values
+// of the tree, a string made of a single ${path} is replaced by the node itself keeping its type.
+// The key is left untouched when a path is missing and has no default, e.g. ${path|'default'}.
+type SetRule struct {
+	Key   Selector
+	Value interface{}
+}
+
+// Set creates a set rule.
+func Set(key Selector, value interface{}) *SetRule {
+	return &SetRule{Key: key, Value: value}
+}
+
+// Apply sets the key to the interpolated value.
+func (r *SetRule) Apply(_ AgentInfo, ast *AST) (err error) {
+	defer func() {
+		if err != nil {
+			err = errors.New(err, "failed to set key of configuration")
+		}
+	}()
+
+	node, err := load(reflect.ValueOf(r.Value))
+	if err != nil {
+		return err
+	}
+	if node == nil {
+		return fmt.Errorf("cannot set key '%s' without value", r.Key)
+	}
+	node, err = node.Apply(&Vars{tree: ast})
+	if errors.Is(err, ErrNoMatch) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	// a value made of a single path is the node of the tree itself
+	node = node.Clone()
+
+	parts := splitPath(r.Key)
+	if len(parts) == 0 {
+		return fmt.Errorf("cannot set an empty key")
+	}
+	key := &Key{name: parts[len(parts)-1], value: node}
+	parent := strings.Join(parts[:len(parts)-1], selectorSep)
+	if parent != "" {
+		return Insert(ast, key, parent)
+	}
+
+	root, ok := ast.root.(*Dict)
+	if !ok {
+		return fmt.Errorf("cannot set key, invalid type expected 'Dict' received '%T'", ast.root)
+	}
+	for i, n := range root.value {
+		if k, ok := n.(*Key); ok && k.name == key.name {
+			root.value[i] = key
+			return nil
+		}
+	}
+	root.value = append(root.value, key)
+	root.sort()
+	return nil
+}
+
 // NewRuleList returns a new list of rules to be executed.
 func NewRuleList(rules ...Rule) *RuleList {
 	return &RuleList{Rules: rules}
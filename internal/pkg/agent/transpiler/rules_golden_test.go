@@ -0,0 +1,80 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package transpiler
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/internal/yamltest"
+)
+
+var generateFlag = flag.Bool("generate", false, "Write golden files")
+
+// TestRulesGolden applies the rules of every test case of tests/rules to its configuration and
+// compares the result with the golden file of the test case.
+func TestRulesGolden(t *testing.T) {
+	casesPath := filepath.Join("tests", "rules")
+	cases, err := filepath.Glob(filepath.Join(casesPath, "*.yml"))
+	require.NoError(t, err)
+	require.NotEmpty(t, cases)
+
+	generatedFilesDir := filepath.Join(casesPath, "generated")
+	if *generateFlag {
+		require.NoError(t, os.RemoveAll(generatedFilesDir))
+		require.NoError(t, os.MkdirAll(generatedFilesDir, 0755))
+	}
+
+	for _, c := range cases {
+		t.Run(c, func(t *testing.T) {
+			name := strings.TrimSuffix(filepath.Base(c), ".yml")
+			b, err := ioutil.ReadFile(c)
+			require.NoError(t, err)
+
+			var testCase struct {
+				Rules  RuleList               `yaml:"rules"`
+				Config map[string]interface{} `yaml:"config"`
+			}
+			require.NoError(t, yaml.Unmarshal(b, &testCase))
+
+			// the rules survive a round trip through YAML
+			serialized, err := yaml.Marshal(&testCase.Rules)
+			require.NoError(t, err)
+			var rules RuleList
+			require.NoError(t, yaml.Unmarshal(serialized, &rules))
+			require.Equal(t, testCase.Rules, rules)
+
+			ast, err := NewAST(testCase.Config)
+			require.NoError(t, err)
+			require.NoError(t, rules.Apply(FakeAgentInfo(), ast))
+			got, err := ast.Map()
+			require.NoError(t, err)
+
+			generatedPath := filepath.Join(generatedFilesDir, name+".golden.yml")
+			if *generateFlag {
+				d, err := yaml.Marshal(got)
+				require.NoError(t, err)
+				require.NoError(t, ioutil.WriteFile(generatedPath, d, 0644))
+			}
+
+			golden, err := ioutil.ReadFile(generatedPath)
+			require.NoError(t, err)
+			var want map[string]interface{}
+			require.NoError(t, yamltest.FromYAML(golden, &want))
+
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("%s mismatch (-want +got):\n%s", c, diff)
+			}
+		})
+	}
+}
@@ -889,6 +889,93 @@ output:
 				},
 			},
 		},
+		"inject proxy": {
+			givenYAML: `
+agent:
+  proxy:
+    rules:
+      - hosts: [".internal.example.com", "10.0.0.0/8"]
+        direct: true
+      - hosts: ["*.cloud.es.io"]
+        url: http://proxy.example.com:3128
+        headers:
+          proxy-authorization: token
+      - hosts: ["*"]
+        url: socks5://socks.example.com:1080
+output:
+  elasticsearch:
+    hosts:
+      - "https://a.cloud.es.io:443"
+      - "b.cloud.es.io:443"
+outputs:
+  internal:
+    type: elasticsearch
+    hosts: ["es.internal.example.com:9200", "10.1.2.3:9200"]
+  configured:
+    type: elasticsearch
+    hosts: ["es.example.org:9200"]
+    proxy_url: http://other.example.com:3128
+  ls:
+    type: logstash
+    hosts: ["ls.example.org:5044"]
+`,
+			expectedYAML: `
+agent:
+  proxy:
+    rules:
+      - hosts: [".internal.example.com", "10.0.0.0/8"]
+        direct: true
+      - hosts: ["*.cloud.es.io"]
+        url: http://proxy.example.com:3128
+        headers:
+          proxy-authorization: token
+      - hosts: ["*"]
+        url: socks5://socks.example.com:1080
+output:
+  elasticsearch:
+    hosts:
+      - "https://a.cloud.es.io:443"
+      - "b.cloud.es.io:443"
+    proxy_url: http://proxy.example.com:3128
+    proxy_headers:
+      proxy-authorization: token
+outputs:
+  internal:
+    type: elasticsearch
+    hosts: ["es.internal.example.com:9200", "10.1.2.3:9200"]
+    proxy_disable: true
+  configured:
+    type: elasticsearch
+    hosts: ["es.example.org:9200"]
+    proxy_url: http://other.example.com:3128
+  ls:
+    type: logstash
+    hosts: ["ls.example.org:5044"]
+    proxy_url: socks5://socks.example.com:1080
+`,
+			rule: &RuleList{
+				Rules: []Rule{
+					InjectProxy(),
+				},
+			},
+		},
+		"inject proxy: no rules": {
+			givenYAML: `
+output:
+  elasticsearch:
+    hosts: ["127.0.0.1:9200"]
+`,
+			expectedYAML: `
+output:
+  elasticsearch:
+    hosts: ["127.0.0.1:9200"]
+`,
+			rule: &RuleList{
+				Rules: []Rule{
+					InjectProxy(),
+				},
+			},
+		},
 		"inject queue settings": {
 			givenYAML: `
 output:
@@ -985,6 +1072,21 @@ func makeASTFromYAML(yamlStr string) (*AST, error) {
 	return NewAST(m)
 }
 
+func TestInjectProxyHostsWithDifferentRules(t *testing.T) {
+	a, err := makeASTFromYAML(`
+agent:
+  proxy:
+    rules:
+      - hosts: ["*.cloud.es.io"]
+        url: http://proxy.example.com:3128
+output:
+  elasticsearch:
+    hosts: ["a.cloud.es.io:443", "es.example.org:9200"]
+`)
+	require.NoError(t, err)
+	assert.Error(t, InjectProxy().Apply(FakeAgentInfo(), a))
+}
+
 func TestSerialization(t *testing.T) {
 	value := NewRuleList(
 		Rename("from-value", "to-value"),
@@ -1010,6 +1112,10 @@ func TestSerialization(t *testing.T) {
 		SelectInto("target", "s1", "s2"),
 		InsertDefaults("target", "s1", "s2"),
 		InjectHeaders(),
+		InjectProxy(),
+		When("${type} == 'logfile'",
+			Set("index", "logs-${data_stream.dataset}"),
+		),
 	)
 
 	y := `- rename:
@@ -1081,6 +1187,13 @@ func TestSerialization(t *testing.T) {
     - s2
     path: target
 - inject_headers: {}
+- inject_proxy: {}
+- when:
+    condition: ${type} == 'logfile'
+    rules:
+    - set:
+        key: index
+        value: logs-${data_stream.dataset}
 `
 
 	t.Run("serialize_rules", func(t *testing.T) {
@@ -0,0 +1,39 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package vault
+
+import (
+	"errors"
+	"fmt"
+)
+
+// SeedBackend is where the seed of the vault is held.
+type SeedBackend string
+
+const (
+	// SeedBackendFile holds the seed in a file of the vault directory.
+	SeedBackendFile SeedBackend = "file"
+	// SeedBackendKeyring holds the seed in the persistent kernel keyring of the user, with an
+	// escrow copy encrypted with systemd-creds to reseed the keyring after a reboot.
+	SeedBackendKeyring SeedBackend = "keyring"
+	// SeedBackendSystemdCreds holds the seed in a file of the vault directory encrypted with systemd-creds.
+	SeedBackendSystemdCreds SeedBackend = "systemd-creds"
+)
+
+// ErrSeedBackendNotSupported is returned when the seed backend is not supported on the platform.
+var ErrSeedBackendNotSupported = errors.New("vault seed backend is not supported on this platform")
+
+// ParseSeedBackend parses the name of a seed backend, an empty name is the file backend.
+func ParseSeedBackend(name string) (SeedBackend, error) {
+	switch b := SeedBackend(name); b {
+	case "":
+		return SeedBackendFile, nil
+	case SeedBackendFile, SeedBackendKeyring, SeedBackendSystemdCreds:
+		return b, nil
+	default:
+		return "", fmt.Errorf("unknown vault seed backend %q, expected one of %s, %s or %s",
+			name, SeedBackendFile, SeedBackendKeyring, SeedBackendSystemdCreds)
+	}
+}
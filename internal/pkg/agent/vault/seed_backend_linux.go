@@ -0,0 +1,366 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+//go:build linux
+// +build linux
+
+package vault
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// seedRefHeader starts the seed file of a vault whose seed is held by another backend.
+	seedRefHeader = "elastic-agent-vault-seed-ref\n"
+	// keyringKeyPrefix prefixes the description of the seeds in the kernel keyring.
+	keyringKeyPrefix = "elastic-agent-vault:"
+	// credName is the name of the systemd credential, it is checked when the seed is decrypted.
+	credName = "elastic-agent-vault-seed"
+)
+
+// seedRef references a seed held by another backend than the seed file.
+type seedRef struct {
+	Backend SeedBackend `json:"backend"`
+	Name    string      `json:"name"`
+}
+
+// seedStore holds seeds by name.
+type seedStore interface {
+	store(path, name string, seed []byte) error
+	load(path, name string) ([]byte, error)
+	// remove removes a seed, it does nothing if the seed does not exist.
+	remove(path, name string) error
+}
+
+var seedStores = map[SeedBackend]seedStore{
+	SeedBackendKeyring: &keyringSeedStore{
+		keyring: kernelKeyring{},
+		escrow:  &systemdCredsSeedStore{command: "systemd-creds"},
+	},
+	SeedBackendSystemdCreds: &systemdCredsSeedStore{command: "systemd-creds"},
+}
+
+// newSeedRef returns a reference to a new seed held by the backend, nil for the file backend.
+func newSeedRef(backend SeedBackend) (*seedRef, error) {
+	if backend == "" || backend == SeedBackendFile {
+		return nil, nil
+	}
+	if _, ok := seedStores[backend]; !ok {
+		return nil, fmt.Errorf("unknown vault seed backend %q", backend)
+	}
+	id, err := NewKey(AES128)
+	if err != nil {
+		return nil, err
+	}
+	name := hex.EncodeToString(id)
+	switch backend {
+	case SeedBackendKeyring:
+		name = keyringKeyPrefix + name
+	case SeedBackendSystemdCreds:
+		name = seedFile + "." + name + ".cred"
+	}
+	return &seedRef{Backend: backend, Name: name}, nil
+}
+
+// storeSeed stores the seed with the backend of the reference and returns the content of the
+// seed file, the seed itself for the file backend.
+func storeSeed(path string, ref *seedRef, seed []byte) ([]byte, error) {
+	if ref == nil {
+		return seed, nil
+	}
+	if err := seedStores[ref.Backend].store(path, ref.Name, seed); err != nil {
+		return nil, fmt.Errorf("could not store the vault seed in %s: %w", ref.Backend, err)
+	}
+	// a seed that cannot be read back would make the vault unreadable once committed
+	stored, err := ref.load(path)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(stored, seed) {
+		return nil, fmt.Errorf("vault seed read back from %s does not match", ref.Backend)
+	}
+
+	b, err := json.Marshal(ref)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(seedRefHeader), b...), nil
+}
+
+// removeSeed removes a seed referenced by a seed file, nothing is removed for the file backend.
+func removeSeed(path string, ref *seedRef) error {
+	if ref == nil {
+		return nil
+	}
+	store, ok := seedStores[ref.Backend]
+	if !ok {
+		return fmt.Errorf("unknown vault seed backend %q", ref.Backend)
+	}
+	return store.remove(path, ref.Name)
+}
+
+func (r *seedRef) load(path string) ([]byte, error) {
+	store, ok := seedStores[r.Backend]
+	if !ok {
+		return nil, fmt.Errorf("unknown vault seed backend %q", r.Backend)
+	}
+	seed, err := store.load(path, r.Name)
+	if err != nil {
+		return nil, fmt.Errorf("could not load the vault seed from %s: %w", r.Backend, err)
+	}
+	if len(seed) != int(AES256) {
+		return nil, fmt.Errorf("invalid seed length, expected: %v, got: %v", int(AES256), len(seed))
+	}
+	return seed, nil
+}
+
+// parseSeedRef returns the reference of a seed file, nil when the file holds the seed itself.
+func parseSeedRef(b []byte) (*seedRef, error) {
+	if !bytes.HasPrefix(b, []byte(seedRefHeader)) {
+		return nil, nil
+	}
+	var ref seedRef
+	if err := json.Unmarshal(b[len(seedRefHeader):], &ref); err != nil {
+		return nil, fmt.Errorf("could not parse the vault seed reference: %w", err)
+	}
+	return &ref, nil
+}
+
+// loadSeed returns the seed of the vault and its reference when it is held by another backend,
+// a seed file is created when the vault has none and is not readonly.
+func loadSeed(path string, readonly bool) ([]byte, *seedRef, error) {
+	mxSeed.Lock()
+	b, err := ioutil.ReadFile(filepath.Join(path, seedFile))
+	mxSeed.Unlock()
+	if err == nil {
+		ref, err := parseSeedRef(b)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ref != nil {
+			seed, err := ref.load(path)
+			return seed, ref, err
+		}
+	}
+
+	seed, err := getOrCreateSeed(path, readonly)
+	return seed, nil, err
+}
+
+// MigrateSeed moves the seed of the vault to the backend. The entries stay encrypted with the
+// same seed, the seed file is atomically replaced and the seed is removed from the previous
+// backend once the vault references the new one.
+func (v *Vault) MigrateSeed(backend SeedBackend) error {
+	if backend == "" {
+		backend = SeedBackendFile
+	}
+
+	v.mx.Lock()
+	if v.backend() == backend {
+		v.mx.Unlock()
+		return nil
+	}
+	r, err := v.startRotation(v.key, backend)
+	if err != nil {
+		v.mx.Unlock()
+		return err
+	}
+	return r.Commit()
+}
+
+// SeedBackend returns where the seed of the vault is held.
+func (v *Vault) SeedBackend() SeedBackend {
+	v.mx.Lock()
+	defer v.mx.Unlock()
+	return v.backend()
+}
+
+// backend returns where the seed of the vault is held.
+func (v *Vault) backend() SeedBackend {
+	if v.ref == nil {
+		return SeedBackendFile
+	}
+	return v.ref.Backend
+}
+
+// keyringSeedStore holds the seeds in a kernel keyring. The keyring does not survive a reboot, an
+// escrow copy of the seed is kept in the vault directory and reseeds the keyring when the seed is
+// missing from it. The escrow copy is encrypted with systemd-creds so it cannot be decrypted on
+// another host.
+type keyringSeedStore struct {
+	keyring keyring
+	escrow  seedStore
+}
+
+// escrowName returns the name of the escrow copy of a seed held in the keyring.
+func escrowName(name string) string {
+	return seedFile + "." + strings.TrimPrefix(name, keyringKeyPrefix) + ".escrow.cred"
+}
+
+func (s *keyringSeedStore) store(path, name string, seed []byte) error {
+	if err := s.escrow.store(path, escrowName(name), seed); err != nil {
+		return fmt.Errorf("could not store the escrow copy of the seed: %w", err)
+	}
+	return s.keyring.add(name, seed)
+}
+
+func (s *keyringSeedStore) load(path, name string) ([]byte, error) {
+	seed, err := s.keyring.read(name)
+	if !errors.Is(err, fs.ErrNotExist) {
+		return seed, err
+	}
+
+	// the keyring was flushed by a reboot or expired, it is reseeded from the escrow copy
+	seed, err = s.escrow.load(path, escrowName(name))
+	if err != nil {
+		return nil, fmt.Errorf("could not load the escrow copy of the seed: %w", err)
+	}
+	if err := s.keyring.add(name, seed); err != nil {
+		return nil, fmt.Errorf("could not reseed the keyring: %w", err)
+	}
+	return seed, nil
+}
+
+func (s *keyringSeedStore) remove(path, name string) error {
+	if err := s.keyring.unlink(name); err != nil {
+		return err
+	}
+	return s.escrow.remove(path, escrowName(name))
+}
+
+// keyring holds keys by description.
+type keyring interface {
+	add(description string, payload []byte) error
+	// read returns fs.ErrNotExist when the keyring has no key with the description.
+	read(description string) ([]byte, error)
+	// unlink removes a key, it does nothing if the keyring has no key with the description.
+	unlink(description string) error
+}
+
+// kernelKeyring holds the keys in the persistent keyring of the user, it outlives the sessions
+// of the user but not a reboot. The user keyring is used when the kernel has no persistent keyrings.
+type kernelKeyring struct{}
+
+func (kernelKeyring) ring() (int, error) {
+	// uid -1 is the user of the process, the keyring is linked to the process keyring
+	id, err := unix.KeyctlInt(unix.KEYCTL_GET_PERSISTENT, -1, unix.KEY_SPEC_PROCESS_KEYRING, 0, 0)
+	if errors.Is(err, unix.EOPNOTSUPP) {
+		return unix.KeyctlGetKeyringID(unix.KEY_SPEC_USER_KEYRING, true)
+	}
+	return id, err
+}
+
+func (k kernelKeyring) search(description string) (int, int, error) {
+	ring, err := k.ring()
+	if err != nil {
+		return 0, 0, err
+	}
+	id, err := unix.KeyctlSearch(ring, "user", description, 0)
+	if errors.Is(err, unix.ENOKEY) || errors.Is(err, unix.EKEYEXPIRED) {
+		return ring, 0, fmt.Errorf("no key %s in the keyring: %w", description, fs.ErrNotExist)
+	}
+	return ring, id, err
+}
+
+func (k kernelKeyring) add(description string, payload []byte) error {
+	ring, err := k.ring()
+	if err != nil {
+		return err
+	}
+	_, err = unix.AddKey("user", description, payload, ring)
+	return err
+}
+
+func (k kernelKeyring) read(description string) ([]byte, error) {
+	_, id, err := k.search(description)
+	if err != nil {
+		return nil, err
+	}
+	size, err := unix.KeyctlBuffer(unix.KEYCTL_READ, id, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	n, err := unix.KeyctlBuffer(unix.KEYCTL_READ, id, buf, 0)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+func (k kernelKeyring) unlink(description string) error {
+	ring, id, err := k.search(description)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, err = unix.KeyctlInt(unix.KEYCTL_UNLINK, id, ring, 0, 0)
+	return err
+}
+
+// systemdCredsSeedStore holds the seeds in files of the vault directory encrypted with
+// systemd-creds, with the TPM2 and/or the host key of systemd, they cannot be decrypted on another host.
+type systemdCredsSeedStore struct {
+	command string
+}
+
+func (s *systemdCredsSeedStore) store(path, name string, seed []byte) error {
+	fp := filepath.Join(path, name)
+	cmd := exec.Command(s.command, "encrypt", "--name="+credName, "-", fp)
+	cmd.Stdin = bytes.NewReader(seed)
+	if err := runCommand(cmd); err != nil {
+		return err
+	}
+	if err := os.Chmod(fp, 0600); err != nil {
+		return err
+	}
+	fd, err := os.Open(fp)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+	return fd.Sync()
+}
+
+func (s *systemdCredsSeedStore) load(path, name string) ([]byte, error) {
+	fp := filepath.Join(path, name)
+	if _, err := os.Stat(fp); err != nil {
+		return nil, err
+	}
+	var stdout bytes.Buffer
+	cmd := exec.Command(s.command, "decrypt", "--name="+credName, fp, "-")
+	cmd.Stdout = &stdout
+	if err := runCommand(cmd); err != nil {
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+func (s *systemdCredsSeedStore) remove(path, name string) error {
+	return removeIfExists(filepath.Join(path, name))
+}
+
+func runCommand(cmd *exec.Cmd) error {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s %s failed: %w: %s", cmd.Path, cmd.Args[1], err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
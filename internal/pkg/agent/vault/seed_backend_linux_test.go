@@ -0,0 +1,336 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+//go:build linux
+// +build linux
+
+package vault
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/sys/unix"
+)
+
+// fakeSystemdCreds "encrypts" the credentials with base64 and checks their name on decrypt.
+const fakeSystemdCreds = `#!/bin/sh
+set -e
+case "$1" in
+encrypt) { printf '%s\n' "$2"; base64 -; } > "$4" ;;
+decrypt) [ "$(head -n 1 "$3")" = "$2" ] || { echo "credential name mismatch" >&2; exit 1; }; tail -n +2 "$3" | base64 -d ;;
+*) exit 2 ;;
+esac
+`
+
+type memKeyring struct {
+	mx   sync.Mutex
+	keys map[string][]byte
+}
+
+func (k *memKeyring) add(description string, payload []byte) error {
+	k.mx.Lock()
+	defer k.mx.Unlock()
+	k.keys[description] = append([]byte(nil), payload...)
+	return nil
+}
+
+func (k *memKeyring) read(description string) ([]byte, error) {
+	k.mx.Lock()
+	defer k.mx.Unlock()
+	b, ok := k.keys[description]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	return b, nil
+}
+
+func (k *memKeyring) unlink(description string) error {
+	k.mx.Lock()
+	defer k.mx.Unlock()
+	delete(k.keys, description)
+	return nil
+}
+
+// useFakeSeedStores replaces the keyring and systemd-creds with fakes for the duration of the test.
+func useFakeSeedStores(t *testing.T) *memKeyring {
+	t.Helper()
+	cmd := filepath.Join(t.TempDir(), "systemd-creds")
+	if err := ioutil.WriteFile(cmd, []byte(fakeSystemdCreds), 0700); err != nil {
+		t.Fatal(err)
+	}
+	kr := &memKeyring{keys: map[string][]byte{}}
+
+	prev := seedStores
+	seedStores = map[SeedBackend]seedStore{
+		SeedBackendKeyring: &keyringSeedStore{
+			keyring: kr,
+			escrow:  &systemdCredsSeedStore{command: cmd},
+		},
+		SeedBackendSystemdCreds: &systemdCredsSeedStore{command: cmd},
+	}
+	t.Cleanup(func() { seedStores = prev })
+	return kr
+}
+
+// credFiles returns the systemd credentials holding seeds, or their escrow copies, in the vault directory.
+func credFiles(t *testing.T, vaultPath string) []string {
+	t.Helper()
+	creds, err := filepath.Glob(filepath.Join(vaultPath, seedFile+".*.cred"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return creds
+}
+
+func TestMigrateSeed(t *testing.T) {
+	kr := useFakeSeedStores(t)
+	vaultPath := getTestVaultPath(t)
+
+	v, err := New(vaultPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Set("foo", []byte("bar")); err != nil {
+		t.Fatal(err)
+	}
+	seed := v.key
+
+	for _, backend := range []SeedBackend{SeedBackendKeyring, SeedBackendSystemdCreds, SeedBackendFile} {
+		t.Run(string(backend), func(t *testing.T) {
+			if err := v.MigrateSeed(backend); err != nil {
+				t.Fatal(err)
+			}
+
+			// a new vault finds the seed in the backend
+			migrated, err := New(vaultPath, WithReadonly(true))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if migrated.SeedBackend() != backend {
+				t.Fatalf("expected backend %s, got %s", backend, migrated.SeedBackend())
+			}
+			if !bytes.Equal(migrated.key, seed) {
+				t.Fatal("seed changed by the migration")
+			}
+			got, err := migrated.Get("foo")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff([]byte("bar"), got); diff != "" {
+				t.Fatal(diff)
+			}
+
+			b, err := ioutil.ReadFile(filepath.Join(vaultPath, seedFile))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if backend != SeedBackendFile && bytes.Contains(b, seed) {
+				t.Fatal("seed file still holds the seed")
+			}
+
+			// the seed is only held by the current backend, the keyring keeps an escrow credential
+			var wantKeys int
+			if backend == SeedBackendKeyring {
+				wantKeys = 1
+			}
+			if len(kr.keys) != wantKeys {
+				t.Fatalf("expected %d keys in the keyring, got %d", wantKeys, len(kr.keys))
+			}
+			creds := credFiles(t, vaultPath)
+			var wantCreds int
+			if backend != SeedBackendFile {
+				wantCreds = 1
+			}
+			if len(creds) != wantCreds {
+				t.Fatalf("expected %d systemd credentials, got %d", wantCreds, len(creds))
+			}
+		})
+	}
+}
+
+func TestKeyringSeedAfterReboot(t *testing.T) {
+	kr := useFakeSeedStores(t)
+	vaultPath := getTestVaultPath(t)
+
+	v, err := New(vaultPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Set("foo", []byte("bar")); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.MigrateSeed(SeedBackendKeyring); err != nil {
+		t.Fatal(err)
+	}
+
+	// the keyring is empty after a reboot, the seed is restored from the escrow copy
+	kr.keys = map[string][]byte{}
+	rebooted, err := New(vaultPath, WithReadonly(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := rebooted.Get("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]byte("bar"), got); diff != "" {
+		t.Fatal(diff)
+	}
+	if diff := cmp.Diff(v.key, kr.keys[v.ref.Name]); diff != "" {
+		t.Fatalf("keyring was not reseeded: %s", diff)
+	}
+
+	// without the escrow copy the seed is lost
+	kr.keys = map[string][]byte{}
+	if err := os.Remove(filepath.Join(vaultPath, escrowName(v.ref.Name))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(vaultPath, WithReadonly(true)); err == nil {
+		t.Fatal("expected the vault to fail without the seed")
+	}
+}
+
+func TestRotationWithSeedBackend(t *testing.T) {
+	useFakeSeedStores(t)
+	vaultPath := getTestVaultPath(t)
+
+	v, err := New(vaultPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Set("foo", []byte("bar")); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.MigrateSeed(SeedBackendSystemdCreds); err != nil {
+		t.Fatal(err)
+	}
+	prevRef := *v.ref
+
+	r, err := v.BeginRotation("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	if v.ref == nil || v.ref.Name == prevRef.Name {
+		t.Fatal("expected the rotated seed to be held by a new credential")
+	}
+	if _, err := os.Stat(filepath.Join(vaultPath, prevRef.Name)); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatal("previous seed credential was not removed")
+	}
+	rotated, err := New(vaultPath, WithReadonly(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := rotated.Get("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]byte("bar"), got); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestMigrateSeedRecovery(t *testing.T) {
+	useFakeSeedStores(t)
+	vaultPath := getTestVaultPath(t)
+
+	v, err := New(vaultPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Set("foo", []byte("bar")); err != nil {
+		t.Fatal(err)
+	}
+
+	// crash after the seed was stored in the credential, before the seed file was replaced
+	v.mx.Lock()
+	if _, err := v.startRotation(v.key, SeedBackendSystemdCreds); err != nil {
+		t.Fatal(err)
+	}
+	v.mx.Unlock()
+	if creds := credFiles(t, vaultPath); len(creds) != 1 {
+		t.Fatalf("expected the seed in a credential, got %d credentials", len(creds))
+	}
+
+	recovered, err := New(vaultPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recovered.backend() != SeedBackendFile {
+		t.Fatalf("expected the migration to be rolled back, got backend %s", recovered.backend())
+	}
+	if creds := credFiles(t, vaultPath); len(creds) != 0 {
+		t.Fatalf("expected the staged seed credential to be removed, got %d credentials", len(creds))
+	}
+	if _, err := recovered.Get("foo"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigrateSeedUnreadableBackend(t *testing.T) {
+	useFakeSeedStores(t)
+	seedStores[SeedBackendSystemdCreds] = &systemdCredsSeedStore{command: "/bin/false"}
+	vaultPath := getTestVaultPath(t)
+
+	v, err := New(vaultPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.MigrateSeed(SeedBackendSystemdCreds); err == nil {
+		t.Fatal("expected the migration to fail")
+	}
+
+	if _, err := os.Stat(filepath.Join(vaultPath, rotationFile)); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected the rotation journal to be removed, got %v", err)
+	}
+	if _, err := New(vaultPath, WithReadonly(true)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKernelKeyring(t *testing.T) {
+	k := kernelKeyring{}
+	if _, err := k.ring(); err != nil {
+		// keyctl is commonly filtered by the seccomp profile of the containers
+		if errors.Is(err, unix.EPERM) || errors.Is(err, unix.ENOSYS) || errors.Is(err, unix.EACCES) {
+			t.Skipf("kernel keyring not available: %v", err)
+		}
+		t.Fatal(err)
+	}
+
+	description := fmt.Sprintf("%stest-%d", keyringKeyPrefix, os.Getpid())
+	t.Cleanup(func() { _ = k.unlink(description) })
+
+	if err := k.add(description, []byte("seed")); err != nil {
+		t.Fatal(err)
+	}
+	got, err := k.read(description)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]byte("seed"), got); diff != "" {
+		t.Fatal(diff)
+	}
+	if err := k.unlink(description); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := k.read(description); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected fs.ErrNotExist, got %v", err)
+	}
+	if err := k.unlink(description); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,21 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+//go:build !linux
+// +build !linux
+
+package vault
+
+// MigrateSeed only supports the default backend on this platform.
+func (v *Vault) MigrateSeed(backend SeedBackend) error {
+	if backend == "" || backend == SeedBackendFile {
+		return nil
+	}
+	return ErrSeedBackendNotSupported
+}
+
+// SeedBackend returns where the seed of the vault is held, always the default backend on this platform.
+func (v *Vault) SeedBackend() SeedBackend {
+	return SeedBackendFile
+}
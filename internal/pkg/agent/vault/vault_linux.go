@@ -27,7 +27,9 @@ const saltSize = 8
 type Vault struct {
 	path string
 	key  []byte
-	mx   sync.Mutex
+	// ref references the seed when it is not held by the seed file.
+	ref *seedRef
+	mx  sync.Mutex
 }
 
 // New creates the vault store
@@ -60,7 +62,11 @@ func New(path string, opts ...OptionFunc) (v *Vault, err error) {
 		}
 	}
 
-	key, err := getOrCreateSeed(path, options.readonly)
+	if err := recoverRotation(path); err != nil {
+		return nil, fmt.Errorf("could not recover the vault rotation: %w", err)
+	}
+
+	key, ref, err := loadSeed(path, options.readonly)
 	if err != nil {
 		return nil, fmt.Errorf("could not get seed to create new valt: %w", err)
 	}
@@ -68,6 +74,7 @@ func New(path string, opts ...OptionFunc) (v *Vault, err error) {
 	return &Vault{
 		path: path,
 		key:  key,
+		ref:  ref,
 	}, nil
 }
 
@@ -0,0 +1,14 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package vault
+
+import "errors"
+
+// ErrRotationNotSupported is returned when the seed of the vault cannot be rotated on the platform.
+var ErrRotationNotSupported = errors.New("vault seed rotation is not supported on this platform")
+
+// ErrRotationIncomplete is returned when the new seed of the vault is committed but the rotation
+// could not be completed, it is completed the next time the vault is opened.
+var ErrRotationIncomplete = errors.New("vault seed committed but the rotation is not completed")
@@ -0,0 +1,368 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+//go:build linux
+// +build linux
+
+package vault
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// newSeedFile holds the seed of a rotation until it is committed by renaming it to seedFile.
+	newSeedFile = ".seed.new"
+	// rotationFile is the journal of a rotation in progress.
+	rotationFile = ".rotation"
+	// replaceSuffix is appended to the files replacing their target when a rotation is committed.
+	replaceSuffix = ".rotate"
+)
+
+// rotationJournal records every file touched by a rotation so a rotation interrupted by a crash is
+// rolled back when the new seed is not committed yet and completed otherwise.
+type rotationJournal struct {
+	// Created are the entries encrypted with the new seed, removed on rollback.
+	Created []string `json:"created"`
+	// Removed are the entries encrypted with the previous seed, removed once committed.
+	Removed []string `json:"removed"`
+	// Replaced are the files replacing their target once committed, removed on rollback.
+	Replaced []string `json:"replaced"`
+	// NewSeed is the new seed when it is not held by the seed file, removed on rollback.
+	NewSeed *seedRef `json:"new_seed,omitempty"`
+	// PrevSeed is the previous seed when it is not held by the seed file, removed once committed.
+	PrevSeed *seedRef `json:"prev_seed,omitempty"`
+}
+
+// Rotation is a rotation of the seed of the vault in progress. The entries are re-encrypted with
+// a new seed and the rotation is committed by atomically replacing the seed, the previous seed is
+// kept until then. The vault cannot be used until the rotation is committed or aborted.
+type Rotation struct {
+	v       *Vault
+	seed    []byte
+	ref     *seedRef
+	journal rotationJournal
+	done    bool
+}
+
+// BeginRotation starts the rotation of the seed of the vault, the entries of the keys are
+// re-encrypted with a new seed. The vault must not hold entries of other keys as they could
+// not be read anymore after the rotation.
+func (v *Vault) BeginRotation(keys ...string) (*Rotation, error) {
+	v.mx.Lock()
+
+	r, err := v.beginRotation(keys)
+	if err != nil {
+		v.mx.Unlock()
+		return nil, err
+	}
+	return r, nil
+}
+
+func (v *Vault) beginRotation(keys []string) (*Rotation, error) {
+	known := make(map[string]string, len(keys))
+	for _, key := range keys {
+		known[fileNameFromKey(v.key, key)] = key
+	}
+	files, err := ioutil.ReadDir(v.path)
+	if err != nil {
+		return nil, fmt.Errorf("could not list the vault entries: %w", err)
+	}
+	var existing []string
+	for _, f := range files {
+		if strings.HasPrefix(f.Name(), ".") {
+			continue
+		}
+		key, ok := known[f.Name()]
+		if !ok {
+			return nil, fmt.Errorf("vault %s holds entries of unknown keys, cannot rotate its seed", v.path)
+		}
+		existing = append(existing, key)
+	}
+
+	seed, err := NewKey(AES256)
+	if err != nil {
+		return nil, err
+	}
+	r, err := v.startRotation(seed, v.backend())
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range existing {
+		enc, err := ioutil.ReadFile(filepath.Join(v.path, fileNameFromKey(v.key, key)))
+		if err != nil {
+			_ = r.rollback()
+			return nil, fmt.Errorf("could not read the vault entry of %s: %w", key, err)
+		}
+		data, err := v.decrypt(enc)
+		if err != nil {
+			_ = r.rollback()
+			return nil, fmt.Errorf("could not decrypt the vault entry of %s: %w", key, err)
+		}
+		if err := r.set(key, data); err != nil {
+			_ = r.rollback()
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// startRotation stages the seed replacing the seed of the vault, held by the backend.
+func (v *Vault) startRotation(seed []byte, backend SeedBackend) (*Rotation, error) {
+	if _, err := os.Stat(filepath.Join(v.path, rotationFile)); err == nil {
+		return nil, fmt.Errorf("a rotation of the vault %s is already in progress", v.path)
+	}
+
+	ref, err := newSeedRef(backend)
+	if err != nil {
+		return nil, err
+	}
+	r := &Rotation{
+		v:       v,
+		seed:    seed,
+		ref:     ref,
+		journal: rotationJournal{NewSeed: ref, PrevSeed: v.ref},
+	}
+
+	// the journal is always written before the files it lists are created
+	if err := r.writeJournal(); err != nil {
+		return nil, err
+	}
+	content, err := storeSeed(v.path, ref, seed)
+	if err != nil {
+		_ = r.rollback()
+		return nil, err
+	}
+	if err := writeFileSync(filepath.Join(v.path, newSeedFile), content, 0600); err != nil {
+		_ = r.rollback()
+		return nil, err
+	}
+	return r, nil
+}
+
+// Set stores the value of a key encrypted with the new seed, replacing the re-encrypted value.
+func (r *Rotation) Set(key string, data []byte) error {
+	if r.done {
+		return errors.New("vault rotation is already completed")
+	}
+	return r.set(key, data)
+}
+
+func (r *Rotation) set(key string, data []byte) error {
+	created := fileNameFromKey(r.seed, key)
+	removed := fileNameFromKey(r.v.key, key)
+	r.journal.Created = appendOnce(r.journal.Created, created)
+	if _, err := os.Stat(filepath.Join(r.v.path, removed)); err == nil {
+		r.journal.Removed = appendOnce(r.journal.Removed, removed)
+	}
+	if err := r.writeJournal(); err != nil {
+		return err
+	}
+
+	enc, err := encryptWithSeed(r.seed, data)
+	if err != nil {
+		return err
+	}
+	return writeFileSync(filepath.Join(r.v.path, created), enc, 0600)
+}
+
+// ReplaceFile writes the content of a file that replaces target when the rotation is committed,
+// the target is left untouched when the rotation is aborted.
+func (r *Rotation) ReplaceFile(target string, perm os.FileMode, write func(io.Writer) error) error {
+	if r.done {
+		return errors.New("vault rotation is already completed")
+	}
+
+	r.journal.Replaced = appendOnce(r.journal.Replaced, target)
+	if err := r.writeJournal(); err != nil {
+		return err
+	}
+
+	tmp := target + replaceSuffix
+	fd, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	if err := write(fd); err != nil {
+		fd.Close()
+		return err
+	}
+	if err := fd.Sync(); err != nil {
+		fd.Close()
+		return err
+	}
+	return fd.Close()
+}
+
+// Commit replaces the seed of the vault with the new seed, then replaces the files and removes the
+// entries encrypted with the previous seed. ErrRotationIncomplete is returned when the seed is
+// committed but the rotation could not be completed.
+func (r *Rotation) Commit() error {
+	if r.done {
+		return errors.New("vault rotation is already completed")
+	}
+	r.done = true
+	defer r.v.mx.Unlock()
+
+	mxSeed.Lock()
+	err := os.Rename(filepath.Join(r.v.path, newSeedFile), filepath.Join(r.v.path, seedFile))
+	mxSeed.Unlock()
+	if err != nil {
+		_ = r.rollback()
+		return fmt.Errorf("could not commit the vault seed: %w", err)
+	}
+	// the new seed is committed, the vault uses it even when the rotation is not completed
+	r.v.key = r.seed
+	r.v.ref = r.ref
+	if err := syncDir(r.v.path); err != nil {
+		return fmt.Errorf("%w: %v", ErrRotationIncomplete, err)
+	}
+	if err := completeRotation(r.v.path, r.journal); err != nil {
+		return fmt.Errorf("%w: %v", ErrRotationIncomplete, err)
+	}
+	return nil
+}
+
+// Abort removes everything written by the rotation, the vault keeps its previous seed.
+func (r *Rotation) Abort() error {
+	if r.done {
+		return nil
+	}
+	r.done = true
+	defer r.v.mx.Unlock()
+	return r.rollback()
+}
+
+func (r *Rotation) rollback() error {
+	return rollbackRotation(r.v.path, r.journal)
+}
+
+func (r *Rotation) writeJournal() error {
+	b, err := json.Marshal(r.journal)
+	if err != nil {
+		return err
+	}
+	fp := filepath.Join(r.v.path, rotationFile)
+	if err := writeFileSync(fp+".tmp", b, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(fp+".tmp", fp); err != nil {
+		return err
+	}
+	return syncDir(r.v.path)
+}
+
+// recoverRotation rolls back or completes a rotation interrupted by a crash. The rotation is
+// committed once the new seed replaced the previous one.
+func recoverRotation(path string) error {
+	b, err := ioutil.ReadFile(filepath.Join(path, rotationFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not read the vault rotation journal: %w", err)
+	}
+
+	var journal rotationJournal
+	if err := json.Unmarshal(b, &journal); err != nil {
+		return fmt.Errorf("could not parse the vault rotation journal: %w", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(path, newSeedFile)); err == nil {
+		return rollbackRotation(path, journal)
+	}
+	return completeRotation(path, journal)
+}
+
+func rollbackRotation(path string, journal rotationJournal) error {
+	for _, name := range journal.Created {
+		if err := removeIfExists(filepath.Join(path, name)); err != nil {
+			return err
+		}
+	}
+	for _, target := range journal.Replaced {
+		if err := removeIfExists(target + replaceSuffix); err != nil {
+			return err
+		}
+	}
+	if err := removeIfExists(filepath.Join(path, newSeedFile)); err != nil {
+		return err
+	}
+	if err := removeSeed(path, journal.NewSeed); err != nil {
+		return err
+	}
+	return removeIfExists(filepath.Join(path, rotationFile))
+}
+
+func completeRotation(path string, journal rotationJournal) error {
+	for _, target := range journal.Replaced {
+		err := os.Rename(target+replaceSuffix, target)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("could not replace %s: %w", target, err)
+		}
+	}
+	for _, name := range journal.Removed {
+		if err := removeIfExists(filepath.Join(path, name)); err != nil {
+			return err
+		}
+	}
+	if err := removeSeed(path, journal.PrevSeed); err != nil {
+		return err
+	}
+	return removeIfExists(filepath.Join(path, rotationFile))
+}
+
+func encryptWithSeed(seed []byte, data []byte) ([]byte, error) {
+	v := &Vault{key: seed}
+	return v.encrypt(data)
+}
+
+func writeFileSync(fp string, data []byte, perm os.FileMode) error {
+	fd, err := os.OpenFile(fp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := fd.Write(data); err != nil {
+		fd.Close()
+		return err
+	}
+	if err := fd.Sync(); err != nil {
+		fd.Close()
+		return err
+	}
+	return fd.Close()
+}
+
+func syncDir(path string) error {
+	d, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+func removeIfExists(fp string) error {
+	if err := os.Remove(fp); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func appendOnce(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
@@ -0,0 +1,334 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+//go:build linux
+// +build linux
+
+package vault
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestVaultRotation(t *testing.T) {
+	vaultPath := getTestVaultPath(t)
+	target := filepath.Join(t.TempDir(), "target")
+	if err := ioutil.WriteFile(target, []byte("previous"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := New(vaultPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Set("foo", []byte("bar")); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Set("secret", []byte("previous secret")); err != nil {
+		t.Fatal(err)
+	}
+	prevSeed, err := ioutil.ReadFile(filepath.Join(vaultPath, seedFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := v.BeginRotation("foo", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Set("secret", []byte("new secret")); err != nil {
+		t.Fatal(err)
+	}
+	err = r.ReplaceFile(target, 0600, func(w io.Writer) error {
+		_, err := w.Write([]byte("rotated"))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	seed, err := ioutil.ReadFile(filepath.Join(vaultPath, seedFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(prevSeed, seed) {
+		t.Fatal("seed was not rotated")
+	}
+
+	// a new vault instance reads the entries with the new seed
+	v, err = New(vaultPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{"foo": "bar", "secret": "new secret"} {
+		got, err := v.Get(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(want, string(got)); diff != "" {
+			t.Fatal(diff)
+		}
+	}
+
+	content, err := ioutil.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff("rotated", string(content)); diff != "" {
+		t.Fatal(diff)
+	}
+
+	// only the entries of the new seed are left
+	diff := cmp.Diff([]string{
+		fileNameFromKey(seed, "foo"),
+		fileNameFromKey(seed, "secret"),
+	}, vaultEntries(t, vaultPath), sortStrings)
+	if diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestVaultRotationAbort(t *testing.T) {
+	vaultPath := getTestVaultPath(t)
+	target := filepath.Join(t.TempDir(), "target")
+	if err := ioutil.WriteFile(target, []byte("previous"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := New(vaultPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Set("foo", []byte("bar")); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := v.BeginRotation("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.ReplaceFile(target, 0600, func(w io.Writer) error {
+		_, err := w.Write([]byte("rotated"))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Abort(); err != nil {
+		t.Fatal(err)
+	}
+
+	assertVaultUnchanged(t, v, vaultPath, target)
+}
+
+func TestVaultRotationIncomplete(t *testing.T) {
+	vaultPath := getTestVaultPath(t)
+	target := filepath.Join(t.TempDir(), "target")
+
+	v, err := New(vaultPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Set("foo", []byte("bar")); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := v.BeginRotation("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.ReplaceFile(target, 0600, func(w io.Writer) error {
+		_, err := w.Write([]byte("rotated"))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the target cannot be replaced by a file
+	if err := os.MkdirAll(filepath.Join(target, "dir"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	err = r.Commit()
+	if !errors.Is(err, ErrRotationIncomplete) {
+		t.Fatalf("expected ErrRotationIncomplete, got %v", err)
+	}
+	// the vault uses the committed seed
+	b, err := v.Get("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff("bar", string(b)); diff != "" {
+		t.Fatal(diff)
+	}
+
+	// the rotation is completed when the vault is opened again
+	if err := os.RemoveAll(target); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(vaultPath); err != nil {
+		t.Fatal(err)
+	}
+	content, err := ioutil.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff("rotated", string(content)); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestVaultRotationUnknownEntries(t *testing.T) {
+	vaultPath := getTestVaultPath(t)
+	v, err := New(vaultPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Set("foo", []byte("bar")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := v.BeginRotation("other"); err == nil {
+		t.Fatal("expected rotation to fail with entries of unknown keys")
+	}
+
+	// the vault is still usable
+	if _, err := v.Get("foo"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVaultRotationRecovery(t *testing.T) {
+	prepare := func(t *testing.T) (*Vault, *Rotation, string, string) {
+		vaultPath := getTestVaultPath(t)
+		target := filepath.Join(t.TempDir(), "target")
+		if err := ioutil.WriteFile(target, []byte("previous"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		v, err := New(vaultPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := v.Set("foo", []byte("bar")); err != nil {
+			t.Fatal(err)
+		}
+		r, err := v.BeginRotation("foo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = r.ReplaceFile(target, 0600, func(w io.Writer) error {
+			_, err := w.Write([]byte("rotated"))
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v, r, vaultPath, target
+	}
+
+	t.Run("crash before commit rolls back", func(t *testing.T) {
+		v, r, vaultPath, target := prepare(t)
+		// simulate a crash: the rotation is neither committed nor aborted
+		r.done = true
+		v.mx.Unlock()
+
+		v, err := New(vaultPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertVaultUnchanged(t, v, vaultPath, target)
+	})
+
+	t.Run("crash after commit completes", func(t *testing.T) {
+		v, r, vaultPath, target := prepare(t)
+		// simulate a crash right after the new seed is committed
+		if err := os.Rename(filepath.Join(vaultPath, newSeedFile), filepath.Join(vaultPath, seedFile)); err != nil {
+			t.Fatal(err)
+		}
+		r.done = true
+		v.mx.Unlock()
+
+		v, err := New(vaultPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := v.Get("foo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff("bar", string(got)); diff != "" {
+			t.Fatal(diff)
+		}
+		content, err := ioutil.ReadFile(target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff("rotated", string(content)); diff != "" {
+			t.Fatal(diff)
+		}
+		if diff := cmp.Diff([]string{fileNameFromKey(r.seed, "foo")}, vaultEntries(t, vaultPath)); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+}
+
+func assertVaultUnchanged(t *testing.T, v *Vault, vaultPath string, target string) {
+	t.Helper()
+
+	got, err := v.Get("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff("bar", string(got)); diff != "" {
+		t.Fatal(diff)
+	}
+	content, err := ioutil.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff("previous", string(content)); diff != "" {
+		t.Fatal(diff)
+	}
+	if _, err := os.Stat(target + replaceSuffix); !os.IsNotExist(err) {
+		t.Fatalf("expected the replacement of the target to be removed, got: %v", err)
+	}
+	if diff := cmp.Diff([]string{fileNameFromKey(v.key, "foo")}, vaultEntries(t, vaultPath)); diff != "" {
+		t.Fatal(diff)
+	}
+	for _, name := range []string{newSeedFile, rotationFile} {
+		if _, err := os.Stat(filepath.Join(vaultPath, name)); !os.IsNotExist(err) {
+			t.Fatalf("expected %s to be removed, got: %v", name, err)
+		}
+	}
+}
+
+func vaultEntries(t *testing.T, vaultPath string) []string {
+	t.Helper()
+
+	files, err := ioutil.ReadDir(vaultPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []string
+	for _, f := range files {
+		if f.Name()[0] != '.' {
+			entries = append(entries, f.Name())
+		}
+	}
+	return entries
+}
+
+var sortStrings = cmpopts.SortSlices(func(a, b string) bool { return a < b })
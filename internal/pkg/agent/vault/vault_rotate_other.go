@@ -0,0 +1,41 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+//go:build !linux
+// +build !linux
+
+package vault
+
+import (
+	"io"
+	"os"
+)
+
+// Rotation is a rotation of the seed of the vault, only supported on Linux.
+type Rotation struct{}
+
+// BeginRotation is not supported on this platform.
+func (v *Vault) BeginRotation(_ ...string) (*Rotation, error) {
+	return nil, ErrRotationNotSupported
+}
+
+// Set is not supported on this platform.
+func (r *Rotation) Set(_ string, _ []byte) error {
+	return ErrRotationNotSupported
+}
+
+// ReplaceFile is not supported on this platform.
+func (r *Rotation) ReplaceFile(_ string, _ os.FileMode, _ func(io.Writer) error) error {
+	return ErrRotationNotSupported
+}
+
+// Commit is not supported on this platform.
+func (r *Rotation) Commit() error {
+	return ErrRotationNotSupported
+}
+
+// Abort is not supported on this platform.
+func (r *Rotation) Abort() error {
+	return ErrRotationNotSupported
+}
@@ -0,0 +1,214 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+// Package bundle implements offline upgrade bundles. A bundle is an
+// uncompressed tar archive holding the Elastic Agent package together with its
+// .sha512 and .asc sidecar files, optionally followed by program artifacts,
+// and a manifest describing the content. Extracted bundles are laid out the
+// same way as a drop path so the fs downloader and verifier can consume them.
+package bundle
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+)
+
+const (
+	// ManifestFile is the name of the manifest stored in the bundle.
+	ManifestFile = "manifest.yml"
+
+	hashSuffix = ".sha512"
+	ascSuffix  = ".asc"
+)
+
+// Manifest describes the content of a bundle.
+type Manifest struct {
+	// Version is the version of Elastic Agent contained in the bundle.
+	Version string `yaml:"version"`
+	// OS is the operating system the bundle was created for.
+	OS string `yaml:"os"`
+	// Arch is the architecture the bundle was created for.
+	Arch string `yaml:"arch"`
+	// Agent is the Elastic Agent package.
+	Agent Artifact `yaml:"agent"`
+	// Artifacts are optional program packages.
+	Artifacts []Artifact `yaml:"artifacts,omitempty"`
+}
+
+// Artifact is a single package in the bundle.
+type Artifact struct {
+	// Name is the name of the program.
+	Name string `yaml:"name"`
+	// Package is the file name of the package.
+	Package string `yaml:"package"`
+	// Signed is true when the bundle contains the .asc signature of the package.
+	Signed bool `yaml:"signed"`
+}
+
+// Files returns the names of the files belonging to the artifact.
+func (a Artifact) Files() []string {
+	files := []string{a.Package, a.Package + hashSuffix}
+	if a.Signed {
+		files = append(files, a.Package+ascSuffix)
+	}
+	return files
+}
+
+// files returns the names of all files referenced by the manifest.
+func (m *Manifest) files() []string {
+	files := m.Agent.Files()
+	for _, a := range m.Artifacts {
+		files = append(files, a.Files()...)
+	}
+	return files
+}
+
+func (m *Manifest) validate() error {
+	if m.Version == "" {
+		return errors.New("bundle manifest is missing version", errors.TypeConfig)
+	}
+	if m.Agent.Package == "" {
+		return errors.New("bundle manifest is missing agent package", errors.TypeConfig)
+	}
+	for _, f := range m.files() {
+		if !validFileName(f) {
+			return errors.New(fmt.Sprintf("bundle manifest contains invalid file name %q", f), errors.TypeConfig)
+		}
+	}
+	return nil
+}
+
+// IsBundle returns true when path points to a regular file, which is the way
+// a bundle is distinguished from a drop path directory.
+func IsBundle(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.Mode().IsRegular()
+}
+
+// ReadManifest reads the manifest of the bundle without extracting it.
+func ReadManifest(path string) (*Manifest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.New(err, "opening bundle", errors.TypeFilesystem, errors.M(errors.MetaKeyPath, path))
+	}
+	defer f.Close()
+
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, errors.New(err, "reading bundle", errors.TypeFilesystem, errors.M(errors.MetaKeyPath, path))
+		}
+
+		if hdr.Name == ManifestFile {
+			return decodeManifest(tr)
+		}
+	}
+
+	return nil, errors.New(fmt.Sprintf("bundle %q does not contain %s", path, ManifestFile), errors.TypeFilesystem, errors.M(errors.MetaKeyPath, path))
+}
+
+// Extract extracts the bundle into dir and checks that every file listed in
+// the manifest is present. Extracted files are not verified, verification is
+// left to the download verifiers.
+func Extract(path, dir string) (*Manifest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.New(err, "opening bundle", errors.TypeFilesystem, errors.M(errors.MetaKeyPath, path))
+	}
+	defer f.Close()
+
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, errors.New(err, "creating bundle directory", errors.TypeFilesystem, errors.M(errors.MetaKeyPath, dir))
+	}
+
+	var manifest *Manifest
+	extracted := make(map[string]bool)
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, errors.New(err, "reading bundle", errors.TypeFilesystem, errors.M(errors.MetaKeyPath, path))
+		}
+
+		if !validFileName(hdr.Name) {
+			return nil, errors.New(fmt.Sprintf("bundle contains invalid file name %q", hdr.Name), errors.TypeFilesystem, errors.M(errors.MetaKeyPath, path))
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			return nil, errors.New(fmt.Sprintf("bundle entry %q is not a regular file", hdr.Name), errors.TypeFilesystem, errors.M(errors.MetaKeyPath, path))
+		}
+
+		if hdr.Name == ManifestFile {
+			manifest, err = decodeManifest(tr)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if err := extractFile(tr, filepath.Join(dir, hdr.Name)); err != nil {
+			return nil, err
+		}
+		extracted[hdr.Name] = true
+	}
+
+	if manifest == nil {
+		return nil, errors.New(fmt.Sprintf("bundle %q does not contain %s", path, ManifestFile), errors.TypeFilesystem, errors.M(errors.MetaKeyPath, path))
+	}
+
+	for _, name := range manifest.files() {
+		if !extracted[name] {
+			return nil, errors.New(fmt.Sprintf("bundle is missing file %q", name), errors.TypeFilesystem, errors.M(errors.MetaKeyPath, path))
+		}
+	}
+
+	return manifest, nil
+}
+
+func decodeManifest(r io.Reader) (*Manifest, error) {
+	var m Manifest
+	if err := yaml.NewDecoder(r).Decode(&m); err != nil {
+		return nil, errors.New(err, "decoding bundle manifest", errors.TypeConfig)
+	}
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func extractFile(r io.Reader, dst string) error {
+	w, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0640)
+	if err != nil {
+		return errors.New(err, "creating file", errors.TypeFilesystem, errors.M(errors.MetaKeyPath, dst))
+	}
+
+	//nolint:gosec // size of the bundle is controlled by the operator
+	_, err = io.Copy(w, r)
+	if closeErr := w.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.New(err, "extracting file", errors.TypeFilesystem, errors.M(errors.MetaKeyPath, dst))
+	}
+	return nil
+}
+
+// validFileName accepts only plain file names, bundles are flat.
+func validFileName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
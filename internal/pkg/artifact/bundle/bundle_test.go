@@ -0,0 +1,116 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package bundle
+
+import (
+	"archive/tar"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteExtract(t *testing.T) {
+	src := t.TempDir()
+	manifest := &Manifest{
+		Version: "8.6.0",
+		OS:      "linux",
+		Arch:    "64",
+		Agent:   Artifact{Name: "elastic-agent", Package: "elastic-agent-8.6.0-linux-x86_64.tar.gz", Signed: true},
+		Artifacts: []Artifact{
+			{Name: "filebeat", Package: "filebeat-8.6.0-linux-x86_64.tar.gz"},
+		},
+	}
+	for _, name := range manifest.files() {
+		require.NoError(t, os.WriteFile(filepath.Join(src, name), []byte(name), 0600))
+	}
+
+	bundlePath := filepath.Join(t.TempDir(), "bundle.tar")
+	require.NoError(t, Write(bundlePath, manifest, src))
+	assert.True(t, IsBundle(bundlePath))
+	assert.False(t, IsBundle(src))
+
+	read, err := ReadManifest(bundlePath)
+	require.NoError(t, err)
+	assert.Equal(t, manifest, read)
+
+	dst := t.TempDir()
+	extracted, err := Extract(bundlePath, dst)
+	require.NoError(t, err)
+	assert.Equal(t, manifest, extracted)
+
+	for _, name := range manifest.files() {
+		content, err := os.ReadFile(filepath.Join(dst, name))
+		require.NoError(t, err)
+		assert.Equal(t, name, string(content))
+	}
+}
+
+func TestWriteMissingFile(t *testing.T) {
+	manifest := &Manifest{
+		Version: "8.6.0",
+		Agent:   Artifact{Name: "elastic-agent", Package: "elastic-agent-8.6.0-linux-x86_64.tar.gz"},
+	}
+
+	bundlePath := filepath.Join(t.TempDir(), "bundle.tar")
+	require.Error(t, Write(bundlePath, manifest, t.TempDir()))
+
+	_, err := os.Stat(bundlePath)
+	assert.True(t, os.IsNotExist(err), "partial bundle should be removed")
+}
+
+func TestExtractInvalid(t *testing.T) {
+	t.Run("path traversal", func(t *testing.T) {
+		bundlePath := writeRawBundle(t, map[string]string{
+			ManifestFile:      "version: 8.6.0\nagent:\n  package: agent.tar.gz\n",
+			"../agent.tar.gz": "evil",
+		})
+		_, err := Extract(bundlePath, t.TempDir())
+		require.Error(t, err)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		bundlePath := writeRawBundle(t, map[string]string{
+			ManifestFile:   "version: 8.6.0\nagent:\n  package: agent.tar.gz\n",
+			"agent.tar.gz": "content",
+		})
+		_, err := Extract(bundlePath, t.TempDir())
+		require.Error(t, err)
+	})
+
+	t.Run("missing manifest", func(t *testing.T) {
+		bundlePath := writeRawBundle(t, map[string]string{
+			"agent.tar.gz": "content",
+		})
+		_, err := Extract(bundlePath, t.TempDir())
+		require.Error(t, err)
+		_, err = ReadManifest(bundlePath)
+		require.Error(t, err)
+	})
+}
+
+func writeRawBundle(t *testing.T, files map[string]string) string {
+	t.Helper()
+	bundlePath := filepath.Join(t.TempDir(), "bundle.tar")
+	f, err := os.Create(bundlePath)
+	require.NoError(t, err)
+	defer f.Close()
+
+	tw := tar.NewWriter(f)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0600,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return bundlePath
+}
@@ -0,0 +1,171 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package bundle
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/program"
+	"github.com/elastic/elastic-agent/internal/pkg/artifact"
+	"github.com/elastic/elastic-agent/internal/pkg/artifact/download/fs"
+	"github.com/elastic/elastic-agent/internal/pkg/artifact/download/http"
+	"github.com/elastic/elastic-agent/internal/pkg/release"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+// AgentSpec is the specification of the Elastic Agent package.
+var AgentSpec = program.Spec{
+	Name:     "Elastic Agent",
+	Cmd:      "elastic-agent",
+	Artifact: "beats/elastic-agent",
+}
+
+// Create downloads and verifies the Elastic Agent package of the given version
+// and the packages of the given programs and writes them into a bundle at out.
+func Create(ctx context.Context, log *logger.Logger, cfg *artifact.Config, version string, programs []program.Spec, out string) error {
+	workDir, err := os.MkdirTemp("", "elastic-agent-bundle-")
+	if err != nil {
+		return errors.New(err, "creating working directory", errors.TypeFilesystem)
+	}
+	defer os.RemoveAll(workDir)
+
+	settings := *cfg
+	settings.TargetDirectory = workDir
+
+	allowEmptyPgp, pgp := release.PGP()
+	downloader, err := http.NewDownloader(log, &settings)
+	if err != nil {
+		return errors.New(err, "initiating fetcher")
+	}
+	verifier, err := fs.NewVerifier(log, &settings, allowEmptyPgp, pgp)
+	if err != nil {
+		return errors.New(err, "initiating verifier")
+	}
+
+	fetch := func(spec program.Spec) (Artifact, error) {
+		path, err := downloader.Download(ctx, spec, version)
+		if err != nil {
+			return Artifact{}, errors.New(err, fmt.Sprintf("downloading %s", spec.Cmd))
+		}
+
+		signed := true
+		if _, err := downloader.DownloadAsc(ctx, spec, version); err != nil {
+			if !allowEmptyPgp {
+				return Artifact{}, errors.New(err, fmt.Sprintf("downloading signature of %s", spec.Cmd))
+			}
+			log.Warnw("Signature not available, bundling unsigned package", "package", filepath.Base(path), "error.message", err)
+			signed = false
+		}
+
+		if err := verifier.Verify(spec, version); err != nil {
+			return Artifact{}, errors.New(err, fmt.Sprintf("verifying %s", spec.Cmd))
+		}
+
+		return Artifact{Name: spec.Cmd, Package: filepath.Base(path), Signed: signed}, nil
+	}
+
+	manifest := Manifest{
+		Version: version,
+		OS:      settings.OS(),
+		Arch:    settings.Arch(),
+	}
+
+	manifest.Agent, err = fetch(AgentSpec)
+	if err != nil {
+		return err
+	}
+
+	for _, spec := range programs {
+		a, err := fetch(spec)
+		if err != nil {
+			return err
+		}
+		manifest.Artifacts = append(manifest.Artifacts, a)
+	}
+
+	return Write(out, &manifest, workDir)
+}
+
+// Write writes a bundle at out with the given manifest, files referenced by
+// the manifest are read from dir.
+func Write(out string, manifest *Manifest, dir string) (err error) {
+	if err := manifest.validate(); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(out, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0640)
+	if err != nil {
+		return errors.New(err, "creating bundle", errors.TypeFilesystem, errors.M(errors.MetaKeyPath, out))
+	}
+	defer func() {
+		if closeErr := f.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(out)
+		}
+	}()
+
+	tw := tar.NewWriter(f)
+
+	// manifest goes first so it can be read without scanning the whole bundle
+	manifestBytes, err := yaml.Marshal(manifest)
+	if err != nil {
+		return errors.New(err, "encoding bundle manifest")
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Name:     ManifestFile,
+		Mode:     0640,
+		Size:     int64(len(manifestBytes)),
+		Typeflag: tar.TypeReg,
+	}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(manifestBytes); err != nil {
+		return err
+	}
+
+	for _, name := range manifest.files() {
+		if err := addFile(tw, filepath.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+
+	return tw.Close()
+}
+
+func addFile(tw *tar.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.New(err, "opening bundled file", errors.TypeFilesystem, errors.M(errors.MetaKeyPath, path))
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return errors.New(err, "reading bundled file", errors.TypeFilesystem, errors.M(errors.MetaKeyPath, path))
+	}
+
+	if err := tw.WriteHeader(&tar.Header{
+		Name:     filepath.Base(path),
+		Mode:     0640,
+		Size:     fi.Size(),
+		ModTime:  fi.ModTime(),
+		Typeflag: tar.TypeReg,
+	}); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(tw, f)
+	return err
+}
@@ -5,6 +5,7 @@
 package artifact
 
 import (
+	"fmt"
 	"runtime"
 	"strings"
 	"time"
@@ -14,6 +15,7 @@ import (
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
 	"github.com/elastic/elastic-agent/internal/pkg/config"
+	"github.com/elastic/elastic-agent/internal/pkg/proxy"
 	"github.com/elastic/elastic-agent/pkg/core/logger"
 )
 
@@ -53,9 +55,49 @@ type Config struct {
 	// If not provided FileSystem Downloader will fallback to /beats subfolder of elastic-agent directory.
 	DropPath string `yaml:"dropPath" config:"drop_path"`
 
+	// PGP: additional public keys trusted to sign artifacts next to the one embedded in elastic-agent.
+	PGP PGPConfig `yaml:"pgp" config:"pgp"`
+
+	// ProxyRules: set from the proxy rules of the agent, `agent.proxy.rules`, they are kept on reload.
+	ProxyRules proxy.Rules `json:"-" yaml:"-" config:",ignore"`
+
 	httpcommon.HTTPTransportSettings `config:",inline" yaml:",inline"` // Note: use anonymous struct for json inline
 }
 
+// PGPConfig describes additional keys used when verifying artifact signatures.
+type PGPConfig struct {
+	// KeysDir: directory containing ASCII armored public keys (.asc, .gpg, .pgp), every key found is trusted.
+	KeysDir string `yaml:"keysDir" config:"keys_dir"`
+
+	// Keys: explicitly listed public keys with optional expiration.
+	Keys []PGPKeyConfig `yaml:"keys" config:"keys"`
+}
+
+// PGPKeyConfig describes a single trusted public key.
+type PGPKeyConfig struct {
+	// Name: name used to report the key in logs, defaults to file name.
+	Name string `yaml:"name" config:"name"`
+
+	// Path: path to the ASCII armored public key.
+	Path string `yaml:"path" config:"path" validate:"required"`
+
+	// Expires: RFC3339 timestamp after which the key is no longer trusted.
+	Expires string `yaml:"expires" config:"expires"`
+}
+
+// ExpiresAt returns the parsed expiration of the key, zero time means the key does not expire.
+func (k PGPKeyConfig) ExpiresAt() (time.Time, error) {
+	if k.Expires == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, k.Expires)
+	if err != nil {
+		return time.Time{}, errors.New(err, fmt.Sprintf("invalid expiration for key %q", k.Path), errors.TypeConfig)
+	}
+	return t, nil
+}
+
 type Reloader struct {
 	log       *logger.Logger
 	cfg       *Config
@@ -106,6 +148,8 @@ func (r *Reloader) reloadConfig(rawConfig *config.Config) error {
 		TargetDirectory:       tmp.C.TargetDirectory,
 		InstallPath:           tmp.C.InstallPath,
 		DropPath:              tmp.C.DropPath,
+		PGP:                   tmp.C.PGP,
+		ProxyRules:            r.cfg.ProxyRules,
 		HTTPTransportSettings: tmp.C.HTTPTransportSettings,
 	}
 
@@ -201,12 +245,13 @@ func (c *Config) Arch() string {
 // Unpack reads a config object into the settings.
 func (c *Config) Unpack(cfg *c.C) error {
 	tmp := struct {
-		OperatingSystem string `json:"-" config:",ignore"`
-		Architecture    string `json:"-" config:",ignore"`
-		SourceURI       string `json:"sourceURI" config:"sourceURI"`
-		TargetDirectory string `json:"targetDirectory" config:"target_directory"`
-		InstallPath     string `yaml:"installPath" config:"install_path"`
-		DropPath        string `yaml:"dropPath" config:"drop_path"`
+		OperatingSystem string    `json:"-" config:",ignore"`
+		Architecture    string    `json:"-" config:",ignore"`
+		SourceURI       string    `json:"sourceURI" config:"sourceURI"`
+		TargetDirectory string    `json:"targetDirectory" config:"target_directory"`
+		InstallPath     string    `yaml:"installPath" config:"install_path"`
+		DropPath        string    `yaml:"dropPath" config:"drop_path"`
+		PGP             PGPConfig `yaml:"pgp" config:"pgp"`
 	}{
 		OperatingSystem: c.OperatingSystem,
 		Architecture:    c.Architecture,
@@ -214,6 +259,7 @@ func (c *Config) Unpack(cfg *c.C) error {
 		TargetDirectory: c.TargetDirectory,
 		InstallPath:     c.InstallPath,
 		DropPath:        c.DropPath,
+		PGP:             c.PGP,
 	}
 
 	if err := cfg.Unpack(&tmp); err != nil {
@@ -232,6 +278,8 @@ func (c *Config) Unpack(cfg *c.C) error {
 		TargetDirectory:       tmp.TargetDirectory,
 		InstallPath:           tmp.InstallPath,
 		DropPath:              tmp.DropPath,
+		PGP:                   tmp.PGP,
+		ProxyRules:            c.ProxyRules,
 		HTTPTransportSettings: transport,
 	}
 	return nil
@@ -61,7 +61,21 @@ func (e *Downloader) Download(ctx context.Context, spec program.Spec, version st
 
 	hashPath, err := e.downloadHash(e.config.OS(), spec, version)
 	downloadedFiles = append(downloadedFiles, hashPath)
-	return path, err
+	if err != nil {
+		return "", err
+	}
+
+	// signature is optional, when present in the drop path it is copied
+	// next to the package so the verifier does not need to fetch it
+	if _, err := os.Stat(filepath.Join(e.dropPath, filepath.Base(path)+ascSuffix)); err == nil {
+		ascPath, err := e.downloadFile(filepath.Base(path)+ascSuffix, path+ascSuffix)
+		downloadedFiles = append(downloadedFiles, ascPath)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return path, nil
 }
 
 func (e *Downloader) download(operatingSystem string, spec program.Spec, version string) (string, error) {
@@ -14,6 +14,7 @@ import (
 	"github.com/elastic/elastic-agent/internal/pkg/agent/program"
 	"github.com/elastic/elastic-agent/internal/pkg/artifact"
 	"github.com/elastic/elastic-agent/internal/pkg/artifact/download"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
 )
 
 const (
@@ -24,6 +25,7 @@ const (
 // The signature is validated against Elastic's public GPG key that is
 // embedded into Elastic Agent.
 type Verifier struct {
+	log           *logger.Logger
 	config        *artifact.Config
 	pgpBytes      []byte
 	allowEmptyPgp bool
@@ -31,12 +33,13 @@ type Verifier struct {
 
 // NewVerifier creates a verifier checking downloaded package on preconfigured
 // location against a key stored on elastic.co website.
-func NewVerifier(config *artifact.Config, allowEmptyPgp bool, pgp []byte) (*Verifier, error) {
+func NewVerifier(log *logger.Logger, config *artifact.Config, allowEmptyPgp bool, pgp []byte) (*Verifier, error) {
 	if len(pgp) == 0 && !allowEmptyPgp {
 		return nil, errors.New("expecting PGP but retrieved none", errors.TypeSecurity)
 	}
 
 	v := &Verifier{
+		log:           log,
 		config:        config,
 		allowEmptyPgp: allowEmptyPgp,
 		pgpBytes:      pgp,
@@ -76,7 +79,12 @@ func (v *Verifier) Verify(spec program.Spec, version string) error {
 }
 
 func (v *Verifier) verifyAsc(fullPath string) error {
-	if len(v.pgpBytes) == 0 {
+	keyring, err := download.NewKeyringFromConfig(v.pgpBytes, v.config.PGP)
+	if err != nil {
+		return err
+	}
+
+	if keyring.Len() == 0 {
 		// no pgp available skip verification process
 		return nil
 	}
@@ -89,7 +97,13 @@ func (v *Verifier) verifyAsc(fullPath string) error {
 		return err
 	}
 
-	return download.VerifyGPGSignature(fullPath, ascBytes, v.pgpBytes)
+	keyName, err := keyring.Verify(fullPath, ascBytes)
+	if err != nil {
+		return err
+	}
+
+	v.log.Infow("Verified artifact signature", "file", fullPath, "key", keyName)
+	return nil
 }
 
 func (v *Verifier) getPublicAsc(fullPath string) ([]byte, error) {
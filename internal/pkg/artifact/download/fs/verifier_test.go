@@ -22,6 +22,7 @@ import (
 	"github.com/elastic/elastic-agent/internal/pkg/artifact"
 	"github.com/elastic/elastic-agent/internal/pkg/artifact/download"
 	"github.com/elastic/elastic-agent/internal/pkg/release"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
 )
 
 const (
@@ -61,8 +62,9 @@ func TestFetchVerify(t *testing.T) {
 	err := prepareFetchVerifyTests(dropPath, targetPath, targetFilePath, hashTargetFilePath)
 	assert.NoError(t, err)
 
+	log, _ := logger.New("", false)
 	downloader := NewDownloader(config)
-	verifier, err := NewVerifier(config, true, nil)
+	verifier, err := NewVerifier(log, config, true, nil)
 	assert.NoError(t, err)
 
 	// first download verify should fail:
@@ -203,7 +205,8 @@ func TestVerify(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	testVerifier, err := NewVerifier(config, true, nil)
+	log, _ := logger.New("", false)
+	testVerifier, err := NewVerifier(log, config, true, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
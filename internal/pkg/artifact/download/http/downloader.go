@@ -54,6 +54,7 @@ type Downloader struct {
 func NewDownloader(log progressLogger, config *artifact.Config) (*Downloader, error) {
 	client, err := config.HTTPTransportSettings.Client(
 		httpcommon.WithAPMHTTPInstrumentation(),
+		config.ProxyRules.TransportOption(),
 	)
 	if err != nil {
 		return nil, err
@@ -76,6 +77,7 @@ func (e *Downloader) Reload(c *artifact.Config) error {
 	// reload client
 	client, err := c.HTTPTransportSettings.Client(
 		httpcommon.WithAPMHTTPInstrumentation(),
+		c.ProxyRules.TransportOption(),
 	)
 	if err != nil {
 		return errors.New(err, "http.downloader: failed to generate client out of config")
@@ -115,6 +117,28 @@ func (e *Downloader) Download(ctx context.Context, spec program.Spec, version st
 	return path, err
 }
 
+// DownloadAsc fetches the ASCII armored signature of the package from configured source.
+// Returns absolute path to downloaded signature placed next to the package.
+func (e *Downloader) DownloadAsc(ctx context.Context, spec program.Spec, version string) (string, error) {
+	filename, err := artifact.GetArtifactName(spec, version, e.config.OS(), e.config.Arch())
+	if err != nil {
+		return "", errors.New(err, "generating package name failed")
+	}
+
+	fullPath, err := artifact.GetArtifactPath(spec, version, e.config.OS(), e.config.Arch(), e.config.TargetDirectory)
+	if err != nil {
+		return "", errors.New(err, "generating package path failed")
+	}
+
+	path, err := e.downloadFile(ctx, spec.Artifact, filename+ascSuffix, fullPath+ascSuffix)
+	if err != nil && path != "" {
+		if err := os.Remove(path); err != nil {
+			e.log.Warnf("failed to cleanup %s: %v", path, err)
+		}
+	}
+	return path, err
+}
+
 func (e *Downloader) composeURI(artifactName, packageName string) (string, error) {
 	upstream := e.config.SourceURI
 	if !strings.HasPrefix(upstream, "http") && !strings.HasPrefix(upstream, "file") && !strings.HasPrefix(upstream, "/") {
@@ -18,6 +18,7 @@ import (
 	"github.com/elastic/elastic-agent/internal/pkg/agent/program"
 	"github.com/elastic/elastic-agent/internal/pkg/artifact"
 	"github.com/elastic/elastic-agent/internal/pkg/artifact/download"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
 )
 
 const (
@@ -27,6 +28,7 @@ const (
 // Verifier verifies a downloaded package by comparing with public ASC
 // file from elastic.co website.
 type Verifier struct {
+	log           *logger.Logger
 	config        *artifact.Config
 	client        http.Client
 	pgpBytes      []byte
@@ -35,13 +37,14 @@ type Verifier struct {
 
 // NewVerifier create a verifier checking downloaded package on preconfigured
 // location against a key stored on elastic.co website.
-func NewVerifier(config *artifact.Config, allowEmptyPgp bool, pgp []byte) (*Verifier, error) {
+func NewVerifier(log *logger.Logger, config *artifact.Config, allowEmptyPgp bool, pgp []byte) (*Verifier, error) {
 	if len(pgp) == 0 && !allowEmptyPgp {
 		return nil, errors.New("expecting PGP but retrieved none", errors.TypeSecurity)
 	}
 
 	client, err := config.HTTPTransportSettings.Client(
 		httpcommon.WithAPMHTTPInstrumentation(),
+		config.ProxyRules.TransportOption(),
 		httpcommon.WithModRoundtripper(func(rt http.RoundTripper) http.RoundTripper {
 			return withHeaders(rt, headers)
 		}),
@@ -51,6 +54,7 @@ func NewVerifier(config *artifact.Config, allowEmptyPgp bool, pgp []byte) (*Veri
 	}
 
 	v := &Verifier{
+		log:           log,
 		config:        config,
 		client:        *client,
 		allowEmptyPgp: allowEmptyPgp,
@@ -64,6 +68,7 @@ func (v *Verifier) Reload(c *artifact.Config) error {
 	// reload client
 	client, err := c.HTTPTransportSettings.Client(
 		httpcommon.WithAPMHTTPInstrumentation(),
+		c.ProxyRules.TransportOption(),
 		httpcommon.WithModRoundtripper(func(rt http.RoundTripper) http.RoundTripper {
 			return withHeaders(rt, headers)
 		}),
@@ -107,7 +112,12 @@ func (v *Verifier) Verify(spec program.Spec, version string) error {
 }
 
 func (v *Verifier) verifyAsc(spec program.Spec, version string) error {
-	if len(v.pgpBytes) == 0 {
+	keyring, err := download.NewKeyringFromConfig(v.pgpBytes, v.config.PGP)
+	if err != nil {
+		return err
+	}
+
+	if keyring.Len() == 0 {
 		// no pgp available skip verification process
 		return nil
 	}
@@ -135,7 +145,13 @@ func (v *Verifier) verifyAsc(spec program.Spec, version string) error {
 		return errors.New(err, fmt.Sprintf("fetching asc file from %s", ascURI), errors.TypeNetwork, errors.M(errors.MetaKeyURI, ascURI))
 	}
 
-	return download.VerifyGPGSignature(fullPath, ascBytes, v.pgpBytes)
+	keyName, err := keyring.Verify(fullPath, ascBytes)
+	if err != nil {
+		return err
+	}
+
+	v.log.Infow("Verified artifact signature", "file", fullPath, "key", keyName)
+	return nil
 }
 
 func (v *Verifier) composeURI(filename, artifactName string) (string, error) {
@@ -0,0 +1,198 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package download
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/openpgp" //nolint:staticcheck // crypto/openpgp is only receiving security updates.
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/internal/pkg/artifact"
+)
+
+// EmbeddedKeyName is the name under which the public key embedded into the
+// Elastic Agent binary is registered in a keyring.
+const EmbeddedKeyName = "elastic"
+
+// keyExtensions are the extensions of files loaded from a keys directory.
+var keyExtensions = []string{".asc", ".gpg", ".pgp"}
+
+// TrustedKey is a public GPG key trusted to sign artifacts.
+type TrustedKey struct {
+	// Name identifies the key in logs and errors.
+	Name string
+	// Expires is the time after which the key is no longer trusted. Zero
+	// value means the key never expires.
+	Expires time.Time
+
+	entities openpgp.EntityList
+}
+
+// Expired returns true when the key is not trusted anymore at the given time.
+func (k TrustedKey) Expired(now time.Time) bool {
+	return !k.Expires.IsZero() && now.After(k.Expires)
+}
+
+// Keyring is an ordered set of trusted keys. A signature is considered valid
+// when any of the non-expired keys verifies it, this allows artifacts signed
+// with a rotated or a mirror key to be verified next to the official key.
+type Keyring struct {
+	keys []TrustedKey
+	now  func() time.Time
+}
+
+// NewKeyring creates an empty keyring.
+func NewKeyring() *Keyring {
+	return &Keyring{now: time.Now}
+}
+
+// NewKeyringFromConfig creates a keyring containing the embedded public key
+// followed by the additional keys defined in the configuration.
+func NewKeyringFromConfig(embedded []byte, cfg artifact.PGPConfig) (*Keyring, error) {
+	k := NewKeyring()
+	if len(embedded) > 0 {
+		if err := k.Add(EmbeddedKeyName, embedded, time.Time{}); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, keyCfg := range cfg.Keys {
+		b, err := ioutil.ReadFile(keyCfg.Path)
+		if err != nil {
+			return nil, errors.New(err, "reading trusted key", errors.TypeFilesystem, errors.M(errors.MetaKeyPath, keyCfg.Path))
+		}
+
+		expires, err := keyCfg.ExpiresAt()
+		if err != nil {
+			return nil, err
+		}
+
+		name := keyCfg.Name
+		if name == "" {
+			name = filepath.Base(keyCfg.Path)
+		}
+		if err := k.Add(name, b, expires); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.KeysDir != "" {
+		if err := k.AddFromDir(cfg.KeysDir); err != nil {
+			return nil, err
+		}
+	}
+
+	return k, nil
+}
+
+// Add parses the ASCII armored public key and appends it to the keyring.
+func (k *Keyring) Add(name string, armoredKey []byte, expires time.Time) error {
+	entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(armoredKey))
+	if err != nil {
+		return errors.New(err, fmt.Sprintf("read armored key ring %q", name), errors.TypeSecurity)
+	}
+
+	k.keys = append(k.keys, TrustedKey{
+		Name:     name,
+		Expires:  expires,
+		entities: entities,
+	})
+	return nil
+}
+
+// AddFromDir loads every armored public key file (.asc, .gpg, .pgp) found in
+// dir in lexical order. Keys are named after their file name. Missing
+// directory is not considered an error.
+func (k *Keyring) AddFromDir(dir string) error {
+	entries, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return errors.New(err, "reading trusted keys directory", errors.TypeFilesystem, errors.M(errors.MetaKeyPath, dir))
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	for _, e := range entries {
+		if e.IsDir() || !hasKeyExtension(e.Name()) {
+			continue
+		}
+
+		path := filepath.Join(dir, e.Name())
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return errors.New(err, "reading trusted key", errors.TypeFilesystem, errors.M(errors.MetaKeyPath, path))
+		}
+
+		if err := k.Add(e.Name(), b, time.Time{}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Len returns the number of keys in the keyring, including expired ones.
+func (k *Keyring) Len() int {
+	return len(k.keys)
+}
+
+// Names returns the names of all keys in the keyring.
+func (k *Keyring) Names() []string {
+	names := make([]string, 0, len(k.keys))
+	for _, key := range k.keys {
+		names = append(names, key.Name)
+	}
+	return names
+}
+
+// Verify checks the detached ASCII armored signature of file against every
+// non-expired key in the keyring and returns the name of the first key which
+// validated it. If no key validates the signature then a
+// *download.InvalidSignatureError is returned.
+func (k *Keyring) Verify(file string, asciiArmorSignature []byte) (string, error) {
+	now := k.now()
+	tried := make([]string, 0, len(k.keys))
+	var lastErr error
+	for _, key := range k.keys {
+		if key.Expired(now) {
+			continue
+		}
+		tried = append(tried, key.Name)
+
+		f, err := os.Open(file)
+		if err != nil {
+			return "", errors.New(err, errors.TypeFilesystem, errors.M(errors.MetaKeyPath, file))
+		}
+		_, err = openpgp.CheckArmoredDetachedSignature(key.entities, f, bytes.NewReader(asciiArmorSignature))
+		f.Close()
+		if err == nil {
+			return key.Name, nil
+		}
+		lastErr = err
+	}
+
+	if lastErr == nil {
+		lastErr = errors.New("no trusted key available", errors.TypeSecurity)
+	}
+	return "", &InvalidSignatureError{File: file, Keys: tried, Err: lastErr}
+}
+
+func hasKeyExtension(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, e := range keyExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package download
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/openpgp"       //nolint:staticcheck // crypto/openpgp is only receiving security updates.
+	"golang.org/x/crypto/openpgp/armor" //nolint:staticcheck // crypto/openpgp is only receiving security updates.
+
+	"github.com/elastic/elastic-agent/internal/pkg/artifact"
+)
+
+func TestKeyring(t *testing.T) {
+	dir := t.TempDir()
+	artifactPath := filepath.Join(dir, "artifact.tar.gz")
+	require.NoError(t, ioutil.WriteFile(artifactPath, []byte("artifact content"), 0600))
+
+	official := newTestKey(t)
+	mirror := newTestKey(t)
+	unknown := newTestKey(t)
+
+	mirrorSignature := sign(t, mirror, artifactPath)
+
+	t.Run("single key rejects foreign signature", func(t *testing.T) {
+		err := VerifyGPGSignature(artifactPath, mirrorSignature, armoredPublicKey(t, official))
+		var invalidSignatureErr *InvalidSignatureError
+		require.ErrorAs(t, err, &invalidSignatureErr)
+		assert.Equal(t, []string{EmbeddedKeyName}, invalidSignatureErr.Keys)
+	})
+
+	t.Run("second key verifies", func(t *testing.T) {
+		k := NewKeyring()
+		require.NoError(t, k.Add("official", armoredPublicKey(t, official), time.Time{}))
+		require.NoError(t, k.Add("mirror", armoredPublicKey(t, mirror), time.Time{}))
+
+		keyName, err := k.Verify(artifactPath, mirrorSignature)
+		require.NoError(t, err)
+		assert.Equal(t, "mirror", keyName)
+	})
+
+	t.Run("expired key is skipped", func(t *testing.T) {
+		k := NewKeyring()
+		require.NoError(t, k.Add("official", armoredPublicKey(t, official), time.Time{}))
+		require.NoError(t, k.Add("mirror", armoredPublicKey(t, mirror), time.Now().Add(-time.Hour)))
+
+		_, err := k.Verify(artifactPath, mirrorSignature)
+		var invalidSignatureErr *InvalidSignatureError
+		require.ErrorAs(t, err, &invalidSignatureErr)
+		assert.Equal(t, []string{"official"}, invalidSignatureErr.Keys)
+	})
+
+	t.Run("keys from config", func(t *testing.T) {
+		keysDir := filepath.Join(dir, "keys")
+		require.NoError(t, os.MkdirAll(keysDir, 0700))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(keysDir, "mirror.asc"), armoredPublicKey(t, mirror), 0600))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(keysDir, "README.md"), []byte("not a key"), 0600))
+
+		unknownPath := filepath.Join(dir, "unknown.asc")
+		require.NoError(t, ioutil.WriteFile(unknownPath, armoredPublicKey(t, unknown), 0600))
+
+		k, err := NewKeyringFromConfig(armoredPublicKey(t, official), artifact.PGPConfig{
+			KeysDir: keysDir,
+			Keys: []artifact.PGPKeyConfig{
+				{Name: "unknown", Path: unknownPath, Expires: "2099-01-01T00:00:00Z"},
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{EmbeddedKeyName, "unknown", "mirror.asc"}, k.Names())
+
+		keyName, err := k.Verify(artifactPath, mirrorSignature)
+		require.NoError(t, err)
+		assert.Equal(t, "mirror.asc", keyName)
+	})
+
+	t.Run("invalid expiration", func(t *testing.T) {
+		_, err := NewKeyringFromConfig(nil, artifact.PGPConfig{
+			Keys: []artifact.PGPKeyConfig{
+				{Path: filepath.Join(dir, "unknown.asc"), Expires: "tomorrow"},
+			},
+		})
+		require.Error(t, err)
+	})
+}
+
+func newTestKey(t *testing.T) *openpgp.Entity {
+	t.Helper()
+	e, err := openpgp.NewEntity("test", "", "test@example.com", nil)
+	require.NoError(t, err)
+	return e
+}
+
+func armoredPublicKey(t *testing.T, e *openpgp.Entity) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, e.Serialize(w))
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func sign(t *testing.T, e *openpgp.Entity, file string) []byte {
+	t.Helper()
+	f, err := os.Open(file)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var buf bytes.Buffer
+	require.NoError(t, openpgp.ArmoredDetachSign(&buf, e, f, nil))
+	return buf.Bytes()
+}
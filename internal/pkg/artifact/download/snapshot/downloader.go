@@ -70,16 +70,23 @@ func snapshotConfig(config *artifact.Config, versionOverride string) (*artifact.
 		return nil, fmt.Errorf("failed to detect remote snapshot repo, proceeding with configured: %w", err)
 	}
 
+	return withSourceURI(config, snapshotURI), nil
+}
+
+// withSourceURI returns a copy of the config fetching artifacts from sourceURI.
+func withSourceURI(config *artifact.Config, sourceURI string) *artifact.Config {
 	return &artifact.Config{
 		OperatingSystem: config.OperatingSystem,
 		Architecture:    config.Architecture,
-		SourceURI:       snapshotURI,
+		SourceURI:       sourceURI,
 		TargetDirectory: config.TargetDirectory,
 		InstallPath:     config.InstallPath,
 		DropPath:        config.DropPath,
+		PGP:             config.PGP,
+		ProxyRules:      config.ProxyRules,
 
 		HTTPTransportSettings: config.HTTPTransportSettings,
-	}, nil
+	}
 }
 
 func snapshotURI(versionOverride string, config *artifact.Config) (string, error) {
@@ -89,7 +96,10 @@ func snapshotURI(versionOverride string, config *artifact.Config) (string, error
 		version = versionOverride
 	}
 
-	client, err := config.HTTPTransportSettings.Client(httpcommon.WithAPMHTTPInstrumentation())
+	client, err := config.HTTPTransportSettings.Client(
+		httpcommon.WithAPMHTTPInstrumentation(),
+		config.ProxyRules.TransportOption(),
+	)
 	if err != nil {
 		return "", err
 	}
@@ -10,6 +10,7 @@ import (
 	"github.com/elastic/elastic-agent/internal/pkg/artifact"
 	"github.com/elastic/elastic-agent/internal/pkg/artifact/download"
 	"github.com/elastic/elastic-agent/internal/pkg/artifact/download/http"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
 )
 
 type Verifier struct {
@@ -19,12 +20,12 @@ type Verifier struct {
 
 // NewVerifier creates a downloader which first checks local directory
 // and then fallbacks to remote if configured.
-func NewVerifier(config *artifact.Config, allowEmptyPgp bool, pgp []byte, versionOverride string) (download.Verifier, error) {
+func NewVerifier(log *logger.Logger, config *artifact.Config, allowEmptyPgp bool, pgp []byte, versionOverride string) (download.Verifier, error) {
 	cfg, err := snapshotConfig(config, versionOverride)
 	if err != nil {
 		return nil, err
 	}
-	v, err := http.NewVerifier(cfg, allowEmptyPgp, pgp)
+	v, err := http.NewVerifier(log, cfg, allowEmptyPgp, pgp)
 	if err != nil {
 		return nil, errors.New(err, "failed to create snapshot verifier")
 	}
@@ -0,0 +1,87 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package snapshot
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	gohttp "net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/openpgp"       //nolint:staticcheck // crypto/openpgp is only receiving security updates.
+	"golang.org/x/crypto/openpgp/armor" //nolint:staticcheck // crypto/openpgp is only receiving security updates.
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/program"
+	"github.com/elastic/elastic-agent/internal/pkg/artifact"
+	"github.com/elastic/elastic-agent/internal/pkg/artifact/download"
+	"github.com/elastic/elastic-agent/internal/pkg/artifact/download/http"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+func TestSnapshotVerifierUsesConfiguredKeys(t *testing.T) {
+	const version = "8.3.0-SNAPSHOT"
+	spec := program.Spec{Name: "Filebeat", Cmd: "filebeat", Artifact: "beats/filebeat"}
+
+	targetDir := t.TempDir()
+	keysDir := t.TempDir()
+	cfg := &artifact.Config{
+		OperatingSystem: "linux",
+		Architecture:    "64",
+		TargetDirectory: targetDir,
+		PGP:             artifact.PGPConfig{KeysDir: keysDir},
+	}
+
+	artifactPath, err := artifact.GetArtifactPath(spec, version, cfg.OS(), cfg.Arch(), targetDir)
+	require.NoError(t, err)
+	content := []byte("snapshot built and signed by a mirror")
+	require.NoError(t, ioutil.WriteFile(artifactPath, content, 0600))
+	hash := sha512.Sum512(content)
+	checksum := fmt.Sprintf("%s  %s", hex.EncodeToString(hash[:]), filepath.Base(artifactPath))
+	require.NoError(t, ioutil.WriteFile(artifactPath+".sha512", []byte(checksum), 0600))
+
+	official, err := openpgp.NewEntity("official", "", "official@example.com", nil)
+	require.NoError(t, err)
+	mirror, err := openpgp.NewEntity("mirror", "", "mirror@example.com", nil)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(keysDir, "mirror.asc"), armoredPublicKey(t, mirror), 0600))
+
+	var signature bytes.Buffer
+	require.NoError(t, openpgp.ArmoredDetachSign(&signature, mirror, bytes.NewReader(content), nil))
+	srv := httptest.NewServer(gohttp.HandlerFunc(func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		_, _ = w.Write(signature.Bytes())
+	}))
+	defer srv.Close()
+
+	log, _ := logger.New("", false)
+	snapshotCfg := withSourceURI(cfg, srv.URL)
+	require.Equal(t, cfg.PGP, snapshotCfg.PGP)
+
+	v, err := http.NewVerifier(log, snapshotCfg, false, armoredPublicKey(t, official))
+	require.NoError(t, err)
+	require.NoError(t, v.Verify(spec, version))
+
+	// without the configured keys only the embedded key is trusted
+	cfg.PGP = artifact.PGPConfig{}
+	v, err = http.NewVerifier(log, withSourceURI(cfg, srv.URL), false, armoredPublicKey(t, official))
+	require.NoError(t, err)
+	var invalidSignatureErr *download.InvalidSignatureError
+	require.ErrorAs(t, v.Verify(spec, version), &invalidSignatureErr)
+}
+
+func armoredPublicKey(t *testing.T, e *openpgp.Entity) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, e.Serialize(w))
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
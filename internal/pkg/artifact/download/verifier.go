@@ -6,7 +6,6 @@ package download
 
 import (
 	"bufio"
-	"bytes"
 	"crypto/sha512"
 	"encoding/hex"
 	"fmt"
@@ -14,8 +13,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
-
-	"golang.org/x/crypto/openpgp" //nolint:staticcheck // crypto/openpgp is only receiving security updates.
+	"time"
 
 	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/program"
@@ -36,11 +34,16 @@ func (e *ChecksumMismatchError) Error() string {
 // InvalidSignatureError indicates the file's GPG signature is invalid.
 type InvalidSignatureError struct {
 	File string
+	// Keys are the names of the trusted keys the signature was checked against.
+	Keys []string
 	Err  error
 }
 
 func (e *InvalidSignatureError) Error() string {
-	return "invalid signature for " + e.File + ": " + e.Err.Error()
+	if len(e.Keys) == 0 {
+		return "invalid signature for " + e.File + ": " + e.Err.Error()
+	}
+	return "invalid signature for " + e.File + " (trusted keys: " + strings.Join(e.Keys, ", ") + "): " + e.Err.Error()
 }
 
 // Unwrap returns the cause.
@@ -137,21 +140,11 @@ func readChecksumFile(checksumFile, filename string) (string, error) {
 // check against. If there is a problem with the signature then a
 // *download.InvalidSignatureError is returned.
 func VerifyGPGSignature(file string, asciiArmorSignature, publicKey []byte) error {
-	keyring, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(publicKey))
-	if err != nil {
-		return errors.New(err, "read armored key ring", errors.TypeSecurity)
-	}
-
-	f, err := os.Open(file)
-	if err != nil {
-		return errors.New(err, errors.TypeFilesystem, errors.M(errors.MetaKeyPath, file))
-	}
-	defer f.Close()
-
-	_, err = openpgp.CheckArmoredDetachedSignature(keyring, f, bytes.NewReader(asciiArmorSignature))
-	if err != nil {
-		return &InvalidSignatureError{File: file, Err: err}
+	keyring := NewKeyring()
+	if err := keyring.Add(EmbeddedKeyName, publicKey, time.Time{}); err != nil {
+		return err
 	}
 
-	return nil
+	_, err := keyring.Verify(file, asciiArmorSignature)
+	return err
 }
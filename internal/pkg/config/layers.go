@@ -0,0 +1,360 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	yamlv2 "gopkg.in/yaml.v2"
+	"gopkg.in/yaml.v3"
+)
+
+// MergeStrategy defines how the value of a key of a configuration file is merged with the value
+// of the same key in the previous files.
+type MergeStrategy string
+
+const (
+	// MergeDeep merges the objects key by key and the lists item by item, it is the default.
+	MergeDeep MergeStrategy = "deep_merge"
+	// MergeReplace replaces the previous value.
+	MergeReplace MergeStrategy = "replace"
+	// MergeAppend appends the items of the list after the previous ones.
+	MergeAppend MergeStrategy = "append"
+	// MergePrepend inserts the items of the list before the previous ones.
+	MergePrepend MergeStrategy = "prepend"
+)
+
+// Unpack the merge strategy, the names of the merge strategies of the transpiler, insert_after
+// and insert_before, are accepted as well.
+func (s *MergeStrategy) Unpack(from string) error {
+	switch from {
+	case "insert_after":
+		from = string(MergeAppend)
+	case "insert_before":
+		from = string(MergePrepend)
+	}
+
+	switch MergeStrategy(from) {
+	case MergeDeep, MergeReplace, MergeAppend, MergePrepend:
+	default:
+		return fmt.Errorf("invalid merge strategy %s, accepted values are 'deep_merge', 'replace', 'append' and 'prepend'", from)
+	}
+
+	*s = MergeStrategy(from)
+	return nil
+}
+
+// MergeRule sets the merge strategy of the key at Path.
+type MergeRule struct {
+	// Path is the dotted path of the key, e.g. inputs or outputs.default.
+	Path     string        `config:"path" yaml:"path" validate:"required"`
+	Strategy MergeStrategy `config:"strategy" yaml:"strategy" validate:"required"`
+}
+
+// MergeStrategies are the merge strategies of the keys of the configuration files.
+type MergeStrategies []MergeRule
+
+// Strategy returns the merge strategy of the key at path, the last matching rule wins.
+func (ms MergeStrategies) Strategy(path string) MergeStrategy {
+	strategy := MergeDeep
+	for _, r := range ms {
+		if r.Path == path {
+			strategy = r.Strategy
+		}
+	}
+	return strategy
+}
+
+// Source is the location of a value in the configuration files.
+type Source struct {
+	File string
+	// Line is the line of the key of the value, 0 when unknown.
+	Line int
+}
+
+// String returns the location as file:line.
+func (s Source) String() string {
+	if s.Line == 0 {
+		return s.File
+	}
+	return fmt.Sprintf("%s:%d", s.File, s.Line)
+}
+
+// Provenance holds the source of every key of a configuration merged from several files, the
+// keys are dotted paths, the items of the lists are referenced by their index.
+type Provenance map[string]Source
+
+// Lookup returns the source of the key at path.
+func (p Provenance) Lookup(path string) (Source, bool) {
+	s, ok := p[path]
+	return s, ok
+}
+
+// LoadLayers loads the configuration files and merges them in order, every file is merged over
+// the previous ones with the merge strategies. It returns the merged configuration and the source
+// of each of its keys.
+func LoadLayers(files []string, strategies MergeStrategies) (*Config, Provenance, error) {
+	merged, err := mergeLayers(files, strategies)
+	if err != nil {
+		return nil, nil, err
+	}
+	return merged.export()
+}
+
+func mergeLayers(files []string, strategies MergeStrategies) (*layerNode, error) {
+	var merged *layerNode
+	for _, f := range files {
+		n, err := loadLayerFile(f)
+		if err != nil {
+			return nil, err
+		}
+		merged = mergeNodes("", merged, n, strategies)
+	}
+	if merged == nil {
+		merged = &layerNode{dict: map[string]*layerNode{}}
+	}
+	return merged, nil
+}
+
+// layerNode is a value of a configuration file with its source.
+type layerNode struct {
+	src   Source
+	dict  map[string]*layerNode
+	list  []*layerNode
+	value interface{}
+}
+
+func (n *layerNode) isDict() bool { return n != nil && n.dict != nil }
+func (n *layerNode) isList() bool { return n != nil && n.list != nil }
+
+func loadLayerFile(path string) (*layerNode, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// the values are read like LoadFile does, the YAML nodes only give the lines of the keys
+	var data map[string]interface{}
+	if err := yamlv2.Unmarshal(content, &data); err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	lines := make(map[string]int)
+	if len(doc.Content) > 0 {
+		indexLines("", doc.Content[0], lines, true)
+	}
+
+	root := &layerNode{src: Source{File: path, Line: 1}, dict: map[string]*layerNode{}}
+	for k, v := range data {
+		if k == "inputs" {
+			// the inputs are read without expanding their dotted keys, see VarSkipKeys
+			root.dict[k] = newLayerNode(k, v, path, lines, false)
+			continue
+		}
+		setLayerValue(root, "", strings.Split(k, "."), v, path, lines, true)
+	}
+	return root, nil
+}
+
+// indexLines records the line of every key of the YAML node, the dotted keys are expanded except
+// under the top level inputs.
+func indexLines(prefix string, n *yaml.Node, lines map[string]int, expand bool) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			keys := []string{key.Value}
+			if expand {
+				keys = strings.Split(key.Value, ".")
+			}
+			path := prefix
+			for _, k := range keys {
+				path = joinPath(path, k)
+				if _, ok := lines[path]; !ok {
+					lines[path] = key.Line
+				}
+			}
+			indexLines(path, value, lines, expand && path != "inputs")
+		}
+	case yaml.SequenceNode:
+		for i, item := range n.Content {
+			path := joinPath(prefix, strconv.Itoa(i))
+			lines[path] = item.Line
+			indexLines(path, item, lines, expand)
+		}
+	case yaml.AliasNode:
+		if n.Alias != nil {
+			indexLines(prefix, n.Alias, lines, expand)
+		}
+	}
+}
+
+func setLayerValue(parent *layerNode, prefix string, keys []string, v interface{}, file string, lines map[string]int, expand bool) {
+	path := prefix
+	for _, k := range keys[:len(keys)-1] {
+		path = joinPath(path, k)
+		next, ok := parent.dict[k]
+		if !ok || !next.isDict() {
+			next = &layerNode{src: sourceOf(file, path, lines), dict: map[string]*layerNode{}}
+			parent.dict[k] = next
+		}
+		parent = next
+	}
+	key := keys[len(keys)-1]
+	path = joinPath(path, key)
+	n := newLayerNode(path, v, file, lines, expand)
+	if existing, ok := parent.dict[key]; ok && existing.isDict() && n.isDict() {
+		// the same object is set with dotted and nested keys
+		n = mergeNodes(path, existing, n, nil)
+	}
+	parent.dict[key] = n
+}
+
+func newLayerNode(path string, v interface{}, file string, lines map[string]int, expand bool) *layerNode {
+	n := &layerNode{src: sourceOf(file, path, lines)}
+	keys := func(k string) []string {
+		if expand {
+			return strings.Split(k, ".")
+		}
+		return []string{k}
+	}
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		n.dict = make(map[string]*layerNode, len(t))
+		for k, child := range t {
+			setLayerValue(n, path, keys(fmt.Sprint(k)), child, file, lines, expand)
+		}
+	case map[string]interface{}:
+		n.dict = make(map[string]*layerNode, len(t))
+		for k, child := range t {
+			setLayerValue(n, path, keys(k), child, file, lines, expand)
+		}
+	case []interface{}:
+		n.list = make([]*layerNode, 0, len(t))
+		for i, child := range t {
+			n.list = append(n.list, newLayerNode(joinPath(path, strconv.Itoa(i)), child, file, lines, expand))
+		}
+	default:
+		n.value = v
+	}
+	return n
+}
+
+// sourceOf returns the source of the key at path, the line of the closest parent is used when the
+// key has no line, e.g. when it comes from a YAML merge key.
+func sourceOf(file, path string, lines map[string]int) Source {
+	for {
+		if line, ok := lines[path]; ok {
+			return Source{File: file, Line: line}
+		}
+		i := strings.LastIndex(path, ".")
+		if i < 0 {
+			return Source{File: file}
+		}
+		path = path[:i]
+	}
+}
+
+// mergeNodes merges src over dst with the strategy of the key at path.
+func mergeNodes(path string, dst, src *layerNode, strategies MergeStrategies) *layerNode {
+	if dst == nil {
+		return src
+	}
+	if src == nil {
+		return dst
+	}
+
+	strategy := strategies.Strategy(path)
+	if strategy == MergeReplace {
+		return src
+	}
+
+	switch {
+	case dst.isDict() && src.isDict():
+		for k, child := range src.dict {
+			dst.dict[k] = mergeNodes(joinPath(path, k), dst.dict[k], child, strategies)
+		}
+		return dst
+	case dst.isList() && src.isList():
+		switch strategy {
+		case MergeAppend:
+			dst.list = append(dst.list, src.list...)
+		case MergePrepend:
+			dst.list = append(append([]*layerNode{}, src.list...), dst.list...)
+		default:
+			// item by item, like go-ucfg merges the lists
+			for i, child := range src.list {
+				if i < len(dst.list) {
+					dst.list[i] = mergeNodes(joinPath(path, strconv.Itoa(i)), dst.list[i], child, strategies)
+				} else {
+					dst.list = append(dst.list, child)
+				}
+			}
+		}
+		return dst
+	case !src.isDict() && !src.isList() && src.value == nil:
+		// an empty value keeps the previous one
+		return dst
+	}
+	return src
+}
+
+// appendItems appends the items of src, a list or a single object, to the list at key of dst.
+func (n *layerNode) appendItems(key string, src *layerNode) {
+	if src == nil {
+		return
+	}
+	items := src.list
+	if src.isDict() {
+		items = []*layerNode{src}
+	}
+	if len(items) == 0 {
+		return
+	}
+
+	existing, ok := n.dict[key]
+	if !ok || !existing.isList() {
+		existing = &layerNode{src: items[0].src, list: []*layerNode{}}
+		n.dict[key] = existing
+	}
+	existing.list = append(existing.list, items...)
+}
+
+func (n *layerNode) export() (*Config, Provenance, error) {
+	provenance := make(Provenance)
+	data, _ := n.toMap("", provenance).(map[string]interface{})
+	cfg, err := NewConfigFrom(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, provenance, nil
+}
+
+func (n *layerNode) toMap(path string, provenance Provenance) interface{} {
+	if path != "" {
+		provenance[path] = n.src
+	}
+	switch {
+	case n.isDict():
+		m := make(map[string]interface{}, len(n.dict))
+		for k, child := range n.dict {
+			m[k] = child.toMap(joinPath(path, k), provenance)
+		}
+		return m
+	case n.isList():
+		l := make([]interface{}, 0, len(n.list))
+		for i, child := range n.list {
+			l = append(l, child.toMap(joinPath(path, strconv.Itoa(i)), provenance))
+		}
+		return l
+	}
+	return n.value
+}
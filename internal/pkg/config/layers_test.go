@@ -0,0 +1,112 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadLayers(t *testing.T) {
+	base := filepath.Join("testdata", "layers", "base.yml")
+	overlay := filepath.Join("testdata", "layers", "overlay.yml")
+
+	inputIDs := func(t *testing.T, cfg *Config) []interface{} {
+		m, err := cfg.ToMapStr()
+		require.NoError(t, err)
+		var ids []interface{}
+		for _, input := range m["inputs"].([]interface{}) {
+			ids = append(ids, input.(map[string]interface{})["id"])
+		}
+		return ids
+	}
+
+	testcases := map[string]struct {
+		strategies MergeStrategies
+		inputs     []interface{}
+	}{
+		"deep merge":     {inputs: []interface{}{"nginx-logs"}},
+		"append":         {strategies: MergeStrategies{{Path: "inputs", Strategy: MergeAppend}}, inputs: []interface{}{"system-logs", "nginx-logs"}},
+		"prepend":        {strategies: MergeStrategies{{Path: "inputs", Strategy: MergePrepend}}, inputs: []interface{}{"nginx-logs", "system-logs"}},
+		"replace":        {strategies: MergeStrategies{{Path: "inputs", Strategy: MergeReplace}}, inputs: []interface{}{"nginx-logs"}},
+		"last rule wins": {strategies: MergeStrategies{{Path: "inputs", Strategy: MergeReplace}, {Path: "inputs", Strategy: MergeAppend}}, inputs: []interface{}{"system-logs", "nginx-logs"}},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			cfg, _, err := LoadLayers([]string{base, overlay}, tc.strategies)
+			require.NoError(t, err)
+			assert.Equal(t, tc.inputs, inputIDs(t, cfg))
+		})
+	}
+
+	t.Run("deep merge keeps the keys of the previous files", func(t *testing.T) {
+		cfg, _, err := LoadLayers([]string{base, overlay}, nil)
+		require.NoError(t, err)
+		m, err := cfg.ToMapStr()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{
+			"type":    "elasticsearch",
+			"hosts":   []interface{}{"https://es.example.com:9200"},
+			"api_key": "secret",
+		}, m["outputs"].(map[string]interface{})["default"])
+		// the dotted keys of the inputs are not expanded, like with LoadFile
+		assert.Equal(t, "default", m["inputs"].([]interface{})[0].(map[string]interface{})["data_stream.namespace"])
+	})
+
+	t.Run("replace an object", func(t *testing.T) {
+		cfg, _, err := LoadLayers([]string{base, overlay}, MergeStrategies{{Path: "outputs.default", Strategy: MergeReplace}})
+		require.NoError(t, err)
+		m, err := cfg.ToMapStr()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{
+			"hosts":   []interface{}{"https://es.example.com:9200"},
+			"api_key": "secret",
+		}, m["outputs"].(map[string]interface{})["default"])
+	})
+}
+
+func TestLoadLayersProvenance(t *testing.T) {
+	base := filepath.Join("testdata", "layers", "base.yml")
+	overlay := filepath.Join("testdata", "layers", "overlay.yml")
+
+	_, provenance, err := LoadLayers([]string{base, overlay}, MergeStrategies{{Path: "inputs", Strategy: MergeAppend}})
+	require.NoError(t, err)
+
+	for path, expected := range map[string]Source{
+		"outputs.default.type":           {File: base, Line: 3},
+		"outputs.default.hosts.0":        {File: overlay, Line: 3},
+		"outputs.default.api_key":        {File: overlay, Line: 4},
+		"agent.logging.level":            {File: overlay, Line: 8},
+		"inputs.0.id":                    {File: base, Line: 9},
+		"inputs.0.data_stream.namespace": {File: base, Line: 11},
+		"inputs.1.type":                  {File: overlay, Line: 12},
+	} {
+		src, ok := provenance.Lookup(path)
+		if assert.True(t, ok, path) {
+			assert.Equal(t, expected, src, path)
+		}
+	}
+}
+
+func TestMergeStrategyUnpack(t *testing.T) {
+	var rules struct {
+		Merge MergeStrategies `config:"merge"`
+	}
+	cfg := MustNewConfigFrom(`
+merge:
+  - path: inputs
+    strategy: insert_after
+  - path: outputs
+    strategy: replace
+`)
+	require.NoError(t, cfg.Unpack(&rules))
+	assert.Equal(t, MergeStrategies{{Path: "inputs", Strategy: MergeAppend}, {Path: "outputs", Strategy: MergeReplace}}, rules.Merge)
+
+	cfg = MustNewConfigFrom(`merge: [{path: inputs, strategy: shuffle}]`)
+	assert.Error(t, cfg.Unpack(&rules))
+}
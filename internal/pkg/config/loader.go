@@ -18,6 +18,8 @@ import (
 type Loader struct {
 	logger       *logger.Logger
 	inputsFolder string
+	overlays     []string
+	strategies   MergeStrategies
 }
 
 // NewLoader creates a new Loader instance to load configuration
@@ -26,12 +28,25 @@ func NewLoader(logger *logger.Logger, inputsFolder string) *Loader {
 	return &Loader{logger: logger, inputsFolder: inputsFolder}
 }
 
+// SetLayers sets the patterns of the overlays and the merge strategies of their keys. The regular
+// config files are merged with the merge strategies when one of them is an overlay or when there
+// is a merge strategy.
+func (l *Loader) SetLayers(overlays []string, strategies MergeStrategies) {
+	l.overlays = overlays
+	l.strategies = strategies
+}
+
 // Load iterates over the list of files and loads the confguration from them.
 // If a configuration file is under the folder set in `agent.config.inputs.path`
 // it is appended to a list. If it is a regular config file, it is merged into
 // the result config. The list of input configurations is merged into the result
 // last.
 func (l *Loader) Load(files []string) (*Config, error) {
+	if l.isLayered(files) {
+		cfg, _, err := l.LoadWithProvenance(files)
+		return cfg, err
+	}
+
 	inputsList := make([]*ucfg.Config, 0)
 	merger := cfgutil.NewCollector(nil)
 	for _, f := range files {
@@ -49,7 +64,7 @@ func (l *Loader) Load(files []string) (*Config, error) {
 				return nil, fmt.Errorf("cannot get configuration from '%s': %w", f, err)
 			}
 			inputsList = append(inputsList, inp...)
-			l.logger.Debugf("Loaded %s input(s) from configuration from %s", len(inp), f)
+			l.logger.Debugf("Loaded %d input(s) from configuration from %s", len(inp), f)
 		} else {
 			if err := merger.Add(cfg.access(), err); err != nil {
 				return nil, fmt.Errorf("failed to merge configuration file '%s' to existing one: %w", f, err)
@@ -85,6 +100,50 @@ func (l *Loader) Load(files []string) (*Config, error) {
 	return newConfigFrom(config), nil
 }
 
+// LoadWithProvenance loads the configuration like Load and returns the source of each of its keys,
+// the regular config files are always merged with the merge strategies.
+func (l *Loader) LoadWithProvenance(files []string) (*Config, Provenance, error) {
+	var merged *layerNode
+	var inputsList []*layerNode
+	for _, f := range files {
+		cfg, err := loadLayerFile(f)
+		if err != nil {
+			if l.isFileUnderInputsFolder(f) {
+				return nil, nil, fmt.Errorf("failed to load external configuration file '%s': %w. Are you sure it contains an inputs section?", f, err)
+			}
+			return nil, nil, fmt.Errorf("failed to load configuration file '%s': %w", f, err)
+		}
+		l.logger.Debugf("Loaded configuration from %s", f)
+		if l.isFileUnderInputsFolder(f) {
+			inp, err := getLayerInputs(cfg)
+			if err != nil {
+				return nil, nil, fmt.Errorf("cannot get configuration from '%s': %w", f, err)
+			}
+			inputsList = append(inputsList, inp...)
+			l.logger.Debugf("Loaded %d input(s) from configuration from %s", len(inp), f)
+		} else {
+			merged = mergeNodes("", merged, cfg, l.strategies)
+			l.logger.Debugf("Merged configuration from %s into result", f)
+		}
+	}
+	if merged == nil {
+		merged = &layerNode{dict: map[string]*layerNode{}}
+	}
+
+	// if there is no input configuration, return what we have collected.
+	if len(inputsList) == 0 {
+		l.logger.Debugf("Merged all configuration files from %v, no external input files", files)
+		return merged.export()
+	}
+
+	// merge inputs sections from the last standalone configuration
+	// file and all files from the inputs folder
+	merged.appendItems("inputs", &layerNode{list: inputsList})
+
+	l.logger.Debugf("Merged all configuration files from %v, with external input files", files)
+	return merged.export()
+}
+
 func getInput(c *Config) ([]*ucfg.Config, error) {
 	tmpConfig := struct {
 		Inputs []*ucfg.Config `config:"inputs"`
@@ -96,6 +155,37 @@ func getInput(c *Config) ([]*ucfg.Config, error) {
 	return tmpConfig.Inputs, nil
 }
 
+func getLayerInputs(c *layerNode) ([]*layerNode, error) {
+	inputs, ok := c.dict["inputs"]
+	if !ok {
+		return nil, nil
+	}
+	switch {
+	case inputs.isList():
+		return inputs.list, nil
+	case inputs.isDict():
+		return []*layerNode{inputs}, nil
+	case inputs.value == nil:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("failed to parse inputs section from configuration: expecting a list of inputs at %s", inputs.src)
+}
+
+// isLayered returns true when the files are merged with the merge strategies.
+func (l *Loader) isLayered(files []string) bool {
+	if len(l.strategies) > 0 {
+		return true
+	}
+	for _, f := range files {
+		for _, pattern := range l.overlays {
+			if matches, err := filepath.Match(pattern, f); matches && err == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (l *Loader) isFileUnderInputsFolder(f string) bool {
 	if matches, err := filepath.Match(l.inputsFolder, f); !matches || err != nil {
 		return false
@@ -210,3 +210,45 @@ func mustNewLoader(inputsFolder string) *Loader {
 	}
 	return NewLoader(log, inputsFolder)
 }
+
+func TestLoaderProvenance(t *testing.T) {
+	standalone := filepath.Join("testdata", "standalone1.yml")
+	inputs := filepath.Join("testdata", "inputs", "log-inputs.yml")
+
+	l := mustNewLoader(filepath.Join("testdata", "inputs", "*.yml"))
+	_, provenance, err := l.LoadWithProvenance([]string{standalone, inputs})
+	require.NoError(t, err)
+
+	src, ok := provenance.Lookup("outputs.default.hosts")
+	require.True(t, ok)
+	require.Equal(t, Source{File: standalone, Line: 4}, src)
+
+	src, ok = provenance.Lookup("inputs.0.id")
+	require.True(t, ok)
+	require.Equal(t, Source{File: inputs, Line: 6}, src)
+}
+
+func TestLoaderLayers(t *testing.T) {
+	base := filepath.Join("testdata", "layers", "base.yml")
+	overlay := filepath.Join("testdata", "layers", "overlay.yml")
+
+	inputs := func(l *Loader) int {
+		cfg, err := l.Load([]string{base, overlay})
+		require.NoError(t, err)
+		m, err := cfg.ToMapStr()
+		require.NoError(t, err)
+		return len(m["inputs"].([]interface{}))
+	}
+
+	// without overlay nor merge strategy the files are merged by ucfg
+	l := mustNewLoader("")
+	require.Equal(t, 1, inputs(l))
+
+	l.SetLayers([]string{filepath.Join("testdata", "layers", "overlay*.yml")}, nil)
+	require.True(t, l.isLayered([]string{base, overlay}))
+	require.False(t, l.isLayered([]string{base}))
+	require.Equal(t, 1, inputs(l))
+
+	l.SetLayers(nil, MergeStrategies{{Path: "inputs", Strategy: MergeAppend}})
+	require.Equal(t, 2, inputs(l))
+}
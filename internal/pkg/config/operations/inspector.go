@@ -9,6 +9,7 @@ import (
 
 	"github.com/elastic/elastic-agent-libs/logp"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/info"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/override"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/configuration"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
@@ -27,37 +28,62 @@ var (
 // LoadFullAgentConfig load agent config based on provided paths and defined capabilities.
 // In case fleet is used, config from policy action is returned.
 func LoadFullAgentConfig(cfgPath string, failOnFleetMissing bool) (*config.Config, error) {
+	cfg, _, err := LoadFullAgentConfigWithOverride(cfgPath, failOnFleetMissing)
+	return cfg, err
+}
+
+// LoadFullAgentConfigWithOverride loads the agent config like LoadFullAgentConfig, in case fleet
+// is used the local override file is merged over the policy and the paths it changed are returned.
+func LoadFullAgentConfigWithOverride(cfgPath string, failOnFleetMissing bool) (*config.Config, override.Result, error) {
 	rawConfig, err := loadConfig(cfgPath)
 	if err != nil {
-		return nil, err
+		return nil, override.Result{}, err
 	}
 
 	cfg, err := configuration.NewFromConfig(rawConfig)
 	if err != nil {
-		return nil, err
+		return nil, override.Result{}, err
 	}
 
 	if configuration.IsStandalone(cfg.Fleet) {
-		return rawConfig, nil
+		return rawConfig, override.Result{}, nil
 	}
 
 	fleetConfig, err := loadFleetConfig()
 	if err != nil {
-		return nil, err
+		return nil, override.Result{}, err
 	} else if fleetConfig == nil {
 		if failOnFleetMissing {
-			return nil, ErrNoFleetConfig
+			return nil, override.Result{}, ErrNoFleetConfig
 		}
 
 		// resolving fleet config but not fleet config retrieved yet, returning last applied config
-		return rawConfig, nil
+		return rawConfig, override.Result{}, nil
+	}
+
+	policy, err := config.NewConfigFrom(fleetConfig)
+	if err != nil {
+		return nil, override.Result{}, err
+	}
+	if len(cfg.Settings.Override.Allow) == 0 {
+		return policy, override.Result{}, nil
 	}
 
-	return config.NewConfigFrom(fleetConfig)
+	o, err := override.Load(paths.AgentOverrideFile())
+	if err != nil || o == nil {
+		return policy, override.Result{}, err
+	}
+	mapStr, err := policy.ToMapStr()
+	if err != nil {
+		return nil, override.Result{}, err
+	}
+	res := o.Apply(mapStr, cfg.Settings.Override.Allow)
+	policy, err = config.NewConfigFrom(mapStr)
+	return policy, res, err
 }
 
 func loadConfig(configPath string) (*config.Config, error) {
-	rawConfig, err := config.LoadFile(configPath)
+	rawConfig, _, err := configuration.LoadLayered(configPath)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,339 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SchemaDraft is the JSON schema version of the generated schemas.
+const SchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// Schema is a JSON schema.
+type Schema map[string]interface{}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// SchemaOf generates the JSON schema of the configuration unpacked into v, it follows the rules of
+// go-ucfg: the field names come from the `config` tags, inline fields are merged into their parent
+// and ignored fields are left out. The `validate` tags give the bounds and the required fields.
+//
+// The objects generated from structs do not accept unknown keys.
+func SchemaOf(v interface{}) Schema {
+	g := &schemaGenerator{visiting: make(map[reflect.Type]bool)}
+	return g.schema(reflect.TypeOf(v))
+}
+
+type schemaGenerator struct {
+	visiting map[reflect.Type]bool
+}
+
+func (g *schemaGenerator) schema(t reflect.Type) Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == durationType {
+		// durations are either strings like 30s or a number of seconds
+		return Schema{"type": []string{"string", "number"}}
+	}
+	if s, ok := unpackerSchema(t); ok {
+		return s
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return Schema{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		if g.visiting[t] {
+			// recursive type, accept anything
+			return Schema{}
+		}
+		g.visiting[t] = true
+		defer delete(g.visiting, t)
+
+		s := Schema{
+			"type":                 "object",
+			"properties":           Schema{},
+			"additionalProperties": false,
+		}
+		g.fields(t, s)
+		return s
+	}
+
+	// interfaces and unknown kinds accept any value
+	return Schema{}
+}
+
+func (g *schemaGenerator) fields(t reflect.Type, s Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			// unexported
+			continue
+		}
+
+		name, opts := parseConfigTag(f.Tag.Get("config"))
+		if opts["ignore"] || name == "-" {
+			continue
+		}
+
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct && (opts["inline"] || (f.Anonymous && name == "")) {
+			g.fields(ft, s)
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fs := g.schema(f.Type)
+		// a dotted name is a path in the configuration
+		parent, keys := s, strings.Split(name, ".")
+		for _, k := range keys[:len(keys)-1] {
+			next, ok := parent["properties"].(Schema)[k].(Schema)
+			if !ok {
+				next = Schema{"type": "object", "properties": Schema{}, "additionalProperties": false}
+				parent["properties"].(Schema)[k] = next
+			}
+			parent = next
+		}
+		name = keys[len(keys)-1]
+		if applyValidateTag(fs, f.Tag.Get("validate")) {
+			required, _ := parent["required"].([]string)
+			parent["required"] = append(required, name)
+		}
+		parent["properties"].(Schema)[name] = fs
+	}
+}
+
+// unpackerSchema returns the schema of the types unpacking themselves from a primitive value.
+func unpackerSchema(t reflect.Type) (Schema, bool) {
+	m, ok := reflect.PtrTo(t).MethodByName("Unpack")
+	if !ok || m.Type.NumIn() != 2 {
+		return nil, false
+	}
+
+	switch m.Type.In(1).Kind() {
+	case reflect.String:
+		return Schema{"type": "string"}, true
+	case reflect.Bool:
+		return Schema{"type": "boolean"}, true
+	case reflect.Int, reflect.Int64, reflect.Uint64:
+		return Schema{"type": "integer"}, true
+	case reflect.Float64:
+		return Schema{"type": "number"}, true
+	}
+	if t.Kind() == reflect.Struct {
+		// unpacked from a configuration object, assume it reads the keys of its fields
+		return nil, false
+	}
+	return Schema{}, true
+}
+
+func parseConfigTag(tag string) (string, map[string]bool) {
+	parts := strings.Split(tag, ",")
+	opts := make(map[string]bool, len(parts)-1)
+	for _, o := range parts[1:] {
+		opts[strings.TrimSpace(o)] = true
+	}
+	return parts[0], opts
+}
+
+// applyValidateTag adds the bounds of the validate tag to the schema, it returns true when the
+// field is required.
+func applyValidateTag(s Schema, tag string) bool {
+	required := false
+	for _, v := range strings.Split(tag, ",") {
+		v = strings.TrimSpace(v)
+		switch {
+		case v == "required":
+			required = true
+		case v == "positive":
+			s["exclusiveMinimum"] = 0
+		case v == "nonzero":
+			if s["type"] == "string" {
+				s["minLength"] = 1
+			}
+		case strings.HasPrefix(v, "min="):
+			if n, err := strconv.ParseFloat(strings.TrimPrefix(v, "min="), 64); err == nil {
+				s["minimum"] = n
+			}
+		case strings.HasPrefix(v, "max="):
+			if n, err := strconv.ParseFloat(strings.TrimPrefix(v, "max="), 64); err == nil {
+				s["maximum"] = n
+			}
+		}
+	}
+	return required
+}
+
+// Property returns the schema of the property at the dotted path, nil when it does not exist.
+func (s Schema) Property(path string) Schema {
+	cur := s
+	for _, k := range strings.Split(path, ".") {
+		properties, _ := cur["properties"].(Schema)
+		next, ok := properties[k].(Schema)
+		if !ok {
+			return nil
+		}
+		cur = next
+	}
+	return cur
+}
+
+// SetProperty sets the schema of the property at the dotted path, the objects on the path must
+// exist.
+func (s Schema) SetProperty(path string, prop Schema) error {
+	keys := strings.Split(path, ".")
+	parent := s
+	if len(keys) > 1 {
+		parent = s.Property(strings.Join(keys[:len(keys)-1], "."))
+	}
+	properties, ok := parent["properties"].(Schema)
+	if !ok {
+		return fmt.Errorf("no object at %s", path)
+	}
+	properties[keys[len(keys)-1]] = prop
+	return nil
+}
+
+// UnknownKey is a key of a configuration not described by its schema.
+type UnknownKey struct {
+	// Path is the full dotted path of the key.
+	Path string
+	// Suggestion is the closest known key, empty when no key is close enough.
+	Suggestion string
+}
+
+// String returns a message describing the key.
+func (k UnknownKey) String() string {
+	if k.Suggestion == "" {
+		return fmt.Sprintf("unknown configuration key %s", k.Path)
+	}
+	return fmt.Sprintf("unknown configuration key %s, did you mean %s?", k.Path, k.Suggestion)
+}
+
+// UnknownKeys returns the keys of the value not described by the schema, prefix is the path of the
+// value in the configuration. The keys are sorted by path.
+func (s Schema) UnknownKeys(prefix string, value interface{}) []UnknownKey {
+	var keys []UnknownKey
+	s.unknownKeys(prefix, value, &keys)
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Path < keys[j].Path })
+	return keys
+}
+
+func (s Schema) unknownKeys(prefix string, value interface{}, keys *[]UnknownKey) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		properties, _ := s["properties"].(Schema)
+		for k, child := range v {
+			path := joinPath(prefix, k)
+			if prop, ok := properties[k].(Schema); ok {
+				prop.unknownKeys(path, child, keys)
+				continue
+			}
+			switch additional := s["additionalProperties"].(type) {
+			case Schema:
+				additional.unknownKeys(path, child, keys)
+			case bool:
+				if !additional {
+					*keys = append(*keys, UnknownKey{Path: path, Suggestion: suggest(prefix, k, properties)})
+				}
+			}
+		}
+	case []interface{}:
+		items, ok := s["items"].(Schema)
+		if !ok {
+			return
+		}
+		for i, child := range v {
+			items.unknownKeys(joinPath(prefix, strconv.Itoa(i)), child, keys)
+		}
+	}
+}
+
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// suggest returns the path of the property closest to the key, when the distance between them
+// is small compared to their length.
+func suggest(prefix, key string, properties Schema) string {
+	best, bestDistance := "", -1
+	for name := range properties {
+		d := levenshtein(strings.ToLower(key), strings.ToLower(name))
+		if bestDistance == -1 || d < bestDistance || (d == bestDistance && name < best) {
+			best, bestDistance = name, d
+		}
+	}
+	if best == "" || bestDistance > maxSuggestionDistance(key) {
+		return ""
+	}
+	return joinPath(prefix, best)
+}
+
+func maxSuggestionDistance(key string) int {
+	if d := len(key) / 3; d > 2 {
+		return d
+	}
+	return 2
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
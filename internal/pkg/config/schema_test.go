@@ -0,0 +1,78 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type schemaLevel string
+
+func (l *schemaLevel) Unpack(from string) error {
+	*l = schemaLevel(strings.ToLower(from))
+	return nil
+}
+
+type schemaInline struct {
+	Timeout time.Duration `config:"timeout"`
+}
+
+type schemaTest struct {
+	Name     string            `config:"name" validate:"required"`
+	Port     int               `config:"port" validate:"min=1,max=65535"`
+	Level    *schemaLevel      `config:"level"`
+	Tags     []string          `config:"tags"`
+	Headers  map[string]string `config:"headers"`
+	SpaceID  string            `config:"space.id"`
+	Internal string            `config:",ignore"`
+	Default  bool
+	Inline   schemaInline `config:",inline"`
+}
+
+func TestSchemaOf(t *testing.T) {
+	assert.Equal(t, Schema{
+		"type":                 "object",
+		"additionalProperties": false,
+		"required":             []string{"name"},
+		"properties": Schema{
+			"name":    Schema{"type": "string"},
+			"port":    Schema{"type": "integer", "minimum": float64(1), "maximum": float64(65535)},
+			"level":   Schema{"type": "string"},
+			"tags":    Schema{"type": "array", "items": Schema{"type": "string"}},
+			"headers": Schema{"type": "object", "additionalProperties": Schema{"type": "string"}},
+			"space": Schema{
+				"type":                 "object",
+				"additionalProperties": false,
+				"properties":           Schema{"id": Schema{"type": "string"}},
+			},
+			"default": Schema{"type": "boolean"},
+			"timeout": Schema{"type": []string{"string", "number"}},
+		},
+	}, SchemaOf(schemaTest{}))
+}
+
+func TestSchemaUnknownKeys(t *testing.T) {
+	cfg := MustNewConfigFrom(`
+name: test
+prot: 8080
+headers.X-Custom: value
+space.ids: default
+timeot: 10s
+completely_different: true
+`)
+	m, err := cfg.ToMapStr()
+	assert.NoError(t, err)
+
+	assert.Equal(t, []UnknownKey{
+		{Path: "test.completely_different"},
+		{Path: "test.prot", Suggestion: "test.port"},
+		{Path: "test.space.ids", Suggestion: "test.space.id"},
+		{Path: "test.timeot", Suggestion: "test.timeout"},
+	}, SchemaOf(&schemaTest{}).UnknownKeys("test", m))
+}
@@ -148,6 +148,9 @@ func (a *Application) start(ctx context.Context, t app.Taggable, cfg map[string]
 			a.Name(), spec.BinaryPath, err)
 	}
 
+	// only the started process is accepted on the unix socket of the server
+	a.srvState.SetPeer(a.state.ProcessInfo.PID, a.uid)
+
 	// write connect info to stdin
 	go a.writeToStdin(a.srvState, a.state.ProcessInfo.Stdin)
 
@@ -186,6 +189,6 @@ func injectLogLevel(logLevel string, args []string) []string {
 }
 
 func injectDataPath(args []string, pipelineID, id string) []string {
-	dataPath := filepath.Join(paths.Home(), "run", pipelineID, id)
+	dataPath := filepath.Join(paths.Run(), pipelineID, id)
 	return append(args, "-E", "path.data="+dataPath)
 }
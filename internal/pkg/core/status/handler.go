@@ -16,12 +16,15 @@ type LivenessResponse struct {
 	Status     string    `json:"status"`
 	Message    string    `json:"message"`
 	UpdateTime time.Time `json:"update_timestamp"`
+	// Components holds the payload reported by local components, e.g. the fleet checkin statistics.
+	Components map[string]interface{} `json:"components,omitempty"`
 }
 
 // ServeHTTP is an HTTP Handler for the status controller.
 // It uses the local agent status so it is able to report a degraded state if the fleet-server checkin has issues.
 // Respose code is 200 for a healthy agent, and 503 otherwise.
-// Response body is a JSON object that contains the agent ID, status, message, and the last status update time.
+// Response body is a JSON object that contains the agent ID, status, message, the last status update time and the
+// payload of local components.
 func (r *controller) ServeHTTP(wr http.ResponseWriter, req *http.Request) {
 	s := r.LocalStatus()
 	lr := LivenessResponse{
@@ -29,6 +32,7 @@ func (r *controller) ServeHTTP(wr http.ResponseWriter, req *http.Request) {
 		Status:     s.Status.String(),
 		Message:    s.Message,
 		UpdateTime: s.UpdateTime,
+		Components: r.localPayloads(),
 	}
 	status := http.StatusOK
 	if s.Status != Healthy {
@@ -247,6 +247,25 @@ func (r *controller) LocalStatus() AgentStatus {
 
 }
 
+// localPayloads returns the payloads reported by local components indexed by component name.
+func (r *controller) localPayloads() map[string]interface{} {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	var payloads map[string]interface{}
+	for _, rep := range r.localReporters {
+		rep.mx.Lock()
+		if rep.payload != nil {
+			if payloads == nil {
+				payloads = make(map[string]interface{})
+			}
+			payloads[rep.name] = rep.payload
+		}
+		rep.mx.Unlock()
+	}
+	return payloads
+}
+
 // StatusCode retrieves current agent status code.
 func (r *controller) StatusCode() AgentStatusCode {
 	r.mx.Lock()
@@ -5,10 +5,14 @@
 package status
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/elastic/elastic-agent/internal/pkg/core/state"
 	"github.com/elastic/elastic-agent/pkg/core/logger"
@@ -170,3 +174,21 @@ func TestReporter(t *testing.T) {
 		assert.NotEqual(t, time.Time{}, s.UpdateTime)
 	})
 }
+
+func TestLivenessComponentsPayload(t *testing.T) {
+	l, _ := logger.New("", false)
+	r := NewController(l)
+	local := r.RegisterLocalComponent("gateway-checkin")
+	local.Update(state.Healthy, "", map[string]interface{}{"consecutive_checkin_failures": 0})
+	r.RegisterLocalComponent("no-payload").Update(state.Healthy, "", nil)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/liveness", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp LivenessResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, map[string]interface{}{
+		"gateway-checkin": map[string]interface{}{"consecutive_checkin_failures": float64(0)},
+	}, resp.Components)
+}
@@ -78,19 +78,26 @@ func (f *Acker) Ack(ctx context.Context, action fleetapi.Action) (err error) {
 // AckBatch acknowledges multiple actions at once.
 func (f *Acker) AckBatch(ctx context.Context, actions []fleetapi.Action) (res *fleetapi.AckResponse, err error) {
 	f.log.Debugf("fleet acker: ackbatch, actions: %#v", actions)
-	span, ctx := apm.StartSpan(ctx, "ackBatch", "app.internal")
-	defer func() {
-		apm.CaptureError(ctx, err).Send()
-		span.End()
-	}()
-	// checkin
+	return f.AckEvents(ctx, f.Events(actions))
+}
+
+// Events returns the ack events of the actions.
+func (f *Acker) Events(actions []fleetapi.Action) []fleetapi.AckEvent {
 	agentID := f.agentInfo.AgentID()
 	events := make([]fleetapi.AckEvent, 0, len(actions))
-	ids := make([]string, 0, len(actions))
 	for _, action := range actions {
 		events = append(events, constructEvent(action, agentID))
-		ids = append(ids, action.ID())
 	}
+	return events
+}
+
+// AckEvents sends the ack events at once, the events are created by Events.
+func (f *Acker) AckEvents(ctx context.Context, events []fleetapi.AckEvent) (res *fleetapi.AckResponse, err error) {
+	span, ctx := apm.StartSpan(ctx, "ackBatch", "app.internal")
+	defer func() {
+		apm.CaptureError(ctx, err).Send()
+		span.End()
+	}()
 
 	f.log.Debugf("fleet acker: ackbatch, events: %#v", events)
 	if len(events) == 0 {
@@ -98,6 +105,11 @@ func (f *Acker) AckBatch(ctx context.Context, actions []fleetapi.Action) (res *f
 		return &fleetapi.AckResponse{}, nil
 	}
 
+	ids := make([]string, 0, len(events))
+	for _, ev := range events {
+		ids = append(ids, ev.ActionID)
+	}
+
 	cmd := fleetapi.NewAckCmd(f.agentInfo, f.client)
 	req := &fleetapi.AckRequest{
 		Events: events,
@@ -107,7 +119,7 @@ func (f *Acker) AckBatch(ctx context.Context, actions []fleetapi.Action) (res *f
 
 	res, err = cmd.Execute(ctx, req)
 	if err != nil {
-		return nil, errors.New(err, fmt.Sprintf("acknowledge %d actions '%v' for elastic-agent '%s' failed", len(actions), actions, agentID), errors.TypeNetwork)
+		return nil, errors.New(err, fmt.Sprintf("acknowledge %d actions '%s' for elastic-agent '%s' failed", len(ids), strings.Join(ids, ","), f.agentInfo.AgentID()), errors.TypeNetwork)
 	}
 	return res, nil
 }
@@ -18,7 +18,8 @@ type batchAcker interface {
 	AckBatch(ctx context.Context, actions []fleetapi.Action) (*fleetapi.AckResponse, error)
 }
 
-type retrier interface {
+// Retrier retries the acks of the actions which failed, implemented by the retrier and the spool.
+type Retrier interface {
 	Enqueue([]fleetapi.Action)
 }
 
@@ -27,7 +28,7 @@ type Acker struct {
 	log     *logger.Logger
 	acker   batchAcker
 	queue   []fleetapi.Action
-	retrier retrier
+	retrier Retrier
 }
 
 // Option Acker option function
@@ -49,7 +50,7 @@ func NewAcker(baseAcker batchAcker, log *logger.Logger, opts ...Option) *Acker {
 }
 
 // WithRetrier option allows to specify the Retrier for acking
-func WithRetrier(r retrier) Option {
+func WithRetrier(r Retrier) Option {
 	return func(f *Acker) {
 		f.retrier = r
 	}
@@ -0,0 +1,25 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package spool
+
+const defaultMaxEvents = 2048
+
+// Config is the configuration of the ack spool.
+type Config struct {
+	// Enabled persists the acks not sent to Fleet on disk, when disabled they are retried from
+	// memory and lost on restart.
+	Enabled bool `config:"enabled" yaml:"enabled"`
+	// MaxEvents is the number of acks kept in the spool, the oldest ones are dropped when the spool
+	// is full.
+	MaxEvents int `config:"max_events" yaml:"max_events" validate:"positive"`
+}
+
+// DefaultConfig creates a config with the spool enabled.
+func DefaultConfig() *Config {
+	return &Config{
+		Enabled:   true,
+		MaxEvents: defaultMaxEvents,
+	}
+}
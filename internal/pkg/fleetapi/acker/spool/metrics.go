@@ -0,0 +1,18 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package spool
+
+import (
+	"github.com/elastic/elastic-agent-libs/monitoring"
+)
+
+// Spool metrics exposed under fleet.ack_spool by the /stats monitoring endpoint.
+var (
+	spoolRegistry = monitoring.GetNamespace("stats").GetRegistry().NewRegistry("fleet.ack_spool")
+
+	spoolDepth   = monitoring.NewInt(spoolRegistry, "depth")
+	spoolAcked   = monitoring.NewUint(spoolRegistry, "acked")
+	spoolDropped = monitoring.NewUint(spoolRegistry, "dropped")
+)
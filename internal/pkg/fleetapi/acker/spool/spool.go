@@ -0,0 +1,363 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+// Package spool keeps the acks not sent to Fleet on disk until Fleet is reachable again, they are
+// sent in order after a restart or a long outage of Fleet.
+package spool
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/storage"
+	"github.com/elastic/elastic-agent/internal/pkg/core/backoff"
+	"github.com/elastic/elastic-agent/internal/pkg/fleetapi"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+const (
+	spoolVersion = 1
+
+	defaultBatchSize     = 100
+	defaultMaxRejections = 5
+
+	defaultInitialRetryInterval = 1 * time.Minute
+	defaultMaxRetryInterval     = 5 * time.Minute
+)
+
+// EventAcker sends the ack events to Fleet, implemented by the fleet acker.
+type EventAcker interface {
+	Events(actions []fleetapi.Action) []fleetapi.AckEvent
+	AckEvents(ctx context.Context, events []fleetapi.AckEvent) (*fleetapi.AckResponse, error)
+}
+
+// Option Spool option function
+type Option func(*Spool)
+
+// Spool is a bounded ack retrier persisting the pending acks in the encrypted storage, it is used
+// by the lazy acker in place of the in memory retrier.
+type Spool struct {
+	acker   EventAcker
+	store   storage.Storage
+	log     *logger.Logger
+	agentID string // the acks spooled for another agent, before a re-enrollment, are dropped
+
+	kickCh chan struct{} // signal channel to kickoff drain loop if not running
+	doneCh chan struct{} // signal channel when drain loop is done
+
+	entries []entry // pending acks, in order
+
+	maxEvents            int           // max number of pending acks
+	maxRejections        int           // max number of times Fleet can reject an ack before it is dropped
+	batchSize            int           // max number of acks sent at once
+	initialRetryInterval time.Duration // initial retry interval
+	maxRetryInterval     time.Duration // max retry interval
+
+	mx sync.Mutex
+}
+
+type entry struct {
+	Event      fleetapi.AckEvent `json:"event"`
+	Rejections int               `json:"rejections,omitempty"`
+}
+
+type spoolFile struct {
+	Version int     `json:"version"`
+	AgentID string  `json:"agent_id,omitempty"`
+	Entries []entry `json:"entries"`
+}
+
+// New creates a new spool, the acks spooled before a restart are loaded from the store.
+func New(acker EventAcker, store storage.Storage, log *logger.Logger, cfg *Config, opts ...Option) (*Spool, error) {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	s := &Spool{
+		acker:                acker,
+		store:                store,
+		log:                  log,
+		maxEvents:            cfg.MaxEvents,
+		maxRejections:        defaultMaxRejections,
+		batchSize:            defaultBatchSize,
+		initialRetryInterval: defaultInitialRetryInterval,
+		maxRetryInterval:     defaultMaxRetryInterval,
+		kickCh:               make(chan struct{}, 1),
+		doneCh:               make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.maxEvents <= 0 {
+		s.maxEvents = defaultMaxEvents
+	}
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	if len(s.entries) > 0 {
+		s.log.Infof("ack spool: %d acks pending from the previous run", len(s.entries))
+		s.kick()
+	}
+	return s, nil
+}
+
+// WithInitialRetryInterval configures spool with initial retry delay provided
+func WithInitialRetryInterval(dur time.Duration) Option {
+	return func(s *Spool) {
+		s.initialRetryInterval = dur
+	}
+}
+
+// WithMaxRetryInterval configures spool with max retry interval provided
+func WithMaxRetryInterval(dur time.Duration) Option {
+	return func(s *Spool) {
+		s.maxRetryInterval = dur
+	}
+}
+
+// WithAgentID configures the ID of the agent the acks are spooled for, the acks spooled for
+// another agent, e.g. before the agent is enrolled again, are dropped when the spool is loaded.
+func WithAgentID(id string) Option {
+	return func(s *Spool) {
+		s.agentID = id
+	}
+}
+
+// WithMaxRejections configures the number of times Fleet can reject an ack before it is dropped.
+// Network errors do not count, the acks are kept until Fleet is reachable.
+func WithMaxRejections(n int) Option {
+	return func(s *Spool) {
+		s.maxRejections = n
+	}
+}
+
+// WithBatchSize configures the max number of acks sent at once.
+func WithBatchSize(n int) Option {
+	return func(s *Spool) {
+		s.batchSize = n
+	}
+}
+
+// Done signals when drain loop is done, useful for testing
+func (s *Spool) Done() <-chan struct{} {
+	return s.doneCh
+}
+
+// Len returns the number of pending acks.
+func (s *Spool) Len() int {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return len(s.entries)
+}
+
+// Run runs the drain loop
+func (s *Spool) Run(ctx context.Context) {
+	for {
+		select {
+		case <-s.kickCh:
+			s.drain(ctx)
+		case <-ctx.Done():
+			s.log.Debugf("ack spool: exit on %v", ctx.Err())
+			return
+		}
+	}
+}
+
+// Enqueue spools the acks of the actions, an action already spooled is replaced by its latest ack.
+func (s *Spool) Enqueue(actions []fleetapi.Action) {
+	if len(actions) == 0 {
+		return
+	}
+	events := s.acker.Events(actions)
+
+	s.mx.Lock()
+	for _, ev := range events {
+		if i := s.indexOf(ev.ActionID); i >= 0 {
+			s.log.Debugf("ack spool: action with id '%s' has already been spooled", ev.ActionID)
+			s.entries[i] = entry{Event: ev}
+			continue
+		}
+		s.entries = append(s.entries, entry{Event: ev})
+	}
+	if dropped := len(s.entries) - s.maxEvents; dropped > 0 {
+		s.log.Warnf("ack spool: spool is full, dropping the %d oldest acks", dropped)
+		s.entries = append([]entry{}, s.entries[dropped:]...)
+		spoolDropped.Add(uint64(dropped))
+	}
+	s.save()
+	s.mx.Unlock()
+
+	s.kick()
+}
+
+func (s *Spool) kick() {
+	// Signal to kick off drain loop, non blocking if the signal is already pending
+	select {
+	case s.kickCh <- struct{}{}:
+	default:
+	}
+}
+
+// drain sends the acks in order until the spool is empty, it backs off while Fleet fails.
+func (s *Spool) drain(ctx context.Context) {
+	s.log.Debug("ack spool: enter drain loop")
+
+	b := backoff.NewEqualJitterBackoff(ctx.Done(), s.initialRetryInterval, s.maxRetryInterval)
+	for {
+		if s.sendAll(ctx) {
+			break
+		}
+		if !b.Wait() {
+			break
+		}
+	}
+
+	// Signal loop is done
+	select {
+	case s.doneCh <- struct{}{}:
+	default:
+	}
+	s.log.Debug("ack spool: exit drain loop")
+}
+
+// sendAll sends the batches of acks while Fleet accepts them, it returns true when the spool is
+// empty.
+func (s *Spool) sendAll(ctx context.Context) bool {
+	for {
+		s.mx.Lock()
+		n := len(s.entries)
+		if n > s.batchSize {
+			n = s.batchSize
+		}
+		events := make([]fleetapi.AckEvent, 0, n)
+		for _, e := range s.entries[:n] {
+			events = append(events, e.Event)
+		}
+		s.mx.Unlock()
+
+		if len(events) == 0 {
+			return true
+		}
+
+		resp, err := s.acker.AckEvents(ctx, events)
+		if err != nil {
+			s.log.Errorf("ack spool: sending %d acks failed, keeping them for retry: %v", len(events), err)
+			return false
+		}
+		if !s.commit(events, resp) {
+			return false
+		}
+	}
+}
+
+// commit removes the acks accepted by Fleet from the spool, it returns false when Fleet rejected
+// some of the acks.
+func (s *Spool) commit(events []fleetapi.AckEvent, resp *fleetapi.AckResponse) bool {
+	isFailed := func(pos int) bool {
+		if resp == nil || !resp.Errors {
+			return false
+		}
+		if pos >= len(resp.Items) {
+			return true
+		}
+		return resp.Items[pos].Status >= http.StatusBadRequest
+	}
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	rejected := false
+	for i, ev := range events {
+		idx := s.indexOf(ev.ActionID)
+		if idx < 0 {
+			continue
+		}
+		if !isFailed(i) {
+			s.remove(idx)
+			spoolAcked.Inc()
+			continue
+		}
+
+		rejected = true
+		s.entries[idx].Rejections++
+		if s.entries[idx].Rejections >= s.maxRejections {
+			s.log.Errorf("ack spool: ack of action '%s' rejected %d times, dropping it", ev.ActionID, s.entries[idx].Rejections)
+			s.remove(idx)
+			spoolDropped.Inc()
+		}
+	}
+	s.save()
+	return !rejected
+}
+
+func (s *Spool) indexOf(actionID string) int {
+	for i, e := range s.entries {
+		if e.Event.ActionID == actionID {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Spool) remove(i int) {
+	s.entries = append(s.entries[:i], s.entries[i+1:]...)
+}
+
+func (s *Spool) load() error {
+	exists, err := s.store.Exists()
+	if err != nil || !exists {
+		return err
+	}
+
+	reader, err := s.store.Load()
+	if err != nil {
+		return errors.New(err, "could not load the ack spool", errors.TypeFilesystem)
+	}
+	defer reader.Close()
+
+	var f spoolFile
+	if err := json.NewDecoder(reader).Decode(&f); err != nil {
+		// a corrupted spool must not prevent the agent from starting
+		s.log.Errorf("ack spool: could not decode the ack spool, pending acks are lost: %v", err)
+		return nil
+	}
+	if f.Version != spoolVersion {
+		s.log.Errorf("ack spool: unsupported ack spool version %d, pending acks are lost", f.Version)
+		return nil
+	}
+	if f.AgentID != s.agentID {
+		// the agent was enrolled again, Fleet does not know the actions of the previous enrollment
+		s.log.Infof("ack spool: dropping the %d acks spooled before the agent was enrolled again", len(f.Entries))
+		spoolDropped.Add(uint64(len(f.Entries)))
+		return nil
+	}
+
+	s.entries = f.Entries
+	if len(s.entries) > s.maxEvents {
+		dropped := len(s.entries) - s.maxEvents
+		s.entries = s.entries[dropped:]
+		spoolDropped.Add(uint64(dropped))
+	}
+	spoolDepth.Set(int64(len(s.entries)))
+	return nil
+}
+
+// save persists the entries, it is called with the lock held.
+func (s *Spool) save() {
+	spoolDepth.Set(int64(len(s.entries)))
+
+	data, err := json.Marshal(spoolFile{Version: spoolVersion, AgentID: s.agentID, Entries: s.entries})
+	if err != nil {
+		s.log.Errorf("ack spool: could not encode the ack spool: %v", err)
+		return
+	}
+	if err := s.store.Save(bytes.NewReader(data)); err != nil {
+		s.log.Errorf("ack spool: could not save the ack spool, pending acks are kept in memory: %v", err)
+	}
+}
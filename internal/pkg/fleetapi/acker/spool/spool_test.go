@@ -0,0 +1,251 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package spool
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent-libs/monitoring"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/storage"
+	"github.com/elastic/elastic-agent/internal/pkg/fleetapi"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+var errFleet = errors.New("fleet unavailable")
+
+type testAcker struct {
+	mx sync.Mutex
+
+	err error
+	// status returned for the actions, 200 when missing
+	status map[string]int
+
+	batches [][]string
+}
+
+func (a *testAcker) Events(actions []fleetapi.Action) []fleetapi.AckEvent {
+	events := make([]fleetapi.AckEvent, 0, len(actions))
+	for _, action := range actions {
+		events = append(events, fleetapi.AckEvent{
+			EventType: "ACTION_RESULT",
+			SubType:   "ACKNOWLEDGED",
+			ActionID:  action.ID(),
+			AgentID:   "agent-id",
+		})
+	}
+	return events
+}
+
+func (a *testAcker) AckEvents(_ context.Context, events []fleetapi.AckEvent) (*fleetapi.AckResponse, error) {
+	a.mx.Lock()
+	defer a.mx.Unlock()
+
+	ids := make([]string, 0, len(events))
+	for _, ev := range events {
+		ids = append(ids, ev.ActionID)
+	}
+	a.batches = append(a.batches, ids)
+	if a.err != nil {
+		return nil, a.err
+	}
+
+	resp := &fleetapi.AckResponse{Action: "acks"}
+	for _, id := range ids {
+		status, ok := a.status[id]
+		if !ok {
+			status = http.StatusOK
+		}
+		if status >= http.StatusBadRequest {
+			resp.Errors = true
+		}
+		resp.Items = append(resp.Items, fleetapi.AckResponseItem{Status: status})
+	}
+	return resp, nil
+}
+
+func (a *testAcker) setErr(err error) {
+	a.mx.Lock()
+	defer a.mx.Unlock()
+	a.err = err
+}
+
+func (a *testAcker) sent() [][]string {
+	a.mx.Lock()
+	defer a.mx.Unlock()
+	return append([][]string{}, a.batches...)
+}
+
+func actions(ids ...string) []fleetapi.Action {
+	actions := make([]fleetapi.Action, 0, len(ids))
+	for _, id := range ids {
+		actions = append(actions, &fleetapi.ActionUnknown{ActionID: id})
+	}
+	return actions
+}
+
+func newTestSpool(t *testing.T, acker EventAcker, store storage.Storage, cfg *Config, opts ...Option) *Spool {
+	log, err := logger.New("", false)
+	require.NoError(t, err)
+
+	opts = append([]Option{
+		WithInitialRetryInterval(10 * time.Millisecond),
+		WithMaxRetryInterval(20 * time.Millisecond),
+	}, opts...)
+	s, err := New(acker, store, log, cfg, opts...)
+	require.NoError(t, err)
+	return s
+}
+
+func waitDone(t *testing.T, s *Spool) {
+	select {
+	case <-s.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("ack spool not drained")
+	}
+}
+
+func TestSpoolDrainsInOrder(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	acker := &testAcker{}
+	s := newTestSpool(t, acker, storage.NewDiskStore(filepath.Join(t.TempDir(), "ack_spool.enc")), nil, WithBatchSize(2))
+	s.Enqueue(actions("1", "2", "3"))
+
+	go s.Run(ctx)
+	waitDone(t, s)
+
+	assert.Equal(t, [][]string{{"1", "2"}, {"3"}}, acker.sent())
+	assert.Equal(t, 0, s.Len())
+}
+
+func TestSpoolDeduplicates(t *testing.T) {
+	acker := &testAcker{}
+	s := newTestSpool(t, acker, storage.NewDiskStore(filepath.Join(t.TempDir(), "ack_spool.enc")), nil)
+
+	s.Enqueue(actions("1", "2"))
+	s.Enqueue(actions("2", "1", "3"))
+	assert.Equal(t, 3, s.Len())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+	waitDone(t, s)
+
+	assert.Equal(t, [][]string{{"1", "2", "3"}}, acker.sent())
+}
+
+func TestSpoolBounded(t *testing.T) {
+	acker := &testAcker{}
+	s := newTestSpool(t, acker, storage.NewDiskStore(filepath.Join(t.TempDir(), "ack_spool.enc")), &Config{Enabled: true, MaxEvents: 2})
+
+	dropped := spoolDropped.Get()
+	s.Enqueue(actions("1", "2", "3"))
+	assert.Equal(t, 2, s.Len())
+	assert.Equal(t, dropped+1, spoolDropped.Get())
+	assert.Equal(t, int64(2), spoolDepth.Get())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+	waitDone(t, s)
+
+	assert.Equal(t, [][]string{{"2", "3"}}, acker.sent())
+	assert.Equal(t, int64(0), spoolDepth.Get())
+}
+
+func TestSpoolSurvivesRestart(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "ack_spool.enc")
+
+	// Fleet is unreachable, the acks are kept
+	acker := &testAcker{err: errFleet}
+	s := newTestSpool(t, acker, storage.NewDiskStore(file), nil)
+	s.Enqueue(actions("1", "2"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go s.Run(ctx)
+	require.Eventually(t, func() bool { return len(acker.sent()) >= 2 }, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	assert.Equal(t, 2, s.Len())
+
+	// restart, the acks are loaded from the store and sent once Fleet is back
+	acker = &testAcker{}
+	s = newTestSpool(t, acker, storage.NewDiskStore(file), nil)
+	assert.Equal(t, 2, s.Len())
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+	waitDone(t, s)
+
+	assert.Equal(t, [][]string{{"1", "2"}}, acker.sent())
+
+	s = newTestSpool(t, &testAcker{}, storage.NewDiskStore(file), nil)
+	assert.Equal(t, 0, s.Len())
+}
+
+func TestSpoolDropsAcksOfPreviousEnrollment(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "ack_spool.enc")
+
+	s := newTestSpool(t, &testAcker{err: errFleet}, storage.NewDiskStore(file), nil, WithAgentID("agent-id"))
+	s.Enqueue(actions("1", "2"))
+	assert.Equal(t, 2, s.Len())
+
+	// restart as the same agent, the acks are kept
+	s = newTestSpool(t, &testAcker{}, storage.NewDiskStore(file), nil, WithAgentID("agent-id"))
+	assert.Equal(t, 2, s.Len())
+
+	// restart after the agent was enrolled again, Fleet does not know the actions anymore
+	dropped := spoolDropped.Get()
+	s = newTestSpool(t, &testAcker{}, storage.NewDiskStore(file), nil, WithAgentID("new-agent-id"))
+	assert.Equal(t, 0, s.Len())
+	assert.Equal(t, dropped+2, spoolDropped.Get())
+}
+
+func TestSpoolMetricsInStats(t *testing.T) {
+	snapshot := monitoring.CollectFlatSnapshot(monitoring.GetNamespace("stats").GetRegistry(), monitoring.Full, false)
+	assert.Contains(t, snapshot.Ints, "fleet.ack_spool.depth")
+}
+
+func TestSpoolRecoversFromOutage(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	acker := &testAcker{err: errFleet}
+	s := newTestSpool(t, acker, storage.NewDiskStore(filepath.Join(t.TempDir(), "ack_spool.enc")), nil)
+	s.Enqueue(actions("1"))
+
+	go s.Run(ctx)
+	require.Eventually(t, func() bool { return len(acker.sent()) >= 3 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, s.Len())
+
+	acker.setErr(nil)
+	waitDone(t, s)
+	assert.Equal(t, 0, s.Len())
+}
+
+func TestSpoolDropsRejectedAcks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	acker := &testAcker{status: map[string]int{"2": http.StatusNotFound}}
+	s := newTestSpool(t, acker, storage.NewDiskStore(filepath.Join(t.TempDir(), "ack_spool.enc")), nil, WithMaxRejections(2))
+	s.Enqueue(actions("1", "2", "3"))
+
+	go s.Run(ctx)
+	waitDone(t, s)
+
+	assert.Equal(t, [][]string{{"1", "2", "3"}, {"2"}}, acker.sent())
+	assert.Equal(t, 0, s.Len())
+}
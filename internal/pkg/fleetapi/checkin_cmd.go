@@ -16,6 +16,7 @@ import (
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/info"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
 	"github.com/elastic/elastic-agent/internal/pkg/fleetapi/client"
+	"github.com/elastic/elastic-agent/internal/pkg/remote"
 )
 
 const checkingPath = "/api/fleet/agents/%s/checkin"
@@ -26,6 +27,9 @@ type CheckinRequest struct {
 	Message  string        `json:"message,omitempty"`
 	AckToken string        `json:"ack_token,omitempty"`
 	Metadata *info.ECSMeta `json:"local_metadata,omitempty"`
+	// PollTimeout is the poll timeout previously sent by fleet-server, the agent
+	// waits up to this duration for the server to answer the long poll.
+	PollTimeout string `json:"poll_timeout,omitempty"`
 }
 
 // SerializableEvent is a representation of the event to be send to the Fleet Server API via the checkin
@@ -53,6 +57,12 @@ func (e *CheckinRequest) Validate() error {
 type CheckinResponse struct {
 	AckToken string  `json:"ack_token"`
 	Actions  Actions `json:"actions"`
+	// PollTimeout is how long fleet-server holds the next checkin open while
+	// waiting for actions, as a duration string (e.g. "5m").
+	PollTimeout string `json:"poll_timeout,omitempty"`
+	// NextCheckin is how long fleet-server asks the agent to wait before the
+	// next checkin, as a duration string (e.g. "30s").
+	NextCheckin string `json:"next_checkin,omitempty"`
 }
 
 // Validate validates the response send from the server.
@@ -60,6 +70,32 @@ func (e *CheckinResponse) Validate() error {
 	return nil
 }
 
+// PollTimeoutDuration returns the poll timeout sent by fleet-server, zero when none was sent.
+func (e *CheckinResponse) PollTimeoutDuration() (time.Duration, error) {
+	return parseHint("poll_timeout", e.PollTimeout)
+}
+
+// NextCheckinDuration returns the delay before the next checkin requested by fleet-server,
+// zero when none was sent.
+func (e *CheckinResponse) NextCheckinDuration() (time.Duration, error) {
+	return parseHint("next_checkin", e.NextCheckin)
+}
+
+func parseHint(name, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, errors.New(err, fmt.Sprintf("invalid %s received from fleet-server", name), errors.TypeUnexpected)
+	}
+	if d < 0 {
+		return 0, errors.New(fmt.Sprintf("negative %s %q received from fleet-server", name, value), errors.TypeUnexpected)
+	}
+	return d, nil
+}
+
 // CheckinCmd is a fleet API command.
 type CheckinCmd struct {
 	client client.Sender
@@ -94,7 +130,8 @@ func (e *CheckinCmd) Execute(ctx context.Context, r *CheckinRequest) (*CheckinRe
 
 	cp := fmt.Sprintf(checkingPath, e.info.AgentID())
 	sendStart := time.Now()
-	resp, err := e.client.Send(ctx, "POST", cp, nil, nil, bytes.NewBuffer(b))
+	// fleet-server holds the checkin until it has actions for the agent or the poll times out
+	resp, err := e.client.Send(remote.WithLongPoll(ctx), "POST", cp, nil, nil, bytes.NewBuffer(b))
 	sendDuration := time.Now().Sub(sendStart)
 	if err != nil {
 		return nil, sendDuration, errors.New(err,
@@ -104,6 +141,10 @@ func (e *CheckinCmd) Execute(ctx context.Context, r *CheckinRequest) (*CheckinRe
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, sendDuration, client.NewTooManyRequestsError(resp)
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		return nil, sendDuration, client.ExtractError(resp.Body)
 	}
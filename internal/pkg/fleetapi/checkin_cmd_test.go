@@ -269,4 +269,78 @@ func TestCheckin(t *testing.T) {
 			require.Equal(t, 0, len(r.Actions))
 		},
 	))
+
+	t.Run("Checkin receives poll hints", withServerWithAuthClient(
+		func(t *testing.T) *http.ServeMux {
+			raw := `{"actions": [], "poll_timeout": "5m", "next_checkin": "30s"}`
+			mux := http.NewServeMux()
+			path := fmt.Sprintf("/api/fleet/agents/%s/checkin", agentInfo.AgentID())
+			mux.HandleFunc(path, authHandler(func(w http.ResponseWriter, r *http.Request) {
+				var req *CheckinRequest
+
+				content, err := ioutil.ReadAll(r.Body)
+				assert.NoError(t, err)
+				assert.NoError(t, json.Unmarshal(content, &req))
+				assert.Equal(t, "2m", req.PollTimeout)
+
+				w.WriteHeader(http.StatusOK)
+				fmt.Fprint(w, raw)
+			}, withAPIKey))
+			return mux
+		}, withAPIKey,
+		func(t *testing.T, client client.Sender) {
+			cmd := NewCheckinCmd(agentInfo, client)
+
+			request := CheckinRequest{PollTimeout: "2m"}
+
+			r, _, err := cmd.Execute(ctx, &request)
+			require.NoError(t, err)
+
+			pollTimeout, err := r.PollTimeoutDuration()
+			require.NoError(t, err)
+			assert.Equal(t, 5*time.Minute, pollTimeout)
+
+			nextCheckin, err := r.NextCheckinDuration()
+			require.NoError(t, err)
+			assert.Equal(t, 30*time.Second, nextCheckin)
+		},
+	))
+
+	t.Run("Too many requests returns the retry after delay", withServerWithAuthClient(
+		func(t *testing.T) *http.ServeMux {
+			mux := http.NewServeMux()
+			path := fmt.Sprintf("/api/fleet/agents/%s/checkin", agentInfo.AgentID())
+			mux.HandleFunc(path, authHandler(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", "42")
+				w.WriteHeader(http.StatusTooManyRequests)
+				fmt.Fprint(w, `{"statusCode": 429, "error": "Too Many Requests"}`)
+			}, withAPIKey))
+			return mux
+		}, withAPIKey,
+		func(t *testing.T, c client.Sender) {
+			cmd := NewCheckinCmd(agentInfo, c)
+
+			_, _, err := cmd.Execute(ctx, &CheckinRequest{})
+			require.Error(t, err)
+
+			var tooManyRequests *client.TooManyRequestsError
+			require.ErrorAs(t, err, &tooManyRequests)
+			assert.Equal(t, 42*time.Second, tooManyRequests.RetryAfter)
+		},
+	))
+}
+
+func TestCheckinResponseHints(t *testing.T) {
+	r := &CheckinResponse{}
+	d, err := r.NextCheckinDuration()
+	require.NoError(t, err)
+	assert.Zero(t, d)
+
+	r.NextCheckin = "soon"
+	_, err = r.NextCheckinDuration()
+	assert.Error(t, err)
+
+	r.PollTimeout = "-1m"
+	_, err = r.PollTimeoutDuration()
+	assert.Error(t, err)
 }
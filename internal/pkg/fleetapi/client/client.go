@@ -64,9 +64,15 @@ func init() {
 // - Send the API Key on every HTTP request.
 // - Ensure a minimun version of fleet-server is required.
 // - Send the Fleet User Agent on every HTTP request.
+// - Record the requests and the responses when the recorder is enabled.
 func NewAuthWithConfig(log *logger.Logger, apiKey string, cfg remote.Config) (*remote.Client, error) {
+	record, err := recorderFor(cfg.Recorder)
+	if err != nil {
+		return nil, err
+	}
+
 	return remote.NewWithConfig(log, cfg, func(rt http.RoundTripper) (http.RoundTripper, error) {
-		rt, err := baseRoundTrippers(rt)
+		rt, err := baseRoundTrippers(record(rt))
 		if err != nil {
 			return nil, err
 		}
@@ -82,7 +88,14 @@ func NewAuthWithConfig(log *logger.Logger, apiKey string, cfg remote.Config) (*r
 
 // NewWithConfig takes a fleet-server configuration and create a remote.client with the appropriate tripper.
 func NewWithConfig(log *logger.Logger, cfg remote.Config) (*remote.Client, error) {
-	return remote.NewWithConfig(log, cfg, baseRoundTrippers)
+	record, err := recorderFor(cfg.Recorder)
+	if err != nil {
+		return nil, err
+	}
+
+	return remote.NewWithConfig(log, cfg, func(rt http.RoundTripper) (http.RoundTripper, error) {
+		return baseRoundTrippers(record(rt))
+	})
 }
 
 // ExtractError extracts error from a fleet-server response
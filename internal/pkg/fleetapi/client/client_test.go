@@ -147,3 +147,25 @@ func TestExtract(t *testing.T) {
 		assert.True(t, strings.Index(err.Error(), "fails because") > 0)
 	})
 }
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2022, 11, 1, 10, 0, 0, 0, time.UTC)
+
+	testCases := map[string]struct {
+		value    string
+		expected time.Duration
+	}{
+		"empty":          {value: "", expected: 0},
+		"seconds":        {value: "120", expected: 2 * time.Minute},
+		"negative":       {value: "-5", expected: 0},
+		"http date":      {value: "Tue, 01 Nov 2022 10:00:30 GMT", expected: 30 * time.Second},
+		"date in past":   {value: "Tue, 01 Nov 2022 09:00:00 GMT", expected: 0},
+		"invalid format": {value: "soon", expected: 0},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ParseRetryAfter(tc.value, now))
+		})
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package client
+
+import (
+	"net/http"
+	"path/filepath"
+	"sync"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/internal/pkg/remote"
+)
+
+// recorders are shared by all the clients recording to the same files, the clients are created
+// again when the Fleet Server hosts change.
+var (
+	recordersMx sync.Mutex
+	recorders   = map[string]*remote.Recorder{}
+)
+
+// recorderFor returns a function wrapping the transport of a client with the recorder configured,
+// the transport is returned as is when the recorder is disabled.
+func recorderFor(cfg remote.RecorderConfig) (func(http.RoundTripper) http.RoundTripper, error) {
+	if !cfg.Enabled {
+		return func(rt http.RoundTripper) http.RoundTripper { return rt }, nil
+	}
+	if cfg.Path == "" {
+		cfg.Path = filepath.Join(paths.Logs(), "fleet-recording")
+	}
+
+	recordersMx.Lock()
+	defer recordersMx.Unlock()
+	recorder, ok := recorders[cfg.Path]
+	if !ok {
+		var err error
+		recorder, err = remote.NewRecorder(cfg)
+		if err != nil {
+			return nil, errors.New(err, "fail to create the recorder of the Fleet Server requests",
+				errors.TypeFilesystem,
+				errors.M(errors.MetaKeyPath, cfg.Path))
+		}
+		recorders[cfg.Path] = recorder
+	}
+	return recorder.Wrap, nil
+}
@@ -0,0 +1,150 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/internal/pkg/remote"
+)
+
+// ErrReplayExhausted is returned by a replay when its recording has no more responses for a request.
+var ErrReplayExhausted = fmt.Errorf("no more recorded responses for the request")
+
+// Replay is a Sender serving the responses of a recording written by the recorder of the client,
+// it reproduces the interactions of an agent with Fleet Server offline. A request is answered by
+// the first response recorded for the same method and path not served yet, so the requests of the
+// same kind are answered in the order they were recorded. The redacted secrets are replayed as is.
+type Replay struct {
+	mx      sync.Mutex
+	uri     string
+	entries []remote.Entry
+	served  []bool
+}
+
+// NewReplay returns a replay serving the recorded entries.
+func NewReplay(entries []remote.Entry) (*Replay, error) {
+	r := &Replay{
+		entries: entries,
+		served:  make([]bool, len(entries)),
+	}
+	for _, e := range entries {
+		u, err := url.Parse(e.Request.URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recorded URL %s: %w", e.Request.URL, err)
+		}
+		if r.uri == "" {
+			r.uri = u.Scheme + "://" + u.Host
+		}
+	}
+	return r, nil
+}
+
+// LoadReplay returns a replay serving the entries recorded in the file.
+func LoadReplay(path string) (*Replay, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.New(err,
+			fmt.Sprintf("could not open the recording %s", path),
+			errors.TypeFilesystem,
+			errors.M(errors.MetaKeyPath, path))
+	}
+	defer f.Close()
+
+	entries, err := remote.ReadEntries(f)
+	if err != nil {
+		return nil, errors.New(err,
+			fmt.Sprintf("could not read the recording %s", path),
+			errors.TypeConfig,
+			errors.M(errors.MetaKeyPath, path))
+	}
+	return NewReplay(entries)
+}
+
+// Send answers the request with the next recorded response of the same method and path.
+func (r *Replay) Send(
+	ctx context.Context,
+	method string,
+	path string,
+	params url.Values,
+	headers http.Header,
+	body io.Reader,
+) (*http.Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if body != nil {
+		// the request is consumed like a real client would
+		_, _ = io.Copy(ioutil.Discard, body)
+	}
+
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	suffix := "/" + strings.TrimPrefix(path, "/")
+	for i, e := range r.entries {
+		if r.served[i] || e.Request.Method != method {
+			continue
+		}
+		u, err := url.Parse(e.Request.URL)
+		if err != nil || !strings.HasSuffix(strings.TrimSuffix(u.Path, "/"), strings.TrimSuffix(suffix, "/")) {
+			continue
+		}
+
+		r.served[i] = true
+		if e.Error != "" {
+			return nil, fmt.Errorf("recorded error: %s", e.Error)
+		}
+		return newReplayedResponse(method, u, e.Response), nil
+	}
+	return nil, fmt.Errorf("%s %s: %w", method, path, ErrReplayExhausted)
+}
+
+// URI returns the URI of the recorded Fleet Server.
+func (r *Replay) URI() string {
+	return r.uri
+}
+
+// Remaining returns the number of recorded responses not served yet.
+func (r *Replay) Remaining() int {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	var n int
+	for _, served := range r.served {
+		if !served {
+			n++
+		}
+	}
+	return n
+}
+
+func newReplayedResponse(method string, u *url.URL, e remote.EntryResponse) *http.Response {
+	header := http.Header{}
+	for _, h := range e.Headers {
+		header.Add(h.Name, h.Value)
+	}
+	// the recorded length does not match the redacted body
+	header.Del("Content-Length")
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status)),
+		StatusCode:    e.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(strings.NewReader(e.Content.Text)),
+		ContentLength: int64(len(e.Content.Text)),
+		Request:       &http.Request{Method: method, URL: u, Header: http.Header{}},
+	}
+}
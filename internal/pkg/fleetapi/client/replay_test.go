@@ -0,0 +1,112 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent/internal/pkg/remote"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+const (
+	checkinPath = "/api/fleet/agents/agent-id/checkin"
+	ackPath     = "/api/fleet/agents/agent-id/acks"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	ctx := context.Background()
+	recording := filepath.Join(t.TempDir(), "fleet-recording")
+
+	checkins := 0
+	withServer(
+		func(t *testing.T) *http.ServeMux {
+			mux := http.NewServeMux()
+			mux.HandleFunc(checkinPath, authHandler(func(w http.ResponseWriter, r *http.Request) {
+				checkins++
+				fmt.Fprintf(w, `{"ack_token":"token-%d","actions":[{"id":"action-%d","type":"POLICY_CHANGE","data":{"policy":{"outputs":{"default":{"api_key":"id:key"}}}}}]}`, checkins, checkins)
+			}, "abc123"))
+			mux.HandleFunc(ackPath, authHandler(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"statusCode":400,"error":"Bad Request"}`)
+			}, "abc123"))
+			return mux
+		}, func(t *testing.T, host string) {
+			l, err := logger.New("", false)
+			require.NoError(t, err)
+			cfg := remote.DefaultClientConfig()
+			cfg.Host = host
+			cfg.Recorder = remote.RecorderConfig{Enabled: true, Path: recording}
+
+			client, err := NewAuthWithConfig(l, "abc123", cfg)
+			require.NoError(t, err)
+			for _, path := range []string{checkinPath, ackPath, checkinPath} {
+				resp, err := client.Send(ctx, http.MethodPost, path, nil, nil, strings.NewReader(`{"status":"online"}`))
+				require.NoError(t, err)
+				resp.Body.Close()
+			}
+		},
+	)(t)
+
+	files, err := filepath.Glob(recording + "-*.ndjson")
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	raw, err := ioutil.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "abc123")
+	assert.NotContains(t, string(raw), "id:key")
+
+	replay, err := LoadReplay(files[0])
+	require.NoError(t, err)
+	assert.Equal(t, 3, replay.Remaining())
+	assert.True(t, strings.HasPrefix(replay.URI(), "http://127.0.0.1:"))
+
+	// the acks are answered independently of the checkins, in the order they were recorded
+	body := send(t, replay, ackPath, http.StatusBadRequest)
+	assert.JSONEq(t, `{"statusCode":400,"error":"Bad Request"}`, body)
+	body = send(t, replay, checkinPath, http.StatusOK)
+	assert.Contains(t, body, `"ack_token":"token-1"`)
+	assert.Contains(t, body, `"api_key":"[REDACTED]"`)
+	body = send(t, replay, checkinPath, http.StatusOK)
+	assert.Contains(t, body, `"ack_token":"token-2"`)
+	assert.Equal(t, 0, replay.Remaining())
+
+	_, err = replay.Send(ctx, http.MethodPost, checkinPath, nil, nil, nil) //nolint:bodyclose // no response
+	assert.True(t, errors.Is(err, ErrReplayExhausted))
+}
+
+func TestReplayRecordedError(t *testing.T) {
+	replay, err := NewReplay([]remote.Entry{
+		{Request: remote.EntryRequest{Method: http.MethodPost, URL: "https://fleet:8220" + checkinPath + "?"}, Error: "connection refused"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "https://fleet:8220", replay.URI())
+
+	_, err = replay.Send(context.Background(), http.MethodGet, checkinPath, nil, nil, nil) //nolint:bodyclose // no response
+	assert.True(t, errors.Is(err, ErrReplayExhausted), "the method must match")
+	_, err = replay.Send(context.Background(), http.MethodPost, checkinPath, nil, nil, nil) //nolint:bodyclose // no response
+	assert.EqualError(t, err, "recorded error: connection refused")
+}
+
+func send(t *testing.T, s Sender, path string, expectedStatus int) string {
+	t.Helper()
+	resp, err := s.Send(context.Background(), http.MethodPost, path, nil, nil, strings.NewReader(`{}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, expectedStatus, resp.StatusCode)
+	b, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(b)
+}
@@ -0,0 +1,70 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TooManyRequestsError is returned when fleet-server rejects a request with an
+// HTTP 429 status code, RetryAfter is the delay requested by fleet-server
+// through the Retry-After header, zero when none was sent.
+type TooManyRequestsError struct {
+	RetryAfter time.Duration
+	Err        error
+}
+
+// NewTooManyRequestsError creates a TooManyRequestsError from a fleet-server response,
+// the body of the response is consumed.
+func NewTooManyRequestsError(resp *http.Response) *TooManyRequestsError {
+	return &TooManyRequestsError{
+		RetryAfter: ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		Err:        ExtractError(resp.Body),
+	}
+}
+
+// Error returns the error message.
+func (e *TooManyRequestsError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("too many requests, retry after %s: %v", e.RetryAfter, e.Err)
+	}
+	return fmt.Sprintf("too many requests: %v", e.Err)
+}
+
+// Unwrap returns the error extracted from the response.
+func (e *TooManyRequestsError) Unwrap() error {
+	return e.Err
+}
+
+// ParseRetryAfter parses the value of a Retry-After header, either a number of
+// seconds or an HTTP date, into a delay relative to now. It returns zero when
+// the value is empty, invalid or in the past.
+func ParseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0
+	}
+
+	if d := date.Sub(now); d > 0 {
+		return d
+	}
+	return 0
+}
@@ -88,7 +88,9 @@ func (p EnrollType) MarshalJSON() ([]byte, error) {
 type EnrollRequest struct {
 	EnrollAPIKey string     `json:"-"`
 	Type         EnrollType `json:"type"`
-	Metadata     Metadata   `json:"metadata"`
+	// ID is the agent ID to reuse, Fleet Server may ignore it and assign a new one.
+	ID       string   `json:"id,omitempty"`
+	Metadata Metadata `json:"metadata"`
 }
 
 // Metadata is a all the metadata send or received from the elastic-agent.
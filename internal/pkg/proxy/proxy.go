@@ -0,0 +1,197 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+// Package proxy routes the connections of the agent through different proxies depending on their
+// destination.
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/elastic/elastic-agent-libs/transport/httpcommon"
+)
+
+// Config is the proxy rule set of the agent.
+type Config struct {
+	// Rules are matched in order against the host of a connection, the first rule matching
+	// routes it. The connections matching no rule use the proxy configured by the client.
+	Rules Rules `config:"rules" yaml:"rules,omitempty"`
+}
+
+// DefaultConfig returns the default configuration, without rules.
+func DefaultConfig() *Config {
+	return &Config{}
+}
+
+// Rule routes the connections to the hosts matching one of its patterns through its proxy, or
+// directly when Direct is set.
+//
+// A pattern is either:
+//   - "*", matching every host,
+//   - "*.example.com", matching the subdomains of example.com,
+//   - ".example.com", matching example.com and its subdomains,
+//   - "10.0.0.0/8", matching the IP addresses of the network,
+//   - a host name or an IP address, matching only itself.
+type Rule struct {
+	Hosts   []string          `config:"hosts" yaml:"hosts"`
+	URL     string            `config:"url" yaml:"url,omitempty"`
+	Headers map[string]string `config:"headers" yaml:"headers,omitempty"`
+	Direct  bool              `config:"direct" yaml:"direct,omitempty"`
+}
+
+// Validate validates the rule.
+func (r *Rule) Validate() error {
+	if len(r.Hosts) == 0 {
+		return fmt.Errorf("proxy rule without hosts")
+	}
+	for _, p := range r.Hosts {
+		if err := validatePattern(p); err != nil {
+			return err
+		}
+	}
+	if r.Direct == (r.URL != "") {
+		return fmt.Errorf("proxy rule for %v must set either url or direct", r.Hosts)
+	}
+	if r.Direct {
+		return nil
+	}
+	u, err := url.Parse(r.URL)
+	if err != nil {
+		return fmt.Errorf("invalid proxy url %s: %w", r.URL, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return fmt.Errorf("invalid proxy url %s, the scheme must be http, https or socks5", r.URL)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid proxy url %s, missing host", r.URL)
+	}
+	return nil
+}
+
+// ProxyURL returns the URL of the proxy, nil when the rule connects directly.
+func (r *Rule) ProxyURL() *url.URL {
+	if r.Direct {
+		return nil
+	}
+	u, err := url.Parse(r.URL)
+	if err != nil {
+		return nil
+	}
+	return u
+}
+
+// Matches returns true when the host matches one of the patterns of the rule.
+func (r *Rule) Matches(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, p := range r.Hosts {
+		if matchPattern(strings.ToLower(p), host) {
+			return true
+		}
+	}
+	return false
+}
+
+func validatePattern(p string) error {
+	switch {
+	case p == "":
+		return fmt.Errorf("empty proxy rule host")
+	case strings.Contains(p, "/"):
+		if _, _, err := net.ParseCIDR(p); err != nil {
+			return fmt.Errorf("invalid proxy rule network %s: %w", p, err)
+		}
+	case strings.Contains(p, "://"):
+		return fmt.Errorf("invalid proxy rule host %s, expecting a host name without scheme", p)
+	case strings.Contains(p[1:], "*"):
+		return fmt.Errorf("invalid proxy rule host %s, a wildcard is only allowed as first label", p)
+	}
+	return nil
+}
+
+func matchPattern(p, host string) bool {
+	switch {
+	case p == "*":
+		return true
+	case strings.HasPrefix(p, "*."):
+		return strings.HasSuffix(host, p[1:])
+	case strings.HasPrefix(p, "."):
+		return host == p[1:] || strings.HasSuffix(host, p)
+	case strings.Contains(p, "/"):
+		_, network, err := net.ParseCIDR(p)
+		if err != nil {
+			return false
+		}
+		ip := net.ParseIP(host)
+		return ip != nil && network.Contains(ip)
+	}
+	return p == host
+}
+
+// Rules is an ordered proxy rule set.
+type Rules []Rule
+
+// Match returns the first rule matching the host.
+func (rs Rules) Match(host string) (*Rule, bool) {
+	for i := range rs {
+		if rs[i].Matches(host) {
+			return &rs[i], true
+		}
+	}
+	return nil, false
+}
+
+// ProxyFunc returns the proxy function of a transport routing the requests with the rules, the
+// requests matching no rule use fallback.
+func (rs Rules) ProxyFunc(fallback func(*http.Request) (*url.URL, error)) func(*http.Request) (*url.URL, error) {
+	if len(rs) == 0 {
+		return fallback
+	}
+	return func(req *http.Request) (*url.URL, error) {
+		r, ok := rs.Match(req.URL.Hostname())
+		if !ok {
+			if fallback == nil {
+				return nil, nil
+			}
+			return fallback(req)
+		}
+		return r.ProxyURL(), nil
+	}
+}
+
+// Apply routes the requests of the transport with the rules. The headers of the matching rule are
+// sent to the proxy in place of the ones of the transport.
+func (rs Rules) Apply(t *http.Transport) {
+	if len(rs) == 0 {
+		return
+	}
+	t.Proxy = rs.ProxyFunc(t.Proxy)
+
+	fallback := t.ProxyConnectHeader
+	t.GetProxyConnectHeader = func(_ context.Context, _ *url.URL, target string) (http.Header, error) {
+		host, _, err := net.SplitHostPort(target)
+		if err != nil {
+			host = target
+		}
+		if r, ok := rs.Match(host); ok && len(r.Headers) > 0 {
+			headers := make(http.Header, len(r.Headers))
+			for k, v := range r.Headers {
+				headers.Set(k, v)
+			}
+			return headers, nil
+		}
+		return fallback, nil
+	}
+}
+
+// TransportOption returns the option of a transport built from httpcommon settings applying the
+// rules.
+func (rs Rules) TransportOption() httpcommon.TransportOption {
+	return httpcommon.WithTransportFunc(rs.Apply)
+}
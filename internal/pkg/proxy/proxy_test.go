@@ -0,0 +1,94 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent/internal/pkg/config"
+)
+
+func TestRuleMatches(t *testing.T) {
+	testcases := map[string]struct {
+		pattern string
+		match   []string
+		noMatch []string
+	}{
+		"any":       {pattern: "*", match: []string{"example.com", "10.0.0.1"}},
+		"wildcard":  {pattern: "*.example.com", match: []string{"a.example.com", "a.b.EXAMPLE.com"}, noMatch: []string{"example.com", "badexample.com"}},
+		"domain":    {pattern: ".example.com", match: []string{"example.com", "a.example.com."}, noMatch: []string{"badexample.com"}},
+		"host":      {pattern: "example.com", match: []string{"example.com"}, noMatch: []string{"a.example.com"}},
+		"network":   {pattern: "10.0.0.0/8", match: []string{"10.1.2.3"}, noMatch: []string{"11.1.2.3", "example.com"}},
+		"ipv6 host": {pattern: "::1", match: []string{"::1"}, noMatch: []string{"::2"}},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			r := Rule{Hosts: []string{tc.pattern}, Direct: true}
+			require.NoError(t, r.Validate())
+			for _, host := range tc.match {
+				assert.True(t, r.Matches(host), host)
+			}
+			for _, host := range tc.noMatch {
+				assert.False(t, r.Matches(host), host)
+			}
+		})
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	for name, rule := range map[string]map[string]interface{}{
+		"no hosts":         {"direct": true},
+		"url and direct":   {"hosts": []string{"*"}, "direct": true, "url": "http://proxy:3128"},
+		"neither":          {"hosts": []string{"*"}},
+		"invalid scheme":   {"hosts": []string{"*"}, "url": "ftp://proxy:21"},
+		"invalid network":  {"hosts": []string{"10.0.0.0/33"}, "direct": true},
+		"inner wildcard":   {"hosts": []string{"a.*.com"}, "direct": true},
+		"pattern with url": {"hosts": []string{"https://example.com"}, "direct": true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c, err := config.NewConfigFrom(map[string]interface{}{"rules": []interface{}{rule}})
+			require.NoError(t, err)
+			assert.Error(t, c.Unpack(DefaultConfig()))
+		})
+	}
+}
+
+func TestRulesApply(t *testing.T) {
+	fallback, _ := url.Parse("http://fallback:3128")
+	rules := Rules{
+		{Hosts: []string{".internal"}, Direct: true},
+		{Hosts: []string{"*.elastic.co"}, URL: "http://proxy:3128", Headers: map[string]string{"Proxy-Authorization": "token"}},
+	}
+
+	transport := &http.Transport{
+		Proxy:              http.ProxyURL(fallback),
+		ProxyConnectHeader: http.Header{"X-Fallback": []string{"1"}},
+	}
+	rules.Apply(transport)
+
+	proxyOf := func(rawURL string) *url.URL {
+		req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+		require.NoError(t, err)
+		u, err := transport.Proxy(req)
+		require.NoError(t, err)
+		return u
+	}
+	assert.Nil(t, proxyOf("https://fleet.internal:8220/api/status"))
+	assert.Equal(t, "http://proxy:3128", proxyOf("https://artifacts.elastic.co/downloads/").String())
+	assert.Equal(t, fallback, proxyOf("https://example.com"))
+
+	headers, err := transport.GetProxyConnectHeader(context.Background(), nil, "artifacts.elastic.co:443")
+	require.NoError(t, err)
+	assert.Equal(t, "token", headers.Get("Proxy-Authorization"))
+	headers, err = transport.GetProxyConnectHeader(context.Background(), nil, "example.com:443")
+	require.NoError(t, err)
+	assert.Equal(t, "1", headers.Get("X-Fallback"))
+}
@@ -6,6 +6,7 @@ package queue
 
 import (
 	"container/heap"
+	"sync"
 	"time"
 
 	"github.com/elastic/elastic-agent/internal/pkg/fleetapi"
@@ -129,3 +130,43 @@ func (q *ActionQueue) Actions() []fleetapi.Action {
 	}
 	return actions
 }
+
+// SyncActionQueue wraps an ActionQueue so it can be shared between goroutines,
+// e.g. between the fleet gateway and the control server.
+type SyncActionQueue struct {
+	mx sync.Mutex
+	q  *ActionQueue
+}
+
+// NewSyncActionQueue creates a new SyncActionQueue wrapping q.
+func NewSyncActionQueue(q *ActionQueue) *SyncActionQueue {
+	return &SyncActionQueue{q: q}
+}
+
+// Add will add an action to the queue with the associated priority.
+func (s *SyncActionQueue) Add(action fleetapi.Action, priority int64) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.q.Add(action, priority)
+}
+
+// DequeueActions will dequeue all actions that have a priority less then time.Now().
+func (s *SyncActionQueue) DequeueActions() []fleetapi.Action {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return s.q.DequeueActions()
+}
+
+// Cancel will remove any actions in the queue with a matching actionID and return the number of entries cancelled.
+func (s *SyncActionQueue) Cancel(actionID string) int {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return s.q.Cancel(actionID)
+}
+
+// Actions returns all actions in the queue.
+func (s *SyncActionQueue) Actions() []fleetapi.Action {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return s.q.Actions()
+}
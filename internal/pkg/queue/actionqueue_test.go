@@ -8,6 +8,8 @@ package queue
 import (
 	"container/heap"
 	"errors"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -483,3 +485,27 @@ func Test_ActionQueue_Actions(t *testing.T) {
 		assert.Equal(t, "test-1", actions[0].ID())
 	})
 }
+
+func Test_SyncActionQueue(t *testing.T) {
+	q, err := NewActionQueue(nil)
+	require.NoError(t, err)
+	sq := NewSyncActionQueue(q)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			a := &mockAction{}
+			a.On("ID").Return(fmt.Sprintf("test-%d", i))
+			sq.Add(a, int64(i))
+			_ = sq.Actions()
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Len(t, sq.Actions(), 10)
+	assert.Equal(t, 1, sq.Cancel("test-3"))
+	assert.Len(t, sq.Actions(), 9)
+	assert.Zero(t, sq.Cancel("test-3"))
+}
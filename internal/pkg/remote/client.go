@@ -37,6 +37,8 @@ type requestClient struct {
 	lastUsed   time.Time
 	lastErr    error
 	lastErrOcc time.Time
+	preferred  bool
+	health     hostHealth
 }
 
 // Client wraps a http.Client and takes care of making the raw calls, the client should
@@ -44,10 +46,15 @@ type requestClient struct {
 // to the client. For authenticated calls or sending fields on every request, create a custom RoundTripper
 // implementation that will take care of the boilerplate.
 type Client struct {
-	log        *logger.Logger
+	log *logger.Logger
+	// clientLock guards the clients, their health and rand.
 	clientLock sync.Mutex
 	clients    []*requestClient
 	config     Config
+	selection  HostSelectionConfig
+	// clock and rand are replaced by the tests, the package defaults are used when nil.
+	clock func() time.Time
+	rand  *rand.Rand
 }
 
 // NewConfigFromURL returns a Config based on a received host.
@@ -112,6 +119,7 @@ func NewWithConfig(log *logger.Logger, cfg Config, wrapper wrapperFunc) (*Client
 		transport, err := cfg.Transport.RoundTripper(
 			httpcommon.WithAPMHTTPInstrumentation(),
 			httpcommon.WithForceAttemptHTTP2(true),
+			cfg.ProxyRules.TransportOption(),
 		)
 		if err != nil {
 			return nil, err
@@ -130,8 +138,9 @@ func NewWithConfig(log *logger.Logger, cfg Config, wrapper wrapperFunc) (*Client
 		}
 
 		clients[i] = &requestClient{
-			host:   baseURL,
-			client: httpClient,
+			host:      baseURL,
+			client:    httpClient,
+			preferred: cfg.Selection.Preferred != "" && host == cfg.Selection.Preferred,
 		}
 	}
 
@@ -166,8 +175,7 @@ func (c *Client) Send(
 	var resp *http.Response
 	var multiErr error
 
-	c.sortClients()
-	for i, requester := range c.clients {
+	for i, requester := range c.orderClients() {
 		req, err := requester.newRequest(method, path, params, body)
 		if err != nil {
 			return nil, fmt.Errorf(
@@ -194,12 +202,17 @@ func (c *Client) Send(
 			}
 		}
 
-		requester.lastUsed = time.Now().UTC()
+		requester.lastUsed = c.now()
 
+		start := c.now()
 		resp, err = requester.client.Do(req.WithContext(ctx))
 		if err != nil {
 			requester.lastErr = err
-			requester.lastErrOcc = time.Now().UTC()
+			requester.lastErrOcc = c.now()
+			// a canceled request says nothing about the health of the host
+			if ctx.Err() == nil {
+				c.record(requester, true, 0)
+			}
 
 			msg := fmt.Sprintf("requester %d/%d to host %s errored",
 				i, len(c.clients), requester.host)
@@ -212,6 +225,12 @@ func (c *Client) Send(
 
 		requester.lastErr = nil
 		requester.lastErrOcc = time.Time{}
+		// the response is returned to the caller, a server error only counts against the host
+		var latency time.Duration
+		if !isLongPoll(ctx) {
+			latency = c.now().Sub(start)
+		}
+		c.record(requester, resp.StatusCode >= http.StatusInternalServerError, latency)
 		return resp, nil
 	}
 
@@ -235,10 +254,13 @@ func newClient(
 		clients[i], clients[j] = clients[j], clients[i]
 	})
 
+	selection := cfg.Selection
+	selection.CircuitBreaker = selection.CircuitBreaker.withDefaults()
 	c := &Client{
-		log:     log,
-		clients: clients,
-		config:  cfg,
+		log:       log,
+		clients:   clients,
+		config:    cfg,
+		selection: selection,
 	}
 	return c, nil
 }
@@ -249,7 +271,7 @@ func newClient(
 //  - last errored.
 // It also removes the last error after retryOnBadConnTimeout has elapsed.
 func (c *Client) sortClients() {
-	now := time.Now().UTC()
+	now := c.now()
 
 	sort.Slice(c.clients, func(i, j int) bool {
 		// First, set them good if the timout has elapsed
@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"github.com/elastic/elastic-agent-libs/transport/httpcommon"
+
+	"github.com/elastic/elastic-agent/internal/pkg/proxy"
 )
 
 // Config is the configuration for the client.
@@ -19,6 +21,12 @@ type Config struct {
 	Host     string   `config:"host" yaml:"host,omitempty"`
 	Hosts    []string `config:"hosts" yaml:"hosts,omitempty"`
 
+	Selection HostSelectionConfig `config:"selection" yaml:"selection,omitempty"`
+	// Recorder is never persisted, it is only enabled by the local configuration.
+	Recorder RecorderConfig `config:"recorder" yaml:"-"`
+	// ProxyRules are set from the proxy rules of the agent, `agent.proxy.rules`.
+	ProxyRules proxy.Rules `config:",ignore" yaml:"-"`
+
 	Transport httpcommon.HTTPTransportSettings `config:",inline" yaml:",inline"`
 }
 
@@ -57,6 +65,66 @@ func DefaultClientConfig() Config {
 	}
 }
 
+// SelectionStrategy defines how the client orders the hosts of a request.
+type SelectionStrategy string
+
+const (
+	// SelectionOrdered tries the hosts not used yet first, then the least recently used and the
+	// ones which errored last.
+	SelectionOrdered SelectionStrategy = "ordered"
+	// SelectionLatency picks the hosts at random, weighted by the inverse of their latency.
+	SelectionLatency SelectionStrategy = "latency"
+)
+
+// Unpack the selection strategy.
+func (s *SelectionStrategy) Unpack(from string) error {
+	if SelectionStrategy(from) != SelectionOrdered && SelectionStrategy(from) != SelectionLatency {
+		return fmt.Errorf("invalid selection strategy %s, accepted values are 'ordered' and 'latency'", from)
+	}
+
+	*s = SelectionStrategy(from)
+	return nil
+}
+
+// HostSelectionConfig configures how the client picks the host of a request when it has many.
+type HostSelectionConfig struct {
+	// Strategy orders the hosts, defaults to SelectionOrdered.
+	Strategy SelectionStrategy `config:"strategy" yaml:"strategy,omitempty"`
+	// Preferred is a host tried first while its circuit is not open, e.g. a Fleet Server running
+	// on the same network.
+	Preferred string `config:"preferred" yaml:"preferred,omitempty"`
+
+	CircuitBreaker CircuitBreakerConfig `config:"circuit_breaker" yaml:"circuit_breaker,omitempty"`
+}
+
+// CircuitBreakerConfig configures the circuit breaker of every host. The circuit of a host opens
+// after FailureThreshold consecutive failures, the host is then only tried when all the other
+// hosts failed. Once OpenTimeout elapsed a single request probes the host, its circuit closes when
+// the probe succeeds, otherwise it opens again for twice as long up to MaxOpenTimeout.
+type CircuitBreakerConfig struct {
+	Enabled          bool          `config:"enabled" yaml:"enabled,omitempty"`
+	FailureThreshold int           `config:"failure_threshold" yaml:"failure_threshold,omitempty" validate:"min=0"`
+	OpenTimeout      time.Duration `config:"open_timeout" yaml:"open_timeout,omitempty" validate:"min=0"`
+	MaxOpenTimeout   time.Duration `config:"max_open_timeout" yaml:"max_open_timeout,omitempty" validate:"min=0"`
+}
+
+// withDefaults returns the configuration with the default values of the unset settings.
+func (c CircuitBreakerConfig) withDefaults() CircuitBreakerConfig {
+	if c.FailureThreshold <= 0 {
+		c.FailureThreshold = 3
+	}
+	if c.OpenTimeout <= 0 {
+		c.OpenTimeout = 30 * time.Second
+	}
+	if c.MaxOpenTimeout <= 0 {
+		c.MaxOpenTimeout = retryOnBadConnTimeout
+	}
+	if c.MaxOpenTimeout < c.OpenTimeout {
+		c.MaxOpenTimeout = c.OpenTimeout
+	}
+	return c
+}
+
 // GetHosts returns the hosts to connect.
 //
 // This looks first at `Hosts` and then at `Host` when `Hosts` is not defined.
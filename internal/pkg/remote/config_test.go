@@ -15,6 +15,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/elastic/elastic-agent-libs/transport/httpcommon"
+
+	"github.com/elastic/elastic-agent/internal/pkg/config"
 )
 
 func TestPackUnpack(t *testing.T) {
@@ -37,3 +39,31 @@ func TestPackUnpack(t *testing.T) {
 
 	assert.True(t, reflect.DeepEqual(c, c2))
 }
+
+func TestUnpackSelection(t *testing.T) {
+	cfg, err := config.NewConfigFrom(`
+hosts: [fleet-1:8220, fleet-2:8220]
+selection:
+  strategy: latency
+  preferred: fleet-2:8220
+  circuit_breaker:
+    enabled: true
+    open_timeout: 1m
+`)
+	require.NoError(t, err)
+
+	c := DefaultClientConfig()
+	require.NoError(t, cfg.Unpack(&c))
+	assert.Equal(t, SelectionLatency, c.Selection.Strategy)
+	assert.Equal(t, "fleet-2:8220", c.Selection.Preferred)
+
+	breaker := c.Selection.CircuitBreaker.withDefaults()
+	assert.True(t, breaker.Enabled)
+	assert.Equal(t, 3, breaker.FailureThreshold)
+	assert.Equal(t, time.Minute, breaker.OpenTimeout)
+	assert.Equal(t, retryOnBadConnTimeout, breaker.MaxOpenTimeout)
+
+	cfg, err = config.NewConfigFrom("selection.strategy: fastest")
+	require.NoError(t, err)
+	assert.Error(t, cfg.Unpack(&c))
+}
@@ -0,0 +1,243 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package remote
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// latencyWeight is the weight of a new sample in the moving average of the latency of a host.
+const latencyWeight = 0.3
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+func (s circuitState) String() string {
+	switch s {
+	case circuitOpen:
+		return "open"
+	case circuitHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+type longPollKey struct{}
+
+// WithLongPoll marks the requests sent with the context as long polls. The server holds them open
+// until it has something to answer, their duration is not used as the latency of the host.
+func WithLongPoll(ctx context.Context) context.Context {
+	return context.WithValue(ctx, longPollKey{}, true)
+}
+
+func isLongPoll(ctx context.Context) bool {
+	v, _ := ctx.Value(longPollKey{}).(bool)
+	return v
+}
+
+// hostHealth keeps track of the latency and of the circuit breaker of a host.
+type hostHealth struct {
+	state       circuitState
+	failures    int
+	openedAt    time.Time
+	openTimeout time.Duration
+	// latency is the moving average of the latency of the host, zero until it is measured.
+	latency time.Duration
+}
+
+// available returns true when the host can be tried before the hosts with an open circuit, an
+// open circuit becomes half-open once its timeout elapsed so the next request probes the host.
+func (h *hostHealth) available(now time.Time) bool {
+	if h.state == circuitOpen && !now.Before(h.probeAt()) {
+		h.state = circuitHalfOpen
+	}
+	return h.state != circuitOpen
+}
+
+// probeAt returns when an open circuit becomes half-open.
+func (h *hostHealth) probeAt() time.Time {
+	return h.openedAt.Add(h.openTimeout)
+}
+
+// success closes the circuit, latency is zero when the request was not a measure of the latency.
+func (h *hostHealth) success(latency time.Duration) {
+	h.state = circuitClosed
+	h.failures = 0
+	h.openTimeout = 0
+	if latency <= 0 {
+		return
+	}
+	if h.latency == 0 {
+		h.latency = latency
+		return
+	}
+	h.latency = time.Duration(latencyWeight*float64(latency) + (1-latencyWeight)*float64(h.latency))
+}
+
+// failure opens the circuit once the threshold of consecutive failures is reached, a failed
+// probe opens it again for twice as long.
+func (h *hostHealth) failure(now time.Time, cfg CircuitBreakerConfig) {
+	h.failures++
+	if !cfg.Enabled {
+		return
+	}
+	switch {
+	case h.state == circuitHalfOpen:
+		h.open(now, minDuration(2*h.openTimeout, cfg.MaxOpenTimeout))
+	case h.state == circuitClosed && h.failures >= cfg.FailureThreshold:
+		h.open(now, cfg.OpenTimeout)
+	}
+}
+
+func (h *hostHealth) open(now time.Time, timeout time.Duration) {
+	h.state = circuitOpen
+	h.openedAt = now
+	h.openTimeout = timeout
+}
+
+// orderClients returns the clients in the order they are tried by a request:
+//   - the preferred host, unless it errored recently or its circuit is open
+//   - the hosts ordered by the selection strategy
+//   - the hosts with an open circuit, they are only tried when all the others failed.
+//
+// It is called with clientLock held.
+func (c *Client) orderClients() []*requestClient {
+	now := c.now()
+
+	var ordered []*requestClient
+	if c.selection.Strategy == SelectionLatency {
+		ordered = c.latencyOrder(now)
+	} else {
+		c.sortClients()
+		ordered = append(ordered, c.clients...)
+	}
+
+	for i, requester := range ordered {
+		if requester.preferred && !requester.errored(now) {
+			copy(ordered[1:i+1], ordered[:i])
+			ordered[0] = requester
+			break
+		}
+	}
+
+	if !c.selection.CircuitBreaker.Enabled {
+		return ordered
+	}
+	available := make([]*requestClient, 0, len(ordered))
+	var open []*requestClient
+	for _, requester := range ordered {
+		if requester.health.available(now) {
+			available = append(available, requester)
+		} else {
+			open = append(open, requester)
+		}
+	}
+	sort.SliceStable(open, func(i, j int) bool {
+		return open[i].health.probeAt().Before(open[j].health.probeAt())
+	})
+	return append(available, open...)
+}
+
+// latencyOrder orders the hosts not measured yet first so all the hosts get a latency, then the
+// hosts picked at random weighted by the inverse of their latency and lastly the ones which
+// errored, the one which errored first before the others.
+func (c *Client) latencyOrder(now time.Time) []*requestClient {
+	var unmeasured, measured, errored []*requestClient
+	for _, requester := range c.clients {
+		switch {
+		case requester.errored(now):
+			errored = append(errored, requester)
+		case requester.health.latency == 0:
+			unmeasured = append(unmeasured, requester)
+		default:
+			measured = append(measured, requester)
+		}
+	}
+
+	ordered := append(unmeasured, c.weightedShuffle(measured)...)
+	sort.SliceStable(errored, func(i, j int) bool {
+		return errored[i].lastErrOcc.Before(errored[j].lastErrOcc)
+	})
+	return append(ordered, errored...)
+}
+
+// weightedShuffle orders the clients at random, a client is picked with a probability
+// proportional to the inverse of its latency.
+func (c *Client) weightedShuffle(clients []*requestClient) []*requestClient {
+	remaining := append([]*requestClient(nil), clients...)
+	ordered := make([]*requestClient, 0, len(clients))
+	for len(remaining) > 0 {
+		var total float64
+		for _, requester := range remaining {
+			total += 1 / float64(requester.health.latency)
+		}
+
+		pick := c.float64() * total
+		i := 0
+		for ; i < len(remaining)-1; i++ {
+			pick -= 1 / float64(remaining[i].health.latency)
+			if pick < 0 {
+				break
+			}
+		}
+		ordered = append(ordered, remaining[i])
+		remaining = append(remaining[:i], remaining[i+1:]...)
+	}
+	return ordered
+}
+
+// record updates the health and the metrics of a host with the result of a request, latency is
+// zero when the request was not a measure of the latency of the host. It is called with
+// clientLock held, the metrics get a copy of the health.
+func (c *Client) record(requester *requestClient, failed bool, latency time.Duration) {
+	if failed {
+		requester.health.failure(c.now(), c.selection.CircuitBreaker)
+	} else {
+		requester.health.success(latency)
+	}
+	hostsMetrics.record(requester.host, failed, requester.health)
+}
+
+func (c *Client) now() time.Time {
+	if c.clock != nil {
+		return c.clock()
+	}
+	return time.Now().UTC()
+}
+
+// float64 returns a random number in [0.0,1.0), it is called with clientLock held as rand.Rand
+// is not safe for concurrent use.
+func (c *Client) float64() float64 {
+	if c.rand != nil {
+		return c.rand.Float64()
+	}
+	return rand.Float64() //nolint:gosec // only used to spread the requests over the hosts
+}
+
+// errored returns true when the last request to the host failed less than retryOnBadConnTimeout ago,
+// an older error is forgotten.
+func (r *requestClient) errored(now time.Time) bool {
+	if r.lastErr != nil && now.Sub(r.lastErrOcc) > retryOnBadConnTimeout {
+		r.lastErr = nil
+		r.lastErrOcc = time.Time{}
+	}
+	return r.lastErr != nil
+}
+
+func minDuration(a, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+	return b
+}
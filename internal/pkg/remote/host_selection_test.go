@@ -0,0 +1,344 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package remote
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent-libs/monitoring"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// fakeRoundTripper answers with the status or fails with the error, taking latency on the clock.
+type fakeRoundTripper struct {
+	clock   *fakeClock
+	latency time.Duration
+	status  int
+	err     error
+	calls   int
+}
+
+func (rt *fakeRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.calls++
+	rt.clock.Advance(rt.latency)
+	if rt.err != nil {
+		return nil, rt.err
+	}
+	return &http.Response{
+		StatusCode: rt.status,
+		Body:       ioutil.NopCloser(strings.NewReader("")),
+		Request:    req,
+	}, nil
+}
+
+func newFakeClient(t *testing.T, clock *fakeClock, selection HostSelectionConfig, hosts map[string]*fakeRoundTripper, order ...string) *Client {
+	t.Helper()
+	l, err := logger.New("", false)
+	require.NoError(t, err)
+
+	clients := make([]*requestClient, 0, len(order))
+	for _, host := range order {
+		clients = append(clients, &requestClient{
+			host:      host,
+			client:    http.Client{Transport: hosts[host]},
+			preferred: host == selection.Preferred,
+		})
+	}
+	selection.CircuitBreaker = selection.CircuitBreaker.withDefaults()
+	return &Client{
+		log:       l,
+		clients:   clients,
+		selection: selection,
+		clock:     clock.Now,
+		rand:      rand.New(rand.NewSource(1)), //nolint:gosec // rand is ok for test
+	}
+}
+
+// resetHostMetrics removes the metrics of the hosts recorded by a previous run of the test.
+func resetHostMetrics(hosts ...string) {
+	hostsMetrics.mx.Lock()
+	defer hostsMetrics.mx.Unlock()
+	for _, host := range hosts {
+		delete(hostsMetrics.hosts, host)
+	}
+}
+
+func hostsOf(clients []*requestClient) []string {
+	hosts := make([]string, 0, len(clients))
+	for _, c := range clients {
+		hosts = append(hosts, c.host)
+	}
+	return hosts
+}
+
+func TestHostHealthCircuitBreaker(t *testing.T) {
+	cfg := CircuitBreakerConfig{
+		Enabled:          true,
+		FailureThreshold: 2,
+		OpenTimeout:      10 * time.Second,
+		MaxOpenTimeout:   30 * time.Second,
+	}
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := &hostHealth{}
+
+	h.failure(now, cfg)
+	assert.Equal(t, circuitClosed, h.state)
+	h.failure(now, cfg)
+	require.Equal(t, circuitOpen, h.state)
+	assert.False(t, h.available(now.Add(9*time.Second)))
+
+	// a failed probe opens the circuit for twice as long, up to the maximum
+	require.True(t, h.available(now.Add(10*time.Second)))
+	assert.Equal(t, circuitHalfOpen, h.state)
+	now = now.Add(10 * time.Second)
+	h.failure(now, cfg)
+	assert.Equal(t, circuitOpen, h.state)
+	assert.Equal(t, 20*time.Second, h.openTimeout)
+
+	now = now.Add(20 * time.Second)
+	require.True(t, h.available(now))
+	h.failure(now, cfg)
+	assert.Equal(t, 30*time.Second, h.openTimeout)
+
+	// a successful probe closes the circuit
+	now = now.Add(30 * time.Second)
+	require.True(t, h.available(now))
+	h.success(0)
+	assert.Equal(t, circuitClosed, h.state)
+	h.failure(now, cfg)
+	assert.Equal(t, circuitClosed, h.state, "consecutive failures must be reset by a success")
+
+	t.Run("disabled", func(t *testing.T) {
+		h := &hostHealth{}
+		for i := 0; i < 10; i++ {
+			h.failure(now, CircuitBreakerConfig{FailureThreshold: 1})
+		}
+		assert.Equal(t, circuitClosed, h.state)
+	})
+}
+
+func TestHostHealthLatency(t *testing.T) {
+	h := &hostHealth{}
+	h.success(100 * time.Millisecond)
+	assert.Equal(t, 100*time.Millisecond, h.latency)
+	h.success(0)
+	assert.Equal(t, 100*time.Millisecond, h.latency, "unmeasured requests must not change the latency")
+	h.success(200 * time.Millisecond)
+	assert.Equal(t, 130*time.Millisecond, h.latency)
+}
+
+func TestOrderClients(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)}
+	used := clock.now.Add(-time.Minute)
+
+	t.Run("open circuits last", func(t *testing.T) {
+		c := newFakeClient(t, clock, HostSelectionConfig{CircuitBreaker: CircuitBreakerConfig{Enabled: true}}, nil, "a", "b", "c")
+		c.clients[0].health.open(clock.now, time.Minute)
+		c.clients[2].health.open(clock.now.Add(-30*time.Second), time.Minute)
+		for _, r := range c.clients {
+			r.lastUsed = used
+		}
+
+		assert.Equal(t, []string{"b", "c", "a"}, hostsOf(c.orderClients()))
+	})
+
+	t.Run("half-open circuits are probed", func(t *testing.T) {
+		c := newFakeClient(t, clock, HostSelectionConfig{CircuitBreaker: CircuitBreakerConfig{Enabled: true}}, nil, "a", "b")
+		c.clients[0].health.open(clock.now.Add(-time.Minute), time.Minute)
+		c.clients[0].lastUsed = used.Add(-time.Minute)
+		c.clients[1].lastUsed = used
+
+		assert.Equal(t, []string{"a", "b"}, hostsOf(c.orderClients()))
+		assert.Equal(t, circuitHalfOpen, c.clients[0].health.state)
+	})
+
+	t.Run("preferred host first", func(t *testing.T) {
+		selection := HostSelectionConfig{Preferred: "c", CircuitBreaker: CircuitBreakerConfig{Enabled: true}}
+		c := newFakeClient(t, clock, selection, nil, "a", "b", "c")
+		for _, r := range c.clients {
+			r.lastUsed = used
+		}
+		assert.Equal(t, "c", c.orderClients()[0].host)
+
+		c.clients[2].lastErr = errors.New("fake error")
+		c.clients[2].lastErrOcc = clock.now
+		assert.NotEqual(t, "c", c.orderClients()[0].host)
+
+		c.clients[2].lastErr = nil
+		c.clients[2].health.open(clock.now, time.Minute)
+		assert.NotEqual(t, "c", c.orderClients()[0].host)
+	})
+
+	t.Run("latency", func(t *testing.T) {
+		c := newFakeClient(t, clock, HostSelectionConfig{Strategy: SelectionLatency}, nil, "fast", "slow", "errored", "new")
+		c.clients[0].health.latency = 10 * time.Millisecond
+		c.clients[1].health.latency = 90 * time.Millisecond
+		c.clients[2].health.latency = time.Millisecond
+		c.clients[2].lastErr = errors.New("fake error")
+		c.clients[2].lastErrOcc = clock.now
+
+		fastFirst := 0
+		for i := 0; i < 1000; i++ {
+			order := hostsOf(c.orderClients())
+			require.Len(t, order, 4)
+			assert.Equal(t, "new", order[0])
+			assert.Equal(t, "errored", order[3])
+			if order[1] == "fast" {
+				fastFirst++
+			}
+		}
+		// the fast host is picked 9 times out of 10
+		assert.InDelta(t, 900, fastFirst, 50)
+	})
+}
+
+func TestSendHostHealth(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)}
+	hosts := map[string]*fakeRoundTripper{
+		"http://down.health.test/":    {clock: clock, err: errors.New("connection refused")},
+		"http://failing.health.test/": {clock: clock, status: http.StatusServiceUnavailable},
+		"http://up.health.test/":      {clock: clock, status: http.StatusOK, latency: 20 * time.Millisecond},
+	}
+	selection := HostSelectionConfig{
+		CircuitBreaker: CircuitBreakerConfig{Enabled: true, FailureThreshold: 1, OpenTimeout: time.Minute},
+	}
+	order := []string{"http://down.health.test/", "http://failing.health.test/", "http://up.health.test/"}
+	resetHostMetrics(order...)
+	c := newFakeClient(t, clock, selection, hosts, order...)
+	ctx := context.Background()
+
+	// the server error is returned to the caller but opens the circuit of the host
+	resp, err := c.Send(ctx, http.MethodGet, "/", nil, nil, nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, circuitOpen, c.clients[0].health.state)
+	assert.Equal(t, circuitOpen, c.clients[1].health.state)
+
+	resp, err = c.Send(ctx, http.MethodGet, "/", nil, nil, nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 1, hosts["http://down.health.test/"].calls)
+	assert.Equal(t, 1, hosts["http://failing.health.test/"].calls)
+
+	// long polls are not a measure of the latency
+	hosts["http://up.health.test/"].latency = 5 * time.Minute
+	resp, err = c.Send(WithLongPoll(ctx), http.MethodGet, "/", nil, nil, nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, 2, hosts["http://up.health.test/"].calls)
+
+	up := hostsMetrics.get("http://up.health.test/")
+	assert.Equal(t, int64(2), up.success)
+	assert.Equal(t, 20*time.Millisecond, up.latency)
+	assert.Equal(t, circuitClosed, up.circuitState)
+	down := hostsMetrics.get("http://down.health.test/")
+	assert.Equal(t, int64(1), down.errors)
+	assert.Equal(t, int64(1), down.circuitOpened)
+	assert.Equal(t, circuitOpen, down.circuitState)
+}
+
+func TestSendCanceledRequest(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)}
+	host := "http://canceled.health.test/"
+	hosts := map[string]*fakeRoundTripper{
+		host: {clock: clock, err: context.Canceled},
+	}
+	selection := HostSelectionConfig{
+		CircuitBreaker: CircuitBreakerConfig{Enabled: true, FailureThreshold: 1},
+	}
+	resetHostMetrics(host)
+	c := newFakeClient(t, clock, selection, hosts, host)
+
+	// a canceled request does not count against the host
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := c.Send(ctx, http.MethodGet, "/", nil, nil, nil)
+	require.Error(t, err)
+	assert.Equal(t, circuitClosed, c.clients[0].health.state)
+	assert.Equal(t, int64(0), hostsMetrics.get(host).errors)
+}
+
+// okRoundTripper answers every request with a 200, it is safe for concurrent use.
+type okRoundTripper struct{}
+
+func (okRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	time.Sleep(time.Millisecond)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(strings.NewReader("")),
+		Request:    req,
+	}, nil
+}
+
+func TestSendConcurrentWithLatencySelection(t *testing.T) {
+	order := []string{"http://a.concurrent.test/", "http://b.concurrent.test/", "http://c.concurrent.test/"}
+	resetHostMetrics(order...)
+	l, err := logger.New("", false)
+	require.NoError(t, err)
+	clients := make([]*requestClient, 0, len(order))
+	for _, host := range order {
+		clients = append(clients, &requestClient{host: host, client: http.Client{Transport: okRoundTripper{}}})
+	}
+	c := &Client{
+		log:     l,
+		clients: clients,
+		selection: HostSelectionConfig{
+			Strategy:       SelectionLatency,
+			CircuitBreaker: CircuitBreakerConfig{Enabled: true}.withDefaults(),
+		},
+		rand: rand.New(rand.NewSource(1)), //nolint:gosec // rand is ok for test
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				resp, err := c.Send(context.Background(), http.MethodGet, "/", nil, nil, nil)
+				if assert.NoError(t, err) {
+					resp.Body.Close()
+				}
+				// the metrics are served while the requests are sent
+				monitoring.CollectFlatSnapshot(monitoring.GetNamespace("stats").GetRegistry(), monitoring.Full, false)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var success int64
+	for _, host := range order {
+		success += hostsMetrics.get(host).success
+	}
+	assert.Equal(t, int64(80), success)
+
+	snapshot := monitoring.CollectFlatSnapshot(monitoring.GetNamespace("stats").GetRegistry(), monitoring.Full, false)
+	assert.Contains(t, snapshot.Ints, "remote.hosts.http://a.concurrent.test/.success")
+}
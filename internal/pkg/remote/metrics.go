@@ -0,0 +1,92 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package remote
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/elastic/elastic-agent-libs/monitoring"
+)
+
+// Host metrics exposed under remote.hosts by the /stats monitoring endpoint, keyed by the URL of the host.
+var (
+	remoteRegistry = monitoring.GetNamespace("stats").GetRegistry().NewRegistry("remote")
+
+	hostsMetrics = &hostMetricsSet{hosts: map[string]*hostMetrics{}}
+	_            = monitoring.NewFunc(remoteRegistry, "hosts", hostsMetrics.report, monitoring.Report)
+)
+
+// hostMetrics are the metrics of a host, shared by all the clients sending requests to the host.
+type hostMetrics struct {
+	success       int64
+	errors        int64
+	latency       time.Duration
+	circuitState  circuitState
+	circuitOpened int64
+}
+
+type hostMetricsSet struct {
+	mx    sync.Mutex
+	hosts map[string]*hostMetrics
+}
+
+// record updates the metrics of a host with the result of a request and the health of the host
+// after the request.
+func (s *hostMetricsSet) record(host string, failed bool, h hostHealth) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	m, ok := s.hosts[host]
+	if !ok {
+		m = &hostMetrics{}
+		s.hosts[host] = m
+	}
+	if failed {
+		m.errors++
+	} else {
+		m.success++
+	}
+	if h.state == circuitOpen && m.circuitState != circuitOpen {
+		m.circuitOpened++
+	}
+	m.latency = h.latency
+	m.circuitState = h.state
+}
+
+// get returns a copy of the metrics of a host.
+func (s *hostMetricsSet) get(host string) hostMetrics {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	if m, ok := s.hosts[host]; ok {
+		return *m
+	}
+	return hostMetrics{}
+}
+
+func (s *hostMetricsSet) report(_ monitoring.Mode, V monitoring.Visitor) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	hosts := make([]string, 0, len(s.hosts))
+	for host := range s.hosts {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	V.OnRegistryStart()
+	defer V.OnRegistryFinished()
+	for _, host := range hosts {
+		m := s.hosts[host]
+		monitoring.ReportNamespace(V, host, func() {
+			monitoring.ReportInt(V, "success", m.success)
+			monitoring.ReportInt(V, "errors", m.errors)
+			monitoring.ReportInt(V, "latency_ms", m.latency.Milliseconds())
+			monitoring.ReportString(V, "circuit_state", m.circuitState.String())
+			monitoring.ReportInt(V, "circuit_opened", m.circuitOpened)
+		})
+	}
+}
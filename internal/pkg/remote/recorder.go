@@ -0,0 +1,326 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package remote
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elastic/elastic-agent-libs/file"
+)
+
+const (
+	// Redacted replaces the secrets in the recorded requests and responses.
+	Redacted = "[REDACTED]"
+
+	defaultRecorderMaxSize     = 10 * 1024 * 1024
+	defaultRecorderMaxFiles    = 3
+	defaultRecorderMaxBodySize = 1024 * 1024
+)
+
+// redactedHeaders are the headers holding credentials.
+var redactedHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+	"Kbn-Fleet-Token":     true,
+}
+
+// redactedKeys are the keys of the JSON bodies holding credentials, e.g. the API key returned by
+// the enrollment or the credentials of the outputs in a policy.
+var redactedKeys = map[string]bool{
+	"access_api_key":   true,
+	"api_key":          true,
+	"enrollment_token": true,
+	"key":              true,
+	"passphrase":       true,
+	"password":         true,
+	"secret":           true,
+	"service_token":    true,
+	"token":            true,
+}
+
+// RecorderConfig configures the recording of the requests and the responses of a client, the
+// recorded entries are redacted so the recording can be shared to diagnose an issue.
+type RecorderConfig struct {
+	Enabled bool `config:"enabled"`
+	// Path is the prefix of the recording files, the date and the .ndjson extension are appended.
+	Path string `config:"path"`
+	// MaxSize is the size of a recording file in bytes before it is rotated.
+	MaxSize uint `config:"max_size"`
+	// MaxFiles is the number of rotated recording files kept.
+	MaxFiles uint `config:"max_files"`
+	// MaxBodySize truncates the recorded bodies.
+	MaxBodySize int `config:"max_body_size"`
+}
+
+// Entry is a recorded request and its response, modeled on the entries of the HTTP Archive format.
+type Entry struct {
+	StartedDateTime time.Time     `json:"startedDateTime"`
+	Time            float64       `json:"time"`
+	Request         EntryRequest  `json:"request"`
+	Response        EntryResponse `json:"response"`
+	// Error is the error of a request which got no response.
+	Error string `json:"_error,omitempty"`
+}
+
+// EntryRequest is a recorded request.
+type EntryRequest struct {
+	Method   string        `json:"method"`
+	URL      string        `json:"url"`
+	Headers  []EntryHeader `json:"headers"`
+	PostData *EntryContent `json:"postData,omitempty"`
+}
+
+// EntryResponse is a recorded response.
+type EntryResponse struct {
+	Status  int           `json:"status"`
+	Headers []EntryHeader `json:"headers"`
+	Content EntryContent  `json:"content"`
+}
+
+// EntryHeader is a recorded header.
+type EntryHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// EntryContent is a recorded body.
+type EntryContent struct {
+	MimeType  string `json:"mimeType"`
+	Text      string `json:"text"`
+	Truncated bool   `json:"_truncated,omitempty"`
+}
+
+// Recorder writes the requests and the responses of the round trippers it wraps to rotated files,
+// one entry per line. The secrets of the headers and of the JSON bodies are redacted.
+type Recorder struct {
+	mx          sync.Mutex
+	w           io.WriteCloser
+	maxBodySize int
+	now         func() time.Time
+}
+
+// NewRecorder returns a recorder writing to the files configured.
+func NewRecorder(cfg RecorderConfig) (*Recorder, error) {
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("recorder path cannot be empty")
+	}
+	if cfg.MaxSize == 0 {
+		cfg.MaxSize = defaultRecorderMaxSize
+	}
+	if cfg.MaxFiles == 0 {
+		cfg.MaxFiles = defaultRecorderMaxFiles
+	}
+	w, err := file.NewFileRotator(cfg.Path,
+		file.MaxSizeBytes(cfg.MaxSize),
+		file.MaxBackups(cfg.MaxFiles),
+		file.Permissions(0600),
+		file.RotateOnStartup(false),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the recording file %s: %w", cfg.Path, err)
+	}
+	return newRecorder(w, cfg.MaxBodySize), nil
+}
+
+func newRecorder(w io.WriteCloser, maxBodySize int) *Recorder {
+	if maxBodySize <= 0 {
+		maxBodySize = defaultRecorderMaxBodySize
+	}
+	return &Recorder{
+		w:           w,
+		maxBodySize: maxBodySize,
+		now:         time.Now,
+	}
+}
+
+// Wrap returns a round tripper recording the requests sent to rt and its responses.
+func (r *Recorder) Wrap(rt http.RoundTripper) http.RoundTripper {
+	return &recordingRoundTripper{rt: rt, recorder: r}
+}
+
+// Close closes the recording file.
+func (r *Recorder) Close() error {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	return r.w.Close()
+}
+
+func (r *Recorder) write(e *Entry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	_, err = r.w.Write(append(b, '\n'))
+	return err
+}
+
+// content returns the redacted and truncated recording of a body.
+func (r *Recorder) content(header http.Header, body []byte) EntryContent {
+	c := EntryContent{
+		MimeType: header.Get("Content-Type"),
+		Text:     string(redactBody(body)),
+	}
+	if len(c.Text) > r.maxBodySize {
+		c.Text = c.Text[:r.maxBodySize]
+		c.Truncated = true
+	}
+	return c
+}
+
+type recordingRoundTripper struct {
+	rt       http.RoundTripper
+	recorder *Recorder
+}
+
+// RoundTrip sends the request and records it along with its response, a failure to record does
+// not fail the request.
+func (r *recordingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	e := &Entry{
+		StartedDateTime: r.recorder.now().UTC(),
+		Request: EntryRequest{
+			Method:  req.Method,
+			URL:     req.URL.String(),
+			Headers: redactHeaders(req.Header),
+		},
+	}
+
+	if req.Body != nil && req.Body != http.NoBody {
+		data, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, fmt.Errorf("fail to read the body of the request: %w", err)
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(data))
+		content := r.recorder.content(req.Header, data)
+		e.Request.PostData = &content
+	}
+
+	resp, err := r.rt.RoundTrip(req)
+	e.Time = float64(r.recorder.now().Sub(e.StartedDateTime).Microseconds()) / 1000
+	if err != nil {
+		e.Error = err.Error()
+		_ = r.recorder.write(e)
+		return resp, err
+	}
+
+	e.Response = EntryResponse{
+		Status:  resp.StatusCode,
+		Headers: redactHeaders(resp.Header),
+	}
+	if resp.Body != nil {
+		data, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = ioutil.NopCloser(bytes.NewReader(data))
+		if err != nil {
+			e.Error = err.Error()
+			_ = r.recorder.write(e)
+			return nil, fmt.Errorf("fail to read the body of the response: %w", err)
+		}
+		e.Response.Content = r.recorder.content(resp.Header, data)
+	}
+	_ = r.recorder.write(e)
+	return resp, nil
+}
+
+// ReadEntries reads the entries of a recording.
+func ReadEntries(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 64*1024*1024)
+	for line := 1; s.Scan(); line++ {
+		if len(bytes.TrimSpace(s.Bytes())) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(s.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("invalid recorded entry on line %d: %w", line, err)
+		}
+		entries = append(entries, e)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func redactHeaders(header http.Header) []EntryHeader {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	headers := make([]EntryHeader, 0, len(header))
+	for _, name := range names {
+		for _, v := range header[name] {
+			if redactedHeaders[http.CanonicalHeaderKey(name)] {
+				v = Redacted
+			}
+			headers = append(headers, EntryHeader{Name: name, Value: v})
+		}
+	}
+	return headers
+}
+
+// redactBody redacts the secrets of a JSON body, other bodies are entirely redacted as their
+// secrets cannot be found.
+func redactBody(body []byte) []byte {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return body
+	}
+	d := json.NewDecoder(bytes.NewReader(body))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return []byte(Redacted)
+	}
+	b, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return []byte(Redacted)
+	}
+	return b
+}
+
+func redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, value := range v {
+			if isRedactedKey(k) {
+				v[k] = Redacted
+				continue
+			}
+			v[k] = redactValue(value)
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = redactValue(value)
+		}
+	}
+	return v
+}
+
+// isRedactedKey returns true for the keys holding a secret, including the dotted keys of the
+// policies, e.g. ssl.key.
+func isRedactedKey(k string) bool {
+	k = strings.ToLower(k)
+	if i := strings.LastIndex(k, "."); i >= 0 {
+		k = k[i+1:]
+	}
+	return redactedKeys[k]
+}
@@ -0,0 +1,147 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package remote
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type nopWriteCloser struct {
+	bytes.Buffer
+}
+
+func (*nopWriteCloser) Close() error {
+	return nil
+}
+
+func TestRecorder(t *testing.T) {
+	const enrollResponse = `{"action":"created","item":{"id":"agent-id","access_api_key":"secret-api-key","policy_id":"policy"}}`
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, `{"type":"PERMANENT","metadata":{"password":"changeme"}}`, string(body))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, enrollResponse)
+	}))
+	defer s.Close()
+
+	w := &nopWriteCloser{}
+	rt := newRecorder(w, 0).Wrap(http.DefaultTransport)
+
+	req, err := http.NewRequest(http.MethodPost, s.URL+"/api/fleet/agents/enroll?",
+		strings.NewReader(`{"type":"PERMANENT","metadata":{"password":"changeme"}}`))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "ApiKey enrollment-token")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, enrollResponse, string(body), "the response must be left untouched")
+
+	assert.NotContains(t, w.String(), "enrollment-token")
+	assert.NotContains(t, w.String(), "changeme")
+	assert.NotContains(t, w.String(), "secret-api-key")
+
+	entries, err := ReadEntries(&w.Buffer)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	e := entries[0]
+	assert.Equal(t, http.MethodPost, e.Request.Method)
+	assert.Equal(t, s.URL+"/api/fleet/agents/enroll?", e.Request.URL)
+	assert.Contains(t, e.Request.Headers, EntryHeader{Name: "Authorization", Value: Redacted})
+	require.NotNil(t, e.Request.PostData)
+	assert.JSONEq(t, `{"type":"PERMANENT","metadata":{"password":"[REDACTED]"}}`, e.Request.PostData.Text)
+	assert.Equal(t, http.StatusOK, e.Response.Status)
+	assert.Equal(t, "application/json", e.Response.Content.MimeType)
+	assert.JSONEq(t, `{"action":"created","item":{"id":"agent-id","access_api_key":"[REDACTED]","policy_id":"policy"}}`, e.Response.Content.Text)
+}
+
+type errorRoundTripper struct{}
+
+func (errorRoundTripper) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestRecorderError(t *testing.T) {
+	w := &nopWriteCloser{}
+	rt := newRecorder(w, 0).Wrap(errorRoundTripper{})
+
+	req, err := http.NewRequest(http.MethodPost, "http://localhost/api/fleet/agents/id/acks?", nil)
+	require.NoError(t, err)
+	_, err = rt.RoundTrip(req) //nolint:bodyclose // no response
+	require.Error(t, err)
+
+	entries, err := ReadEntries(&w.Buffer)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "connection refused", entries[0].Error)
+	assert.Nil(t, entries[0].Request.PostData)
+}
+
+func TestRedactBody(t *testing.T) {
+	testCases := map[string]struct {
+		body     string
+		expected string
+	}{
+		"policy outputs": {
+			body:     `{"actions":[{"data":{"policy":{"outputs":{"default":{"api_key":"id:key","ssl.key":"pem","username":"elastic"}}}}}],"ack_token":"abc"}`,
+			expected: `{"actions":[{"data":{"policy":{"outputs":{"default":{"api_key":"[REDACTED]","ssl.key":"[REDACTED]","username":"elastic"}}}}}],"ack_token":"abc"}`,
+		},
+		"numbers are kept": {
+			body:     `{"seq_no":12345678901234567890}`,
+			expected: `{"seq_no":12345678901234567890}`,
+		},
+		"not json": {
+			body:     `token=secret`,
+			expected: `"[REDACTED]"`,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.JSONEq(t, tc.expected, jsonOrString(redactBody([]byte(tc.body))))
+		})
+	}
+}
+
+func jsonOrString(b []byte) string {
+	if string(b) == Redacted {
+		return `"` + Redacted + `"`
+	}
+	return string(b)
+}
+
+func TestRecorderTruncatesBodies(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"message":"`+strings.Repeat("a", 100)+`"}`)
+	}))
+	defer s.Close()
+
+	w := &nopWriteCloser{}
+	rt := newRecorder(w, 32).Wrap(http.DefaultTransport)
+	req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+	require.NoError(t, err)
+	resp, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	entries, err := ReadEntries(&w.Buffer)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.True(t, entries[0].Response.Content.Truncated)
+	assert.Len(t, entries[0].Response.Content.Text, 32)
+}
@@ -0,0 +1,52 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package reporter
+
+import "time"
+
+// Document is the JSON representation of an event written by the backends.
+type Document struct {
+	Timestamp time.Time              `json:"@timestamp"`
+	Type      string                 `json:"type"`
+	SubType   string                 `json:"sub_type"`
+	Message   string                 `json:"message"`
+	Payload   map[string]interface{} `json:"payload,omitempty"`
+}
+
+// NewDocument creates the document of an event.
+func NewDocument(e Event) Document {
+	return Document{
+		Timestamp: e.Time().UTC(),
+		Type:      e.Type(),
+		SubType:   e.SubType(),
+		Message:   e.Message(),
+		Payload:   e.Payload(),
+	}
+}
+
+// Filter selects the events reported by a backend.
+type Filter struct {
+	// Types of the reported events, e.g. ERROR, all types are reported when empty.
+	Types []string `config:"types" yaml:"types,omitempty"`
+	// SubTypes of the reported events, e.g. FAILED or ROLLBACK, all sub types are reported when empty.
+	SubTypes []string `config:"sub_types" yaml:"sub_types,omitempty"`
+}
+
+// Match returns true when the event must be reported.
+func (f Filter) Match(e Event) bool {
+	return matchAny(f.Types, e.Type()) && matchAny(f.SubTypes, e.SubType())
+}
+
+func matchAny(values []string, v string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
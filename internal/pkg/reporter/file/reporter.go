@@ -0,0 +1,88 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package file
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/elastic/elastic-agent-libs/file"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/internal/pkg/reporter"
+)
+
+// Config is the configuration of the file reporter.
+type Config struct {
+	Enabled bool `config:"enabled" yaml:"enabled"`
+	// Path is the prefix of the events files, the date and the .ndjson extension are appended.
+	Path string `config:"path" yaml:"path,omitempty"`
+	// MaxSize is the size in bytes after which the file is rotated.
+	MaxSize uint `config:"max_size" yaml:"max_size"`
+	// MaxBackups is the number of rotated files to keep.
+	MaxBackups uint            `config:"max_backups" yaml:"max_backups"`
+	Filter     reporter.Filter `config:"filter" yaml:"filter,omitempty"`
+}
+
+// DefaultConfig creates a config with pre-set default values.
+func DefaultConfig() *Config {
+	return &Config{
+		Enabled:    false,
+		MaxSize:    10 * 1024 * 1024, // 10 MiB
+		MaxBackups: 5,
+	}
+}
+
+// Reporter writes the events to a rotating file, one JSON document per line.
+type Reporter struct {
+	rotator *file.Rotator
+	filter  reporter.Filter
+}
+
+// NewReporter creates a new file reporter writing to cfg.Path.
+func NewReporter(cfg *Config) (*Reporter, error) {
+	if cfg.Path == "" {
+		return nil, errors.New("file reporter requires a path", errors.TypeConfig)
+	}
+
+	rotator, err := file.NewFileRotator(
+		cfg.Path,
+		file.MaxSizeBytes(cfg.MaxSize),
+		file.MaxBackups(cfg.MaxBackups),
+		file.Permissions(0600),
+		file.RotateOnStartup(false),
+	)
+	if err != nil {
+		return nil, errors.New(err, "creating file reporter", errors.TypeFilesystem, errors.M(errors.MetaKeyPath, cfg.Path))
+	}
+
+	return &Reporter{
+		rotator: rotator,
+		filter:  cfg.Filter,
+	}, nil
+}
+
+// Report appends the event to the file.
+func (r *Reporter) Report(_ context.Context, record reporter.Event) error {
+	if !r.filter.Match(record) {
+		return nil
+	}
+
+	b, err := json.Marshal(reporter.NewDocument(record))
+	if err != nil {
+		return errors.New(err, "failed to encode event")
+	}
+	if _, err := r.rotator.Write(append(b, '\n')); err != nil {
+		return errors.New(err, "failed to write event", errors.TypeFilesystem)
+	}
+	return nil
+}
+
+// Close closes the file.
+func (r *Reporter) Close() error {
+	return r.rotator.Close()
+}
+
+// Check it is reporter.Backend
+var _ reporter.Backend = &Reporter{}
@@ -0,0 +1,75 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package file
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent/internal/pkg/reporter"
+)
+
+type testEvent struct {
+	eventType, subType, message string
+}
+
+func (e testEvent) Type() string                    { return e.eventType }
+func (e testEvent) SubType() string                 { return e.subType }
+func (e testEvent) Time() time.Time                 { return time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC) }
+func (e testEvent) Message() string                 { return e.message }
+func (e testEvent) Payload() map[string]interface{} { return map[string]interface{}{"app": e.message} }
+
+func TestReporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events", "elastic-agent-events")
+	cfg := DefaultConfig()
+	cfg.Enabled = true
+	cfg.Path = path
+	cfg.Filter = reporter.Filter{SubTypes: []string{reporter.EventSubTypeFailed}}
+
+	r, err := NewReporter(cfg)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, r.Report(ctx, testEvent{reporter.EventTypeError, reporter.EventSubTypeFailed, "filebeat failed"}))
+	require.NoError(t, r.Report(ctx, testEvent{reporter.EventTypeState, reporter.EventSubTypeRunning, "filebeat running"}))
+	require.NoError(t, r.Report(ctx, testEvent{reporter.EventTypeError, reporter.EventSubTypeFailed, "metricbeat failed"}))
+	require.NoError(t, r.Close())
+
+	files, err := filepath.Glob(path + "*.ndjson")
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	f, err := os.Open(files[0])
+	require.NoError(t, err)
+	defer f.Close()
+
+	var docs []reporter.Document
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var doc reporter.Document
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &doc))
+		docs = append(docs, doc)
+	}
+	require.NoError(t, scanner.Err())
+
+	require.Len(t, docs, 2)
+	assert.Equal(t, "filebeat failed", docs[0].Message)
+	assert.Equal(t, reporter.EventSubTypeFailed, docs[0].SubType)
+	assert.Equal(t, "metricbeat failed", docs[1].Message)
+	assert.Equal(t, "metricbeat failed", docs[1].Payload["app"])
+}
+
+func TestReporterRequiresPath(t *testing.T) {
+	_, err := NewReporter(DefaultConfig())
+	assert.Error(t, err)
+}
@@ -40,6 +40,8 @@ const (
 	EventSubTypeStopping = "STOPPING"
 	// EventSubTypeUpdating is an event type indicating update process in progress.
 	EventSubTypeUpdating = "UPDATING"
+	// EventSubTypeRollback is an event type indicating an upgrade was rolled back.
+	EventSubTypeRollback = "ROLLBACK"
 )
 
 type agentInfo interface {
@@ -79,6 +81,20 @@ func (r *Reporter) OnStateChange(id string, name string, state state.State) {
 	r.report(r.ctx, rec)
 }
 
+// OnRollback called when an upgrade is rolled back to the previous version.
+func (r *Reporter) OnRollback(prevHash string, hash string, reason error) {
+	r.report(r.ctx, event{
+		eventype:  EventTypeError,
+		subType:   EventSubTypeRollback,
+		timestamp: time.Now(),
+		message:   fmt.Sprintf("Agent[%s]: Upgrade to %s rolled back to %s: %v", r.info.AgentID(), hash, prevHash, reason),
+		payload: map[string]interface{}{
+			"prev_hash": prevHash,
+			"hash":      hash,
+		},
+	})
+}
+
 func (r *Reporter) report(ctx context.Context, e event) {
 	var err error
 
@@ -118,3 +118,29 @@ func TestTypes(t *testing.T) {
 		})
 	}
 }
+
+func TestRollback(t *testing.T) {
+	rep := NewReporter(context.Background(), nil, &info{}, &testReporter{})
+	rep.OnRollback("abc123", "def456", fmt.Errorf("agent crashed"))
+
+	assert.Equal(t, EventTypeError, result.Type())
+	assert.Equal(t, EventSubTypeRollback, result.SubType())
+	assert.Equal(t, "Agent[id]: Upgrade to def456 rolled back to abc123: agent crashed", result.Message())
+	assert.Equal(t, "abc123", result.Payload()["prev_hash"])
+}
+
+func TestFilter(t *testing.T) {
+	failed := event{eventype: EventTypeError, subType: EventSubTypeFailed}
+	running := event{eventype: EventTypeState, subType: EventSubTypeRunning}
+
+	assert.True(t, Filter{}.Match(failed))
+	assert.True(t, Filter{}.Match(running))
+
+	f := Filter{SubTypes: []string{EventSubTypeFailed, EventSubTypeRollback}}
+	assert.True(t, f.Match(failed))
+	assert.False(t, f.Match(running))
+
+	f = Filter{Types: []string{EventTypeState}}
+	assert.False(t, f.Match(failed))
+	assert.True(t, f.Match(running))
+}
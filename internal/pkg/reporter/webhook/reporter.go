@@ -0,0 +1,272 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/internal/pkg/core/backoff"
+	"github.com/elastic/elastic-agent/internal/pkg/reporter"
+	"github.com/elastic/elastic-agent/internal/pkg/release"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+// SignatureHeader holds the HMAC-SHA256 signature of the request body, hex encoded and
+// prefixed with "sha256=".
+const SignatureHeader = "X-Elastic-Agent-Signature"
+
+// ErrQueueFull is returned when an event is dropped because the queue of pending events is full.
+var ErrQueueFull = errors.New("webhook reporter queue is full, event dropped")
+
+// ErrClosed is returned when an event is reported after the reporter is closed.
+var ErrClosed = errors.New("webhook reporter is closed")
+
+// Config is the configuration of the webhook reporter.
+type Config struct {
+	Enabled bool   `config:"enabled" yaml:"enabled"`
+	URL     string `config:"url" yaml:"url,omitempty"`
+	// Secret is the key used to sign the requests, requests are not signed when empty.
+	Secret  string            `config:"secret" yaml:"secret,omitempty"`
+	Headers map[string]string `config:"headers" yaml:"headers,omitempty"`
+	Timeout time.Duration     `config:"timeout" yaml:"timeout"`
+	// BatchSize is the maximum number of events sent in a single request.
+	BatchSize int `config:"batch_size" yaml:"batch_size"`
+	// FlushInterval is the maximum time an event waits for its batch to be sent.
+	FlushInterval time.Duration `config:"flush_interval" yaml:"flush_interval"`
+	// QueueSize is the maximum number of events waiting to be sent, new events are dropped when full.
+	QueueSize int             `config:"queue_size" yaml:"queue_size"`
+	Retry     RetryConfig     `config:"retry" yaml:"retry"`
+	Filter    reporter.Filter `config:"filter" yaml:"filter,omitempty"`
+}
+
+// RetryConfig configures the retries of the failed requests.
+type RetryConfig struct {
+	MaxRetries int           `config:"max_retries" yaml:"max_retries"`
+	Init       time.Duration `config:"init" yaml:"init"`
+	Max        time.Duration `config:"max" yaml:"max"`
+}
+
+// DefaultConfig creates a config with pre-set default values.
+func DefaultConfig() *Config {
+	return &Config{
+		Enabled:       false,
+		Timeout:       10 * time.Second,
+		BatchSize:     50,
+		FlushInterval: 5 * time.Second,
+		QueueSize:     1000,
+		Retry: RetryConfig{
+			MaxRetries: 3,
+			Init:       time.Second,
+			Max:        30 * time.Second,
+		},
+	}
+}
+
+// Validate validates the configuration.
+func (c *Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return errors.New(err, "invalid webhook reporter url", errors.TypeConfig)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.New(fmt.Sprintf("webhook reporter url must use http or https, received %q", c.URL), errors.TypeConfig)
+	}
+	if c.BatchSize <= 0 || c.QueueSize <= 0 || c.FlushInterval <= 0 {
+		return errors.New("webhook reporter batch_size, queue_size and flush_interval must be positive", errors.TypeConfig)
+	}
+	return nil
+}
+
+// request is the body of the requests sent to the webhook.
+type request struct {
+	Events []reporter.Document `json:"events"`
+}
+
+// Reporter sends the events to a webhook in batches. Events are queued and sent in the
+// background so reporting never blocks.
+type Reporter struct {
+	log    *logger.Logger
+	cfg    *Config
+	client *http.Client
+
+	queue chan reporter.Document
+	done  chan struct{}
+	wg    sync.WaitGroup
+	once  sync.Once
+}
+
+// NewReporter creates a new webhook reporter and starts sending the reported events.
+func NewReporter(log *logger.Logger, cfg *Config) (*Reporter, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	r := &Reporter{
+		log:    log,
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		queue:  make(chan reporter.Document, cfg.QueueSize),
+		done:   make(chan struct{}),
+	}
+
+	r.wg.Add(1)
+	go r.run()
+	return r, nil
+}
+
+// Report queues the event to be sent with the next batch.
+func (r *Reporter) Report(_ context.Context, record reporter.Event) error {
+	if !r.cfg.Filter.Match(record) {
+		return nil
+	}
+
+	select {
+	case <-r.done:
+		return ErrClosed
+	default:
+	}
+
+	select {
+	case r.queue <- reporter.NewDocument(record):
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Close sends the queued events and stops the reporter.
+func (r *Reporter) Close() error {
+	r.once.Do(func() {
+		close(r.done)
+	})
+	r.wg.Wait()
+	return nil
+}
+
+func (r *Reporter) run() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]reporter.Document, 0, r.cfg.BatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := r.send(batch); err != nil {
+			r.log.Errorf("Failed to send %d events to webhook: %v", len(batch), err)
+		}
+		batch = make([]reporter.Document, 0, r.cfg.BatchSize)
+	}
+
+	for {
+		select {
+		case doc := <-r.queue:
+			batch = append(batch, doc)
+			if len(batch) >= r.cfg.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-r.done:
+			// send what is left in the queue before stopping
+			for {
+				select {
+				case doc := <-r.queue:
+					batch = append(batch, doc)
+					if len(batch) >= r.cfg.BatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// send sends a batch, retrying with an exponential backoff. Once the reporter is closed
+// the batch is sent only once.
+func (r *Reporter) send(batch []reporter.Document) error {
+	body, err := json.Marshal(request{Events: batch})
+	if err != nil {
+		return errors.New(err, "failed to encode events")
+	}
+
+	b := backoff.NewExpBackoff(r.done, r.cfg.Retry.Init, r.cfg.Retry.Max)
+	for attempt := 0; ; attempt++ {
+		retryable, err := r.post(body)
+		if err == nil {
+			return nil
+		}
+		if !retryable || attempt >= r.cfg.Retry.MaxRetries {
+			return err
+		}
+		r.log.Debugf("Retrying to send events to webhook after error: %v", err)
+		if !b.Wait() {
+			return err
+		}
+	}
+}
+
+// post sends the body to the webhook, the returned bool tells if the request can be retried.
+func (r *Reporter) post(body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, r.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "elastic-agent/"+release.Version())
+	for k, v := range r.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	if r.cfg.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign([]byte(r.cfg.Secret), body))
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	default:
+		return false, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+}
+
+// Sign returns the value of the signature header of a request body.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Check it is reporter.Backend
+var _ reporter.Backend = &Reporter{}
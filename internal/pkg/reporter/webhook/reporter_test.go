@@ -0,0 +1,216 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent/internal/pkg/reporter"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+type testEvent struct {
+	subType, message string
+}
+
+func (e testEvent) Type() string                    { return reporter.EventTypeError }
+func (e testEvent) SubType() string                 { return e.subType }
+func (e testEvent) Time() time.Time                 { return time.Now() }
+func (e testEvent) Message() string                 { return e.message }
+func (e testEvent) Payload() map[string]interface{} { return nil }
+
+type receiver struct {
+	mx       sync.Mutex
+	requests []request
+	headers  []http.Header
+	bodies   [][]byte
+	statuses []int
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rc.mx.Lock()
+	defer rc.mx.Unlock()
+
+	body, _ := ioutil.ReadAll(r.Body)
+	status := http.StatusOK
+	if len(rc.statuses) > 0 {
+		status, rc.statuses = rc.statuses[0], rc.statuses[1:]
+	}
+	if status == http.StatusOK {
+		var req request
+		_ = json.Unmarshal(body, &req)
+		rc.requests = append(rc.requests, req)
+		rc.headers = append(rc.headers, r.Header.Clone())
+		rc.bodies = append(rc.bodies, body)
+	}
+	w.WriteHeader(status)
+}
+
+func (rc *receiver) received() []request {
+	rc.mx.Lock()
+	defer rc.mx.Unlock()
+	return append([]request(nil), rc.requests...)
+}
+
+func newTestReporter(t *testing.T, url string, modify func(*Config)) *Reporter {
+	t.Helper()
+	log, err := logger.New("", false)
+	require.NoError(t, err)
+
+	cfg := DefaultConfig()
+	cfg.Enabled = true
+	cfg.URL = url
+	cfg.FlushInterval = time.Hour
+	cfg.Retry.Init = time.Millisecond
+	cfg.Retry.Max = 10 * time.Millisecond
+	if modify != nil {
+		modify(cfg)
+	}
+
+	r, err := NewReporter(log, cfg)
+	require.NoError(t, err)
+	return r
+}
+
+func TestReporterBatches(t *testing.T) {
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	r := newTestReporter(t, srv.URL, func(cfg *Config) {
+		cfg.BatchSize = 2
+		cfg.Secret = "s3cr3t"
+		cfg.Headers = map[string]string{"X-Team": "sre"}
+	})
+
+	ctx := context.Background()
+	for _, msg := range []string{"one", "two", "three"} {
+		require.NoError(t, r.Report(ctx, testEvent{reporter.EventSubTypeFailed, msg}))
+	}
+
+	// the first batch is sent as soon as it is full
+	require.Eventually(t, func() bool { return len(rc.received()) == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	// the remaining events are sent on close
+	require.NoError(t, r.Close())
+	requests := rc.received()
+	require.Len(t, requests, 2)
+	require.Len(t, requests[0].Events, 2)
+	assert.Equal(t, "one", requests[0].Events[0].Message)
+	assert.Equal(t, "two", requests[0].Events[1].Message)
+	require.Len(t, requests[1].Events, 1)
+	assert.Equal(t, "three", requests[1].Events[0].Message)
+
+	for i, h := range rc.headers {
+		assert.Equal(t, Sign([]byte("s3cr3t"), rc.bodies[i]), h.Get(SignatureHeader))
+		assert.Equal(t, "sre", h.Get("X-Team"))
+		assert.Equal(t, "application/json", h.Get("Content-Type"))
+	}
+
+	assert.ErrorIs(t, r.Report(ctx, testEvent{reporter.EventSubTypeFailed, "late"}), ErrClosed)
+}
+
+func TestReporterFlushInterval(t *testing.T) {
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	r := newTestReporter(t, srv.URL, func(cfg *Config) {
+		cfg.FlushInterval = 20 * time.Millisecond
+	})
+	defer r.Close()
+
+	require.NoError(t, r.Report(context.Background(), testEvent{reporter.EventSubTypeFailed, "one"}))
+	require.Eventually(t, func() bool { return len(rc.received()) == 1 }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestReporterRetries(t *testing.T) {
+	rc := &receiver{statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	r := newTestReporter(t, srv.URL, func(cfg *Config) {
+		cfg.BatchSize = 1
+	})
+	defer r.Close()
+
+	require.NoError(t, r.Report(context.Background(), testEvent{reporter.EventSubTypeFailed, "one"}))
+	require.Eventually(t, func() bool { return len(rc.received()) == 1 }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestReporterDoesNotRetryClientErrors(t *testing.T) {
+	rc := &receiver{statuses: []int{http.StatusBadRequest}}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	r := newTestReporter(t, srv.URL, nil)
+	require.NoError(t, r.Report(context.Background(), testEvent{reporter.EventSubTypeFailed, "one"}))
+	require.NoError(t, r.Close())
+	assert.Empty(t, rc.received())
+}
+
+func TestReporterFilter(t *testing.T) {
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	r := newTestReporter(t, srv.URL, func(cfg *Config) {
+		cfg.Filter = reporter.Filter{SubTypes: []string{reporter.EventSubTypeRollback}}
+	})
+
+	ctx := context.Background()
+	require.NoError(t, r.Report(ctx, testEvent{reporter.EventSubTypeFailed, "failed"}))
+	require.NoError(t, r.Report(ctx, testEvent{reporter.EventSubTypeRollback, "rolled back"}))
+	require.NoError(t, r.Close())
+
+	requests := rc.received()
+	require.Len(t, requests, 1)
+	require.Len(t, requests[0].Events, 1)
+	assert.Equal(t, "rolled back", requests[0].Events[0].Message)
+}
+
+func TestReporterQueueFull(t *testing.T) {
+	block := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	defer srv.Close()
+	defer close(block)
+
+	r := newTestReporter(t, srv.URL, func(cfg *Config) {
+		cfg.BatchSize = 1
+		cfg.QueueSize = 1
+		cfg.Retry.MaxRetries = 0
+	})
+
+	ctx := context.Background()
+	var err error
+	for i := 0; i < 10 && err == nil; i++ {
+		err = r.Report(ctx, testEvent{reporter.EventSubTypeFailed, "event"})
+	}
+	assert.ErrorIs(t, err, ErrQueueFull)
+}
+
+func TestConfigValidate(t *testing.T) {
+	cfg := DefaultConfig()
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Enabled = true
+	cfg.URL = "ftp://example.com"
+	assert.Error(t, cfg.Validate())
+
+	cfg.URL = "https://example.com/hook"
+	assert.NoError(t, cfg.Validate())
+}
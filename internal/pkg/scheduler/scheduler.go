@@ -16,10 +16,21 @@ type Scheduler interface {
 	Stop()
 }
 
+// NextSetter is implemented by schedulers which allow the delay before the next tick to be
+// overridden, e.g. when the remote endpoint asks to be contacted later.
+type NextSetter interface {
+	// SetNext overrides the delay before the next tick only, a zero duration restores the
+	// default delay.
+	SetNext(time.Duration)
+}
+
 // Stepper is a scheduler where each Tick is manually triggered, this is useful in scenario
 // when you want to test the behavior of asynchronous code in a synchronous way.
 type Stepper struct {
 	C chan time.Time
+
+	// NextDelay is the last delay received by SetNext.
+	NextDelay time.Duration
 }
 
 // Next trigger the WaitTick unblock manually.
@@ -35,6 +46,11 @@ func (s *Stepper) WaitTick() <-chan time.Time {
 // Stop is stopping the scheduler, in the case of the Stepper scheduler nothing is done.
 func (s *Stepper) Stop() {}
 
+// SetNext records the delay, ticks of the Stepper are still manually triggered.
+func (s *Stepper) SetNext(d time.Duration) {
+	s.NextDelay = d
+}
+
 // NewStepper returns a new Stepper scheduler where the tick is manually controlled.
 func NewStepper() *Stepper {
 	return &Stepper{
@@ -82,6 +98,7 @@ type PeriodicJitter struct {
 	C        chan time.Time
 	ran      bool
 	d        time.Duration
+	next     time.Duration
 	variance time.Duration
 	done     chan struct{}
 }
@@ -112,8 +129,14 @@ func (p *PeriodicJitter) WaitTick() <-chan time.Time {
 		return p.C
 	}
 
+	d := p.d
+	if p.next > 0 {
+		d = p.next
+		p.next = 0
+	}
+
 	select {
-	case <-time.After(p.d + p.delay()):
+	case <-time.After(d + p.delay()):
 		p.C <- time.Now()
 	case <-p.done:
 		p.C <- time.Now()
@@ -123,6 +146,12 @@ func (p *PeriodicJitter) WaitTick() <-chan time.Time {
 	return p.C
 }
 
+// SetNext overrides the duration of the next sleep, the jitter is still added to it.
+// Note: SetNext must be called from the goroutine calling WaitTick.
+func (p *PeriodicJitter) SetNext(d time.Duration) {
+	p.next = d
+}
+
 // Stop stops the PeriodicJitter scheduler.
 func (p *PeriodicJitter) Stop() {
 	close(p.done)
@@ -190,4 +190,19 @@ func testPeriodicJitter(t *testing.T) {
 
 		<-scheduler.WaitTick()
 	})
+
+	t.Run("next delay is used once", func(t *testing.T) {
+		duration := 20 * time.Minute
+		variance := 1 * time.Millisecond
+		scheduler := NewPeriodicJitter(duration, variance)
+		defer scheduler.Stop()
+
+		<-scheduler.WaitTick()
+
+		scheduler.SetNext(10 * time.Millisecond)
+		startedAt := time.Now()
+		<-scheduler.WaitTick()
+		require.True(t, time.Since(startedAt) < duration)
+		require.Zero(t, scheduler.next)
+	})
 }
@@ -0,0 +1,225 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package logger
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+)
+
+// LevelOverride sets the level of a named logger or of a program.
+type LevelOverride struct {
+	Name  string     `config:"name" yaml:"name"`
+	Level logp.Level `config:"level" yaml:"level"`
+}
+
+// LevelsConfig configures the level of named loggers and of programs, overriding the level of
+// the agent. The level of a logger applies to its children, e.g. the level of composable
+// applies to composable.providers.kubernetes unless it has its own level.
+type LevelsConfig struct {
+	Loggers  []LevelOverride `config:"loggers" yaml:"loggers,omitempty"`
+	Programs []LevelOverride `config:"programs" yaml:"programs,omitempty"`
+}
+
+// levels holds the level of the agent loggers together with the overrides. They are shared by
+// every logger as logp configures a global logger.
+type levels struct {
+	mx       sync.RWMutex
+	base     zapcore.Level
+	loggers  map[string]zapcore.Level
+	programs map[string]logp.Level
+}
+
+var globalLevels = &levels{
+	base:     DefaultLogLevel.ZapLevel(),
+	loggers:  map[string]zapcore.Level{},
+	programs: map[string]logp.Level{},
+}
+
+// ApplyLevelsConfig replaces the level overrides of the loggers and programs with the
+// configured ones.
+func ApplyLevelsConfig(cfg *LevelsConfig) {
+	globalLevels.mx.Lock()
+	globalLevels.loggers = map[string]zapcore.Level{}
+	globalLevels.programs = map[string]logp.Level{}
+	if cfg != nil {
+		for _, o := range cfg.Loggers {
+			globalLevels.loggers[o.Name] = o.Level.ZapLevel()
+		}
+		for _, o := range cfg.Programs {
+			globalLevels.programs[strings.ToLower(o.Name)] = o.Level
+		}
+	}
+	globalLevels.mx.Unlock()
+}
+
+// SetLoggerLevel sets the level of a named logger and of its children, it applies immediately.
+func SetLoggerLevel(name string, level logp.Level) {
+	globalLevels.mx.Lock()
+	defer globalLevels.mx.Unlock()
+	globalLevels.loggers[name] = level.ZapLevel()
+}
+
+// ResetLoggerLevel removes the level override of a named logger.
+func ResetLoggerLevel(name string) {
+	globalLevels.mx.Lock()
+	defer globalLevels.mx.Unlock()
+	delete(globalLevels.loggers, name)
+}
+
+// LoggerLevels returns the level overrides of the named loggers.
+func LoggerLevels() []LevelOverride {
+	globalLevels.mx.RLock()
+	defer globalLevels.mx.RUnlock()
+
+	overrides := make([]LevelOverride, 0, len(globalLevels.loggers))
+	for name, level := range globalLevels.loggers {
+		overrides = append(overrides, LevelOverride{Name: name, Level: fromZapLevel(level)})
+	}
+	sortOverrides(overrides)
+	return overrides
+}
+
+// SetProgramLevel sets the level of a program, it is passed to the program on its next reconfigure.
+func SetProgramLevel(name string, level logp.Level) {
+	globalLevels.mx.Lock()
+	defer globalLevels.mx.Unlock()
+	globalLevels.programs[strings.ToLower(name)] = level
+}
+
+// ResetProgramLevel removes the level override of a program.
+func ResetProgramLevel(name string) {
+	globalLevels.mx.Lock()
+	defer globalLevels.mx.Unlock()
+	delete(globalLevels.programs, strings.ToLower(name))
+}
+
+// ProgramLevel returns the level override of a program.
+func ProgramLevel(name string) (logp.Level, bool) {
+	globalLevels.mx.RLock()
+	defer globalLevels.mx.RUnlock()
+	level, ok := globalLevels.programs[strings.ToLower(name)]
+	return level, ok
+}
+
+// ProgramLevels returns the level overrides of the programs.
+func ProgramLevels() []LevelOverride {
+	globalLevels.mx.RLock()
+	defer globalLevels.mx.RUnlock()
+
+	overrides := make([]LevelOverride, 0, len(globalLevels.programs))
+	for name, level := range globalLevels.programs {
+		overrides = append(overrides, LevelOverride{Name: name, Level: level})
+	}
+	sortOverrides(overrides)
+	return overrides
+}
+
+// setBase sets the level of the loggers without override, it is the level of the logp outputs.
+func (l *levels) setBase(level zapcore.Level) {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	l.base = level
+}
+
+// baseLevel returns the level of the loggers without override.
+func (l *levels) baseLevel() zapcore.Level {
+	l.mx.RLock()
+	defer l.mx.RUnlock()
+	return l.base
+}
+
+// min returns the lowest level of the loggers.
+func (l *levels) min() zapcore.Level {
+	l.mx.RLock()
+	defer l.mx.RUnlock()
+
+	min := l.base
+	for _, level := range l.loggers {
+		if level < min {
+			min = level
+		}
+	}
+	return min
+}
+
+// levelFor returns the level of a named logger, the override of the logger or of its closest
+// parent, the level of the agent otherwise.
+func (l *levels) levelFor(name string) zapcore.Level {
+	l.mx.RLock()
+	defer l.mx.RUnlock()
+
+	if len(l.loggers) == 0 {
+		return l.base
+	}
+	for {
+		if level, ok := l.loggers[name]; ok {
+			return level
+		}
+		idx := strings.LastIndexByte(name, '.')
+		if idx < 0 {
+			return l.base
+		}
+		name = name[:idx]
+	}
+}
+
+// levelCore filters the entries by the level of the logger they are logged with. The level of
+// the logp outputs stays the level of the agent, the entries of the loggers with a lower level
+// are written to the outputs directly.
+type levelCore struct {
+	zapcore.Core
+	levels *levels
+}
+
+func wrapLevelCore(core zapcore.Core) zapcore.Core {
+	return &levelCore{Core: core, levels: globalLevels}
+}
+
+// Enabled returns false when no logger uses the level.
+func (c *levelCore) Enabled(level zapcore.Level) bool {
+	return level >= c.levels.min()
+}
+
+// With adds structured context to the Core.
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), levels: c.levels}
+}
+
+// Check drops the entries below the level of their logger.
+func (c *levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level < c.levels.levelFor(ent.LoggerName) {
+		return ce
+	}
+	if ent.Level < c.levels.baseLevel() {
+		// below the level of the outputs, the logger has a lower level than the agent
+		return ce.AddCore(ent, c.Core)
+	}
+	return c.Core.Check(ent, ce)
+}
+
+func fromZapLevel(level zapcore.Level) logp.Level {
+	switch level {
+	case zapcore.DebugLevel:
+		return logp.DebugLevel
+	case zapcore.WarnLevel:
+		return logp.WarnLevel
+	case zapcore.ErrorLevel:
+		return logp.ErrorLevel
+	default:
+		return logp.InfoLevel
+	}
+}
+
+func sortOverrides(overrides []LevelOverride) {
+	sort.Slice(overrides, func(i, j int) bool {
+		return overrides[i].Name < overrides[j].Name
+	})
+}
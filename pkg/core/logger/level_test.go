@@ -0,0 +1,80 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+)
+
+func TestLoggerLevels(t *testing.T) {
+	defer ApplyLevelsConfig(nil)
+	globalLevels.setBase(zapcore.InfoLevel)
+	globalLevel := logp.GetLevel()
+
+	ApplyLevelsConfig(&LevelsConfig{
+		Loggers: []LevelOverride{
+			{Name: "composable", Level: logp.DebugLevel},
+			{Name: "fleet_gateway", Level: logp.WarnLevel},
+		},
+	})
+	SetLoggerLevel("composable.providers.docker", logp.ErrorLevel)
+
+	// the outputs keep the level of the agent
+	core, obs := observer.New(zapcore.InfoLevel)
+	root := zap.New(wrapLevelCore(core))
+
+	root.Debug("agent debug")
+	root.Info("agent info")
+	root.Named("composable").Named("providers.kubernetes").Debug("kubernetes debug")
+	root.Named("composable").Named("providers.docker").Warn("docker warning")
+	root.Named("composable").Named("providers.docker").Error("docker error")
+	root.Named("fleet_gateway").Info("gateway info")
+	root.Named("fleet_gateway").Warn("gateway warning")
+	// a logger sharing a prefix is not a child
+	root.Named("composable_other").Debug("other debug")
+
+	var messages []string
+	for _, e := range obs.All() {
+		messages = append(messages, e.Message)
+	}
+	assert.Equal(t, []string{"agent info", "kubernetes debug", "docker error", "gateway warning"}, messages)
+	assert.Equal(t, []LevelOverride{
+		{Name: "composable", Level: logp.DebugLevel},
+		{Name: "composable.providers.docker", Level: logp.ErrorLevel},
+		{Name: "fleet_gateway", Level: logp.WarnLevel},
+	}, LoggerLevels())
+	// the overrides do not change the level of the other loggers
+	assert.Equal(t, globalLevel, logp.GetLevel())
+
+	ResetLoggerLevel("composable")
+	root.Named("composable").Debug("composable debug")
+	assert.Len(t, obs.All(), 4)
+}
+
+func TestProgramLevels(t *testing.T) {
+	defer ApplyLevelsConfig(nil)
+
+	ApplyLevelsConfig(&LevelsConfig{
+		Programs: []LevelOverride{{Name: "Filebeat", Level: logp.DebugLevel}},
+	})
+	SetProgramLevel("metricbeat", logp.WarnLevel)
+
+	level, ok := ProgramLevel("filebeat")
+	require.True(t, ok)
+	assert.Equal(t, logp.DebugLevel, level)
+
+	ResetProgramLevel("metricbeat")
+	_, ok = ProgramLevel("metricbeat")
+	assert.False(t, ok)
+	assert.Equal(t, []LevelOverride{{Name: "filebeat", Level: logp.DebugLevel}}, ProgramLevels())
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"go.elastic.co/ecszap"
+	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"gopkg.in/yaml.v2"
 
@@ -77,7 +78,8 @@ func new(name string, cfg *Config, logInternal bool) (*Logger, error) {
 	if err := configure.LoggingWithOutputs("", commonCfg, outputs...); err != nil {
 		return nil, fmt.Errorf("error initializing logging: %w", err)
 	}
-	return logp.NewLogger(name), nil
+	globalLevels.setBase(cfg.Level.ZapLevel())
+	return logp.NewLogger(name, zap.WrapCore(wrapLevelCore)), nil
 }
 
 func toCommonConfig(cfg *Config) (*config.C, error) {
@@ -134,7 +136,11 @@ func makeInternalFileOutput(cfg *Config) (zapcore.Core, error) {
 	encoderConfig := ecszap.ECSCompatibleEncoderConfig(logp.JSONEncoderConfig())
 	encoderConfig.EncodeTime = utcTimestampEncode
 	encoder := zapcore.NewJSONEncoder(encoderConfig)
-	return ecszap.WrapCore(zapcore.NewCore(encoder, rotator, cfg.Level.ZapLevel())), nil
+	// the level of the entries is checked by the level core, it accepts the lowest level in use
+	enabler := zap.LevelEnablerFunc(func(level zapcore.Level) bool {
+		return level >= globalLevels.min()
+	})
+	return ecszap.WrapCore(zapcore.NewCore(encoder, rotator, enabler)), nil
 }
 
 // utcTimestampEncode is a zapcore.TimeEncoder that formats time.Time in ISO-8601 in UTC.
@@ -6,16 +6,32 @@ package server
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"go.elastic.co/apm"
 
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
 	"github.com/elastic/elastic-agent/pkg/core/logger"
 )
 
+// maxSocketPathLen is the maximum length of the path of a unix socket on every platform.
+const maxSocketPathLen = 104
+
 // Config is a configuration of GRPC server.
 type Config struct {
-	Address string `config:"address"`
-	Port    uint16 `config:"port"`
+	Address string        `config:"address"`
+	Port    uint16        `config:"port"`
+	Socket  *SocketConfig `config:"socket"`
+}
+
+// SocketConfig configures serving the spawned applications on a unix domain socket, they are
+// authenticated with the credentials of their process. The TCP listener is kept for the services
+// and as a fallback when the socket cannot be used. The socket is only accessible to the user and
+// the group of the agent, applications running as another user must be members of the group.
+type SocketConfig struct {
+	Enabled bool `config:"enabled"`
+	// Path of the socket, defaults to elastic-agent-apps.sock in the run directory of the agent.
+	Path string `config:"path"`
 }
 
 // DefaultGRPCConfig creates a default server configuration.
@@ -23,10 +39,27 @@ func DefaultGRPCConfig() *Config {
 	return &Config{
 		Address: "localhost",
 		Port:    6789,
+		Socket: &SocketConfig{
+			Enabled: false,
+		},
 	}
 }
 
 // NewFromConfig creates a new GRPC server for clients to connect to.
 func NewFromConfig(logger *logger.Logger, cfg *Config, handler Handler, tracer *apm.Tracer) (*Server, error) {
-	return New(logger, fmt.Sprintf("%s:%d", cfg.Address, cfg.Port), handler, tracer)
+	srv, err := New(logger, fmt.Sprintf("%s:%d", cfg.Address, cfg.Port), handler, tracer)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Socket != nil && cfg.Socket.Enabled {
+		srv.socketPath = cfg.Socket.Path
+		if srv.socketPath == "" {
+			srv.socketPath = defaultSocketPath()
+		}
+	}
+	return srv, nil
+}
+
+func defaultSocketPath() string {
+	return filepath.Join(paths.Run(), "elastic-agent-apps.sock")
 }
@@ -5,10 +5,13 @@
 package server
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
 )
 
 func TestNewFromConfig(t *testing.T) {
@@ -21,3 +24,12 @@ func TestNewFromConfig(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "0.0.0.0:9876", srv.getListenAddr())
 }
+
+func TestNewFromConfigSocket(t *testing.T) {
+	l := newErrorLogger(t)
+	cfg := DefaultGRPCConfig()
+	cfg.Socket.Enabled = true
+	srv, err := NewFromConfig(l, cfg, &StubHandler{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(paths.Run(), "elastic-agent-apps.sock"), srv.socketPath)
+}
@@ -0,0 +1,89 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// PeerCred are the credentials of the process at the other end of a unix socket.
+type PeerCred struct {
+	PID int
+	UID int
+}
+
+// peerCredInfo is the authentication information of a connection over the unix socket, the TLS
+// handshake is done over the socket as well.
+type peerCredInfo struct {
+	credentials.TLSInfo
+	Cred PeerCred
+}
+
+// AuthType returns the type of the authentication.
+func (peerCredInfo) AuthType() string {
+	return "tls+peercred"
+}
+
+// peerCredTransport reads the credentials of the peers connecting over a unix socket before the
+// TLS handshake, the connections over TCP are only authenticated by TLS.
+type peerCredTransport struct {
+	credentials.TransportCredentials
+}
+
+// ServerHandshake reads the credentials of the peer and does the TLS handshake.
+func (t *peerCredTransport) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	uc, ok := conn.(*net.UnixConn)
+	if !ok {
+		return t.TransportCredentials.ServerHandshake(conn)
+	}
+	cred, err := peerCredentials(uc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read the credentials of the peer: %w", err)
+	}
+	c, info, err := t.TransportCredentials.ServerHandshake(conn)
+	if err != nil {
+		return nil, nil, err
+	}
+	tlsInfo, ok := info.(credentials.TLSInfo)
+	if !ok {
+		return nil, nil, fmt.Errorf("unexpected authentication information %T", info)
+	}
+	return c, peerCredInfo{TLSInfo: tlsInfo, Cred: cred}, nil
+}
+
+// Clone makes a copy of the transport.
+func (t *peerCredTransport) Clone() credentials.TransportCredentials {
+	return &peerCredTransport{TransportCredentials: t.TransportCredentials.Clone()}
+}
+
+// verifyPeer checks that a connection over the unix socket comes from the process of the
+// application, the connections over TCP are accepted.
+func (as *ApplicationState) verifyPeer(ctx context.Context) error {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	info, ok := p.AuthInfo.(peerCredInfo)
+	if !ok {
+		return nil
+	}
+
+	as.peerLock.RLock()
+	expected := as.peer
+	as.peerLock.RUnlock()
+	if expected == nil {
+		return fmt.Errorf("no process registered for the application")
+	}
+	if info.Cred != *expected {
+		return fmt.Errorf("peer pid %d uid %d does not match the application process pid %d uid %d",
+			info.Cred.PID, info.Cred.UID, expected.PID, expected.UID)
+	}
+	return nil
+}
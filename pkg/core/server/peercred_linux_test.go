@@ -0,0 +1,171 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+//go:build linux
+// +build linux
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.elastic.co/apm/apmtest"
+
+	"github.com/elastic/elastic-agent-client/v7/pkg/proto"
+)
+
+func withSocket(t *testing.T) func(*Server) {
+	return func(s *Server) {
+		s.socketPath = filepath.Join(t.TempDir(), "apps.sock")
+	}
+}
+
+func TestServer_SocketCheckIn(t *testing.T) {
+	app := &StubApp{}
+	srv := createAndStartServer(t, &StubHandler{}, withSocket(t))
+	defer srv.Stop()
+	as, err := srv.Register(app, initConfig)
+	require.NoError(t, err)
+	as.SetPeer(os.Getpid(), os.Getuid())
+	assert.Equal(t, "unix://"+srv.socketPath, as.connAddr())
+
+	cImpl := &StubClientImpl{}
+	c := newClientFromApplicationState(t, as, cImpl)
+	require.NoError(t, c.Start(context.Background()))
+	defer c.Stop()
+
+	require.NoError(t, waitFor(func() error {
+		if cImpl.Config() != initConfig {
+			return fmt.Errorf("client never got initial config")
+		}
+		return nil
+	}))
+	require.NoError(t, c.Status(proto.StateObserved_HEALTHY, "Running", nil))
+	assert.NoError(t, waitFor(func() error {
+		if app.Status() != proto.StateObserved_HEALTHY {
+			return fmt.Errorf("server never updated currect application state")
+		}
+		return nil
+	}))
+}
+
+func TestServer_SocketRejectsOtherProcess(t *testing.T) {
+	app := &StubApp{}
+	srv := createAndStartServer(t, &StubHandler{}, withSocket(t))
+	defer srv.Stop()
+	as, err := srv.Register(app, initConfig)
+	require.NoError(t, err)
+	// the connection comes from the test process, not the registered one
+	as.SetPeer(os.Getpid()+1, os.Getuid())
+
+	cImpl := &StubClientImpl{}
+	c := newClientFromApplicationState(t, as, cImpl)
+	require.NoError(t, c.Start(context.Background()))
+	defer c.Stop()
+
+	assert.NoError(t, waitFor(func() error {
+		if cImpl.Error() == nil {
+			return fmt.Errorf("client never got error from the server")
+		}
+		return nil
+	}))
+	assert.Empty(t, cImpl.Config())
+	assert.Equal(t, proto.StateObserved_STARTING, app.Status())
+}
+
+func TestServer_SocketTCPFallback(t *testing.T) {
+	app := &StubApp{}
+	srv := createAndStartServer(t, &StubHandler{}, withSocket(t))
+	defer srv.Stop()
+	as, err := srv.Register(app, initConfig)
+	require.NoError(t, err)
+	// without a process the application connects over TCP
+	assert.Equal(t, srv.getListenAddr(), as.connAddr())
+
+	cImpl := &StubClientImpl{}
+	c := newClientFromApplicationState(t, as, cImpl)
+	require.NoError(t, c.Start(context.Background()))
+	defer c.Stop()
+
+	assert.NoError(t, waitFor(func() error {
+		if cImpl.Config() != initConfig {
+			return fmt.Errorf("client never got initial config")
+		}
+		return nil
+	}))
+}
+
+func TestServer_SocketWithoutTCP(t *testing.T) {
+	// the TCP port of the server is already used
+	busy, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	defer busy.Close()
+
+	srv, err := New(newErrorLogger(t), busy.Addr().String(), &StubHandler{}, apmtest.DiscardTracer)
+	require.NoError(t, err)
+	withSocket(t)(srv)
+	require.NoError(t, srv.Start())
+	defer srv.Stop()
+
+	// an application without a registered process is not accepted on the socket
+	unregistered, err := srv.Register(&StubApp{}, initConfig)
+	require.NoError(t, err)
+	assert.Equal(t, busy.Addr().String(), unregistered.connAddr())
+
+	app := &StubApp{}
+	as, err := srv.Register(app, initConfig)
+	require.NoError(t, err)
+	as.SetPeer(os.Getpid(), os.Getuid())
+	assert.Equal(t, "unix://"+srv.socketPath, as.connAddr())
+
+	cImpl := &StubClientImpl{}
+	c := newClientFromApplicationState(t, as, cImpl)
+	require.NoError(t, c.Start(context.Background()))
+	defer c.Stop()
+
+	assert.NoError(t, waitFor(func() error {
+		if cImpl.Config() != initConfig {
+			return fmt.Errorf("client never got initial config")
+		}
+		return nil
+	}))
+}
+
+func TestListenSocket(t *testing.T) {
+	t.Run("only the user and the group have access", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "run")
+		path := filepath.Join(dir, "apps.sock")
+		lis, err := listenSocket(path)
+		require.NoError(t, err)
+		defer lis.Close()
+
+		fi, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0660), fi.Mode().Perm())
+		fi, err = os.Stat(dir)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0750), fi.Mode().Perm())
+	})
+
+	t.Run("world writable directory", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.Chmod(dir, 0777))
+		_, err := listenSocket(filepath.Join(dir, "apps.sock"))
+		require.Error(t, err)
+	})
+
+	t.Run("path too long", func(t *testing.T) {
+		_, err := listenSocket(filepath.Join(t.TempDir(), strings.Repeat("a", maxSocketPathLen)+".sock"))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,24 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+//go:build !linux
+// +build !linux
+
+package server
+
+import (
+	"errors"
+	"net"
+)
+
+var errPeerCredNotSupported = errors.New("peer credentials of unix sockets are not supported on this platform")
+
+// listenSocket is not supported on this platform, the applications connect over TCP.
+func listenSocket(_ string) (net.Listener, error) {
+	return nil, errPeerCredNotSupported
+}
+
+func peerCredentials(_ *net.UnixConn) (PeerCred, error) {
+	return PeerCred{}, errPeerCredNotSupported
+}
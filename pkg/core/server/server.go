@@ -89,6 +89,10 @@ type ApplicationState struct {
 	actionsLock    sync.RWMutex
 
 	inputTypes map[string]struct{}
+
+	// peer is the process of the application, it connects over the unix socket when set.
+	peer     *PeerCred
+	peerLock sync.RWMutex
 }
 
 // Handler is the used by the server to inform of status changes.
@@ -106,6 +110,8 @@ type Server struct {
 	tracer     *apm.Tracer
 
 	listener     net.Listener
+	socketPath   string
+	socket       net.Listener
 	server       *grpc.Server
 	watchdogDone chan bool
 	watchdogWG   sync.WaitGroup
@@ -141,20 +147,34 @@ func (s *Server) Start() error {
 		return nil
 	}
 
+	if s.socketPath != "" {
+		socket, err := listenSocket(s.socketPath)
+		if err != nil {
+			s.logger.Warnf("failed to listen on unix socket %s, applications connect over TCP: %v", s.socketPath, err)
+		} else {
+			s.socket = socket
+		}
+	}
 	lis, err := net.Listen("tcp", s.listenAddr)
 	if err != nil {
-		return err
+		if s.socket == nil {
+			return err
+		}
+		// the spawned applications connect over the socket, only the services need TCP
+		s.logger.Warnf("failed to listen on %s, only applications registered with their process are served over the unix socket: %v", s.listenAddr, err)
+	} else {
+		s.listener = lis
 	}
-	s.listener = lis
 	certPool := x509.NewCertPool()
 	if ok := certPool.AppendCertsFromPEM(s.ca.Crt()); !ok {
+		s.closeListeners()
 		return errors.New("failed to append root CA", errors.TypeSecurity)
 	}
-	creds := credentials.NewTLS(&tls.Config{
+	creds := &peerCredTransport{credentials.NewTLS(&tls.Config{
 		ClientAuth:     tls.RequireAndVerifyClientCert,
 		ClientCAs:      certPool,
 		GetCertificate: s.getCertificate,
-	})
+	})}
 	if s.tracer != nil {
 		apmInterceptor := apmgrpc.NewUnaryServerInterceptor(apmgrpc.WithRecovery(), apmgrpc.WithTracer(s.tracer))
 		s.server = grpc.NewServer(
@@ -167,12 +187,17 @@ func (s *Server) Start() error {
 	proto.RegisterElasticAgentServer(s.server, s)
 
 	// start serving GRPC connections
-	go func() {
-		err := s.server.Serve(lis)
-		if err != nil {
-			s.logger.Errorf("error listening for GRPC: %s", err)
+	for _, l := range []net.Listener{s.listener, s.socket} {
+		if l == nil {
+			continue
 		}
-	}()
+		go func(l net.Listener) {
+			err := s.server.Serve(l)
+			if err != nil {
+				s.logger.Errorf("error listening for GRPC: %s", err)
+			}
+		}(l)
+	}
 
 	// start the watchdog
 	s.watchdogDone = make(chan bool)
@@ -189,10 +214,22 @@ func (s *Server) Stop() {
 		s.server.Stop()
 		s.server = nil
 		s.listener = nil
+		s.socket = nil
 		s.watchdogWG.Wait()
 	}
 }
 
+// closeListeners closes the listeners when the server fails to start.
+func (s *Server) closeListeners() {
+	for _, l := range []net.Listener{s.listener, s.socket} {
+		if l != nil {
+			_ = l.Close()
+		}
+	}
+	s.listener = nil
+	s.socket = nil
+}
+
 // Get returns the application state from the server for the passed application.
 func (s *Server) Get(app interface{}) (*ApplicationState, bool) {
 	var foundState *ApplicationState
@@ -304,6 +341,10 @@ func (s *Server) Checkin(server proto.ElasticAgent_CheckinServer) error {
 		s.logger.Debug("check-in stream sent an invalid token; closing connection")
 		return status.Error(codes.PermissionDenied, "invalid token")
 	}
+	if err := appState.verifyPeer(server.Context()); err != nil {
+		s.logger.Warnf("check-in stream rejected: %v; closing connection", err)
+		return status.Error(codes.PermissionDenied, "invalid peer")
+	}
 	appState.checkinLock.Lock()
 	if appState.checkinDone != nil {
 		// application is already connected (cannot have multiple); close connection
@@ -425,6 +466,10 @@ func (s *Server) Actions(server proto.ElasticAgent_ActionsServer) error {
 		s.logger.Debug("actions stream sent an invalid token; closing connection")
 		return status.Error(codes.PermissionDenied, "invalid token")
 	}
+	if err := appState.verifyPeer(server.Context()); err != nil {
+		s.logger.Warnf("actions stream rejected: %v; closing connection", err)
+		return status.Error(codes.PermissionDenied, "invalid peer")
+	}
 	appState.actionsLock.Lock()
 	if appState.actionsDone != nil {
 		// application is already connected (cannot have multiple); close connection
@@ -541,7 +586,7 @@ func (s *Server) Actions(server proto.ElasticAgent_ActionsServer) error {
 // Note: If the writer implements io.Closer the writer is also closed.
 func (as *ApplicationState) WriteConnInfo(w io.Writer) error {
 	connInfo := &proto.ConnInfo{
-		Addr:       as.srv.getListenAddr(),
+		Addr:       as.connAddr(),
 		ServerName: as.srvName,
 		Token:      as.token,
 		CaCert:     as.srv.ca.Crt(),
@@ -563,6 +608,27 @@ func (as *ApplicationState) WriteConnInfo(w io.Writer) error {
 	return nil
 }
 
+// SetPeer sets the process of the application, the application is then given the address of the
+// unix socket when the server listens on one and only this process is accepted on the socket.
+func (as *ApplicationState) SetPeer(pid, uid int) {
+	as.peerLock.Lock()
+	defer as.peerLock.Unlock()
+	as.peer = &PeerCred{PID: pid, UID: uid}
+}
+
+// connAddr returns the address the application connects to, the unix socket when the process of
+// the application is known. The socket only accepts registered processes, the other applications
+// are given the TCP address even when the server failed to listen on it.
+func (as *ApplicationState) connAddr() string {
+	as.peerLock.RLock()
+	hasPeer := as.peer != nil
+	as.peerLock.RUnlock()
+	if as.srv.socket != nil && hasPeer {
+		return "unix://" + as.srv.socketPath
+	}
+	return as.srv.getListenAddr()
+}
+
 // Stop instructs the application to stop gracefully within the timeout.
 //
 // Once the application is stopped or the timeout is reached the application is destroyed. Even in the case
@@ -950,7 +1016,7 @@ func (s *Server) getCertificate(chi *tls.ClientHelloInfo) (*tls.Certificate, err
 // getListenAddr returns the listening address of the server.
 func (s *Server) getListenAddr() string {
 	addr := strings.SplitN(s.listenAddr, ":", 2)
-	if len(addr) == 2 && addr[1] == "0" {
+	if len(addr) == 2 && addr[1] == "0" && s.listener != nil {
 		port := s.listener.Addr().(*net.TCPAddr).Port
 		return fmt.Sprintf("%s:%d", addr[0], port)
 	}